@@ -9,6 +9,7 @@ package api
 import (
 	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
 	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	fieldmaskpb "google.golang.org/protobuf/types/known/fieldmaskpb"
 	reflect "reflect"
 	sync "sync"
 	unsafe "unsafe"
@@ -30,6 +31,9 @@ type RegisterAgentRequest struct {
 	MetricsTargets []string               `protobuf:"bytes,5,rep,name=metrics_targets,json=metricsTargets,proto3" json:"metrics_targets,omitempty"`
 	Profiles       bool                   `protobuf:"varint,6,opt,name=profiles,proto3" json:"profiles,omitempty"`
 	Node           string                 `protobuf:"bytes,7,opt,name=node,proto3" json:"node,omitempty"`
+	Ephemeral      bool                   `protobuf:"varint,8,opt,name=ephemeral,proto3" json:"ephemeral,omitempty"`
+	Tenant         string                 `protobuf:"bytes,9,opt,name=tenant,proto3" json:"tenant,omitempty"`
+	RateLimits     map[string]*RateLimit  `protobuf:"bytes,10,rep,name=rate_limits,json=rateLimits,proto3" json:"rate_limits,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"bytes,2,opt,name=value"`
 	unknownFields  protoimpl.UnknownFields
 	sizeCache      protoimpl.SizeCache
 }
@@ -113,6 +117,79 @@ func (x *RegisterAgentRequest) GetNode() string {
 	return ""
 }
 
+func (x *RegisterAgentRequest) GetEphemeral() bool {
+	if x != nil {
+		return x.Ephemeral
+	}
+	return false
+}
+
+func (x *RegisterAgentRequest) GetTenant() string {
+	if x != nil {
+		return x.Tenant
+	}
+	return ""
+}
+
+func (x *RegisterAgentRequest) GetRateLimits() map[string]*RateLimit {
+	if x != nil {
+		return x.RateLimits
+	}
+	return nil
+}
+
+type RateLimit struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Rate          float64                `protobuf:"fixed64,1,opt,name=rate,proto3" json:"rate,omitempty"`
+	Burst         int32                  `protobuf:"varint,2,opt,name=burst,proto3" json:"burst,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *RateLimit) Reset() {
+	*x = RateLimit{}
+	mi := &file_api_api_proto_msgTypes[1]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *RateLimit) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*RateLimit) ProtoMessage() {}
+
+func (x *RateLimit) ProtoReflect() protoreflect.Message {
+	mi := &file_api_api_proto_msgTypes[1]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use RateLimit.ProtoReflect.Descriptor instead.
+func (*RateLimit) Descriptor() ([]byte, []int) {
+	return file_api_api_proto_rawDescGZIP(), []int{1}
+}
+
+func (x *RateLimit) GetRate() float64 {
+	if x != nil {
+		return x.Rate
+	}
+	return 0
+}
+
+func (x *RateLimit) GetBurst() int32 {
+	if x != nil {
+		return x.Burst
+	}
+	return 0
+}
+
 type RegisterAgentResponse struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
 	Rid           string                 `protobuf:"bytes,1,opt,name=rid,proto3" json:"rid,omitempty"`
@@ -122,7 +199,7 @@ type RegisterAgentResponse struct {
 
 func (x *RegisterAgentResponse) Reset() {
 	*x = RegisterAgentResponse{}
-	mi := &file_api_api_proto_msgTypes[1]
+	mi := &file_api_api_proto_msgTypes[2]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -134,7 +211,7 @@ func (x *RegisterAgentResponse) String() string {
 func (*RegisterAgentResponse) ProtoMessage() {}
 
 func (x *RegisterAgentResponse) ProtoReflect() protoreflect.Message {
-	mi := &file_api_api_proto_msgTypes[1]
+	mi := &file_api_api_proto_msgTypes[2]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -147,7 +224,7 @@ func (x *RegisterAgentResponse) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use RegisterAgentResponse.ProtoReflect.Descriptor instead.
 func (*RegisterAgentResponse) Descriptor() ([]byte, []int) {
-	return file_api_api_proto_rawDescGZIP(), []int{1}
+	return file_api_api_proto_rawDescGZIP(), []int{2}
 }
 
 func (x *RegisterAgentResponse) GetRid() string {
@@ -158,15 +235,16 @@ func (x *RegisterAgentResponse) GetRid() string {
 }
 
 type DeregisterAgentRequest struct {
-	state         protoimpl.MessageState `protogen:"open.v1"`
-	Rid           string                 `protobuf:"bytes,1,opt,name=rid,proto3" json:"rid,omitempty"`
-	unknownFields protoimpl.UnknownFields
-	sizeCache     protoimpl.SizeCache
+	state           protoimpl.MessageState `protogen:"open.v1"`
+	Rid             string                 `protobuf:"bytes,1,opt,name=rid,proto3" json:"rid,omitempty"`
+	ResourceVersion *uint64                `protobuf:"varint,2,opt,name=resource_version,json=resourceVersion,proto3,oneof" json:"resource_version,omitempty"`
+	unknownFields   protoimpl.UnknownFields
+	sizeCache       protoimpl.SizeCache
 }
 
 func (x *DeregisterAgentRequest) Reset() {
 	*x = DeregisterAgentRequest{}
-	mi := &file_api_api_proto_msgTypes[2]
+	mi := &file_api_api_proto_msgTypes[3]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -178,7 +256,7 @@ func (x *DeregisterAgentRequest) String() string {
 func (*DeregisterAgentRequest) ProtoMessage() {}
 
 func (x *DeregisterAgentRequest) ProtoReflect() protoreflect.Message {
-	mi := &file_api_api_proto_msgTypes[2]
+	mi := &file_api_api_proto_msgTypes[3]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -191,7 +269,7 @@ func (x *DeregisterAgentRequest) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use DeregisterAgentRequest.ProtoReflect.Descriptor instead.
 func (*DeregisterAgentRequest) Descriptor() ([]byte, []int) {
-	return file_api_api_proto_rawDescGZIP(), []int{2}
+	return file_api_api_proto_rawDescGZIP(), []int{3}
 }
 
 func (x *DeregisterAgentRequest) GetRid() string {
@@ -201,6 +279,13 @@ func (x *DeregisterAgentRequest) GetRid() string {
 	return ""
 }
 
+func (x *DeregisterAgentRequest) GetResourceVersion() uint64 {
+	if x != nil && x.ResourceVersion != nil {
+		return *x.ResourceVersion
+	}
+	return 0
+}
+
 type DeregisterAgentResponse struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
 	unknownFields protoimpl.UnknownFields
@@ -209,7 +294,7 @@ type DeregisterAgentResponse struct {
 
 func (x *DeregisterAgentResponse) Reset() {
 	*x = DeregisterAgentResponse{}
-	mi := &file_api_api_proto_msgTypes[3]
+	mi := &file_api_api_proto_msgTypes[4]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -221,7 +306,7 @@ func (x *DeregisterAgentResponse) String() string {
 func (*DeregisterAgentResponse) ProtoMessage() {}
 
 func (x *DeregisterAgentResponse) ProtoReflect() protoreflect.Message {
-	mi := &file_api_api_proto_msgTypes[3]
+	mi := &file_api_api_proto_msgTypes[4]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -234,7 +319,7 @@ func (x *DeregisterAgentResponse) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use DeregisterAgentResponse.ProtoReflect.Descriptor instead.
 func (*DeregisterAgentResponse) Descriptor() ([]byte, []int) {
-	return file_api_api_proto_rawDescGZIP(), []int{3}
+	return file_api_api_proto_rawDescGZIP(), []int{4}
 }
 
 type GetAgentRequest struct {
@@ -246,7 +331,7 @@ type GetAgentRequest struct {
 
 func (x *GetAgentRequest) Reset() {
 	*x = GetAgentRequest{}
-	mi := &file_api_api_proto_msgTypes[4]
+	mi := &file_api_api_proto_msgTypes[5]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -258,7 +343,7 @@ func (x *GetAgentRequest) String() string {
 func (*GetAgentRequest) ProtoMessage() {}
 
 func (x *GetAgentRequest) ProtoReflect() protoreflect.Message {
-	mi := &file_api_api_proto_msgTypes[4]
+	mi := &file_api_api_proto_msgTypes[5]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -271,7 +356,7 @@ func (x *GetAgentRequest) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use GetAgentRequest.ProtoReflect.Descriptor instead.
 func (*GetAgentRequest) Descriptor() ([]byte, []int) {
-	return file_api_api_proto_rawDescGZIP(), []int{4}
+	return file_api_api_proto_rawDescGZIP(), []int{5}
 }
 
 func (x *GetAgentRequest) GetRid() string {
@@ -282,23 +367,32 @@ func (x *GetAgentRequest) GetRid() string {
 }
 
 type GetAgentResponse struct {
-	state          protoimpl.MessageState `protogen:"open.v1"`
-	ResourceId     string                 `protobuf:"bytes,1,opt,name=resource_id,json=resourceId,proto3" json:"resource_id,omitempty"`
-	Hostname       string                 `protobuf:"bytes,2,opt,name=hostname,proto3" json:"hostname,omitempty"`
-	Labels         []string               `protobuf:"bytes,3,rep,name=labels,proto3" json:"labels,omitempty"`
-	LogSources     []string               `protobuf:"bytes,4,rep,name=log_sources,json=logSources,proto3" json:"log_sources,omitempty"`
-	Metrics        bool                   `protobuf:"varint,5,opt,name=metrics,proto3" json:"metrics,omitempty"`
-	MetricsTargets []string               `protobuf:"bytes,6,rep,name=metrics_targets,json=metricsTargets,proto3" json:"metrics_targets,omitempty"`
-	Profiles       bool                   `protobuf:"varint,7,opt,name=profiles,proto3" json:"profiles,omitempty"`
-	CreatedAt      string                 `protobuf:"bytes,8,opt,name=created_at,json=createdAt,proto3" json:"created_at,omitempty"`
-	Node           string                 `protobuf:"bytes,9,opt,name=node,proto3" json:"node,omitempty"`
-	unknownFields  protoimpl.UnknownFields
-	sizeCache      protoimpl.SizeCache
+	state           protoimpl.MessageState `protogen:"open.v1"`
+	ResourceId      string                 `protobuf:"bytes,1,opt,name=resource_id,json=resourceId,proto3" json:"resource_id,omitempty"`
+	Hostname        string                 `protobuf:"bytes,2,opt,name=hostname,proto3" json:"hostname,omitempty"`
+	Labels          []string               `protobuf:"bytes,3,rep,name=labels,proto3" json:"labels,omitempty"`
+	LogSources      []string               `protobuf:"bytes,4,rep,name=log_sources,json=logSources,proto3" json:"log_sources,omitempty"`
+	Metrics         bool                   `protobuf:"varint,5,opt,name=metrics,proto3" json:"metrics,omitempty"`
+	MetricsTargets  []string               `protobuf:"bytes,6,rep,name=metrics_targets,json=metricsTargets,proto3" json:"metrics_targets,omitempty"`
+	Profiles        bool                   `protobuf:"varint,7,opt,name=profiles,proto3" json:"profiles,omitempty"`
+	CreatedAt       string                 `protobuf:"bytes,8,opt,name=created_at,json=createdAt,proto3" json:"created_at,omitempty"`
+	Node            string                 `protobuf:"bytes,9,opt,name=node,proto3" json:"node,omitempty"`
+	LastSeen        string                 `protobuf:"bytes,10,opt,name=last_seen,json=lastSeen,proto3" json:"last_seen,omitempty"`
+	Active          bool                   `protobuf:"varint,11,opt,name=active,proto3" json:"active,omitempty"`
+	TokensRevoked   string                 `protobuf:"bytes,12,opt,name=tokens_revoked,json=tokensRevoked,proto3" json:"tokens_revoked,omitempty"`
+	Stale           bool                   `protobuf:"varint,13,opt,name=stale,proto3" json:"stale,omitempty"`
+	Ephemeral       bool                   `protobuf:"varint,14,opt,name=ephemeral,proto3" json:"ephemeral,omitempty"`
+	ResourceVersion uint64                 `protobuf:"varint,15,opt,name=resource_version,json=resourceVersion,proto3" json:"resource_version,omitempty"`
+	Collectors      []*CollectorItem       `protobuf:"bytes,16,rep,name=collectors,proto3" json:"collectors,omitempty"`
+	Tenant          string                 `protobuf:"bytes,17,opt,name=tenant,proto3" json:"tenant,omitempty"`
+	RateLimits      map[string]*RateLimit  `protobuf:"bytes,18,rep,name=rate_limits,json=rateLimits,proto3" json:"rate_limits,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"bytes,2,opt,name=value"`
+	unknownFields   protoimpl.UnknownFields
+	sizeCache       protoimpl.SizeCache
 }
 
 func (x *GetAgentResponse) Reset() {
 	*x = GetAgentResponse{}
-	mi := &file_api_api_proto_msgTypes[5]
+	mi := &file_api_api_proto_msgTypes[6]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -310,7 +404,7 @@ func (x *GetAgentResponse) String() string {
 func (*GetAgentResponse) ProtoMessage() {}
 
 func (x *GetAgentResponse) ProtoReflect() protoreflect.Message {
-	mi := &file_api_api_proto_msgTypes[5]
+	mi := &file_api_api_proto_msgTypes[6]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -323,7 +417,7 @@ func (x *GetAgentResponse) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use GetAgentResponse.ProtoReflect.Descriptor instead.
 func (*GetAgentResponse) Descriptor() ([]byte, []int) {
-	return file_api_api_proto_rawDescGZIP(), []int{5}
+	return file_api_api_proto_rawDescGZIP(), []int{6}
 }
 
 func (x *GetAgentResponse) GetResourceId() string {
@@ -389,64 +483,94 @@ func (x *GetAgentResponse) GetNode() string {
 	return ""
 }
 
-type ListAgentsRequest struct {
-	state         protoimpl.MessageState `protogen:"open.v1"`
-	unknownFields protoimpl.UnknownFields
-	sizeCache     protoimpl.SizeCache
+func (x *GetAgentResponse) GetLastSeen() string {
+	if x != nil {
+		return x.LastSeen
+	}
+	return ""
 }
 
-func (x *ListAgentsRequest) Reset() {
-	*x = ListAgentsRequest{}
-	mi := &file_api_api_proto_msgTypes[6]
-	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
-	ms.StoreMessageInfo(mi)
+func (x *GetAgentResponse) GetActive() bool {
+	if x != nil {
+		return x.Active
+	}
+	return false
 }
 
-func (x *ListAgentsRequest) String() string {
-	return protoimpl.X.MessageStringOf(x)
+func (x *GetAgentResponse) GetTokensRevoked() string {
+	if x != nil {
+		return x.TokensRevoked
+	}
+	return ""
 }
 
-func (*ListAgentsRequest) ProtoMessage() {}
+func (x *GetAgentResponse) GetStale() bool {
+	if x != nil {
+		return x.Stale
+	}
+	return false
+}
 
-func (x *ListAgentsRequest) ProtoReflect() protoreflect.Message {
-	mi := &file_api_api_proto_msgTypes[6]
+func (x *GetAgentResponse) GetEphemeral() bool {
 	if x != nil {
-		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
-		if ms.LoadMessageInfo() == nil {
-			ms.StoreMessageInfo(mi)
-		}
-		return ms
+		return x.Ephemeral
 	}
-	return mi.MessageOf(x)
+	return false
 }
 
-// Deprecated: Use ListAgentsRequest.ProtoReflect.Descriptor instead.
-func (*ListAgentsRequest) Descriptor() ([]byte, []int) {
-	return file_api_api_proto_rawDescGZIP(), []int{6}
+func (x *GetAgentResponse) GetResourceVersion() uint64 {
+	if x != nil {
+		return x.ResourceVersion
+	}
+	return 0
 }
 
-type AgentListItem struct {
+func (x *GetAgentResponse) GetCollectors() []*CollectorItem {
+	if x != nil {
+		return x.Collectors
+	}
+	return nil
+}
+
+func (x *GetAgentResponse) GetTenant() string {
+	if x != nil {
+		return x.Tenant
+	}
+	return ""
+}
+
+func (x *GetAgentResponse) GetRateLimits() map[string]*RateLimit {
+	if x != nil {
+		return x.RateLimits
+	}
+	return nil
+}
+
+type CollectorItem struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
-	Rid           string                 `protobuf:"bytes,1,opt,name=rid,proto3" json:"rid,omitempty"`
-	Hostname      string                 `protobuf:"bytes,2,opt,name=hostname,proto3" json:"hostname,omitempty"`
+	Id            string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	Name          string                 `protobuf:"bytes,2,opt,name=name,proto3" json:"name,omitempty"`
+	Attributes    map[string]string      `protobuf:"bytes,3,rep,name=attributes,proto3" json:"attributes,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"bytes,2,opt,name=value"`
+	ConfigHash    string                 `protobuf:"bytes,4,opt,name=config_hash,json=configHash,proto3" json:"config_hash,omitempty"`
+	LastPoll      string                 `protobuf:"bytes,5,opt,name=last_poll,json=lastPoll,proto3" json:"last_poll,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
-func (x *AgentListItem) Reset() {
-	*x = AgentListItem{}
+func (x *CollectorItem) Reset() {
+	*x = CollectorItem{}
 	mi := &file_api_api_proto_msgTypes[7]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *AgentListItem) String() string {
+func (x *CollectorItem) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*AgentListItem) ProtoMessage() {}
+func (*CollectorItem) ProtoMessage() {}
 
-func (x *AgentListItem) ProtoReflect() protoreflect.Message {
+func (x *CollectorItem) ProtoReflect() protoreflect.Message {
 	mi := &file_api_api_proto_msgTypes[7]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
@@ -458,46 +582,77 @@ func (x *AgentListItem) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use AgentListItem.ProtoReflect.Descriptor instead.
-func (*AgentListItem) Descriptor() ([]byte, []int) {
+// Deprecated: Use CollectorItem.ProtoReflect.Descriptor instead.
+func (*CollectorItem) Descriptor() ([]byte, []int) {
 	return file_api_api_proto_rawDescGZIP(), []int{7}
 }
 
-func (x *AgentListItem) GetRid() string {
+func (x *CollectorItem) GetId() string {
 	if x != nil {
-		return x.Rid
+		return x.Id
 	}
 	return ""
 }
 
-func (x *AgentListItem) GetHostname() string {
+func (x *CollectorItem) GetName() string {
 	if x != nil {
-		return x.Hostname
+		return x.Name
 	}
 	return ""
 }
 
-type ListAgentsResponse struct {
-	state         protoimpl.MessageState `protogen:"open.v1"`
-	Agents        []*AgentListItem       `protobuf:"bytes,1,rep,name=agents,proto3" json:"agents,omitempty"`
-	unknownFields protoimpl.UnknownFields
-	sizeCache     protoimpl.SizeCache
+func (x *CollectorItem) GetAttributes() map[string]string {
+	if x != nil {
+		return x.Attributes
+	}
+	return nil
 }
 
-func (x *ListAgentsResponse) Reset() {
-	*x = ListAgentsResponse{}
+func (x *CollectorItem) GetConfigHash() string {
+	if x != nil {
+		return x.ConfigHash
+	}
+	return ""
+}
+
+func (x *CollectorItem) GetLastPoll() string {
+	if x != nil {
+		return x.LastPoll
+	}
+	return ""
+}
+
+type ListAgentsRequest struct {
+	state           protoimpl.MessageState `protogen:"open.v1"`
+	LabelSelector   string                 `protobuf:"bytes,1,opt,name=label_selector,json=labelSelector,proto3" json:"label_selector,omitempty"`
+	Node            *string                `protobuf:"bytes,2,opt,name=node,proto3,oneof" json:"node,omitempty"`
+	Metrics         *bool                  `protobuf:"varint,3,opt,name=metrics,proto3,oneof" json:"metrics,omitempty"`
+	Profiles        *bool                  `protobuf:"varint,4,opt,name=profiles,proto3,oneof" json:"profiles,omitempty"`
+	Active          *bool                  `protobuf:"varint,5,opt,name=active,proto3,oneof" json:"active,omitempty"`
+	Stale           *bool                  `protobuf:"varint,6,opt,name=stale,proto3,oneof" json:"stale,omitempty"`
+	LogSourceScheme *string                `protobuf:"bytes,7,opt,name=log_source_scheme,json=logSourceScheme,proto3,oneof" json:"log_source_scheme,omitempty"`
+	PageSize        int32                  `protobuf:"varint,8,opt,name=page_size,json=pageSize,proto3" json:"page_size,omitempty"`
+	PageToken       string                 `protobuf:"bytes,9,opt,name=page_token,json=pageToken,proto3" json:"page_token,omitempty"`
+	FieldMask       *fieldmaskpb.FieldMask `protobuf:"bytes,10,opt,name=field_mask,json=fieldMask,proto3" json:"field_mask,omitempty"`
+	Tenant          *string                `protobuf:"bytes,11,opt,name=tenant,proto3,oneof" json:"tenant,omitempty"`
+	unknownFields   protoimpl.UnknownFields
+	sizeCache       protoimpl.SizeCache
+}
+
+func (x *ListAgentsRequest) Reset() {
+	*x = ListAgentsRequest{}
 	mi := &file_api_api_proto_msgTypes[8]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *ListAgentsResponse) String() string {
+func (x *ListAgentsRequest) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*ListAgentsResponse) ProtoMessage() {}
+func (*ListAgentsRequest) ProtoMessage() {}
 
-func (x *ListAgentsResponse) ProtoReflect() protoreflect.Message {
+func (x *ListAgentsRequest) ProtoReflect() protoreflect.Message {
 	mi := &file_api_api_proto_msgTypes[8]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
@@ -509,72 +664,358 @@ func (x *ListAgentsResponse) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use ListAgentsResponse.ProtoReflect.Descriptor instead.
-func (*ListAgentsResponse) Descriptor() ([]byte, []int) {
+// Deprecated: Use ListAgentsRequest.ProtoReflect.Descriptor instead.
+func (*ListAgentsRequest) Descriptor() ([]byte, []int) {
 	return file_api_api_proto_rawDescGZIP(), []int{8}
 }
 
-func (x *ListAgentsResponse) GetAgents() []*AgentListItem {
+func (x *ListAgentsRequest) GetLabelSelector() string {
 	if x != nil {
-		return x.Agents
+		return x.LabelSelector
 	}
-	return nil
+	return ""
 }
 
-type GetAgentConfigRequest struct {
-	state         protoimpl.MessageState `protogen:"open.v1"`
-	Rid           string                 `protobuf:"bytes,1,opt,name=rid,proto3" json:"rid,omitempty"`
-	unknownFields protoimpl.UnknownFields
-	sizeCache     protoimpl.SizeCache
+func (x *ListAgentsRequest) GetNode() string {
+	if x != nil && x.Node != nil {
+		return *x.Node
+	}
+	return ""
 }
 
-func (x *GetAgentConfigRequest) Reset() {
-	*x = GetAgentConfigRequest{}
-	mi := &file_api_api_proto_msgTypes[9]
-	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
-	ms.StoreMessageInfo(mi)
+func (x *ListAgentsRequest) GetMetrics() bool {
+	if x != nil && x.Metrics != nil {
+		return *x.Metrics
+	}
+	return false
 }
 
-func (x *GetAgentConfigRequest) String() string {
-	return protoimpl.X.MessageStringOf(x)
+func (x *ListAgentsRequest) GetProfiles() bool {
+	if x != nil && x.Profiles != nil {
+		return *x.Profiles
+	}
+	return false
 }
 
-func (*GetAgentConfigRequest) ProtoMessage() {}
+func (x *ListAgentsRequest) GetActive() bool {
+	if x != nil && x.Active != nil {
+		return *x.Active
+	}
+	return false
+}
 
-func (x *GetAgentConfigRequest) ProtoReflect() protoreflect.Message {
-	mi := &file_api_api_proto_msgTypes[9]
-	if x != nil {
-		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
-		if ms.LoadMessageInfo() == nil {
-			ms.StoreMessageInfo(mi)
-		}
-		return ms
+func (x *ListAgentsRequest) GetStale() bool {
+	if x != nil && x.Stale != nil {
+		return *x.Stale
 	}
-	return mi.MessageOf(x)
+	return false
 }
 
-// Deprecated: Use GetAgentConfigRequest.ProtoReflect.Descriptor instead.
-func (*GetAgentConfigRequest) Descriptor() ([]byte, []int) {
-	return file_api_api_proto_rawDescGZIP(), []int{9}
+func (x *ListAgentsRequest) GetLogSourceScheme() string {
+	if x != nil && x.LogSourceScheme != nil {
+		return *x.LogSourceScheme
+	}
+	return ""
 }
 
-func (x *GetAgentConfigRequest) GetRid() string {
+func (x *ListAgentsRequest) GetPageSize() int32 {
 	if x != nil {
-		return x.Rid
+		return x.PageSize
+	}
+	return 0
+}
+
+func (x *ListAgentsRequest) GetPageToken() string {
+	if x != nil {
+		return x.PageToken
 	}
 	return ""
 }
 
-type GetAgentConfigResponse struct {
-	state         protoimpl.MessageState `protogen:"open.v1"`
-	Config        []byte                 `protobuf:"bytes,1,opt,name=config,proto3" json:"config,omitempty"`
-	unknownFields protoimpl.UnknownFields
-	sizeCache     protoimpl.SizeCache
+func (x *ListAgentsRequest) GetFieldMask() *fieldmaskpb.FieldMask {
+	if x != nil {
+		return x.FieldMask
+	}
+	return nil
+}
+
+func (x *ListAgentsRequest) GetTenant() string {
+	if x != nil && x.Tenant != nil {
+		return *x.Tenant
+	}
+	return ""
+}
+
+type AgentListItem struct {
+	state           protoimpl.MessageState `protogen:"open.v1"`
+	Rid             string                 `protobuf:"bytes,1,opt,name=rid,proto3" json:"rid,omitempty"`
+	Hostname        string                 `protobuf:"bytes,2,opt,name=hostname,proto3" json:"hostname,omitempty"`
+	LastSeen        string                 `protobuf:"bytes,3,opt,name=last_seen,json=lastSeen,proto3" json:"last_seen,omitempty"`
+	Active          bool                   `protobuf:"varint,4,opt,name=active,proto3" json:"active,omitempty"`
+	Stale           bool                   `protobuf:"varint,5,opt,name=stale,proto3" json:"stale,omitempty"`
+	Labels          []string               `protobuf:"bytes,6,rep,name=labels,proto3" json:"labels,omitempty"`
+	LogSources      []string               `protobuf:"bytes,7,rep,name=log_sources,json=logSources,proto3" json:"log_sources,omitempty"`
+	Metrics         bool                   `protobuf:"varint,8,opt,name=metrics,proto3" json:"metrics,omitempty"`
+	MetricsTargets  []string               `protobuf:"bytes,9,rep,name=metrics_targets,json=metricsTargets,proto3" json:"metrics_targets,omitempty"`
+	Profiles        bool                   `protobuf:"varint,10,opt,name=profiles,proto3" json:"profiles,omitempty"`
+	CreatedAt       string                 `protobuf:"bytes,11,opt,name=created_at,json=createdAt,proto3" json:"created_at,omitempty"`
+	Node            string                 `protobuf:"bytes,12,opt,name=node,proto3" json:"node,omitempty"`
+	TokensRevoked   string                 `protobuf:"bytes,13,opt,name=tokens_revoked,json=tokensRevoked,proto3" json:"tokens_revoked,omitempty"`
+	Ephemeral       bool                   `protobuf:"varint,14,opt,name=ephemeral,proto3" json:"ephemeral,omitempty"`
+	ResourceVersion uint64                 `protobuf:"varint,15,opt,name=resource_version,json=resourceVersion,proto3" json:"resource_version,omitempty"`
+	Tenant          string                 `protobuf:"bytes,16,opt,name=tenant,proto3" json:"tenant,omitempty"`
+	unknownFields   protoimpl.UnknownFields
+	sizeCache       protoimpl.SizeCache
+}
+
+func (x *AgentListItem) Reset() {
+	*x = AgentListItem{}
+	mi := &file_api_api_proto_msgTypes[9]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *AgentListItem) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*AgentListItem) ProtoMessage() {}
+
+func (x *AgentListItem) ProtoReflect() protoreflect.Message {
+	mi := &file_api_api_proto_msgTypes[9]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use AgentListItem.ProtoReflect.Descriptor instead.
+func (*AgentListItem) Descriptor() ([]byte, []int) {
+	return file_api_api_proto_rawDescGZIP(), []int{9}
+}
+
+func (x *AgentListItem) GetRid() string {
+	if x != nil {
+		return x.Rid
+	}
+	return ""
+}
+
+func (x *AgentListItem) GetHostname() string {
+	if x != nil {
+		return x.Hostname
+	}
+	return ""
+}
+
+func (x *AgentListItem) GetLastSeen() string {
+	if x != nil {
+		return x.LastSeen
+	}
+	return ""
+}
+
+func (x *AgentListItem) GetActive() bool {
+	if x != nil {
+		return x.Active
+	}
+	return false
+}
+
+func (x *AgentListItem) GetStale() bool {
+	if x != nil {
+		return x.Stale
+	}
+	return false
+}
+
+func (x *AgentListItem) GetLabels() []string {
+	if x != nil {
+		return x.Labels
+	}
+	return nil
+}
+
+func (x *AgentListItem) GetLogSources() []string {
+	if x != nil {
+		return x.LogSources
+	}
+	return nil
+}
+
+func (x *AgentListItem) GetMetrics() bool {
+	if x != nil {
+		return x.Metrics
+	}
+	return false
+}
+
+func (x *AgentListItem) GetMetricsTargets() []string {
+	if x != nil {
+		return x.MetricsTargets
+	}
+	return nil
+}
+
+func (x *AgentListItem) GetProfiles() bool {
+	if x != nil {
+		return x.Profiles
+	}
+	return false
+}
+
+func (x *AgentListItem) GetCreatedAt() string {
+	if x != nil {
+		return x.CreatedAt
+	}
+	return ""
+}
+
+func (x *AgentListItem) GetNode() string {
+	if x != nil {
+		return x.Node
+	}
+	return ""
+}
+
+func (x *AgentListItem) GetTokensRevoked() string {
+	if x != nil {
+		return x.TokensRevoked
+	}
+	return ""
+}
+
+func (x *AgentListItem) GetEphemeral() bool {
+	if x != nil {
+		return x.Ephemeral
+	}
+	return false
+}
+
+func (x *AgentListItem) GetResourceVersion() uint64 {
+	if x != nil {
+		return x.ResourceVersion
+	}
+	return 0
+}
+
+func (x *AgentListItem) GetTenant() string {
+	if x != nil {
+		return x.Tenant
+	}
+	return ""
+}
+
+type ListAgentsResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Agents        []*AgentListItem       `protobuf:"bytes,1,rep,name=agents,proto3" json:"agents,omitempty"`
+	NextPageToken string                 `protobuf:"bytes,2,opt,name=next_page_token,json=nextPageToken,proto3" json:"next_page_token,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListAgentsResponse) Reset() {
+	*x = ListAgentsResponse{}
+	mi := &file_api_api_proto_msgTypes[10]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListAgentsResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListAgentsResponse) ProtoMessage() {}
+
+func (x *ListAgentsResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_api_api_proto_msgTypes[10]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListAgentsResponse.ProtoReflect.Descriptor instead.
+func (*ListAgentsResponse) Descriptor() ([]byte, []int) {
+	return file_api_api_proto_rawDescGZIP(), []int{10}
+}
+
+func (x *ListAgentsResponse) GetAgents() []*AgentListItem {
+	if x != nil {
+		return x.Agents
+	}
+	return nil
+}
+
+func (x *ListAgentsResponse) GetNextPageToken() string {
+	if x != nil {
+		return x.NextPageToken
+	}
+	return ""
+}
+
+type GetAgentConfigRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Rid           string                 `protobuf:"bytes,1,opt,name=rid,proto3" json:"rid,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetAgentConfigRequest) Reset() {
+	*x = GetAgentConfigRequest{}
+	mi := &file_api_api_proto_msgTypes[11]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetAgentConfigRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetAgentConfigRequest) ProtoMessage() {}
+
+func (x *GetAgentConfigRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_api_api_proto_msgTypes[11]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetAgentConfigRequest.ProtoReflect.Descriptor instead.
+func (*GetAgentConfigRequest) Descriptor() ([]byte, []int) {
+	return file_api_api_proto_rawDescGZIP(), []int{11}
+}
+
+func (x *GetAgentConfigRequest) GetRid() string {
+	if x != nil {
+		return x.Rid
+	}
+	return ""
+}
+
+type GetAgentConfigResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Config        []byte                 `protobuf:"bytes,1,opt,name=config,proto3" json:"config,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
 }
 
 func (x *GetAgentConfigResponse) Reset() {
 	*x = GetAgentConfigResponse{}
-	mi := &file_api_api_proto_msgTypes[10]
+	mi := &file_api_api_proto_msgTypes[12]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -583,53 +1024,2126 @@ func (x *GetAgentConfigResponse) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*GetAgentConfigResponse) ProtoMessage() {}
-
-func (x *GetAgentConfigResponse) ProtoReflect() protoreflect.Message {
-	mi := &file_api_api_proto_msgTypes[10]
+func (*GetAgentConfigResponse) ProtoMessage() {}
+
+func (x *GetAgentConfigResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_api_api_proto_msgTypes[12]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetAgentConfigResponse.ProtoReflect.Descriptor instead.
+func (*GetAgentConfigResponse) Descriptor() ([]byte, []int) {
+	return file_api_api_proto_rawDescGZIP(), []int{12}
+}
+
+func (x *GetAgentConfigResponse) GetConfig() []byte {
+	if x != nil {
+		return x.Config
+	}
+	return nil
+}
+
+type GetServiceInfoRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetServiceInfoRequest) Reset() {
+	*x = GetServiceInfoRequest{}
+	mi := &file_api_api_proto_msgTypes[13]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetServiceInfoRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetServiceInfoRequest) ProtoMessage() {}
+
+func (x *GetServiceInfoRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_api_api_proto_msgTypes[13]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetServiceInfoRequest.ProtoReflect.Descriptor instead.
+func (*GetServiceInfoRequest) Descriptor() ([]byte, []int) {
+	return file_api_api_proto_rawDescGZIP(), []int{13}
+}
+
+type GetServiceInfoResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Id            string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	Hostname      string                 `protobuf:"bytes,2,opt,name=hostname,proto3" json:"hostname,omitempty"`
+	CreatedAt     string                 `protobuf:"bytes,3,opt,name=created_at,json=createdAt,proto3" json:"created_at,omitempty"`
+	Release       string                 `protobuf:"bytes,4,opt,name=release,proto3" json:"release,omitempty"`
+	Commit        string                 `protobuf:"bytes,5,opt,name=commit,proto3" json:"commit,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetServiceInfoResponse) Reset() {
+	*x = GetServiceInfoResponse{}
+	mi := &file_api_api_proto_msgTypes[14]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetServiceInfoResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetServiceInfoResponse) ProtoMessage() {}
+
+func (x *GetServiceInfoResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_api_api_proto_msgTypes[14]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetServiceInfoResponse.ProtoReflect.Descriptor instead.
+func (*GetServiceInfoResponse) Descriptor() ([]byte, []int) {
+	return file_api_api_proto_rawDescGZIP(), []int{14}
+}
+
+func (x *GetServiceInfoResponse) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+func (x *GetServiceInfoResponse) GetHostname() string {
+	if x != nil {
+		return x.Hostname
+	}
+	return ""
+}
+
+func (x *GetServiceInfoResponse) GetCreatedAt() string {
+	if x != nil {
+		return x.CreatedAt
+	}
+	return ""
+}
+
+func (x *GetServiceInfoResponse) GetRelease() string {
+	if x != nil {
+		return x.Release
+	}
+	return ""
+}
+
+func (x *GetServiceInfoResponse) GetCommit() string {
+	if x != nil {
+		return x.Commit
+	}
+	return ""
+}
+
+type UpdateAgentRequest struct {
+	state           protoimpl.MessageState `protogen:"open.v1"`
+	Rid             string                 `protobuf:"bytes,1,opt,name=rid,proto3" json:"rid,omitempty"`
+	Labels          []string               `protobuf:"bytes,2,rep,name=labels,proto3" json:"labels,omitempty"`
+	LogSources      []string               `protobuf:"bytes,3,rep,name=log_sources,json=logSources,proto3" json:"log_sources,omitempty"`
+	Metrics         bool                   `protobuf:"varint,4,opt,name=metrics,proto3" json:"metrics,omitempty"`
+	MetricsTargets  []string               `protobuf:"bytes,5,rep,name=metrics_targets,json=metricsTargets,proto3" json:"metrics_targets,omitempty"`
+	Profiles        bool                   `protobuf:"varint,6,opt,name=profiles,proto3" json:"profiles,omitempty"`
+	UpdateMask      *fieldmaskpb.FieldMask `protobuf:"bytes,7,opt,name=update_mask,json=updateMask,proto3" json:"update_mask,omitempty"`
+	ResourceVersion *uint64                `protobuf:"varint,8,opt,name=resource_version,json=resourceVersion,proto3,oneof" json:"resource_version,omitempty"`
+	RateLimits      map[string]*RateLimit  `protobuf:"bytes,9,rep,name=rate_limits,json=rateLimits,proto3" json:"rate_limits,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"bytes,2,opt,name=value"`
+	unknownFields   protoimpl.UnknownFields
+	sizeCache       protoimpl.SizeCache
+}
+
+func (x *UpdateAgentRequest) Reset() {
+	*x = UpdateAgentRequest{}
+	mi := &file_api_api_proto_msgTypes[15]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *UpdateAgentRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*UpdateAgentRequest) ProtoMessage() {}
+
+func (x *UpdateAgentRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_api_api_proto_msgTypes[15]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use UpdateAgentRequest.ProtoReflect.Descriptor instead.
+func (*UpdateAgentRequest) Descriptor() ([]byte, []int) {
+	return file_api_api_proto_rawDescGZIP(), []int{15}
+}
+
+func (x *UpdateAgentRequest) GetRid() string {
+	if x != nil {
+		return x.Rid
+	}
+	return ""
+}
+
+func (x *UpdateAgentRequest) GetLabels() []string {
+	if x != nil {
+		return x.Labels
+	}
+	return nil
+}
+
+func (x *UpdateAgentRequest) GetLogSources() []string {
+	if x != nil {
+		return x.LogSources
+	}
+	return nil
+}
+
+func (x *UpdateAgentRequest) GetMetrics() bool {
+	if x != nil {
+		return x.Metrics
+	}
+	return false
+}
+
+func (x *UpdateAgentRequest) GetMetricsTargets() []string {
+	if x != nil {
+		return x.MetricsTargets
+	}
+	return nil
+}
+
+func (x *UpdateAgentRequest) GetProfiles() bool {
+	if x != nil {
+		return x.Profiles
+	}
+	return false
+}
+
+func (x *UpdateAgentRequest) GetUpdateMask() *fieldmaskpb.FieldMask {
+	if x != nil {
+		return x.UpdateMask
+	}
+	return nil
+}
+
+func (x *UpdateAgentRequest) GetResourceVersion() uint64 {
+	if x != nil && x.ResourceVersion != nil {
+		return *x.ResourceVersion
+	}
+	return 0
+}
+
+func (x *UpdateAgentRequest) GetRateLimits() map[string]*RateLimit {
+	if x != nil {
+		return x.RateLimits
+	}
+	return nil
+}
+
+type UpdateAgentResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *UpdateAgentResponse) Reset() {
+	*x = UpdateAgentResponse{}
+	mi := &file_api_api_proto_msgTypes[16]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *UpdateAgentResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*UpdateAgentResponse) ProtoMessage() {}
+
+func (x *UpdateAgentResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_api_api_proto_msgTypes[16]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use UpdateAgentResponse.ProtoReflect.Descriptor instead.
+func (*UpdateAgentResponse) Descriptor() ([]byte, []int) {
+	return file_api_api_proto_rawDescGZIP(), []int{16}
+}
+
+type RevokeAgentTokensRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Rid           string                 `protobuf:"bytes,1,opt,name=rid,proto3" json:"rid,omitempty"`
+	Before        *string                `protobuf:"bytes,2,opt,name=before,proto3,oneof" json:"before,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *RevokeAgentTokensRequest) Reset() {
+	*x = RevokeAgentTokensRequest{}
+	mi := &file_api_api_proto_msgTypes[17]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *RevokeAgentTokensRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*RevokeAgentTokensRequest) ProtoMessage() {}
+
+func (x *RevokeAgentTokensRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_api_api_proto_msgTypes[17]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use RevokeAgentTokensRequest.ProtoReflect.Descriptor instead.
+func (*RevokeAgentTokensRequest) Descriptor() ([]byte, []int) {
+	return file_api_api_proto_rawDescGZIP(), []int{17}
+}
+
+func (x *RevokeAgentTokensRequest) GetRid() string {
+	if x != nil {
+		return x.Rid
+	}
+	return ""
+}
+
+func (x *RevokeAgentTokensRequest) GetBefore() string {
+	if x != nil && x.Before != nil {
+		return *x.Before
+	}
+	return ""
+}
+
+type RevokeAgentTokensResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	RevokedBefore string                 `protobuf:"bytes,1,opt,name=revoked_before,json=revokedBefore,proto3" json:"revoked_before,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *RevokeAgentTokensResponse) Reset() {
+	*x = RevokeAgentTokensResponse{}
+	mi := &file_api_api_proto_msgTypes[18]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *RevokeAgentTokensResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*RevokeAgentTokensResponse) ProtoMessage() {}
+
+func (x *RevokeAgentTokensResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_api_api_proto_msgTypes[18]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use RevokeAgentTokensResponse.ProtoReflect.Descriptor instead.
+func (*RevokeAgentTokensResponse) Descriptor() ([]byte, []int) {
+	return file_api_api_proto_rawDescGZIP(), []int{18}
+}
+
+func (x *RevokeAgentTokensResponse) GetRevokedBefore() string {
+	if x != nil {
+		return x.RevokedBefore
+	}
+	return ""
+}
+
+type ListAgentTokensRequest struct {
+	state              protoimpl.MessageState `protogen:"open.v1"`
+	Rid                string                 `protobuf:"bytes,1,opt,name=rid,proto3" json:"rid,omitempty"`
+	ExpiringWithinDays *int32                 `protobuf:"varint,2,opt,name=expiring_within_days,json=expiringWithinDays,proto3,oneof" json:"expiring_within_days,omitempty"`
+	unknownFields      protoimpl.UnknownFields
+	sizeCache          protoimpl.SizeCache
+}
+
+func (x *ListAgentTokensRequest) Reset() {
+	*x = ListAgentTokensRequest{}
+	mi := &file_api_api_proto_msgTypes[19]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListAgentTokensRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListAgentTokensRequest) ProtoMessage() {}
+
+func (x *ListAgentTokensRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_api_api_proto_msgTypes[19]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListAgentTokensRequest.ProtoReflect.Descriptor instead.
+func (*ListAgentTokensRequest) Descriptor() ([]byte, []int) {
+	return file_api_api_proto_rawDescGZIP(), []int{19}
+}
+
+func (x *ListAgentTokensRequest) GetRid() string {
+	if x != nil {
+		return x.Rid
+	}
+	return ""
+}
+
+func (x *ListAgentTokensRequest) GetExpiringWithinDays() int32 {
+	if x != nil && x.ExpiringWithinDays != nil {
+		return *x.ExpiringWithinDays
+	}
+	return 0
+}
+
+type AgentTokenItem struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Jti           string                 `protobuf:"bytes,1,opt,name=jti,proto3" json:"jti,omitempty"`
+	Rid           string                 `protobuf:"bytes,2,opt,name=rid,proto3" json:"rid,omitempty"`
+	IssuedAt      string                 `protobuf:"bytes,3,opt,name=issued_at,json=issuedAt,proto3" json:"issued_at,omitempty"`
+	ExpiresAt     string                 `protobuf:"bytes,4,opt,name=expires_at,json=expiresAt,proto3" json:"expires_at,omitempty"`
+	Issuer        string                 `protobuf:"bytes,5,opt,name=issuer,proto3" json:"issuer,omitempty"`
+	Purpose       string                 `protobuf:"bytes,6,opt,name=purpose,proto3" json:"purpose,omitempty"`
+	Revoked       bool                   `protobuf:"varint,7,opt,name=revoked,proto3" json:"revoked,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *AgentTokenItem) Reset() {
+	*x = AgentTokenItem{}
+	mi := &file_api_api_proto_msgTypes[20]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *AgentTokenItem) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*AgentTokenItem) ProtoMessage() {}
+
+func (x *AgentTokenItem) ProtoReflect() protoreflect.Message {
+	mi := &file_api_api_proto_msgTypes[20]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use AgentTokenItem.ProtoReflect.Descriptor instead.
+func (*AgentTokenItem) Descriptor() ([]byte, []int) {
+	return file_api_api_proto_rawDescGZIP(), []int{20}
+}
+
+func (x *AgentTokenItem) GetJti() string {
+	if x != nil {
+		return x.Jti
+	}
+	return ""
+}
+
+func (x *AgentTokenItem) GetRid() string {
+	if x != nil {
+		return x.Rid
+	}
+	return ""
+}
+
+func (x *AgentTokenItem) GetIssuedAt() string {
+	if x != nil {
+		return x.IssuedAt
+	}
+	return ""
+}
+
+func (x *AgentTokenItem) GetExpiresAt() string {
+	if x != nil {
+		return x.ExpiresAt
+	}
+	return ""
+}
+
+func (x *AgentTokenItem) GetIssuer() string {
+	if x != nil {
+		return x.Issuer
+	}
+	return ""
+}
+
+func (x *AgentTokenItem) GetPurpose() string {
+	if x != nil {
+		return x.Purpose
+	}
+	return ""
+}
+
+func (x *AgentTokenItem) GetRevoked() bool {
+	if x != nil {
+		return x.Revoked
+	}
+	return false
+}
+
+type ListAgentTokensResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Tokens        []*AgentTokenItem      `protobuf:"bytes,1,rep,name=tokens,proto3" json:"tokens,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListAgentTokensResponse) Reset() {
+	*x = ListAgentTokensResponse{}
+	mi := &file_api_api_proto_msgTypes[21]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListAgentTokensResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListAgentTokensResponse) ProtoMessage() {}
+
+func (x *ListAgentTokensResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_api_api_proto_msgTypes[21]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListAgentTokensResponse.ProtoReflect.Descriptor instead.
+func (*ListAgentTokensResponse) Descriptor() ([]byte, []int) {
+	return file_api_api_proto_rawDescGZIP(), []int{21}
+}
+
+func (x *ListAgentTokensResponse) GetTokens() []*AgentTokenItem {
+	if x != nil {
+		return x.Tokens
+	}
+	return nil
+}
+
+type SuspendAgentRequest struct {
+	state           protoimpl.MessageState `protogen:"open.v1"`
+	Rid             string                 `protobuf:"bytes,1,opt,name=rid,proto3" json:"rid,omitempty"`
+	ResourceVersion *uint64                `protobuf:"varint,2,opt,name=resource_version,json=resourceVersion,proto3,oneof" json:"resource_version,omitempty"`
+	unknownFields   protoimpl.UnknownFields
+	sizeCache       protoimpl.SizeCache
+}
+
+func (x *SuspendAgentRequest) Reset() {
+	*x = SuspendAgentRequest{}
+	mi := &file_api_api_proto_msgTypes[22]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SuspendAgentRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SuspendAgentRequest) ProtoMessage() {}
+
+func (x *SuspendAgentRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_api_api_proto_msgTypes[22]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SuspendAgentRequest.ProtoReflect.Descriptor instead.
+func (*SuspendAgentRequest) Descriptor() ([]byte, []int) {
+	return file_api_api_proto_rawDescGZIP(), []int{22}
+}
+
+func (x *SuspendAgentRequest) GetRid() string {
+	if x != nil {
+		return x.Rid
+	}
+	return ""
+}
+
+func (x *SuspendAgentRequest) GetResourceVersion() uint64 {
+	if x != nil && x.ResourceVersion != nil {
+		return *x.ResourceVersion
+	}
+	return 0
+}
+
+type SuspendAgentResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *SuspendAgentResponse) Reset() {
+	*x = SuspendAgentResponse{}
+	mi := &file_api_api_proto_msgTypes[23]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SuspendAgentResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SuspendAgentResponse) ProtoMessage() {}
+
+func (x *SuspendAgentResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_api_api_proto_msgTypes[23]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SuspendAgentResponse.ProtoReflect.Descriptor instead.
+func (*SuspendAgentResponse) Descriptor() ([]byte, []int) {
+	return file_api_api_proto_rawDescGZIP(), []int{23}
+}
+
+type ResumeAgentRequest struct {
+	state           protoimpl.MessageState `protogen:"open.v1"`
+	Rid             string                 `protobuf:"bytes,1,opt,name=rid,proto3" json:"rid,omitempty"`
+	ResourceVersion *uint64                `protobuf:"varint,2,opt,name=resource_version,json=resourceVersion,proto3,oneof" json:"resource_version,omitempty"`
+	unknownFields   protoimpl.UnknownFields
+	sizeCache       protoimpl.SizeCache
+}
+
+func (x *ResumeAgentRequest) Reset() {
+	*x = ResumeAgentRequest{}
+	mi := &file_api_api_proto_msgTypes[24]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ResumeAgentRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ResumeAgentRequest) ProtoMessage() {}
+
+func (x *ResumeAgentRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_api_api_proto_msgTypes[24]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ResumeAgentRequest.ProtoReflect.Descriptor instead.
+func (*ResumeAgentRequest) Descriptor() ([]byte, []int) {
+	return file_api_api_proto_rawDescGZIP(), []int{24}
+}
+
+func (x *ResumeAgentRequest) GetRid() string {
+	if x != nil {
+		return x.Rid
+	}
+	return ""
+}
+
+func (x *ResumeAgentRequest) GetResourceVersion() uint64 {
+	if x != nil && x.ResourceVersion != nil {
+		return *x.ResourceVersion
+	}
+	return 0
+}
+
+type ResumeAgentResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ResumeAgentResponse) Reset() {
+	*x = ResumeAgentResponse{}
+	mi := &file_api_api_proto_msgTypes[25]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ResumeAgentResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ResumeAgentResponse) ProtoMessage() {}
+
+func (x *ResumeAgentResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_api_api_proto_msgTypes[25]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ResumeAgentResponse.ProtoReflect.Descriptor instead.
+func (*ResumeAgentResponse) Descriptor() ([]byte, []int) {
+	return file_api_api_proto_rawDescGZIP(), []int{25}
+}
+
+type WatchAgentsRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	SinceRevision *uint64                `protobuf:"varint,1,opt,name=since_revision,json=sinceRevision,proto3,oneof" json:"since_revision,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *WatchAgentsRequest) Reset() {
+	*x = WatchAgentsRequest{}
+	mi := &file_api_api_proto_msgTypes[26]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *WatchAgentsRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*WatchAgentsRequest) ProtoMessage() {}
+
+func (x *WatchAgentsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_api_api_proto_msgTypes[26]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use WatchAgentsRequest.ProtoReflect.Descriptor instead.
+func (*WatchAgentsRequest) Descriptor() ([]byte, []int) {
+	return file_api_api_proto_rawDescGZIP(), []int{26}
+}
+
+func (x *WatchAgentsRequest) GetSinceRevision() uint64 {
+	if x != nil && x.SinceRevision != nil {
+		return *x.SinceRevision
+	}
+	return 0
+}
+
+type WatchAgentsResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Revision      uint64                 `protobuf:"varint,1,opt,name=revision,proto3" json:"revision,omitempty"`
+	Type          string                 `protobuf:"bytes,2,opt,name=type,proto3" json:"type,omitempty"`
+	Rid           string                 `protobuf:"bytes,3,opt,name=rid,proto3" json:"rid,omitempty"`
+	Hostname      string                 `protobuf:"bytes,4,opt,name=hostname,proto3" json:"hostname,omitempty"`
+	ChangedFields []string               `protobuf:"bytes,5,rep,name=changed_fields,json=changedFields,proto3" json:"changed_fields,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *WatchAgentsResponse) Reset() {
+	*x = WatchAgentsResponse{}
+	mi := &file_api_api_proto_msgTypes[27]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *WatchAgentsResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*WatchAgentsResponse) ProtoMessage() {}
+
+func (x *WatchAgentsResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_api_api_proto_msgTypes[27]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use WatchAgentsResponse.ProtoReflect.Descriptor instead.
+func (*WatchAgentsResponse) Descriptor() ([]byte, []int) {
+	return file_api_api_proto_rawDescGZIP(), []int{27}
+}
+
+func (x *WatchAgentsResponse) GetRevision() uint64 {
+	if x != nil {
+		return x.Revision
+	}
+	return 0
+}
+
+func (x *WatchAgentsResponse) GetType() string {
+	if x != nil {
+		return x.Type
+	}
+	return ""
+}
+
+func (x *WatchAgentsResponse) GetRid() string {
+	if x != nil {
+		return x.Rid
+	}
+	return ""
+}
+
+func (x *WatchAgentsResponse) GetHostname() string {
+	if x != nil {
+		return x.Hostname
+	}
+	return ""
+}
+
+func (x *WatchAgentsResponse) GetChangedFields() []string {
+	if x != nil {
+		return x.ChangedFields
+	}
+	return nil
+}
+
+type ListAgentConfigRevisionsRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Rid           string                 `protobuf:"bytes,1,opt,name=rid,proto3" json:"rid,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListAgentConfigRevisionsRequest) Reset() {
+	*x = ListAgentConfigRevisionsRequest{}
+	mi := &file_api_api_proto_msgTypes[28]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListAgentConfigRevisionsRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListAgentConfigRevisionsRequest) ProtoMessage() {}
+
+func (x *ListAgentConfigRevisionsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_api_api_proto_msgTypes[28]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListAgentConfigRevisionsRequest.ProtoReflect.Descriptor instead.
+func (*ListAgentConfigRevisionsRequest) Descriptor() ([]byte, []int) {
+	return file_api_api_proto_rawDescGZIP(), []int{28}
+}
+
+func (x *ListAgentConfigRevisionsRequest) GetRid() string {
+	if x != nil {
+		return x.Rid
+	}
+	return ""
+}
+
+type AgentConfigRevisionItem struct {
+	state          protoimpl.MessageState `protogen:"open.v1"`
+	Revision       uint64                 `protobuf:"varint,1,opt,name=revision,proto3" json:"revision,omitempty"`
+	CreatedAt      string                 `protobuf:"bytes,2,opt,name=created_at,json=createdAt,proto3" json:"created_at,omitempty"`
+	Author         string                 `protobuf:"bytes,3,opt,name=author,proto3" json:"author,omitempty"`
+	ConfigHash     string                 `protobuf:"bytes,4,opt,name=config_hash,json=configHash,proto3" json:"config_hash,omitempty"`
+	Labels         []string               `protobuf:"bytes,5,rep,name=labels,proto3" json:"labels,omitempty"`
+	LogSources     []string               `protobuf:"bytes,6,rep,name=log_sources,json=logSources,proto3" json:"log_sources,omitempty"`
+	Metrics        bool                   `protobuf:"varint,7,opt,name=metrics,proto3" json:"metrics,omitempty"`
+	MetricsTargets []string               `protobuf:"bytes,8,rep,name=metrics_targets,json=metricsTargets,proto3" json:"metrics_targets,omitempty"`
+	Profiles       bool                   `protobuf:"varint,9,opt,name=profiles,proto3" json:"profiles,omitempty"`
+	unknownFields  protoimpl.UnknownFields
+	sizeCache      protoimpl.SizeCache
+}
+
+func (x *AgentConfigRevisionItem) Reset() {
+	*x = AgentConfigRevisionItem{}
+	mi := &file_api_api_proto_msgTypes[29]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *AgentConfigRevisionItem) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*AgentConfigRevisionItem) ProtoMessage() {}
+
+func (x *AgentConfigRevisionItem) ProtoReflect() protoreflect.Message {
+	mi := &file_api_api_proto_msgTypes[29]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use AgentConfigRevisionItem.ProtoReflect.Descriptor instead.
+func (*AgentConfigRevisionItem) Descriptor() ([]byte, []int) {
+	return file_api_api_proto_rawDescGZIP(), []int{29}
+}
+
+func (x *AgentConfigRevisionItem) GetRevision() uint64 {
+	if x != nil {
+		return x.Revision
+	}
+	return 0
+}
+
+func (x *AgentConfigRevisionItem) GetCreatedAt() string {
+	if x != nil {
+		return x.CreatedAt
+	}
+	return ""
+}
+
+func (x *AgentConfigRevisionItem) GetAuthor() string {
+	if x != nil {
+		return x.Author
+	}
+	return ""
+}
+
+func (x *AgentConfigRevisionItem) GetConfigHash() string {
+	if x != nil {
+		return x.ConfigHash
+	}
+	return ""
+}
+
+func (x *AgentConfigRevisionItem) GetLabels() []string {
+	if x != nil {
+		return x.Labels
+	}
+	return nil
+}
+
+func (x *AgentConfigRevisionItem) GetLogSources() []string {
+	if x != nil {
+		return x.LogSources
+	}
+	return nil
+}
+
+func (x *AgentConfigRevisionItem) GetMetrics() bool {
+	if x != nil {
+		return x.Metrics
+	}
+	return false
+}
+
+func (x *AgentConfigRevisionItem) GetMetricsTargets() []string {
+	if x != nil {
+		return x.MetricsTargets
+	}
+	return nil
+}
+
+func (x *AgentConfigRevisionItem) GetProfiles() bool {
+	if x != nil {
+		return x.Profiles
+	}
+	return false
+}
+
+type ListAgentConfigRevisionsResponse struct {
+	state         protoimpl.MessageState     `protogen:"open.v1"`
+	Revisions     []*AgentConfigRevisionItem `protobuf:"bytes,1,rep,name=revisions,proto3" json:"revisions,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListAgentConfigRevisionsResponse) Reset() {
+	*x = ListAgentConfigRevisionsResponse{}
+	mi := &file_api_api_proto_msgTypes[30]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListAgentConfigRevisionsResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListAgentConfigRevisionsResponse) ProtoMessage() {}
+
+func (x *ListAgentConfigRevisionsResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_api_api_proto_msgTypes[30]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListAgentConfigRevisionsResponse.ProtoReflect.Descriptor instead.
+func (*ListAgentConfigRevisionsResponse) Descriptor() ([]byte, []int) {
+	return file_api_api_proto_rawDescGZIP(), []int{30}
+}
+
+func (x *ListAgentConfigRevisionsResponse) GetRevisions() []*AgentConfigRevisionItem {
+	if x != nil {
+		return x.Revisions
+	}
+	return nil
+}
+
+type DiffAgentConfigRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Rid           string                 `protobuf:"bytes,1,opt,name=rid,proto3" json:"rid,omitempty"`
+	FromRevision  uint64                 `protobuf:"varint,2,opt,name=from_revision,json=fromRevision,proto3" json:"from_revision,omitempty"`
+	ToRevision    *uint64                `protobuf:"varint,3,opt,name=to_revision,json=toRevision,proto3,oneof" json:"to_revision,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *DiffAgentConfigRequest) Reset() {
+	*x = DiffAgentConfigRequest{}
+	mi := &file_api_api_proto_msgTypes[31]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *DiffAgentConfigRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DiffAgentConfigRequest) ProtoMessage() {}
+
+func (x *DiffAgentConfigRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_api_api_proto_msgTypes[31]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DiffAgentConfigRequest.ProtoReflect.Descriptor instead.
+func (*DiffAgentConfigRequest) Descriptor() ([]byte, []int) {
+	return file_api_api_proto_rawDescGZIP(), []int{31}
+}
+
+func (x *DiffAgentConfigRequest) GetRid() string {
+	if x != nil {
+		return x.Rid
+	}
+	return ""
+}
+
+func (x *DiffAgentConfigRequest) GetFromRevision() uint64 {
+	if x != nil {
+		return x.FromRevision
+	}
+	return 0
+}
+
+func (x *DiffAgentConfigRequest) GetToRevision() uint64 {
+	if x != nil && x.ToRevision != nil {
+		return *x.ToRevision
+	}
+	return 0
+}
+
+type DiffAgentConfigResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Diff          string                 `protobuf:"bytes,1,opt,name=diff,proto3" json:"diff,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *DiffAgentConfigResponse) Reset() {
+	*x = DiffAgentConfigResponse{}
+	mi := &file_api_api_proto_msgTypes[32]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *DiffAgentConfigResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DiffAgentConfigResponse) ProtoMessage() {}
+
+func (x *DiffAgentConfigResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_api_api_proto_msgTypes[32]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DiffAgentConfigResponse.ProtoReflect.Descriptor instead.
+func (*DiffAgentConfigResponse) Descriptor() ([]byte, []int) {
+	return file_api_api_proto_rawDescGZIP(), []int{32}
+}
+
+func (x *DiffAgentConfigResponse) GetDiff() string {
+	if x != nil {
+		return x.Diff
+	}
+	return ""
+}
+
+type RollbackAgentConfigRequest struct {
+	state           protoimpl.MessageState `protogen:"open.v1"`
+	Rid             string                 `protobuf:"bytes,1,opt,name=rid,proto3" json:"rid,omitempty"`
+	Revision        uint64                 `protobuf:"varint,2,opt,name=revision,proto3" json:"revision,omitempty"`
+	ResourceVersion *uint64                `protobuf:"varint,3,opt,name=resource_version,json=resourceVersion,proto3,oneof" json:"resource_version,omitempty"`
+	unknownFields   protoimpl.UnknownFields
+	sizeCache       protoimpl.SizeCache
+}
+
+func (x *RollbackAgentConfigRequest) Reset() {
+	*x = RollbackAgentConfigRequest{}
+	mi := &file_api_api_proto_msgTypes[33]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *RollbackAgentConfigRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*RollbackAgentConfigRequest) ProtoMessage() {}
+
+func (x *RollbackAgentConfigRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_api_api_proto_msgTypes[33]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use RollbackAgentConfigRequest.ProtoReflect.Descriptor instead.
+func (*RollbackAgentConfigRequest) Descriptor() ([]byte, []int) {
+	return file_api_api_proto_rawDescGZIP(), []int{33}
+}
+
+func (x *RollbackAgentConfigRequest) GetRid() string {
+	if x != nil {
+		return x.Rid
+	}
+	return ""
+}
+
+func (x *RollbackAgentConfigRequest) GetRevision() uint64 {
+	if x != nil {
+		return x.Revision
+	}
+	return 0
+}
+
+func (x *RollbackAgentConfigRequest) GetResourceVersion() uint64 {
+	if x != nil && x.ResourceVersion != nil {
+		return *x.ResourceVersion
+	}
+	return 0
+}
+
+type RollbackAgentConfigResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Revision      uint64                 `protobuf:"varint,1,opt,name=revision,proto3" json:"revision,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *RollbackAgentConfigResponse) Reset() {
+	*x = RollbackAgentConfigResponse{}
+	mi := &file_api_api_proto_msgTypes[34]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *RollbackAgentConfigResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*RollbackAgentConfigResponse) ProtoMessage() {}
+
+func (x *RollbackAgentConfigResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_api_api_proto_msgTypes[34]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use RollbackAgentConfigResponse.ProtoReflect.Descriptor instead.
+func (*RollbackAgentConfigResponse) Descriptor() ([]byte, []int) {
+	return file_api_api_proto_rawDescGZIP(), []int{34}
+}
+
+func (x *RollbackAgentConfigResponse) GetRevision() uint64 {
+	if x != nil {
+		return x.Revision
+	}
+	return 0
+}
+
+type GetAgentUsageRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Rid           string                 `protobuf:"bytes,1,opt,name=rid,proto3" json:"rid,omitempty"`
+	Since         *string                `protobuf:"bytes,2,opt,name=since,proto3,oneof" json:"since,omitempty"`
+	Until         *string                `protobuf:"bytes,3,opt,name=until,proto3,oneof" json:"until,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetAgentUsageRequest) Reset() {
+	*x = GetAgentUsageRequest{}
+	mi := &file_api_api_proto_msgTypes[35]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetAgentUsageRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetAgentUsageRequest) ProtoMessage() {}
+
+func (x *GetAgentUsageRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_api_api_proto_msgTypes[35]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetAgentUsageRequest.ProtoReflect.Descriptor instead.
+func (*GetAgentUsageRequest) Descriptor() ([]byte, []int) {
+	return file_api_api_proto_rawDescGZIP(), []int{35}
+}
+
+func (x *GetAgentUsageRequest) GetRid() string {
+	if x != nil {
+		return x.Rid
+	}
+	return ""
+}
+
+func (x *GetAgentUsageRequest) GetSince() string {
+	if x != nil && x.Since != nil {
+		return *x.Since
+	}
+	return ""
+}
+
+func (x *GetAgentUsageRequest) GetUntil() string {
+	if x != nil && x.Until != nil {
+		return *x.Until
+	}
+	return ""
+}
+
+type AgentUsageItem struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Backend       string                 `protobuf:"bytes,1,opt,name=backend,proto3" json:"backend,omitempty"`
+	Bucket        string                 `protobuf:"bytes,2,opt,name=bucket,proto3" json:"bucket,omitempty"`
+	Requests      int64                  `protobuf:"varint,3,opt,name=requests,proto3" json:"requests,omitempty"`
+	Bytes         int64                  `protobuf:"varint,4,opt,name=bytes,proto3" json:"bytes,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *AgentUsageItem) Reset() {
+	*x = AgentUsageItem{}
+	mi := &file_api_api_proto_msgTypes[36]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *AgentUsageItem) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*AgentUsageItem) ProtoMessage() {}
+
+func (x *AgentUsageItem) ProtoReflect() protoreflect.Message {
+	mi := &file_api_api_proto_msgTypes[36]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use AgentUsageItem.ProtoReflect.Descriptor instead.
+func (*AgentUsageItem) Descriptor() ([]byte, []int) {
+	return file_api_api_proto_rawDescGZIP(), []int{36}
+}
+
+func (x *AgentUsageItem) GetBackend() string {
+	if x != nil {
+		return x.Backend
+	}
+	return ""
+}
+
+func (x *AgentUsageItem) GetBucket() string {
+	if x != nil {
+		return x.Bucket
+	}
+	return ""
+}
+
+func (x *AgentUsageItem) GetRequests() int64 {
+	if x != nil {
+		return x.Requests
+	}
+	return 0
+}
+
+func (x *AgentUsageItem) GetBytes() int64 {
+	if x != nil {
+		return x.Bytes
+	}
+	return 0
+}
+
+type GetAgentUsageResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Buckets       []*AgentUsageItem      `protobuf:"bytes,1,rep,name=buckets,proto3" json:"buckets,omitempty"`
+	Totals        []*AgentUsageItem      `protobuf:"bytes,2,rep,name=totals,proto3" json:"totals,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetAgentUsageResponse) Reset() {
+	*x = GetAgentUsageResponse{}
+	mi := &file_api_api_proto_msgTypes[37]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetAgentUsageResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetAgentUsageResponse) ProtoMessage() {}
+
+func (x *GetAgentUsageResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_api_api_proto_msgTypes[37]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetAgentUsageResponse.ProtoReflect.Descriptor instead.
+func (*GetAgentUsageResponse) Descriptor() ([]byte, []int) {
+	return file_api_api_proto_rawDescGZIP(), []int{37}
+}
+
+func (x *GetAgentUsageResponse) GetBuckets() []*AgentUsageItem {
+	if x != nil {
+		return x.Buckets
+	}
+	return nil
+}
+
+func (x *GetAgentUsageResponse) GetTotals() []*AgentUsageItem {
+	if x != nil {
+		return x.Totals
+	}
+	return nil
+}
+
+type GetDashboardTokenRequest struct {
+	state          protoimpl.MessageState `protogen:"open.v1"`
+	SessionTimeout *int32                 `protobuf:"varint,1,opt,name=session_timeout,json=sessionTimeout,proto3,oneof" json:"session_timeout,omitempty"`
+	Role           string                 `protobuf:"bytes,2,opt,name=role,proto3" json:"role,omitempty"`
+	Scope          []string               `protobuf:"bytes,3,rep,name=scope,proto3" json:"scope,omitempty"`
+	unknownFields  protoimpl.UnknownFields
+	sizeCache      protoimpl.SizeCache
+}
+
+func (x *GetDashboardTokenRequest) Reset() {
+	*x = GetDashboardTokenRequest{}
+	mi := &file_api_api_proto_msgTypes[38]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetDashboardTokenRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetDashboardTokenRequest) ProtoMessage() {}
+
+func (x *GetDashboardTokenRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_api_api_proto_msgTypes[38]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetDashboardTokenRequest.ProtoReflect.Descriptor instead.
+func (*GetDashboardTokenRequest) Descriptor() ([]byte, []int) {
+	return file_api_api_proto_rawDescGZIP(), []int{38}
+}
+
+func (x *GetDashboardTokenRequest) GetSessionTimeout() int32 {
+	if x != nil && x.SessionTimeout != nil {
+		return *x.SessionTimeout
+	}
+	return 0
+}
+
+func (x *GetDashboardTokenRequest) GetRole() string {
+	if x != nil {
+		return x.Role
+	}
+	return ""
+}
+
+func (x *GetDashboardTokenRequest) GetScope() []string {
+	if x != nil {
+		return x.Scope
+	}
+	return nil
+}
+
+type GetDashboardTokenResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Token         string                 `protobuf:"bytes,1,opt,name=token,proto3" json:"token,omitempty"`
+	ExpiresAt     string                 `protobuf:"bytes,2,opt,name=expires_at,json=expiresAt,proto3" json:"expires_at,omitempty"`
+	DashboardUrl  string                 `protobuf:"bytes,3,opt,name=dashboard_url,json=dashboardUrl,proto3" json:"dashboard_url,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetDashboardTokenResponse) Reset() {
+	*x = GetDashboardTokenResponse{}
+	mi := &file_api_api_proto_msgTypes[39]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetDashboardTokenResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetDashboardTokenResponse) ProtoMessage() {}
+
+func (x *GetDashboardTokenResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_api_api_proto_msgTypes[39]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetDashboardTokenResponse.ProtoReflect.Descriptor instead.
+func (*GetDashboardTokenResponse) Descriptor() ([]byte, []int) {
+	return file_api_api_proto_rawDescGZIP(), []int{39}
+}
+
+func (x *GetDashboardTokenResponse) GetToken() string {
+	if x != nil {
+		return x.Token
+	}
+	return ""
+}
+
+func (x *GetDashboardTokenResponse) GetExpiresAt() string {
+	if x != nil {
+		return x.ExpiresAt
+	}
+	return ""
+}
+
+func (x *GetDashboardTokenResponse) GetDashboardUrl() string {
+	if x != nil {
+		return x.DashboardUrl
+	}
+	return ""
+}
+
+type ListAuditEventsRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Since         *string                `protobuf:"bytes,1,opt,name=since,proto3,oneof" json:"since,omitempty"`
+	Until         *string                `protobuf:"bytes,2,opt,name=until,proto3,oneof" json:"until,omitempty"`
+	Actor         string                 `protobuf:"bytes,3,opt,name=actor,proto3" json:"actor,omitempty"`
+	Action        string                 `protobuf:"bytes,4,opt,name=action,proto3" json:"action,omitempty"`
+	Rid           string                 `protobuf:"bytes,5,opt,name=rid,proto3" json:"rid,omitempty"`
+	Limit         *int32                 `protobuf:"varint,6,opt,name=limit,proto3,oneof" json:"limit,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListAuditEventsRequest) Reset() {
+	*x = ListAuditEventsRequest{}
+	mi := &file_api_api_proto_msgTypes[40]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListAuditEventsRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListAuditEventsRequest) ProtoMessage() {}
+
+func (x *ListAuditEventsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_api_api_proto_msgTypes[40]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListAuditEventsRequest.ProtoReflect.Descriptor instead.
+func (*ListAuditEventsRequest) Descriptor() ([]byte, []int) {
+	return file_api_api_proto_rawDescGZIP(), []int{40}
+}
+
+func (x *ListAuditEventsRequest) GetSince() string {
+	if x != nil && x.Since != nil {
+		return *x.Since
+	}
+	return ""
+}
+
+func (x *ListAuditEventsRequest) GetUntil() string {
+	if x != nil && x.Until != nil {
+		return *x.Until
+	}
+	return ""
+}
+
+func (x *ListAuditEventsRequest) GetActor() string {
+	if x != nil {
+		return x.Actor
+	}
+	return ""
+}
+
+func (x *ListAuditEventsRequest) GetAction() string {
+	if x != nil {
+		return x.Action
+	}
+	return ""
+}
+
+func (x *ListAuditEventsRequest) GetRid() string {
+	if x != nil {
+		return x.Rid
+	}
+	return ""
+}
+
+func (x *ListAuditEventsRequest) GetLimit() int32 {
+	if x != nil && x.Limit != nil {
+		return *x.Limit
+	}
+	return 0
+}
+
+type AuditEventItem struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Id            uint64                 `protobuf:"varint,1,opt,name=id,proto3" json:"id,omitempty"`
+	Timestamp     string                 `protobuf:"bytes,2,opt,name=timestamp,proto3" json:"timestamp,omitempty"`
+	Actor         string                 `protobuf:"bytes,3,opt,name=actor,proto3" json:"actor,omitempty"`
+	Action        string                 `protobuf:"bytes,4,opt,name=action,proto3" json:"action,omitempty"`
+	Rid           string                 `protobuf:"bytes,5,opt,name=rid,proto3" json:"rid,omitempty"`
+	Diff          string                 `protobuf:"bytes,6,opt,name=diff,proto3" json:"diff,omitempty"`
+	SourceIp      string                 `protobuf:"bytes,7,opt,name=source_ip,json=sourceIp,proto3" json:"source_ip,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *AuditEventItem) Reset() {
+	*x = AuditEventItem{}
+	mi := &file_api_api_proto_msgTypes[41]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *AuditEventItem) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*AuditEventItem) ProtoMessage() {}
+
+func (x *AuditEventItem) ProtoReflect() protoreflect.Message {
+	mi := &file_api_api_proto_msgTypes[41]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use AuditEventItem.ProtoReflect.Descriptor instead.
+func (*AuditEventItem) Descriptor() ([]byte, []int) {
+	return file_api_api_proto_rawDescGZIP(), []int{41}
+}
+
+func (x *AuditEventItem) GetId() uint64 {
+	if x != nil {
+		return x.Id
+	}
+	return 0
+}
+
+func (x *AuditEventItem) GetTimestamp() string {
+	if x != nil {
+		return x.Timestamp
+	}
+	return ""
+}
+
+func (x *AuditEventItem) GetActor() string {
+	if x != nil {
+		return x.Actor
+	}
+	return ""
+}
+
+func (x *AuditEventItem) GetAction() string {
+	if x != nil {
+		return x.Action
+	}
+	return ""
+}
+
+func (x *AuditEventItem) GetRid() string {
+	if x != nil {
+		return x.Rid
+	}
+	return ""
+}
+
+func (x *AuditEventItem) GetDiff() string {
+	if x != nil {
+		return x.Diff
+	}
+	return ""
+}
+
+func (x *AuditEventItem) GetSourceIp() string {
+	if x != nil {
+		return x.SourceIp
+	}
+	return ""
+}
+
+type ListAuditEventsResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Events        []*AuditEventItem      `protobuf:"bytes,1,rep,name=events,proto3" json:"events,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListAuditEventsResponse) Reset() {
+	*x = ListAuditEventsResponse{}
+	mi := &file_api_api_proto_msgTypes[42]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListAuditEventsResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListAuditEventsResponse) ProtoMessage() {}
+
+func (x *ListAuditEventsResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_api_api_proto_msgTypes[42]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListAuditEventsResponse.ProtoReflect.Descriptor instead.
+func (*ListAuditEventsResponse) Descriptor() ([]byte, []int) {
+	return file_api_api_proto_rawDescGZIP(), []int{42}
+}
+
+func (x *ListAuditEventsResponse) GetEvents() []*AuditEventItem {
+	if x != nil {
+		return x.Events
+	}
+	return nil
+}
+
+type CreateEnrollmentTokenRequest struct {
+	state           protoimpl.MessageState `protogen:"open.v1"`
+	UsageLimit      *int32                 `protobuf:"varint,1,opt,name=usage_limit,json=usageLimit,proto3,oneof" json:"usage_limit,omitempty"`
+	ExpiresIn       *int64                 `protobuf:"varint,2,opt,name=expires_in,json=expiresIn,proto3,oneof" json:"expires_in,omitempty"`
+	HostnamePattern string                 `protobuf:"bytes,3,opt,name=hostname_pattern,json=hostnamePattern,proto3" json:"hostname_pattern,omitempty"`
+	Labels          []string               `protobuf:"bytes,4,rep,name=labels,proto3" json:"labels,omitempty"`
+	LogSources      []string               `protobuf:"bytes,5,rep,name=log_sources,json=logSources,proto3" json:"log_sources,omitempty"`
+	Ephemeral       bool                   `protobuf:"varint,6,opt,name=ephemeral,proto3" json:"ephemeral,omitempty"`
+	Tenant          string                 `protobuf:"bytes,7,opt,name=tenant,proto3" json:"tenant,omitempty"`
+	unknownFields   protoimpl.UnknownFields
+	sizeCache       protoimpl.SizeCache
+}
+
+func (x *CreateEnrollmentTokenRequest) Reset() {
+	*x = CreateEnrollmentTokenRequest{}
+	mi := &file_api_api_proto_msgTypes[43]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CreateEnrollmentTokenRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CreateEnrollmentTokenRequest) ProtoMessage() {}
+
+func (x *CreateEnrollmentTokenRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_api_api_proto_msgTypes[43]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CreateEnrollmentTokenRequest.ProtoReflect.Descriptor instead.
+func (*CreateEnrollmentTokenRequest) Descriptor() ([]byte, []int) {
+	return file_api_api_proto_rawDescGZIP(), []int{43}
+}
+
+func (x *CreateEnrollmentTokenRequest) GetUsageLimit() int32 {
+	if x != nil && x.UsageLimit != nil {
+		return *x.UsageLimit
+	}
+	return 0
+}
+
+func (x *CreateEnrollmentTokenRequest) GetExpiresIn() int64 {
+	if x != nil && x.ExpiresIn != nil {
+		return *x.ExpiresIn
+	}
+	return 0
+}
+
+func (x *CreateEnrollmentTokenRequest) GetHostnamePattern() string {
+	if x != nil {
+		return x.HostnamePattern
+	}
+	return ""
+}
+
+func (x *CreateEnrollmentTokenRequest) GetLabels() []string {
+	if x != nil {
+		return x.Labels
+	}
+	return nil
+}
+
+func (x *CreateEnrollmentTokenRequest) GetLogSources() []string {
+	if x != nil {
+		return x.LogSources
+	}
+	return nil
+}
+
+func (x *CreateEnrollmentTokenRequest) GetEphemeral() bool {
+	if x != nil {
+		return x.Ephemeral
+	}
+	return false
+}
+
+func (x *CreateEnrollmentTokenRequest) GetTenant() string {
+	if x != nil {
+		return x.Tenant
+	}
+	return ""
+}
+
+type CreateEnrollmentTokenResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Token         string                 `protobuf:"bytes,1,opt,name=token,proto3" json:"token,omitempty"`
+	Jti           string                 `protobuf:"bytes,2,opt,name=jti,proto3" json:"jti,omitempty"`
+	ExpiresAt     string                 `protobuf:"bytes,3,opt,name=expires_at,json=expiresAt,proto3" json:"expires_at,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *CreateEnrollmentTokenResponse) Reset() {
+	*x = CreateEnrollmentTokenResponse{}
+	mi := &file_api_api_proto_msgTypes[44]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CreateEnrollmentTokenResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CreateEnrollmentTokenResponse) ProtoMessage() {}
+
+func (x *CreateEnrollmentTokenResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_api_api_proto_msgTypes[44]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CreateEnrollmentTokenResponse.ProtoReflect.Descriptor instead.
+func (*CreateEnrollmentTokenResponse) Descriptor() ([]byte, []int) {
+	return file_api_api_proto_rawDescGZIP(), []int{44}
+}
+
+func (x *CreateEnrollmentTokenResponse) GetToken() string {
+	if x != nil {
+		return x.Token
+	}
+	return ""
+}
+
+func (x *CreateEnrollmentTokenResponse) GetJti() string {
+	if x != nil {
+		return x.Jti
+	}
+	return ""
+}
+
+func (x *CreateEnrollmentTokenResponse) GetExpiresAt() string {
+	if x != nil {
+		return x.ExpiresAt
+	}
+	return ""
+}
+
+type ListEnrollmentTokensRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListEnrollmentTokensRequest) Reset() {
+	*x = ListEnrollmentTokensRequest{}
+	mi := &file_api_api_proto_msgTypes[45]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListEnrollmentTokensRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListEnrollmentTokensRequest) ProtoMessage() {}
+
+func (x *ListEnrollmentTokensRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_api_api_proto_msgTypes[45]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListEnrollmentTokensRequest.ProtoReflect.Descriptor instead.
+func (*ListEnrollmentTokensRequest) Descriptor() ([]byte, []int) {
+	return file_api_api_proto_rawDescGZIP(), []int{45}
+}
+
+type EnrollmentTokenItem struct {
+	state           protoimpl.MessageState `protogen:"open.v1"`
+	Jti             string                 `protobuf:"bytes,1,opt,name=jti,proto3" json:"jti,omitempty"`
+	IssuedAt        string                 `protobuf:"bytes,2,opt,name=issued_at,json=issuedAt,proto3" json:"issued_at,omitempty"`
+	ExpiresAt       string                 `protobuf:"bytes,3,opt,name=expires_at,json=expiresAt,proto3" json:"expires_at,omitempty"`
+	Issuer          string                 `protobuf:"bytes,4,opt,name=issuer,proto3" json:"issuer,omitempty"`
+	UsageLimit      int32                  `protobuf:"varint,5,opt,name=usage_limit,json=usageLimit,proto3" json:"usage_limit,omitempty"`
+	Uses            int32                  `protobuf:"varint,6,opt,name=uses,proto3" json:"uses,omitempty"`
+	HostnamePattern string                 `protobuf:"bytes,7,opt,name=hostname_pattern,json=hostnamePattern,proto3" json:"hostname_pattern,omitempty"`
+	Labels          []string               `protobuf:"bytes,8,rep,name=labels,proto3" json:"labels,omitempty"`
+	LogSources      []string               `protobuf:"bytes,9,rep,name=log_sources,json=logSources,proto3" json:"log_sources,omitempty"`
+	Ephemeral       bool                   `protobuf:"varint,10,opt,name=ephemeral,proto3" json:"ephemeral,omitempty"`
+	Tenant          string                 `protobuf:"bytes,11,opt,name=tenant,proto3" json:"tenant,omitempty"`
+	unknownFields   protoimpl.UnknownFields
+	sizeCache       protoimpl.SizeCache
+}
+
+func (x *EnrollmentTokenItem) Reset() {
+	*x = EnrollmentTokenItem{}
+	mi := &file_api_api_proto_msgTypes[46]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *EnrollmentTokenItem) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*EnrollmentTokenItem) ProtoMessage() {}
+
+func (x *EnrollmentTokenItem) ProtoReflect() protoreflect.Message {
+	mi := &file_api_api_proto_msgTypes[46]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use EnrollmentTokenItem.ProtoReflect.Descriptor instead.
+func (*EnrollmentTokenItem) Descriptor() ([]byte, []int) {
+	return file_api_api_proto_rawDescGZIP(), []int{46}
+}
+
+func (x *EnrollmentTokenItem) GetJti() string {
+	if x != nil {
+		return x.Jti
+	}
+	return ""
+}
+
+func (x *EnrollmentTokenItem) GetIssuedAt() string {
+	if x != nil {
+		return x.IssuedAt
+	}
+	return ""
+}
+
+func (x *EnrollmentTokenItem) GetExpiresAt() string {
+	if x != nil {
+		return x.ExpiresAt
+	}
+	return ""
+}
+
+func (x *EnrollmentTokenItem) GetIssuer() string {
+	if x != nil {
+		return x.Issuer
+	}
+	return ""
+}
+
+func (x *EnrollmentTokenItem) GetUsageLimit() int32 {
+	if x != nil {
+		return x.UsageLimit
+	}
+	return 0
+}
+
+func (x *EnrollmentTokenItem) GetUses() int32 {
+	if x != nil {
+		return x.Uses
+	}
+	return 0
+}
+
+func (x *EnrollmentTokenItem) GetHostnamePattern() string {
+	if x != nil {
+		return x.HostnamePattern
+	}
+	return ""
+}
+
+func (x *EnrollmentTokenItem) GetLabels() []string {
+	if x != nil {
+		return x.Labels
+	}
+	return nil
+}
+
+func (x *EnrollmentTokenItem) GetLogSources() []string {
 	if x != nil {
-		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
-		if ms.LoadMessageInfo() == nil {
-			ms.StoreMessageInfo(mi)
-		}
-		return ms
+		return x.LogSources
 	}
-	return mi.MessageOf(x)
+	return nil
 }
 
-// Deprecated: Use GetAgentConfigResponse.ProtoReflect.Descriptor instead.
-func (*GetAgentConfigResponse) Descriptor() ([]byte, []int) {
-	return file_api_api_proto_rawDescGZIP(), []int{10}
+func (x *EnrollmentTokenItem) GetEphemeral() bool {
+	if x != nil {
+		return x.Ephemeral
+	}
+	return false
 }
 
-func (x *GetAgentConfigResponse) GetConfig() []byte {
+func (x *EnrollmentTokenItem) GetTenant() string {
 	if x != nil {
-		return x.Config
+		return x.Tenant
 	}
-	return nil
+	return ""
 }
 
-type GetServiceInfoRequest struct {
+type ListEnrollmentTokensResponse struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
+	Tokens        []*EnrollmentTokenItem `protobuf:"bytes,1,rep,name=tokens,proto3" json:"tokens,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
-func (x *GetServiceInfoRequest) Reset() {
-	*x = GetServiceInfoRequest{}
-	mi := &file_api_api_proto_msgTypes[11]
+func (x *ListEnrollmentTokensResponse) Reset() {
+	*x = ListEnrollmentTokensResponse{}
+	mi := &file_api_api_proto_msgTypes[47]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *GetServiceInfoRequest) String() string {
+func (x *ListEnrollmentTokensResponse) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*GetServiceInfoRequest) ProtoMessage() {}
+func (*ListEnrollmentTokensResponse) ProtoMessage() {}
 
-func (x *GetServiceInfoRequest) ProtoReflect() protoreflect.Message {
-	mi := &file_api_api_proto_msgTypes[11]
+func (x *ListEnrollmentTokensResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_api_api_proto_msgTypes[47]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -640,37 +3154,41 @@ func (x *GetServiceInfoRequest) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use GetServiceInfoRequest.ProtoReflect.Descriptor instead.
-func (*GetServiceInfoRequest) Descriptor() ([]byte, []int) {
-	return file_api_api_proto_rawDescGZIP(), []int{11}
+// Deprecated: Use ListEnrollmentTokensResponse.ProtoReflect.Descriptor instead.
+func (*ListEnrollmentTokensResponse) Descriptor() ([]byte, []int) {
+	return file_api_api_proto_rawDescGZIP(), []int{47}
 }
 
-type GetServiceInfoResponse struct {
+func (x *ListEnrollmentTokensResponse) GetTokens() []*EnrollmentTokenItem {
+	if x != nil {
+		return x.Tokens
+	}
+	return nil
+}
+
+type CreateTenantRequest struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
-	Id            string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
-	Hostname      string                 `protobuf:"bytes,2,opt,name=hostname,proto3" json:"hostname,omitempty"`
-	CreatedAt     string                 `protobuf:"bytes,3,opt,name=created_at,json=createdAt,proto3" json:"created_at,omitempty"`
-	Release       string                 `protobuf:"bytes,4,opt,name=release,proto3" json:"release,omitempty"`
-	Commit        string                 `protobuf:"bytes,5,opt,name=commit,proto3" json:"commit,omitempty"`
+	Name          string                 `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"`
+	Description   string                 `protobuf:"bytes,2,opt,name=description,proto3" json:"description,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
-func (x *GetServiceInfoResponse) Reset() {
-	*x = GetServiceInfoResponse{}
-	mi := &file_api_api_proto_msgTypes[12]
+func (x *CreateTenantRequest) Reset() {
+	*x = CreateTenantRequest{}
+	mi := &file_api_api_proto_msgTypes[48]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *GetServiceInfoResponse) String() string {
+func (x *CreateTenantRequest) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*GetServiceInfoResponse) ProtoMessage() {}
+func (*CreateTenantRequest) ProtoMessage() {}
 
-func (x *GetServiceInfoResponse) ProtoReflect() protoreflect.Message {
-	mi := &file_api_api_proto_msgTypes[12]
+func (x *CreateTenantRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_api_api_proto_msgTypes[48]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -681,73 +3199,90 @@ func (x *GetServiceInfoResponse) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use GetServiceInfoResponse.ProtoReflect.Descriptor instead.
-func (*GetServiceInfoResponse) Descriptor() ([]byte, []int) {
-	return file_api_api_proto_rawDescGZIP(), []int{12}
+// Deprecated: Use CreateTenantRequest.ProtoReflect.Descriptor instead.
+func (*CreateTenantRequest) Descriptor() ([]byte, []int) {
+	return file_api_api_proto_rawDescGZIP(), []int{48}
 }
 
-func (x *GetServiceInfoResponse) GetId() string {
+func (x *CreateTenantRequest) GetName() string {
 	if x != nil {
-		return x.Id
+		return x.Name
 	}
 	return ""
 }
 
-func (x *GetServiceInfoResponse) GetHostname() string {
+func (x *CreateTenantRequest) GetDescription() string {
 	if x != nil {
-		return x.Hostname
+		return x.Description
 	}
 	return ""
 }
 
-func (x *GetServiceInfoResponse) GetCreatedAt() string {
-	if x != nil {
-		return x.CreatedAt
-	}
-	return ""
+type CreateTenantResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Name          string                 `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
 }
 
-func (x *GetServiceInfoResponse) GetRelease() string {
+func (x *CreateTenantResponse) Reset() {
+	*x = CreateTenantResponse{}
+	mi := &file_api_api_proto_msgTypes[49]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CreateTenantResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CreateTenantResponse) ProtoMessage() {}
+
+func (x *CreateTenantResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_api_api_proto_msgTypes[49]
 	if x != nil {
-		return x.Release
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
 	}
-	return ""
+	return mi.MessageOf(x)
 }
 
-func (x *GetServiceInfoResponse) GetCommit() string {
+// Deprecated: Use CreateTenantResponse.ProtoReflect.Descriptor instead.
+func (*CreateTenantResponse) Descriptor() ([]byte, []int) {
+	return file_api_api_proto_rawDescGZIP(), []int{49}
+}
+
+func (x *CreateTenantResponse) GetName() string {
 	if x != nil {
-		return x.Commit
+		return x.Name
 	}
 	return ""
 }
 
-type UpdateAgentRequest struct {
-	state          protoimpl.MessageState `protogen:"open.v1"`
-	Rid            string                 `protobuf:"bytes,1,opt,name=rid,proto3" json:"rid,omitempty"`
-	Labels         []string               `protobuf:"bytes,2,rep,name=labels,proto3" json:"labels,omitempty"`
-	LogSources     []string               `protobuf:"bytes,3,rep,name=log_sources,json=logSources,proto3" json:"log_sources,omitempty"`
-	Metrics        bool                   `protobuf:"varint,4,opt,name=metrics,proto3" json:"metrics,omitempty"`
-	MetricsTargets []string               `protobuf:"bytes,5,rep,name=metrics_targets,json=metricsTargets,proto3" json:"metrics_targets,omitempty"`
-	Profiles       bool                   `protobuf:"varint,6,opt,name=profiles,proto3" json:"profiles,omitempty"`
-	unknownFields  protoimpl.UnknownFields
-	sizeCache      protoimpl.SizeCache
+type ListTenantsRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
 }
 
-func (x *UpdateAgentRequest) Reset() {
-	*x = UpdateAgentRequest{}
-	mi := &file_api_api_proto_msgTypes[13]
+func (x *ListTenantsRequest) Reset() {
+	*x = ListTenantsRequest{}
+	mi := &file_api_api_proto_msgTypes[50]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *UpdateAgentRequest) String() string {
+func (x *ListTenantsRequest) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*UpdateAgentRequest) ProtoMessage() {}
+func (*ListTenantsRequest) ProtoMessage() {}
 
-func (x *UpdateAgentRequest) ProtoReflect() protoreflect.Message {
-	mi := &file_api_api_proto_msgTypes[13]
+func (x *ListTenantsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_api_api_proto_msgTypes[50]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -758,74 +3293,93 @@ func (x *UpdateAgentRequest) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use UpdateAgentRequest.ProtoReflect.Descriptor instead.
-func (*UpdateAgentRequest) Descriptor() ([]byte, []int) {
-	return file_api_api_proto_rawDescGZIP(), []int{13}
+// Deprecated: Use ListTenantsRequest.ProtoReflect.Descriptor instead.
+func (*ListTenantsRequest) Descriptor() ([]byte, []int) {
+	return file_api_api_proto_rawDescGZIP(), []int{50}
 }
 
-func (x *UpdateAgentRequest) GetRid() string {
-	if x != nil {
-		return x.Rid
-	}
-	return ""
+type TenantItem struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Name          string                 `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"`
+	Description   string                 `protobuf:"bytes,2,opt,name=description,proto3" json:"description,omitempty"`
+	CreatedAt     string                 `protobuf:"bytes,3,opt,name=created_at,json=createdAt,proto3" json:"created_at,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
 }
 
-func (x *UpdateAgentRequest) GetLabels() []string {
-	if x != nil {
-		return x.Labels
-	}
-	return nil
+func (x *TenantItem) Reset() {
+	*x = TenantItem{}
+	mi := &file_api_api_proto_msgTypes[51]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
 }
 
-func (x *UpdateAgentRequest) GetLogSources() []string {
+func (x *TenantItem) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*TenantItem) ProtoMessage() {}
+
+func (x *TenantItem) ProtoReflect() protoreflect.Message {
+	mi := &file_api_api_proto_msgTypes[51]
 	if x != nil {
-		return x.LogSources
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
 	}
-	return nil
+	return mi.MessageOf(x)
 }
 
-func (x *UpdateAgentRequest) GetMetrics() bool {
+// Deprecated: Use TenantItem.ProtoReflect.Descriptor instead.
+func (*TenantItem) Descriptor() ([]byte, []int) {
+	return file_api_api_proto_rawDescGZIP(), []int{51}
+}
+
+func (x *TenantItem) GetName() string {
 	if x != nil {
-		return x.Metrics
+		return x.Name
 	}
-	return false
+	return ""
 }
 
-func (x *UpdateAgentRequest) GetMetricsTargets() []string {
+func (x *TenantItem) GetDescription() string {
 	if x != nil {
-		return x.MetricsTargets
+		return x.Description
 	}
-	return nil
+	return ""
 }
 
-func (x *UpdateAgentRequest) GetProfiles() bool {
+func (x *TenantItem) GetCreatedAt() string {
 	if x != nil {
-		return x.Profiles
+		return x.CreatedAt
 	}
-	return false
+	return ""
 }
 
-type UpdateAgentResponse struct {
+type ListTenantsResponse struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
+	Tenants       []*TenantItem          `protobuf:"bytes,1,rep,name=tenants,proto3" json:"tenants,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
-func (x *UpdateAgentResponse) Reset() {
-	*x = UpdateAgentResponse{}
-	mi := &file_api_api_proto_msgTypes[14]
+func (x *ListTenantsResponse) Reset() {
+	*x = ListTenantsResponse{}
+	mi := &file_api_api_proto_msgTypes[52]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *UpdateAgentResponse) String() string {
+func (x *ListTenantsResponse) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*UpdateAgentResponse) ProtoMessage() {}
+func (*ListTenantsResponse) ProtoMessage() {}
 
-func (x *UpdateAgentResponse) ProtoReflect() protoreflect.Message {
-	mi := &file_api_api_proto_msgTypes[14]
+func (x *ListTenantsResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_api_api_proto_msgTypes[52]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -836,35 +3390,40 @@ func (x *UpdateAgentResponse) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use UpdateAgentResponse.ProtoReflect.Descriptor instead.
-func (*UpdateAgentResponse) Descriptor() ([]byte, []int) {
-	return file_api_api_proto_rawDescGZIP(), []int{14}
+// Deprecated: Use ListTenantsResponse.ProtoReflect.Descriptor instead.
+func (*ListTenantsResponse) Descriptor() ([]byte, []int) {
+	return file_api_api_proto_rawDescGZIP(), []int{52}
 }
 
-type GetDashboardTokenRequest struct {
-	state          protoimpl.MessageState `protogen:"open.v1"`
-	SessionTimeout *int32                 `protobuf:"varint,1,opt,name=session_timeout,json=sessionTimeout,proto3,oneof" json:"session_timeout,omitempty"`
-	Role           string                 `protobuf:"bytes,2,opt,name=role,proto3" json:"role,omitempty"`
-	Scope          []string               `protobuf:"bytes,3,rep,name=scope,proto3" json:"scope,omitempty"`
-	unknownFields  protoimpl.UnknownFields
-	sizeCache      protoimpl.SizeCache
+func (x *ListTenantsResponse) GetTenants() []*TenantItem {
+	if x != nil {
+		return x.Tenants
+	}
+	return nil
 }
 
-func (x *GetDashboardTokenRequest) Reset() {
-	*x = GetDashboardTokenRequest{}
-	mi := &file_api_api_proto_msgTypes[15]
+type DeleteTenantRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Name          string                 `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *DeleteTenantRequest) Reset() {
+	*x = DeleteTenantRequest{}
+	mi := &file_api_api_proto_msgTypes[53]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *GetDashboardTokenRequest) String() string {
+func (x *DeleteTenantRequest) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*GetDashboardTokenRequest) ProtoMessage() {}
+func (*DeleteTenantRequest) ProtoMessage() {}
 
-func (x *GetDashboardTokenRequest) ProtoReflect() protoreflect.Message {
-	mi := &file_api_api_proto_msgTypes[15]
+func (x *DeleteTenantRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_api_api_proto_msgTypes[53]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -875,56 +3434,39 @@ func (x *GetDashboardTokenRequest) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use GetDashboardTokenRequest.ProtoReflect.Descriptor instead.
-func (*GetDashboardTokenRequest) Descriptor() ([]byte, []int) {
-	return file_api_api_proto_rawDescGZIP(), []int{15}
-}
-
-func (x *GetDashboardTokenRequest) GetSessionTimeout() int32 {
-	if x != nil && x.SessionTimeout != nil {
-		return *x.SessionTimeout
-	}
-	return 0
+// Deprecated: Use DeleteTenantRequest.ProtoReflect.Descriptor instead.
+func (*DeleteTenantRequest) Descriptor() ([]byte, []int) {
+	return file_api_api_proto_rawDescGZIP(), []int{53}
 }
 
-func (x *GetDashboardTokenRequest) GetRole() string {
+func (x *DeleteTenantRequest) GetName() string {
 	if x != nil {
-		return x.Role
+		return x.Name
 	}
 	return ""
 }
 
-func (x *GetDashboardTokenRequest) GetScope() []string {
-	if x != nil {
-		return x.Scope
-	}
-	return nil
-}
-
-type GetDashboardTokenResponse struct {
+type DeleteTenantResponse struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
-	Token         string                 `protobuf:"bytes,1,opt,name=token,proto3" json:"token,omitempty"`
-	ExpiresAt     string                 `protobuf:"bytes,2,opt,name=expires_at,json=expiresAt,proto3" json:"expires_at,omitempty"`
-	DashboardUrl  string                 `protobuf:"bytes,3,opt,name=dashboard_url,json=dashboardUrl,proto3" json:"dashboard_url,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
-func (x *GetDashboardTokenResponse) Reset() {
-	*x = GetDashboardTokenResponse{}
-	mi := &file_api_api_proto_msgTypes[16]
+func (x *DeleteTenantResponse) Reset() {
+	*x = DeleteTenantResponse{}
+	mi := &file_api_api_proto_msgTypes[54]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *GetDashboardTokenResponse) String() string {
+func (x *DeleteTenantResponse) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*GetDashboardTokenResponse) ProtoMessage() {}
+func (*DeleteTenantResponse) ProtoMessage() {}
 
-func (x *GetDashboardTokenResponse) ProtoReflect() protoreflect.Message {
-	mi := &file_api_api_proto_msgTypes[16]
+func (x *DeleteTenantResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_api_api_proto_msgTypes[54]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -935,37 +3477,16 @@ func (x *GetDashboardTokenResponse) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use GetDashboardTokenResponse.ProtoReflect.Descriptor instead.
-func (*GetDashboardTokenResponse) Descriptor() ([]byte, []int) {
-	return file_api_api_proto_rawDescGZIP(), []int{16}
-}
-
-func (x *GetDashboardTokenResponse) GetToken() string {
-	if x != nil {
-		return x.Token
-	}
-	return ""
-}
-
-func (x *GetDashboardTokenResponse) GetExpiresAt() string {
-	if x != nil {
-		return x.ExpiresAt
-	}
-	return ""
-}
-
-func (x *GetDashboardTokenResponse) GetDashboardUrl() string {
-	if x != nil {
-		return x.DashboardUrl
-	}
-	return ""
+// Deprecated: Use DeleteTenantResponse.ProtoReflect.Descriptor instead.
+func (*DeleteTenantResponse) Descriptor() ([]byte, []int) {
+	return file_api_api_proto_rawDescGZIP(), []int{54}
 }
 
 var File_api_api_proto protoreflect.FileDescriptor
 
 const file_api_api_proto_rawDesc = "" +
 	"\n" +
-	"\rapi/api.proto\x12\x05finch\"\xde\x01\n" +
+	"\rapi/api.proto\x12\x05finch\x1a google/protobuf/field_mask.proto\"\xb3\x03\n" +
 	"\x14RegisterAgentRequest\x12\x1a\n" +
 	"\bhostname\x18\x01 \x01(\tR\bhostname\x12\x16\n" +
 	"\x06labels\x18\x02 \x03(\tR\x06labels\x12\x1f\n" +
@@ -974,14 +3495,27 @@ const file_api_api_proto_rawDesc = "" +
 	"\ametrics\x18\x04 \x01(\bR\ametrics\x12'\n" +
 	"\x0fmetrics_targets\x18\x05 \x03(\tR\x0emetricsTargets\x12\x1a\n" +
 	"\bprofiles\x18\x06 \x01(\bR\bprofiles\x12\x12\n" +
-	"\x04node\x18\a \x01(\tR\x04node\")\n" +
+	"\x04node\x18\a \x01(\tR\x04node\x12\x1c\n" +
+	"\tephemeral\x18\b \x01(\bR\tephemeral\x12\x16\n" +
+	"\x06tenant\x18\t \x01(\tR\x06tenant\x12L\n" +
+	"\vrate_limits\x18\n" +
+	" \x03(\v2+.finch.RegisterAgentRequest.RateLimitsEntryR\n" +
+	"rateLimits\x1aO\n" +
+	"\x0fRateLimitsEntry\x12\x10\n" +
+	"\x03key\x18\x01 \x01(\tR\x03key\x12&\n" +
+	"\x05value\x18\x02 \x01(\v2\x10.finch.RateLimitR\x05value:\x028\x01\"5\n" +
+	"\tRateLimit\x12\x12\n" +
+	"\x04rate\x18\x01 \x01(\x01R\x04rate\x12\x14\n" +
+	"\x05burst\x18\x02 \x01(\x05R\x05burst\")\n" +
 	"\x15RegisterAgentResponse\x12\x10\n" +
-	"\x03rid\x18\x01 \x01(\tR\x03rid\"*\n" +
+	"\x03rid\x18\x01 \x01(\tR\x03rid\"o\n" +
 	"\x16DeregisterAgentRequest\x12\x10\n" +
-	"\x03rid\x18\x01 \x01(\tR\x03rid\"\x19\n" +
+	"\x03rid\x18\x01 \x01(\tR\x03rid\x12.\n" +
+	"\x10resource_version\x18\x02 \x01(\x04H\x00R\x0fresourceVersion\x88\x01\x01B\x13\n" +
+	"\x11_resource_version\"\x19\n" +
 	"\x17DeregisterAgentResponse\"#\n" +
 	"\x0fGetAgentRequest\x12\x10\n" +
-	"\x03rid\x18\x01 \x01(\tR\x03rid\"\x9a\x02\n" +
+	"\x03rid\x18\x01 \x01(\tR\x03rid\"\xbe\x05\n" +
 	"\x10GetAgentResponse\x12\x1f\n" +
 	"\vresource_id\x18\x01 \x01(\tR\n" +
 	"resourceId\x12\x1a\n" +
@@ -994,13 +3528,81 @@ const file_api_api_proto_rawDesc = "" +
 	"\bprofiles\x18\a \x01(\bR\bprofiles\x12\x1d\n" +
 	"\n" +
 	"created_at\x18\b \x01(\tR\tcreatedAt\x12\x12\n" +
-	"\x04node\x18\t \x01(\tR\x04node\"\x13\n" +
-	"\x11ListAgentsRequest\"=\n" +
+	"\x04node\x18\t \x01(\tR\x04node\x12\x1b\n" +
+	"\tlast_seen\x18\n" +
+	" \x01(\tR\blastSeen\x12\x16\n" +
+	"\x06active\x18\v \x01(\bR\x06active\x12%\n" +
+	"\x0etokens_revoked\x18\f \x01(\tR\rtokensRevoked\x12\x14\n" +
+	"\x05stale\x18\r \x01(\bR\x05stale\x12\x1c\n" +
+	"\tephemeral\x18\x0e \x01(\bR\tephemeral\x12)\n" +
+	"\x10resource_version\x18\x0f \x01(\x04R\x0fresourceVersion\x124\n" +
+	"\n" +
+	"collectors\x18\x10 \x03(\v2\x14.finch.CollectorItemR\n" +
+	"collectors\x12\x16\n" +
+	"\x06tenant\x18\x11 \x01(\tR\x06tenant\x12H\n" +
+	"\vrate_limits\x18\x12 \x03(\v2'.finch.GetAgentResponse.RateLimitsEntryR\n" +
+	"rateLimits\x1aO\n" +
+	"\x0fRateLimitsEntry\x12\x10\n" +
+	"\x03key\x18\x01 \x01(\tR\x03key\x12&\n" +
+	"\x05value\x18\x02 \x01(\v2\x10.finch.RateLimitR\x05value:\x028\x01\"\xf6\x01\n" +
+	"\rCollectorItem\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\tR\x02id\x12\x12\n" +
+	"\x04name\x18\x02 \x01(\tR\x04name\x12D\n" +
+	"\n" +
+	"attributes\x18\x03 \x03(\v2$.finch.CollectorItem.AttributesEntryR\n" +
+	"attributes\x12\x1f\n" +
+	"\vconfig_hash\x18\x04 \x01(\tR\n" +
+	"configHash\x12\x1b\n" +
+	"\tlast_poll\x18\x05 \x01(\tR\blastPoll\x1a=\n" +
+	"\x0fAttributesEntry\x12\x10\n" +
+	"\x03key\x18\x01 \x01(\tR\x03key\x12\x14\n" +
+	"\x05value\x18\x02 \x01(\tR\x05value:\x028\x01\"\xe8\x03\n" +
+	"\x11ListAgentsRequest\x12%\n" +
+	"\x0elabel_selector\x18\x01 \x01(\tR\rlabelSelector\x12\x17\n" +
+	"\x04node\x18\x02 \x01(\tH\x00R\x04node\x88\x01\x01\x12\x1d\n" +
+	"\ametrics\x18\x03 \x01(\bH\x01R\ametrics\x88\x01\x01\x12\x1f\n" +
+	"\bprofiles\x18\x04 \x01(\bH\x02R\bprofiles\x88\x01\x01\x12\x1b\n" +
+	"\x06active\x18\x05 \x01(\bH\x03R\x06active\x88\x01\x01\x12\x19\n" +
+	"\x05stale\x18\x06 \x01(\bH\x04R\x05stale\x88\x01\x01\x12/\n" +
+	"\x11log_source_scheme\x18\a \x01(\tH\x05R\x0flogSourceScheme\x88\x01\x01\x12\x1b\n" +
+	"\tpage_size\x18\b \x01(\x05R\bpageSize\x12\x1d\n" +
+	"\n" +
+	"page_token\x18\t \x01(\tR\tpageToken\x129\n" +
+	"\n" +
+	"field_mask\x18\n" +
+	" \x01(\v2\x1a.google.protobuf.FieldMaskR\tfieldMask\x12\x1b\n" +
+	"\x06tenant\x18\v \x01(\tH\x06R\x06tenant\x88\x01\x01B\a\n" +
+	"\x05_nodeB\n" +
+	"\n" +
+	"\b_metricsB\v\n" +
+	"\t_profilesB\t\n" +
+	"\a_activeB\b\n" +
+	"\x06_staleB\x14\n" +
+	"\x12_log_source_schemeB\t\n" +
+	"\a_tenant\"\xdb\x03\n" +
 	"\rAgentListItem\x12\x10\n" +
 	"\x03rid\x18\x01 \x01(\tR\x03rid\x12\x1a\n" +
-	"\bhostname\x18\x02 \x01(\tR\bhostname\"B\n" +
+	"\bhostname\x18\x02 \x01(\tR\bhostname\x12\x1b\n" +
+	"\tlast_seen\x18\x03 \x01(\tR\blastSeen\x12\x16\n" +
+	"\x06active\x18\x04 \x01(\bR\x06active\x12\x14\n" +
+	"\x05stale\x18\x05 \x01(\bR\x05stale\x12\x16\n" +
+	"\x06labels\x18\x06 \x03(\tR\x06labels\x12\x1f\n" +
+	"\vlog_sources\x18\a \x03(\tR\n" +
+	"logSources\x12\x18\n" +
+	"\ametrics\x18\b \x01(\bR\ametrics\x12'\n" +
+	"\x0fmetrics_targets\x18\t \x03(\tR\x0emetricsTargets\x12\x1a\n" +
+	"\bprofiles\x18\n" +
+	" \x01(\bR\bprofiles\x12\x1d\n" +
+	"\n" +
+	"created_at\x18\v \x01(\tR\tcreatedAt\x12\x12\n" +
+	"\x04node\x18\f \x01(\tR\x04node\x12%\n" +
+	"\x0etokens_revoked\x18\r \x01(\tR\rtokensRevoked\x12\x1c\n" +
+	"\tephemeral\x18\x0e \x01(\bR\tephemeral\x12)\n" +
+	"\x10resource_version\x18\x0f \x01(\x04R\x0fresourceVersion\x12\x16\n" +
+	"\x06tenant\x18\x10 \x01(\tR\x06tenant\"j\n" +
 	"\x12ListAgentsResponse\x12,\n" +
-	"\x06agents\x18\x01 \x03(\v2\x14.finch.AgentListItemR\x06agents\")\n" +
+	"\x06agents\x18\x01 \x03(\v2\x14.finch.AgentListItemR\x06agents\x12&\n" +
+	"\x0fnext_page_token\x18\x02 \x01(\tR\rnextPageToken\")\n" +
 	"\x15GetAgentConfigRequest\x12\x10\n" +
 	"\x03rid\x18\x01 \x01(\tR\x03rid\"0\n" +
 	"\x16GetAgentConfigResponse\x12\x16\n" +
@@ -1012,7 +3614,7 @@ const file_api_api_proto_rawDesc = "" +
 	"\n" +
 	"created_at\x18\x03 \x01(\tR\tcreatedAt\x12\x18\n" +
 	"\arelease\x18\x04 \x01(\tR\arelease\x12\x16\n" +
-	"\x06commit\x18\x05 \x01(\tR\x06commit\"\xbe\x01\n" +
+	"\x06commit\x18\x05 \x01(\tR\x06commit\"\xdd\x03\n" +
 	"\x12UpdateAgentRequest\x12\x10\n" +
 	"\x03rid\x18\x01 \x01(\tR\x03rid\x12\x16\n" +
 	"\x06labels\x18\x02 \x03(\tR\x06labels\x12\x1f\n" +
@@ -1020,8 +3622,103 @@ const file_api_api_proto_rawDesc = "" +
 	"logSources\x12\x18\n" +
 	"\ametrics\x18\x04 \x01(\bR\ametrics\x12'\n" +
 	"\x0fmetrics_targets\x18\x05 \x03(\tR\x0emetricsTargets\x12\x1a\n" +
-	"\bprofiles\x18\x06 \x01(\bR\bprofiles\"\x15\n" +
-	"\x13UpdateAgentResponse\"\x86\x01\n" +
+	"\bprofiles\x18\x06 \x01(\bR\bprofiles\x12;\n" +
+	"\vupdate_mask\x18\a \x01(\v2\x1a.google.protobuf.FieldMaskR\n" +
+	"updateMask\x12.\n" +
+	"\x10resource_version\x18\b \x01(\x04H\x00R\x0fresourceVersion\x88\x01\x01\x12J\n" +
+	"\vrate_limits\x18\t \x03(\v2).finch.UpdateAgentRequest.RateLimitsEntryR\n" +
+	"rateLimits\x1aO\n" +
+	"\x0fRateLimitsEntry\x12\x10\n" +
+	"\x03key\x18\x01 \x01(\tR\x03key\x12&\n" +
+	"\x05value\x18\x02 \x01(\v2\x10.finch.RateLimitR\x05value:\x028\x01B\x13\n" +
+	"\x11_resource_version\"\x15\n" +
+	"\x13UpdateAgentResponse\"T\n" +
+	"\x18RevokeAgentTokensRequest\x12\x10\n" +
+	"\x03rid\x18\x01 \x01(\tR\x03rid\x12\x1b\n" +
+	"\x06before\x18\x02 \x01(\tH\x00R\x06before\x88\x01\x01B\t\n" +
+	"\a_before\"B\n" +
+	"\x19RevokeAgentTokensResponse\x12%\n" +
+	"\x0erevoked_before\x18\x01 \x01(\tR\rrevokedBefore\"z\n" +
+	"\x16ListAgentTokensRequest\x12\x10\n" +
+	"\x03rid\x18\x01 \x01(\tR\x03rid\x125\n" +
+	"\x14expiring_within_days\x18\x02 \x01(\x05H\x00R\x12expiringWithinDays\x88\x01\x01B\x17\n" +
+	"\x15_expiring_within_days\"\xbc\x01\n" +
+	"\x0eAgentTokenItem\x12\x10\n" +
+	"\x03jti\x18\x01 \x01(\tR\x03jti\x12\x10\n" +
+	"\x03rid\x18\x02 \x01(\tR\x03rid\x12\x1b\n" +
+	"\tissued_at\x18\x03 \x01(\tR\bissuedAt\x12\x1d\n" +
+	"\n" +
+	"expires_at\x18\x04 \x01(\tR\texpiresAt\x12\x16\n" +
+	"\x06issuer\x18\x05 \x01(\tR\x06issuer\x12\x18\n" +
+	"\apurpose\x18\x06 \x01(\tR\apurpose\x12\x18\n" +
+	"\arevoked\x18\a \x01(\bR\arevoked\"H\n" +
+	"\x17ListAgentTokensResponse\x12-\n" +
+	"\x06tokens\x18\x01 \x03(\v2\x15.finch.AgentTokenItemR\x06tokens\"l\n" +
+	"\x13SuspendAgentRequest\x12\x10\n" +
+	"\x03rid\x18\x01 \x01(\tR\x03rid\x12.\n" +
+	"\x10resource_version\x18\x02 \x01(\x04H\x00R\x0fresourceVersion\x88\x01\x01B\x13\n" +
+	"\x11_resource_version\"\x16\n" +
+	"\x14SuspendAgentResponse\"k\n" +
+	"\x12ResumeAgentRequest\x12\x10\n" +
+	"\x03rid\x18\x01 \x01(\tR\x03rid\x12.\n" +
+	"\x10resource_version\x18\x02 \x01(\x04H\x00R\x0fresourceVersion\x88\x01\x01B\x13\n" +
+	"\x11_resource_version\"\x15\n" +
+	"\x13ResumeAgentResponse\"S\n" +
+	"\x12WatchAgentsRequest\x12*\n" +
+	"\x0esince_revision\x18\x01 \x01(\x04H\x00R\rsinceRevision\x88\x01\x01B\x11\n" +
+	"\x0f_since_revision\"\x9a\x01\n" +
+	"\x13WatchAgentsResponse\x12\x1a\n" +
+	"\brevision\x18\x01 \x01(\x04R\brevision\x12\x12\n" +
+	"\x04type\x18\x02 \x01(\tR\x04type\x12\x10\n" +
+	"\x03rid\x18\x03 \x01(\tR\x03rid\x12\x1a\n" +
+	"\bhostname\x18\x04 \x01(\tR\bhostname\x12%\n" +
+	"\x0echanged_fields\x18\x05 \x03(\tR\rchangedFields\"3\n" +
+	"\x1fListAgentConfigRevisionsRequest\x12\x10\n" +
+	"\x03rid\x18\x01 \x01(\tR\x03rid\"\xa5\x02\n" +
+	"\x17AgentConfigRevisionItem\x12\x1a\n" +
+	"\brevision\x18\x01 \x01(\x04R\brevision\x12\x1d\n" +
+	"\n" +
+	"created_at\x18\x02 \x01(\tR\tcreatedAt\x12\x16\n" +
+	"\x06author\x18\x03 \x01(\tR\x06author\x12\x1f\n" +
+	"\vconfig_hash\x18\x04 \x01(\tR\n" +
+	"configHash\x12\x16\n" +
+	"\x06labels\x18\x05 \x03(\tR\x06labels\x12\x1f\n" +
+	"\vlog_sources\x18\x06 \x03(\tR\n" +
+	"logSources\x12\x18\n" +
+	"\ametrics\x18\a \x01(\bR\ametrics\x12'\n" +
+	"\x0fmetrics_targets\x18\b \x03(\tR\x0emetricsTargets\x12\x1a\n" +
+	"\bprofiles\x18\t \x01(\bR\bprofiles\"`\n" +
+	" ListAgentConfigRevisionsResponse\x12<\n" +
+	"\trevisions\x18\x01 \x03(\v2\x1e.finch.AgentConfigRevisionItemR\trevisions\"\x85\x01\n" +
+	"\x16DiffAgentConfigRequest\x12\x10\n" +
+	"\x03rid\x18\x01 \x01(\tR\x03rid\x12#\n" +
+	"\rfrom_revision\x18\x02 \x01(\x04R\ffromRevision\x12$\n" +
+	"\vto_revision\x18\x03 \x01(\x04H\x00R\n" +
+	"toRevision\x88\x01\x01B\x0e\n" +
+	"\f_to_revision\"-\n" +
+	"\x17DiffAgentConfigResponse\x12\x12\n" +
+	"\x04diff\x18\x01 \x01(\tR\x04diff\"\x8f\x01\n" +
+	"\x1aRollbackAgentConfigRequest\x12\x10\n" +
+	"\x03rid\x18\x01 \x01(\tR\x03rid\x12\x1a\n" +
+	"\brevision\x18\x02 \x01(\x04R\brevision\x12.\n" +
+	"\x10resource_version\x18\x03 \x01(\x04H\x00R\x0fresourceVersion\x88\x01\x01B\x13\n" +
+	"\x11_resource_version\"9\n" +
+	"\x1bRollbackAgentConfigResponse\x12\x1a\n" +
+	"\brevision\x18\x01 \x01(\x04R\brevision\"r\n" +
+	"\x14GetAgentUsageRequest\x12\x10\n" +
+	"\x03rid\x18\x01 \x01(\tR\x03rid\x12\x19\n" +
+	"\x05since\x18\x02 \x01(\tH\x00R\x05since\x88\x01\x01\x12\x19\n" +
+	"\x05until\x18\x03 \x01(\tH\x01R\x05until\x88\x01\x01B\b\n" +
+	"\x06_sinceB\b\n" +
+	"\x06_until\"t\n" +
+	"\x0eAgentUsageItem\x12\x18\n" +
+	"\abackend\x18\x01 \x01(\tR\abackend\x12\x16\n" +
+	"\x06bucket\x18\x02 \x01(\tR\x06bucket\x12\x1a\n" +
+	"\brequests\x18\x03 \x01(\x03R\brequests\x12\x14\n" +
+	"\x05bytes\x18\x04 \x01(\x03R\x05bytes\"w\n" +
+	"\x15GetAgentUsageResponse\x12/\n" +
+	"\abuckets\x18\x01 \x03(\v2\x15.finch.AgentUsageItemR\abuckets\x12-\n" +
+	"\x06totals\x18\x02 \x03(\v2\x15.finch.AgentUsageItemR\x06totals\"\x86\x01\n" +
 	"\x18GetDashboardTokenRequest\x12,\n" +
 	"\x0fsession_timeout\x18\x01 \x01(\x05H\x00R\x0esessionTimeout\x88\x01\x01\x12\x12\n" +
 	"\x04role\x18\x02 \x01(\tR\x04role\x12\x14\n" +
@@ -1031,7 +3728,81 @@ const file_api_api_proto_rawDesc = "" +
 	"\x05token\x18\x01 \x01(\tR\x05token\x12\x1d\n" +
 	"\n" +
 	"expires_at\x18\x02 \x01(\tR\texpiresAt\x12#\n" +
-	"\rdashboard_url\x18\x03 \x01(\tR\fdashboardUrl2\xc1\x03\n" +
+	"\rdashboard_url\x18\x03 \x01(\tR\fdashboardUrl\"\xc7\x01\n" +
+	"\x16ListAuditEventsRequest\x12\x19\n" +
+	"\x05since\x18\x01 \x01(\tH\x00R\x05since\x88\x01\x01\x12\x19\n" +
+	"\x05until\x18\x02 \x01(\tH\x01R\x05until\x88\x01\x01\x12\x14\n" +
+	"\x05actor\x18\x03 \x01(\tR\x05actor\x12\x16\n" +
+	"\x06action\x18\x04 \x01(\tR\x06action\x12\x10\n" +
+	"\x03rid\x18\x05 \x01(\tR\x03rid\x12\x19\n" +
+	"\x05limit\x18\x06 \x01(\x05H\x02R\x05limit\x88\x01\x01B\b\n" +
+	"\x06_sinceB\b\n" +
+	"\x06_untilB\b\n" +
+	"\x06_limit\"\xaf\x01\n" +
+	"\x0eAuditEventItem\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\x04R\x02id\x12\x1c\n" +
+	"\ttimestamp\x18\x02 \x01(\tR\ttimestamp\x12\x14\n" +
+	"\x05actor\x18\x03 \x01(\tR\x05actor\x12\x16\n" +
+	"\x06action\x18\x04 \x01(\tR\x06action\x12\x10\n" +
+	"\x03rid\x18\x05 \x01(\tR\x03rid\x12\x12\n" +
+	"\x04diff\x18\x06 \x01(\tR\x04diff\x12\x1b\n" +
+	"\tsource_ip\x18\a \x01(\tR\bsourceIp\"H\n" +
+	"\x17ListAuditEventsResponse\x12-\n" +
+	"\x06events\x18\x01 \x03(\v2\x15.finch.AuditEventItemR\x06events\"\xa1\x02\n" +
+	"\x1cCreateEnrollmentTokenRequest\x12$\n" +
+	"\vusage_limit\x18\x01 \x01(\x05H\x00R\n" +
+	"usageLimit\x88\x01\x01\x12\"\n" +
+	"\n" +
+	"expires_in\x18\x02 \x01(\x03H\x01R\texpiresIn\x88\x01\x01\x12)\n" +
+	"\x10hostname_pattern\x18\x03 \x01(\tR\x0fhostnamePattern\x12\x16\n" +
+	"\x06labels\x18\x04 \x03(\tR\x06labels\x12\x1f\n" +
+	"\vlog_sources\x18\x05 \x03(\tR\n" +
+	"logSources\x12\x1c\n" +
+	"\tephemeral\x18\x06 \x01(\bR\tephemeral\x12\x16\n" +
+	"\x06tenant\x18\a \x01(\tR\x06tenantB\x0e\n" +
+	"\f_usage_limitB\r\n" +
+	"\v_expires_in\"f\n" +
+	"\x1dCreateEnrollmentTokenResponse\x12\x14\n" +
+	"\x05token\x18\x01 \x01(\tR\x05token\x12\x10\n" +
+	"\x03jti\x18\x02 \x01(\tR\x03jti\x12\x1d\n" +
+	"\n" +
+	"expires_at\x18\x03 \x01(\tR\texpiresAt\"\x1d\n" +
+	"\x1bListEnrollmentTokensRequest\"\xca\x02\n" +
+	"\x13EnrollmentTokenItem\x12\x10\n" +
+	"\x03jti\x18\x01 \x01(\tR\x03jti\x12\x1b\n" +
+	"\tissued_at\x18\x02 \x01(\tR\bissuedAt\x12\x1d\n" +
+	"\n" +
+	"expires_at\x18\x03 \x01(\tR\texpiresAt\x12\x16\n" +
+	"\x06issuer\x18\x04 \x01(\tR\x06issuer\x12\x1f\n" +
+	"\vusage_limit\x18\x05 \x01(\x05R\n" +
+	"usageLimit\x12\x12\n" +
+	"\x04uses\x18\x06 \x01(\x05R\x04uses\x12)\n" +
+	"\x10hostname_pattern\x18\a \x01(\tR\x0fhostnamePattern\x12\x16\n" +
+	"\x06labels\x18\b \x03(\tR\x06labels\x12\x1f\n" +
+	"\vlog_sources\x18\t \x03(\tR\n" +
+	"logSources\x12\x1c\n" +
+	"\tephemeral\x18\n" +
+	" \x01(\bR\tephemeral\x12\x16\n" +
+	"\x06tenant\x18\v \x01(\tR\x06tenant\"R\n" +
+	"\x1cListEnrollmentTokensResponse\x122\n" +
+	"\x06tokens\x18\x01 \x03(\v2\x1a.finch.EnrollmentTokenItemR\x06tokens\"K\n" +
+	"\x13CreateTenantRequest\x12\x12\n" +
+	"\x04name\x18\x01 \x01(\tR\x04name\x12 \n" +
+	"\vdescription\x18\x02 \x01(\tR\vdescription\"*\n" +
+	"\x14CreateTenantResponse\x12\x12\n" +
+	"\x04name\x18\x01 \x01(\tR\x04name\"\x14\n" +
+	"\x12ListTenantsRequest\"a\n" +
+	"\n" +
+	"TenantItem\x12\x12\n" +
+	"\x04name\x18\x01 \x01(\tR\x04name\x12 \n" +
+	"\vdescription\x18\x02 \x01(\tR\vdescription\x12\x1d\n" +
+	"\n" +
+	"created_at\x18\x03 \x01(\tR\tcreatedAt\"B\n" +
+	"\x13ListTenantsResponse\x12+\n" +
+	"\atenants\x18\x01 \x03(\v2\x11.finch.TenantItemR\atenants\")\n" +
+	"\x13DeleteTenantRequest\x12\x12\n" +
+	"\x04name\x18\x01 \x01(\tR\x04name\"\x16\n" +
+	"\x14DeleteTenantResponse2\xab\t\n" +
 	"\fAgentService\x12J\n" +
 	"\rRegisterAgent\x12\x1b.finch.RegisterAgentRequest\x1a\x1c.finch.RegisterAgentResponse\x12P\n" +
 	"\x0fDeregisterAgent\x12\x1d.finch.DeregisterAgentRequest\x1a\x1e.finch.DeregisterAgentResponse\x12;\n" +
@@ -1039,11 +3810,29 @@ const file_api_api_proto_rawDesc = "" +
 	"\n" +
 	"ListAgents\x12\x18.finch.ListAgentsRequest\x1a\x19.finch.ListAgentsResponse\x12M\n" +
 	"\x0eGetAgentConfig\x12\x1c.finch.GetAgentConfigRequest\x1a\x1d.finch.GetAgentConfigResponse\x12D\n" +
-	"\vUpdateAgent\x12\x19.finch.UpdateAgentRequest\x1a\x1a.finch.UpdateAgentResponse2\\\n" +
+	"\vUpdateAgent\x12\x19.finch.UpdateAgentRequest\x1a\x1a.finch.UpdateAgentResponse\x12V\n" +
+	"\x11RevokeAgentTokens\x12\x1f.finch.RevokeAgentTokensRequest\x1a .finch.RevokeAgentTokensResponse\x12P\n" +
+	"\x0fListAgentTokens\x12\x1d.finch.ListAgentTokensRequest\x1a\x1e.finch.ListAgentTokensResponse\x12G\n" +
+	"\fSuspendAgent\x12\x1a.finch.SuspendAgentRequest\x1a\x1b.finch.SuspendAgentResponse\x12D\n" +
+	"\vResumeAgent\x12\x19.finch.ResumeAgentRequest\x1a\x1a.finch.ResumeAgentResponse\x12F\n" +
+	"\vWatchAgents\x12\x19.finch.WatchAgentsRequest\x1a\x1a.finch.WatchAgentsResponse0\x01\x12k\n" +
+	"\x18ListAgentConfigRevisions\x12&.finch.ListAgentConfigRevisionsRequest\x1a'.finch.ListAgentConfigRevisionsResponse\x12P\n" +
+	"\x0fDiffAgentConfig\x12\x1d.finch.DiffAgentConfigRequest\x1a\x1e.finch.DiffAgentConfigResponse\x12\\\n" +
+	"\x13RollbackAgentConfig\x12!.finch.RollbackAgentConfigRequest\x1a\".finch.RollbackAgentConfigResponse\x12J\n" +
+	"\rGetAgentUsage\x12\x1b.finch.GetAgentUsageRequest\x1a\x1c.finch.GetAgentUsageResponse2\\\n" +
 	"\vInfoService\x12M\n" +
 	"\x0eGetServiceInfo\x12\x1c.finch.GetServiceInfoRequest\x1a\x1d.finch.GetServiceInfoResponse2j\n" +
 	"\x10DashboardService\x12V\n" +
-	"\x11GetDashboardToken\x12\x1f.finch.GetDashboardTokenRequest\x1a .finch.GetDashboardTokenResponseB$Z\"github.com/tschaefer/finch/api;apib\x06proto3"
+	"\x11GetDashboardToken\x12\x1f.finch.GetDashboardTokenRequest\x1a .finch.GetDashboardTokenResponse2`\n" +
+	"\fAuditService\x12P\n" +
+	"\x0fListAuditEvents\x12\x1d.finch.ListAuditEventsRequest\x1a\x1e.finch.ListAuditEventsResponse2\xe7\x01\n" +
+	"\rTenantService\x12G\n" +
+	"\fCreateTenant\x12\x1a.finch.CreateTenantRequest\x1a\x1b.finch.CreateTenantResponse\x12D\n" +
+	"\vListTenants\x12\x19.finch.ListTenantsRequest\x1a\x1a.finch.ListTenantsResponse\x12G\n" +
+	"\fDeleteTenant\x12\x1a.finch.DeleteTenantRequest\x1a\x1b.finch.DeleteTenantResponse2\xd8\x01\n" +
+	"\x11EnrollmentService\x12b\n" +
+	"\x15CreateEnrollmentToken\x12#.finch.CreateEnrollmentTokenRequest\x1a$.finch.CreateEnrollmentTokenResponse\x12_\n" +
+	"\x14ListEnrollmentTokens\x12\".finch.ListEnrollmentTokensRequest\x1a#.finch.ListEnrollmentTokensResponseB$Z\"github.com/tschaefer/finch/api;apib\x06proto3"
 
 var (
 	file_api_api_proto_rawDescOnce sync.Once
@@ -1057,49 +3846,139 @@ func file_api_api_proto_rawDescGZIP() []byte {
 	return file_api_api_proto_rawDescData
 }
 
-var file_api_api_proto_msgTypes = make([]protoimpl.MessageInfo, 17)
+var file_api_api_proto_msgTypes = make([]protoimpl.MessageInfo, 59)
 var file_api_api_proto_goTypes = []any{
-	(*RegisterAgentRequest)(nil),      // 0: finch.RegisterAgentRequest
-	(*RegisterAgentResponse)(nil),     // 1: finch.RegisterAgentResponse
-	(*DeregisterAgentRequest)(nil),    // 2: finch.DeregisterAgentRequest
-	(*DeregisterAgentResponse)(nil),   // 3: finch.DeregisterAgentResponse
-	(*GetAgentRequest)(nil),           // 4: finch.GetAgentRequest
-	(*GetAgentResponse)(nil),          // 5: finch.GetAgentResponse
-	(*ListAgentsRequest)(nil),         // 6: finch.ListAgentsRequest
-	(*AgentListItem)(nil),             // 7: finch.AgentListItem
-	(*ListAgentsResponse)(nil),        // 8: finch.ListAgentsResponse
-	(*GetAgentConfigRequest)(nil),     // 9: finch.GetAgentConfigRequest
-	(*GetAgentConfigResponse)(nil),    // 10: finch.GetAgentConfigResponse
-	(*GetServiceInfoRequest)(nil),     // 11: finch.GetServiceInfoRequest
-	(*GetServiceInfoResponse)(nil),    // 12: finch.GetServiceInfoResponse
-	(*UpdateAgentRequest)(nil),        // 13: finch.UpdateAgentRequest
-	(*UpdateAgentResponse)(nil),       // 14: finch.UpdateAgentResponse
-	(*GetDashboardTokenRequest)(nil),  // 15: finch.GetDashboardTokenRequest
-	(*GetDashboardTokenResponse)(nil), // 16: finch.GetDashboardTokenResponse
+	(*RegisterAgentRequest)(nil),             // 0: finch.RegisterAgentRequest
+	(*RateLimit)(nil),                        // 1: finch.RateLimit
+	(*RegisterAgentResponse)(nil),            // 2: finch.RegisterAgentResponse
+	(*DeregisterAgentRequest)(nil),           // 3: finch.DeregisterAgentRequest
+	(*DeregisterAgentResponse)(nil),          // 4: finch.DeregisterAgentResponse
+	(*GetAgentRequest)(nil),                  // 5: finch.GetAgentRequest
+	(*GetAgentResponse)(nil),                 // 6: finch.GetAgentResponse
+	(*CollectorItem)(nil),                    // 7: finch.CollectorItem
+	(*ListAgentsRequest)(nil),                // 8: finch.ListAgentsRequest
+	(*AgentListItem)(nil),                    // 9: finch.AgentListItem
+	(*ListAgentsResponse)(nil),               // 10: finch.ListAgentsResponse
+	(*GetAgentConfigRequest)(nil),            // 11: finch.GetAgentConfigRequest
+	(*GetAgentConfigResponse)(nil),           // 12: finch.GetAgentConfigResponse
+	(*GetServiceInfoRequest)(nil),            // 13: finch.GetServiceInfoRequest
+	(*GetServiceInfoResponse)(nil),           // 14: finch.GetServiceInfoResponse
+	(*UpdateAgentRequest)(nil),               // 15: finch.UpdateAgentRequest
+	(*UpdateAgentResponse)(nil),              // 16: finch.UpdateAgentResponse
+	(*RevokeAgentTokensRequest)(nil),         // 17: finch.RevokeAgentTokensRequest
+	(*RevokeAgentTokensResponse)(nil),        // 18: finch.RevokeAgentTokensResponse
+	(*ListAgentTokensRequest)(nil),           // 19: finch.ListAgentTokensRequest
+	(*AgentTokenItem)(nil),                   // 20: finch.AgentTokenItem
+	(*ListAgentTokensResponse)(nil),          // 21: finch.ListAgentTokensResponse
+	(*SuspendAgentRequest)(nil),              // 22: finch.SuspendAgentRequest
+	(*SuspendAgentResponse)(nil),             // 23: finch.SuspendAgentResponse
+	(*ResumeAgentRequest)(nil),               // 24: finch.ResumeAgentRequest
+	(*ResumeAgentResponse)(nil),              // 25: finch.ResumeAgentResponse
+	(*WatchAgentsRequest)(nil),               // 26: finch.WatchAgentsRequest
+	(*WatchAgentsResponse)(nil),              // 27: finch.WatchAgentsResponse
+	(*ListAgentConfigRevisionsRequest)(nil),  // 28: finch.ListAgentConfigRevisionsRequest
+	(*AgentConfigRevisionItem)(nil),          // 29: finch.AgentConfigRevisionItem
+	(*ListAgentConfigRevisionsResponse)(nil), // 30: finch.ListAgentConfigRevisionsResponse
+	(*DiffAgentConfigRequest)(nil),           // 31: finch.DiffAgentConfigRequest
+	(*DiffAgentConfigResponse)(nil),          // 32: finch.DiffAgentConfigResponse
+	(*RollbackAgentConfigRequest)(nil),       // 33: finch.RollbackAgentConfigRequest
+	(*RollbackAgentConfigResponse)(nil),      // 34: finch.RollbackAgentConfigResponse
+	(*GetAgentUsageRequest)(nil),             // 35: finch.GetAgentUsageRequest
+	(*AgentUsageItem)(nil),                   // 36: finch.AgentUsageItem
+	(*GetAgentUsageResponse)(nil),            // 37: finch.GetAgentUsageResponse
+	(*GetDashboardTokenRequest)(nil),         // 38: finch.GetDashboardTokenRequest
+	(*GetDashboardTokenResponse)(nil),        // 39: finch.GetDashboardTokenResponse
+	(*ListAuditEventsRequest)(nil),           // 40: finch.ListAuditEventsRequest
+	(*AuditEventItem)(nil),                   // 41: finch.AuditEventItem
+	(*ListAuditEventsResponse)(nil),          // 42: finch.ListAuditEventsResponse
+	(*CreateEnrollmentTokenRequest)(nil),     // 43: finch.CreateEnrollmentTokenRequest
+	(*CreateEnrollmentTokenResponse)(nil),    // 44: finch.CreateEnrollmentTokenResponse
+	(*ListEnrollmentTokensRequest)(nil),      // 45: finch.ListEnrollmentTokensRequest
+	(*EnrollmentTokenItem)(nil),              // 46: finch.EnrollmentTokenItem
+	(*ListEnrollmentTokensResponse)(nil),     // 47: finch.ListEnrollmentTokensResponse
+	(*CreateTenantRequest)(nil),              // 48: finch.CreateTenantRequest
+	(*CreateTenantResponse)(nil),             // 49: finch.CreateTenantResponse
+	(*ListTenantsRequest)(nil),               // 50: finch.ListTenantsRequest
+	(*TenantItem)(nil),                       // 51: finch.TenantItem
+	(*ListTenantsResponse)(nil),              // 52: finch.ListTenantsResponse
+	(*DeleteTenantRequest)(nil),              // 53: finch.DeleteTenantRequest
+	(*DeleteTenantResponse)(nil),             // 54: finch.DeleteTenantResponse
+	nil,                                      // 55: finch.RegisterAgentRequest.RateLimitsEntry
+	nil,                                      // 56: finch.GetAgentResponse.RateLimitsEntry
+	nil,                                      // 57: finch.CollectorItem.AttributesEntry
+	nil,                                      // 58: finch.UpdateAgentRequest.RateLimitsEntry
+	(*fieldmaskpb.FieldMask)(nil),            // 59: google.protobuf.FieldMask
 }
 var file_api_api_proto_depIdxs = []int32{
-	7,  // 0: finch.ListAgentsResponse.agents:type_name -> finch.AgentListItem
-	0,  // 1: finch.AgentService.RegisterAgent:input_type -> finch.RegisterAgentRequest
-	2,  // 2: finch.AgentService.DeregisterAgent:input_type -> finch.DeregisterAgentRequest
-	4,  // 3: finch.AgentService.GetAgent:input_type -> finch.GetAgentRequest
-	6,  // 4: finch.AgentService.ListAgents:input_type -> finch.ListAgentsRequest
-	9,  // 5: finch.AgentService.GetAgentConfig:input_type -> finch.GetAgentConfigRequest
-	13, // 6: finch.AgentService.UpdateAgent:input_type -> finch.UpdateAgentRequest
-	11, // 7: finch.InfoService.GetServiceInfo:input_type -> finch.GetServiceInfoRequest
-	15, // 8: finch.DashboardService.GetDashboardToken:input_type -> finch.GetDashboardTokenRequest
-	1,  // 9: finch.AgentService.RegisterAgent:output_type -> finch.RegisterAgentResponse
-	3,  // 10: finch.AgentService.DeregisterAgent:output_type -> finch.DeregisterAgentResponse
-	5,  // 11: finch.AgentService.GetAgent:output_type -> finch.GetAgentResponse
-	8,  // 12: finch.AgentService.ListAgents:output_type -> finch.ListAgentsResponse
-	10, // 13: finch.AgentService.GetAgentConfig:output_type -> finch.GetAgentConfigResponse
-	14, // 14: finch.AgentService.UpdateAgent:output_type -> finch.UpdateAgentResponse
-	12, // 15: finch.InfoService.GetServiceInfo:output_type -> finch.GetServiceInfoResponse
-	16, // 16: finch.DashboardService.GetDashboardToken:output_type -> finch.GetDashboardTokenResponse
-	9,  // [9:17] is the sub-list for method output_type
-	1,  // [1:9] is the sub-list for method input_type
-	1,  // [1:1] is the sub-list for extension type_name
-	1,  // [1:1] is the sub-list for extension extendee
-	0,  // [0:1] is the sub-list for field type_name
+	55, // 0: finch.RegisterAgentRequest.rate_limits:type_name -> finch.RegisterAgentRequest.RateLimitsEntry
+	7,  // 1: finch.GetAgentResponse.collectors:type_name -> finch.CollectorItem
+	56, // 2: finch.GetAgentResponse.rate_limits:type_name -> finch.GetAgentResponse.RateLimitsEntry
+	57, // 3: finch.CollectorItem.attributes:type_name -> finch.CollectorItem.AttributesEntry
+	59, // 4: finch.ListAgentsRequest.field_mask:type_name -> google.protobuf.FieldMask
+	9,  // 5: finch.ListAgentsResponse.agents:type_name -> finch.AgentListItem
+	59, // 6: finch.UpdateAgentRequest.update_mask:type_name -> google.protobuf.FieldMask
+	58, // 7: finch.UpdateAgentRequest.rate_limits:type_name -> finch.UpdateAgentRequest.RateLimitsEntry
+	20, // 8: finch.ListAgentTokensResponse.tokens:type_name -> finch.AgentTokenItem
+	29, // 9: finch.ListAgentConfigRevisionsResponse.revisions:type_name -> finch.AgentConfigRevisionItem
+	36, // 10: finch.GetAgentUsageResponse.buckets:type_name -> finch.AgentUsageItem
+	36, // 11: finch.GetAgentUsageResponse.totals:type_name -> finch.AgentUsageItem
+	41, // 12: finch.ListAuditEventsResponse.events:type_name -> finch.AuditEventItem
+	46, // 13: finch.ListEnrollmentTokensResponse.tokens:type_name -> finch.EnrollmentTokenItem
+	51, // 14: finch.ListTenantsResponse.tenants:type_name -> finch.TenantItem
+	1,  // 15: finch.RegisterAgentRequest.RateLimitsEntry.value:type_name -> finch.RateLimit
+	1,  // 16: finch.GetAgentResponse.RateLimitsEntry.value:type_name -> finch.RateLimit
+	1,  // 17: finch.UpdateAgentRequest.RateLimitsEntry.value:type_name -> finch.RateLimit
+	0,  // 18: finch.AgentService.RegisterAgent:input_type -> finch.RegisterAgentRequest
+	3,  // 19: finch.AgentService.DeregisterAgent:input_type -> finch.DeregisterAgentRequest
+	5,  // 20: finch.AgentService.GetAgent:input_type -> finch.GetAgentRequest
+	8,  // 21: finch.AgentService.ListAgents:input_type -> finch.ListAgentsRequest
+	11, // 22: finch.AgentService.GetAgentConfig:input_type -> finch.GetAgentConfigRequest
+	15, // 23: finch.AgentService.UpdateAgent:input_type -> finch.UpdateAgentRequest
+	17, // 24: finch.AgentService.RevokeAgentTokens:input_type -> finch.RevokeAgentTokensRequest
+	19, // 25: finch.AgentService.ListAgentTokens:input_type -> finch.ListAgentTokensRequest
+	22, // 26: finch.AgentService.SuspendAgent:input_type -> finch.SuspendAgentRequest
+	24, // 27: finch.AgentService.ResumeAgent:input_type -> finch.ResumeAgentRequest
+	26, // 28: finch.AgentService.WatchAgents:input_type -> finch.WatchAgentsRequest
+	28, // 29: finch.AgentService.ListAgentConfigRevisions:input_type -> finch.ListAgentConfigRevisionsRequest
+	31, // 30: finch.AgentService.DiffAgentConfig:input_type -> finch.DiffAgentConfigRequest
+	33, // 31: finch.AgentService.RollbackAgentConfig:input_type -> finch.RollbackAgentConfigRequest
+	35, // 32: finch.AgentService.GetAgentUsage:input_type -> finch.GetAgentUsageRequest
+	13, // 33: finch.InfoService.GetServiceInfo:input_type -> finch.GetServiceInfoRequest
+	38, // 34: finch.DashboardService.GetDashboardToken:input_type -> finch.GetDashboardTokenRequest
+	40, // 35: finch.AuditService.ListAuditEvents:input_type -> finch.ListAuditEventsRequest
+	48, // 36: finch.TenantService.CreateTenant:input_type -> finch.CreateTenantRequest
+	50, // 37: finch.TenantService.ListTenants:input_type -> finch.ListTenantsRequest
+	53, // 38: finch.TenantService.DeleteTenant:input_type -> finch.DeleteTenantRequest
+	43, // 39: finch.EnrollmentService.CreateEnrollmentToken:input_type -> finch.CreateEnrollmentTokenRequest
+	45, // 40: finch.EnrollmentService.ListEnrollmentTokens:input_type -> finch.ListEnrollmentTokensRequest
+	2,  // 41: finch.AgentService.RegisterAgent:output_type -> finch.RegisterAgentResponse
+	4,  // 42: finch.AgentService.DeregisterAgent:output_type -> finch.DeregisterAgentResponse
+	6,  // 43: finch.AgentService.GetAgent:output_type -> finch.GetAgentResponse
+	10, // 44: finch.AgentService.ListAgents:output_type -> finch.ListAgentsResponse
+	12, // 45: finch.AgentService.GetAgentConfig:output_type -> finch.GetAgentConfigResponse
+	16, // 46: finch.AgentService.UpdateAgent:output_type -> finch.UpdateAgentResponse
+	18, // 47: finch.AgentService.RevokeAgentTokens:output_type -> finch.RevokeAgentTokensResponse
+	21, // 48: finch.AgentService.ListAgentTokens:output_type -> finch.ListAgentTokensResponse
+	23, // 49: finch.AgentService.SuspendAgent:output_type -> finch.SuspendAgentResponse
+	25, // 50: finch.AgentService.ResumeAgent:output_type -> finch.ResumeAgentResponse
+	27, // 51: finch.AgentService.WatchAgents:output_type -> finch.WatchAgentsResponse
+	30, // 52: finch.AgentService.ListAgentConfigRevisions:output_type -> finch.ListAgentConfigRevisionsResponse
+	32, // 53: finch.AgentService.DiffAgentConfig:output_type -> finch.DiffAgentConfigResponse
+	34, // 54: finch.AgentService.RollbackAgentConfig:output_type -> finch.RollbackAgentConfigResponse
+	37, // 55: finch.AgentService.GetAgentUsage:output_type -> finch.GetAgentUsageResponse
+	14, // 56: finch.InfoService.GetServiceInfo:output_type -> finch.GetServiceInfoResponse
+	39, // 57: finch.DashboardService.GetDashboardToken:output_type -> finch.GetDashboardTokenResponse
+	42, // 58: finch.AuditService.ListAuditEvents:output_type -> finch.ListAuditEventsResponse
+	49, // 59: finch.TenantService.CreateTenant:output_type -> finch.CreateTenantResponse
+	52, // 60: finch.TenantService.ListTenants:output_type -> finch.ListTenantsResponse
+	54, // 61: finch.TenantService.DeleteTenant:output_type -> finch.DeleteTenantResponse
+	44, // 62: finch.EnrollmentService.CreateEnrollmentToken:output_type -> finch.CreateEnrollmentTokenResponse
+	47, // 63: finch.EnrollmentService.ListEnrollmentTokens:output_type -> finch.ListEnrollmentTokensResponse
+	41, // [41:64] is the sub-list for method output_type
+	18, // [18:41] is the sub-list for method input_type
+	18, // [18:18] is the sub-list for extension type_name
+	18, // [18:18] is the sub-list for extension extendee
+	0,  // [0:18] is the sub-list for field type_name
 }
 
 func init() { file_api_api_proto_init() }
@@ -1107,16 +3986,29 @@ func file_api_api_proto_init() {
 	if File_api_api_proto != nil {
 		return
 	}
+	file_api_api_proto_msgTypes[3].OneofWrappers = []any{}
+	file_api_api_proto_msgTypes[8].OneofWrappers = []any{}
 	file_api_api_proto_msgTypes[15].OneofWrappers = []any{}
+	file_api_api_proto_msgTypes[17].OneofWrappers = []any{}
+	file_api_api_proto_msgTypes[19].OneofWrappers = []any{}
+	file_api_api_proto_msgTypes[22].OneofWrappers = []any{}
+	file_api_api_proto_msgTypes[24].OneofWrappers = []any{}
+	file_api_api_proto_msgTypes[26].OneofWrappers = []any{}
+	file_api_api_proto_msgTypes[31].OneofWrappers = []any{}
+	file_api_api_proto_msgTypes[33].OneofWrappers = []any{}
+	file_api_api_proto_msgTypes[35].OneofWrappers = []any{}
+	file_api_api_proto_msgTypes[38].OneofWrappers = []any{}
+	file_api_api_proto_msgTypes[40].OneofWrappers = []any{}
+	file_api_api_proto_msgTypes[43].OneofWrappers = []any{}
 	type x struct{}
 	out := protoimpl.TypeBuilder{
 		File: protoimpl.DescBuilder{
 			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
 			RawDescriptor: unsafe.Slice(unsafe.StringData(file_api_api_proto_rawDesc), len(file_api_api_proto_rawDesc)),
 			NumEnums:      0,
-			NumMessages:   17,
+			NumMessages:   59,
 			NumExtensions: 0,
-			NumServices:   3,
+			NumServices:   6,
 		},
 		GoTypes:           file_api_api_proto_goTypes,
 		DependencyIndexes: file_api_api_proto_depIdxs,
@@ -0,0 +1,413 @@
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// versions:
+// 	protoc-gen-go v1.36.11
+// 	protoc        v6.33.2
+// source: api/collector/v1/collector.proto
+
+package collectorv1
+
+import (
+	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
+	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	reflect "reflect"
+	sync "sync"
+	unsafe "unsafe"
+)
+
+const (
+	// Verify that this generated code is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(20 - protoimpl.MinVersion)
+	// Verify that runtime/protoimpl is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
+)
+
+type GetConfigRequest struct {
+	state           protoimpl.MessageState `protogen:"open.v1"`
+	Id              string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	LocalAttributes map[string]string      `protobuf:"bytes,2,rep,name=local_attributes,json=localAttributes,proto3" json:"local_attributes,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"bytes,2,opt,name=value"`
+	Hash            string                 `protobuf:"bytes,3,opt,name=hash,proto3" json:"hash,omitempty"`
+	unknownFields   protoimpl.UnknownFields
+	sizeCache       protoimpl.SizeCache
+}
+
+func (x *GetConfigRequest) Reset() {
+	*x = GetConfigRequest{}
+	mi := &file_api_collector_v1_collector_proto_msgTypes[0]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetConfigRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetConfigRequest) ProtoMessage() {}
+
+func (x *GetConfigRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_api_collector_v1_collector_proto_msgTypes[0]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetConfigRequest.ProtoReflect.Descriptor instead.
+func (*GetConfigRequest) Descriptor() ([]byte, []int) {
+	return file_api_collector_v1_collector_proto_rawDescGZIP(), []int{0}
+}
+
+func (x *GetConfigRequest) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+func (x *GetConfigRequest) GetLocalAttributes() map[string]string {
+	if x != nil {
+		return x.LocalAttributes
+	}
+	return nil
+}
+
+func (x *GetConfigRequest) GetHash() string {
+	if x != nil {
+		return x.Hash
+	}
+	return ""
+}
+
+type GetConfigResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Content       string                 `protobuf:"bytes,1,opt,name=content,proto3" json:"content,omitempty"`
+	Hash          string                 `protobuf:"bytes,2,opt,name=hash,proto3" json:"hash,omitempty"`
+	NotModified   bool                   `protobuf:"varint,3,opt,name=not_modified,json=notModified,proto3" json:"not_modified,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetConfigResponse) Reset() {
+	*x = GetConfigResponse{}
+	mi := &file_api_collector_v1_collector_proto_msgTypes[1]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetConfigResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetConfigResponse) ProtoMessage() {}
+
+func (x *GetConfigResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_api_collector_v1_collector_proto_msgTypes[1]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetConfigResponse.ProtoReflect.Descriptor instead.
+func (*GetConfigResponse) Descriptor() ([]byte, []int) {
+	return file_api_collector_v1_collector_proto_rawDescGZIP(), []int{1}
+}
+
+func (x *GetConfigResponse) GetContent() string {
+	if x != nil {
+		return x.Content
+	}
+	return ""
+}
+
+func (x *GetConfigResponse) GetHash() string {
+	if x != nil {
+		return x.Hash
+	}
+	return ""
+}
+
+func (x *GetConfigResponse) GetNotModified() bool {
+	if x != nil {
+		return x.NotModified
+	}
+	return false
+}
+
+type RegisterCollectorRequest struct {
+	state           protoimpl.MessageState `protogen:"open.v1"`
+	Id              string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	LocalAttributes map[string]string      `protobuf:"bytes,2,rep,name=local_attributes,json=localAttributes,proto3" json:"local_attributes,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"bytes,2,opt,name=value"`
+	Name            string                 `protobuf:"bytes,3,opt,name=name,proto3" json:"name,omitempty"`
+	unknownFields   protoimpl.UnknownFields
+	sizeCache       protoimpl.SizeCache
+}
+
+func (x *RegisterCollectorRequest) Reset() {
+	*x = RegisterCollectorRequest{}
+	mi := &file_api_collector_v1_collector_proto_msgTypes[2]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *RegisterCollectorRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*RegisterCollectorRequest) ProtoMessage() {}
+
+func (x *RegisterCollectorRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_api_collector_v1_collector_proto_msgTypes[2]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use RegisterCollectorRequest.ProtoReflect.Descriptor instead.
+func (*RegisterCollectorRequest) Descriptor() ([]byte, []int) {
+	return file_api_collector_v1_collector_proto_rawDescGZIP(), []int{2}
+}
+
+func (x *RegisterCollectorRequest) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+func (x *RegisterCollectorRequest) GetLocalAttributes() map[string]string {
+	if x != nil {
+		return x.LocalAttributes
+	}
+	return nil
+}
+
+func (x *RegisterCollectorRequest) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+type RegisterCollectorResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *RegisterCollectorResponse) Reset() {
+	*x = RegisterCollectorResponse{}
+	mi := &file_api_collector_v1_collector_proto_msgTypes[3]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *RegisterCollectorResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*RegisterCollectorResponse) ProtoMessage() {}
+
+func (x *RegisterCollectorResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_api_collector_v1_collector_proto_msgTypes[3]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use RegisterCollectorResponse.ProtoReflect.Descriptor instead.
+func (*RegisterCollectorResponse) Descriptor() ([]byte, []int) {
+	return file_api_collector_v1_collector_proto_rawDescGZIP(), []int{3}
+}
+
+type UnregisterCollectorRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Id            string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *UnregisterCollectorRequest) Reset() {
+	*x = UnregisterCollectorRequest{}
+	mi := &file_api_collector_v1_collector_proto_msgTypes[4]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *UnregisterCollectorRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*UnregisterCollectorRequest) ProtoMessage() {}
+
+func (x *UnregisterCollectorRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_api_collector_v1_collector_proto_msgTypes[4]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use UnregisterCollectorRequest.ProtoReflect.Descriptor instead.
+func (*UnregisterCollectorRequest) Descriptor() ([]byte, []int) {
+	return file_api_collector_v1_collector_proto_rawDescGZIP(), []int{4}
+}
+
+func (x *UnregisterCollectorRequest) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+type UnregisterCollectorResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *UnregisterCollectorResponse) Reset() {
+	*x = UnregisterCollectorResponse{}
+	mi := &file_api_collector_v1_collector_proto_msgTypes[5]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *UnregisterCollectorResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*UnregisterCollectorResponse) ProtoMessage() {}
+
+func (x *UnregisterCollectorResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_api_collector_v1_collector_proto_msgTypes[5]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use UnregisterCollectorResponse.ProtoReflect.Descriptor instead.
+func (*UnregisterCollectorResponse) Descriptor() ([]byte, []int) {
+	return file_api_collector_v1_collector_proto_rawDescGZIP(), []int{5}
+}
+
+var File_api_collector_v1_collector_proto protoreflect.FileDescriptor
+
+const file_api_collector_v1_collector_proto_rawDesc = "" +
+	"\n" +
+	" api/collector/v1/collector.proto\x12\fcollector.v1\"\xda\x01\n" +
+	"\x10GetConfigRequest\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\tR\x02id\x12^\n" +
+	"\x10local_attributes\x18\x02 \x03(\v23.collector.v1.GetConfigRequest.LocalAttributesEntryR\x0flocalAttributes\x12\x12\n" +
+	"\x04hash\x18\x03 \x01(\tR\x04hash\x1aB\n" +
+	"\x14LocalAttributesEntry\x12\x10\n" +
+	"\x03key\x18\x01 \x01(\tR\x03key\x12\x14\n" +
+	"\x05value\x18\x02 \x01(\tR\x05value:\x028\x01\"d\n" +
+	"\x11GetConfigResponse\x12\x18\n" +
+	"\acontent\x18\x01 \x01(\tR\acontent\x12\x12\n" +
+	"\x04hash\x18\x02 \x01(\tR\x04hash\x12!\n" +
+	"\fnot_modified\x18\x03 \x01(\bR\vnotModified\"\xea\x01\n" +
+	"\x18RegisterCollectorRequest\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\tR\x02id\x12f\n" +
+	"\x10local_attributes\x18\x02 \x03(\v2;.collector.v1.RegisterCollectorRequest.LocalAttributesEntryR\x0flocalAttributes\x12\x12\n" +
+	"\x04name\x18\x03 \x01(\tR\x04name\x1aB\n" +
+	"\x14LocalAttributesEntry\x12\x10\n" +
+	"\x03key\x18\x01 \x01(\tR\x03key\x12\x14\n" +
+	"\x05value\x18\x02 \x01(\tR\x05value:\x028\x01\"\x1b\n" +
+	"\x19RegisterCollectorResponse\",\n" +
+	"\x1aUnregisterCollectorRequest\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\tR\x02id\"\x1d\n" +
+	"\x1bUnregisterCollectorResponse2\xb2\x02\n" +
+	"\x10CollectorService\x12L\n" +
+	"\tGetConfig\x12\x1e.collector.v1.GetConfigRequest\x1a\x1f.collector.v1.GetConfigResponse\x12d\n" +
+	"\x11RegisterCollector\x12&.collector.v1.RegisterCollectorRequest\x1a'.collector.v1.RegisterCollectorResponse\x12j\n" +
+	"\x13UnregisterCollector\x12(.collector.v1.UnregisterCollectorRequest\x1a).collector.v1.UnregisterCollectorResponseB9Z7github.com/tschaefer/finch/api/collector/v1;collectorv1b\x06proto3"
+
+var (
+	file_api_collector_v1_collector_proto_rawDescOnce sync.Once
+	file_api_collector_v1_collector_proto_rawDescData []byte
+)
+
+func file_api_collector_v1_collector_proto_rawDescGZIP() []byte {
+	file_api_collector_v1_collector_proto_rawDescOnce.Do(func() {
+		file_api_collector_v1_collector_proto_rawDescData = protoimpl.X.CompressGZIP(unsafe.Slice(unsafe.StringData(file_api_collector_v1_collector_proto_rawDesc), len(file_api_collector_v1_collector_proto_rawDesc)))
+	})
+	return file_api_collector_v1_collector_proto_rawDescData
+}
+
+var file_api_collector_v1_collector_proto_msgTypes = make([]protoimpl.MessageInfo, 8)
+var file_api_collector_v1_collector_proto_goTypes = []any{
+	(*GetConfigRequest)(nil),            // 0: collector.v1.GetConfigRequest
+	(*GetConfigResponse)(nil),           // 1: collector.v1.GetConfigResponse
+	(*RegisterCollectorRequest)(nil),    // 2: collector.v1.RegisterCollectorRequest
+	(*RegisterCollectorResponse)(nil),   // 3: collector.v1.RegisterCollectorResponse
+	(*UnregisterCollectorRequest)(nil),  // 4: collector.v1.UnregisterCollectorRequest
+	(*UnregisterCollectorResponse)(nil), // 5: collector.v1.UnregisterCollectorResponse
+	nil,                                 // 6: collector.v1.GetConfigRequest.LocalAttributesEntry
+	nil,                                 // 7: collector.v1.RegisterCollectorRequest.LocalAttributesEntry
+}
+var file_api_collector_v1_collector_proto_depIdxs = []int32{
+	6, // 0: collector.v1.GetConfigRequest.local_attributes:type_name -> collector.v1.GetConfigRequest.LocalAttributesEntry
+	7, // 1: collector.v1.RegisterCollectorRequest.local_attributes:type_name -> collector.v1.RegisterCollectorRequest.LocalAttributesEntry
+	0, // 2: collector.v1.CollectorService.GetConfig:input_type -> collector.v1.GetConfigRequest
+	2, // 3: collector.v1.CollectorService.RegisterCollector:input_type -> collector.v1.RegisterCollectorRequest
+	4, // 4: collector.v1.CollectorService.UnregisterCollector:input_type -> collector.v1.UnregisterCollectorRequest
+	1, // 5: collector.v1.CollectorService.GetConfig:output_type -> collector.v1.GetConfigResponse
+	3, // 6: collector.v1.CollectorService.RegisterCollector:output_type -> collector.v1.RegisterCollectorResponse
+	5, // 7: collector.v1.CollectorService.UnregisterCollector:output_type -> collector.v1.UnregisterCollectorResponse
+	5, // [5:8] is the sub-list for method output_type
+	2, // [2:5] is the sub-list for method input_type
+	2, // [2:2] is the sub-list for extension type_name
+	2, // [2:2] is the sub-list for extension extendee
+	0, // [0:2] is the sub-list for field type_name
+}
+
+func init() { file_api_collector_v1_collector_proto_init() }
+func file_api_collector_v1_collector_proto_init() {
+	if File_api_collector_v1_collector_proto != nil {
+		return
+	}
+	type x struct{}
+	out := protoimpl.TypeBuilder{
+		File: protoimpl.DescBuilder{
+			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
+			RawDescriptor: unsafe.Slice(unsafe.StringData(file_api_collector_v1_collector_proto_rawDesc), len(file_api_collector_v1_collector_proto_rawDesc)),
+			NumEnums:      0,
+			NumMessages:   8,
+			NumExtensions: 0,
+			NumServices:   1,
+		},
+		GoTypes:           file_api_collector_v1_collector_proto_goTypes,
+		DependencyIndexes: file_api_collector_v1_collector_proto_depIdxs,
+		MessageInfos:      file_api_collector_v1_collector_proto_msgTypes,
+	}.Build()
+	File_api_collector_v1_collector_proto = out.File
+	file_api_collector_v1_collector_proto_goTypes = nil
+	file_api_collector_v1_collector_proto_depIdxs = nil
+}
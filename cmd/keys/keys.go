@@ -0,0 +1,99 @@
+/*
+Copyright (c) Tobias Schäfer. All rights reserved.
+Licensed under the MIT License, see LICENSE file in the project root for details.
+*/
+package keys
+
+import (
+	"fmt"
+	"os"
+	"text/tabwriter"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/tschaefer/finch/internal/config"
+	"github.com/tschaefer/finch/internal/controller"
+	"github.com/tschaefer/finch/internal/database"
+	"github.com/tschaefer/finch/internal/model"
+)
+
+var Cmd = &cobra.Command{
+	Use:   "keys",
+	Short: "Manage token signing keys",
+}
+
+var listCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List signing keys",
+	Args:  cobra.NoArgs,
+	Run:   listCmdRun,
+}
+
+var rotateCmd = &cobra.Command{
+	Use:   "rotate",
+	Short: "Add a new active signing key, keeping the previous keys for verification",
+	Args:  cobra.NoArgs,
+	Run:   rotateCmdRun,
+}
+
+var retireCmd = &cobra.Command{
+	Use:   "retire KID",
+	Short: "Remove a verification key once no unexpired tokens depend on it",
+	Args:  cobra.ExactArgs(1),
+	Run:   retireCmdRun,
+}
+
+func init() {
+	Cmd.PersistentFlags().StringP("stack.config-file", "", "/var/lib/finch/finch.json", "Config file of the stack")
+	retireCmd.Flags().BoolP("force", "f", false, "Retire the key even if unexpired tokens depend on it")
+
+	Cmd.AddCommand(listCmd)
+	Cmd.AddCommand(rotateCmd)
+	Cmd.AddCommand(retireCmd)
+}
+
+func listCmdRun(cmd *cobra.Command, args []string) {
+	ctrl := newController(cmd)
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	_, _ = fmt.Fprintln(w, "KID\tALGORITHM\tSTATE\tCREATED")
+	for _, key := range ctrl.ListSigningKeys() {
+		_, _ = fmt.Fprintf(w, "%s\t%s\t%s\t%s\n", key.Id, key.Algorithm, key.State, key.CreatedAt.Format(time.RFC3339))
+	}
+	_ = w.Flush()
+}
+
+func rotateCmdRun(cmd *cobra.Command, args []string) {
+	ctrl := newController(cmd)
+
+	key, err := ctrl.RotateSigningKey(controller.SystemActor("cli"))
+	cobra.CheckErr(err)
+
+	fmt.Printf("Rotated signing key, new active key %s (%s)\n", key.Id, key.Algorithm)
+}
+
+func retireCmdRun(cmd *cobra.Command, args []string) {
+	force, _ := cmd.Flags().GetBool("force")
+	ctrl := newController(cmd)
+
+	err := ctrl.RetireSigningKey(args[0], force, controller.SystemActor("cli"))
+	cobra.CheckErr(err)
+
+	fmt.Printf("Retired signing key %s\n", args[0])
+}
+
+func newController(cmd *cobra.Command) *controller.Controller {
+	file, _ := cmd.Flags().GetString("stack.config-file")
+
+	cfg, err := config.NewFromFile(file)
+	cobra.CheckErr(err)
+
+	db, err := database.New(cfg)
+	cobra.CheckErr(err)
+	cobra.CheckErr(db.Migrate())
+
+	ctrl := controller.New(model.New(db.Connection()), cfg)
+	cobra.CheckErr(ctrl.LoadSigningKeys())
+
+	return ctrl
+}
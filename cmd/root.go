@@ -8,6 +8,7 @@ import (
 	"os"
 
 	"github.com/spf13/cobra"
+	"github.com/tschaefer/finch/cmd/keys"
 	"github.com/tschaefer/finch/cmd/run"
 	"github.com/tschaefer/finch/internal/version"
 )
@@ -43,6 +44,7 @@ func init() {
 		},
 	}
 
+	rootCmd.AddCommand(keys.Cmd)
 	rootCmd.AddCommand(run.Cmd)
 	rootCmd.AddCommand(versionCmd)
 }
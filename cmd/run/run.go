@@ -8,6 +8,7 @@ import (
 	"context"
 	"os/signal"
 	"syscall"
+	"time"
 
 	"github.com/spf13/cobra"
 	"github.com/tschaefer/finch/internal/manager"
@@ -27,6 +28,9 @@ func init() {
 	Cmd.Flags().StringP("server.log-level", "", "info", "Log level (debug, info, warn, error)")
 	Cmd.Flags().StringP("server.log-format", "", "structured", "Log format (structured, json)")
 	Cmd.Flags().StringP("stack.config-file", "", "/var/lib/finch/finch.json", "Config file of the stack")
+	Cmd.Flags().DurationP("agent.stale-after", "", 24*time.Hour, "Mark agents stale without pushes for this period (0 disables)")
+	Cmd.Flags().DurationP("agent.ephemeral-grace", "", 72*time.Hour, "Deregister ephemeral agents without pushes for this period (0 disables)")
+	Cmd.Flags().DurationP("auth.cache-ttl", "", 30*time.Second, "Cache validated agent tokens in the auth path for this period (0 disables)")
 
 	_ = Cmd.RegisterFlagCompletionFunc("server.log-level", completeServerLogLevel)
 	_ = Cmd.RegisterFlagCompletionFunc("server.log-format", completeServerLogFormat)
@@ -40,6 +44,9 @@ func runCmd(cmd *cobra.Command, args []string) {
 	config, _ := cmd.Flags().GetString("stack.config-file")
 	logLevel, _ := cmd.Flags().GetString("server.log-level")
 	logFormat, _ := cmd.Flags().GetString("server.log-format")
+	staleAfter, _ := cmd.Flags().GetDuration("agent.stale-after")
+	ephemeralGrace, _ := cmd.Flags().GetDuration("agent.ephemeral-grace")
+	authCacheTTL, _ := cmd.Flags().GetDuration("auth.cache-ttl")
 
 	setLogger(logLevel, logFormat)
 
@@ -54,5 +61,10 @@ func runCmd(cmd *cobra.Command, args []string) {
 		HTTP:    httpAddr,
 		Auth:    authAddr,
 		Healthz: healthzAddr,
+	}, manager.Sweep{
+		StaleAfter:     staleAfter,
+		EphemeralGrace: ephemeralGrace,
+	}, manager.AuthCache{
+		TTL: authCacheTTL,
 	})
 }
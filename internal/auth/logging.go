@@ -5,9 +5,10 @@ Licensed under the MIT License, see LICENSE file in the project root for details
 package auth
 
 import (
-	"context"
 	"log/slog"
 	"net/http"
+
+	"github.com/tschaefer/finch/internal/tracing"
 )
 
 func (s *Server) log(r *http.Request, level slog.Level, msg string, args ...any) {
@@ -29,7 +30,7 @@ func (s *Server) log(r *http.Request, level slog.Level, msg string, args ...any)
 	}
 	userAgent := r.Header.Get("User-Agent")
 	args = append(args, "remote_addr", remoteAddr, "user_agent", userAgent)
+	args = append(args, tracing.LogAttrs(r.Context())...)
 
-	ctx := context.Background()
-	slog.Log(ctx, level, msg, args...)
+	slog.Log(r.Context(), level, msg, args...)
 }
@@ -6,12 +6,14 @@ package auth
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
 	"log/slog"
 	"net/http/httptest"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
+	"go.opentelemetry.io/otel/trace"
 )
 
 type logEntry struct {
@@ -20,6 +22,8 @@ type logEntry struct {
 	RemoteAddr string `json:"remote_addr"`
 	UserAgent  string `json:"user_agent"`
 	Rid        string `json:"rid"`
+	TraceId    string `json:"trace_id"`
+	SpanId     string `json:"span_id"`
 }
 
 func Test_Log_ExtractsRemoteAddrFromXForwardedFor(t *testing.T) {
@@ -167,3 +171,45 @@ func Test_Log_AppendsAdditionalArgs(t *testing.T) {
 	assert.Equal(t, "value1", entry["key1"])
 	assert.Equal(t, float64(42), entry["key2"])
 }
+
+func Test_Log_IncludesTraceContext(t *testing.T) {
+	var buf bytes.Buffer
+	logger := slog.New(slog.NewJSONHandler(&buf, &slog.HandlerOptions{Level: slog.LevelDebug}))
+	slog.SetDefault(logger)
+
+	traceId, _ := trace.TraceIDFromHex("4bf92f3577b34da6a3ce929d0e0e4736")
+	spanId, _ := trace.SpanIDFromHex("00f067aa0ba902b7")
+	ctx := trace.ContextWithSpanContext(context.Background(), trace.NewSpanContext(trace.SpanContextConfig{
+		TraceID:    traceId,
+		SpanID:     spanId,
+		TraceFlags: trace.FlagsSampled,
+	}))
+
+	server := &Server{}
+	req := httptest.NewRequest("GET", "/auth", nil).WithContext(ctx)
+
+	server.log(req, slog.LevelInfo, "test message")
+
+	var entry logEntry
+	err := json.Unmarshal(buf.Bytes(), &entry)
+	assert.NoError(t, err, "parse log entry")
+	assert.Equal(t, traceId.String(), entry.TraceId)
+	assert.Equal(t, spanId.String(), entry.SpanId)
+}
+
+func Test_Log_OmitsTraceContextWithoutSpan(t *testing.T) {
+	var buf bytes.Buffer
+	logger := slog.New(slog.NewJSONHandler(&buf, &slog.HandlerOptions{Level: slog.LevelDebug}))
+	slog.SetDefault(logger)
+
+	server := &Server{}
+	req := httptest.NewRequest("GET", "/auth", nil)
+
+	server.log(req, slog.LevelInfo, "test message")
+
+	var entry map[string]any
+	err := json.Unmarshal(buf.Bytes(), &entry)
+	assert.NoError(t, err, "parse log entry")
+	assert.NotContains(t, entry, "trace_id")
+	assert.NotContains(t, entry, "span_id")
+}
@@ -6,14 +6,21 @@ package auth
 
 import (
 	"context"
+	"errors"
 	"log/slog"
+	"math"
 	"net"
 	"net/http"
+	"strconv"
 	"strings"
 	"time"
 
 	"github.com/tschaefer/finch/internal/config"
 	"github.com/tschaefer/finch/internal/controller"
+	"github.com/tschaefer/finch/internal/metrics"
+	"github.com/tschaefer/finch/internal/tracing"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
 )
 
 type Server struct {
@@ -32,7 +39,7 @@ func NewServer(addr string, ctrl *controller.Controller, cfg *config.Config) *Se
 		config:     cfg,
 		server: &http.Server{
 			Addr:         addr,
-			Handler:      mux,
+			Handler:      tracing.HTTPHandler(mux, "finch.auth"),
 			ReadTimeout:  10 * time.Second,
 			WriteTimeout: 10 * time.Second,
 			IdleTimeout:  60 * time.Second,
@@ -67,25 +74,90 @@ func (s *Server) handleAuth(w http.ResponseWriter, r *http.Request) {
 	authHeader := r.Header.Get("Authorization")
 	if authHeader == "" {
 		s.log(r, slog.LevelWarn, "Auth request missing Authorization header")
+		recordDecision(r, metrics.AuthOutcomeUnauthorized, "missing_authorization")
 		w.WriteHeader(http.StatusUnauthorized)
 		return
 	}
 
 	if !strings.HasPrefix(authHeader, "Bearer ") {
 		s.log(r, slog.LevelWarn, "Auth request has invalid Authorization header format")
+		recordDecision(r, metrics.AuthOutcomeUnauthorized, "invalid_authorization")
 		w.WriteHeader(http.StatusUnauthorized)
 		return
 	}
 
 	tokenString := strings.TrimPrefix(authHeader, "Bearer ")
 
-	err := s.controller.ValidateAgentToken(tokenString)
+	forwarded := controller.ForwardedRequest{
+		Uri:           r.Header.Get("X-Forwarded-Uri"),
+		Method:        r.Header.Get("X-Forwarded-Method"),
+		ContentLength: forwardedContentLength(r),
+	}
+
+	trace.SpanFromContext(r.Context()).SetAttributes(
+		attribute.String("finch.backend", controller.BackendForUri(forwarded.Uri)),
+	)
+
+	claims, err := s.controller.WithContext(r.Context()).AuthorizeAgentRequest(tokenString, forwarded)
+	if errors.Is(err, controller.ErrAgentSuspended) {
+		s.log(r, slog.LevelWarn, "Auth request rejected for suspended agent", "error", err)
+		recordDecision(r, metrics.AuthOutcomeDenied, "suspended")
+		w.WriteHeader(http.StatusForbidden)
+		return
+	}
+	if errors.Is(err, controller.ErrRequestNotAllowed) {
+		s.log(r, slog.LevelWarn, "Auth request rejected by agent capabilities", "uri", forwarded.Uri, "method", forwarded.Method, "reason", err)
+		recordDecision(r, metrics.AuthOutcomeDenied, "not_allowed")
+		w.WriteHeader(http.StatusForbidden)
+		return
+	}
+	var limited *controller.RateLimitError
+	if errors.As(err, &limited) {
+		s.log(r, slog.LevelWarn, "Auth request rate limited", "backend", limited.Backend, "retry_after", limited.RetryAfter)
+		recordDecision(r, metrics.AuthOutcomeRateLimited, limited.Backend)
+		w.Header().Set("Retry-After", strconv.Itoa(int(math.Ceil(limited.RetryAfter.Seconds()))))
+		w.WriteHeader(http.StatusTooManyRequests)
+		return
+	}
 	if err != nil {
 		s.log(r, slog.LevelWarn, "Auth request failed validation", "error", err)
+		recordDecision(r, metrics.AuthOutcomeUnauthorized, "invalid_token")
 		w.WriteHeader(http.StatusUnauthorized)
 		return
 	}
 
-	s.log(r, slog.LevelDebug, "Auth request succeeded")
+	w.Header().Set("X-Scope-OrgID", claims.Tenant)
+	w.Header().Set("X-Finch-Agent-Rid", claims.ResourceId)
+	w.Header().Set("X-Finch-Agent-Hostname", claims.Hostname)
+
+	trace.SpanFromContext(r.Context()).SetAttributes(
+		attribute.String("finch.agent.rid", claims.ResourceId),
+		attribute.String("finch.tenant", claims.Tenant),
+	)
+
+	s.log(r, slog.LevelDebug, "Auth request succeeded", "rid", claims.ResourceId)
+	recordDecision(r, metrics.AuthOutcomeAllowed, "ok")
 	w.WriteHeader(http.StatusOK)
 }
+
+func recordDecision(r *http.Request, outcome, reason string) {
+	metrics.AuthDecisions.WithLabelValues(outcome, reason).Inc()
+	trace.SpanFromContext(r.Context()).SetAttributes(
+		attribute.String("finch.auth.outcome", outcome),
+		attribute.String("finch.auth.reason", reason),
+	)
+}
+
+func forwardedContentLength(r *http.Request) int64 {
+	for _, header := range []string{"X-Forwarded-Content-Length", "Content-Length"} {
+		value := r.Header.Get(header)
+		if value == "" {
+			continue
+		}
+		if length, err := strconv.ParseInt(value, 10, 64); err == nil && length >= 0 {
+			return length
+		}
+	}
+
+	return -1
+}
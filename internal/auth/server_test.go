@@ -5,26 +5,41 @@ Licensed under the MIT License, see LICENSE file in the project root for details
 package auth
 
 import (
+	"context"
 	"net/http"
 	"net/http/httptest"
 	"testing"
 	"time"
 
 	"github.com/golang-jwt/jwt/v5"
+	"github.com/prometheus/client_golang/prometheus/testutil"
 	"github.com/stretchr/testify/assert"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/propagation"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
 	"gorm.io/driver/sqlite"
 	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
 
 	"github.com/tschaefer/finch/internal/config"
 	"github.com/tschaefer/finch/internal/controller"
+	"github.com/tschaefer/finch/internal/metrics"
 	"github.com/tschaefer/finch/internal/model"
 )
 
-func setupTestServer(t *testing.T) (*Server, *model.Model, *config.Config) {
-	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{})
+func setupTestServer(t testing.TB) (*Server, *model.Model, *config.Config) {
+	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{
+		Logger: logger.Default.LogMode(logger.Silent),
+	})
 	assert.NoError(t, err)
 
-	err = db.AutoMigrate(&model.Agent{})
+	sqlDB, err := db.DB()
+	assert.NoError(t, err)
+	sqlDB.SetMaxOpenConns(1)
+
+	err = db.AutoMigrate(&model.Agent{}, &model.AgentToken{}, &model.AuditEvent{}, &model.AgentConfigRevision{}, &model.Collector{}, &model.EnrollmentToken{}, &model.Tenant{}, &model.AgentUsage{}, &model.DashboardSession{})
 	assert.NoError(t, err)
 
 	m := model.New(db)
@@ -53,6 +68,13 @@ func generateTestToken(cfg *config.Config, resourceId string, expiration time.Du
 	return tokenString
 }
 
+func newForwardedRequest(uri, method string) *http.Request {
+	req := httptest.NewRequest(http.MethodGet, "/auth", nil)
+	req.Header.Set("X-Forwarded-Uri", uri)
+	req.Header.Set("X-Forwarded-Method", method)
+	return req
+}
+
 func TestHandleAuth_ValidToken(t *testing.T) {
 	server, m, cfg := setupTestServer(t)
 
@@ -65,13 +87,16 @@ func TestHandleAuth_ValidToken(t *testing.T) {
 
 	token := generateTestToken(cfg, agent.ResourceId, 1*time.Hour)
 
-	req := httptest.NewRequest(http.MethodGet, "/auth", nil)
+	req := newForwardedRequest("/loki/loki/api/v1/push", http.MethodPost)
 	req.Header.Set("Authorization", "Bearer "+token)
 
 	w := httptest.NewRecorder()
 	server.handleAuth(w, req)
 
 	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, controller.DefaultTenant, w.Header().Get("X-Scope-OrgID"))
+	assert.Equal(t, agent.ResourceId, w.Header().Get("X-Finch-Agent-Rid"))
+	assert.Equal(t, agent.Hostname, w.Header().Get("X-Finch-Agent-Hostname"))
 }
 
 func TestHandleAuth_ExpiredToken(t *testing.T) {
@@ -86,7 +111,7 @@ func TestHandleAuth_ExpiredToken(t *testing.T) {
 
 	token := generateTestToken(cfg, agent.ResourceId, -1*time.Hour) // Expired
 
-	req := httptest.NewRequest(http.MethodGet, "/auth", nil)
+	req := newForwardedRequest("/loki/loki/api/v1/push", http.MethodPost)
 	req.Header.Set("Authorization", "Bearer "+token)
 
 	w := httptest.NewRecorder()
@@ -100,7 +125,7 @@ func TestHandleAuth_UnknownAgent(t *testing.T) {
 
 	token := generateTestToken(cfg, "rid:unknown:999", 1*time.Hour)
 
-	req := httptest.NewRequest(http.MethodGet, "/auth", nil)
+	req := newForwardedRequest("/loki/loki/api/v1/push", http.MethodPost)
 	req.Header.Set("Authorization", "Bearer "+token)
 
 	w := httptest.NewRecorder()
@@ -159,7 +184,7 @@ func TestHandleAuth_WrongSignature(t *testing.T) {
 
 	token := generateTestToken(wrongCfg, agent.ResourceId, 1*time.Hour)
 
-	req := httptest.NewRequest(http.MethodGet, "/auth", nil)
+	req := newForwardedRequest("/loki/loki/api/v1/push", http.MethodPost)
 	req.Header.Set("Authorization", "Bearer "+token)
 
 	w := httptest.NewRecorder()
@@ -167,3 +192,250 @@ func TestHandleAuth_WrongSignature(t *testing.T) {
 
 	assert.Equal(t, http.StatusUnauthorized, w.Code)
 }
+
+func TestHandleAuth_SuspendedAgent(t *testing.T) {
+	server, m, cfg := setupTestServer(t)
+
+	agent := &model.Agent{
+		Hostname:   "test-host",
+		ResourceId: "rid:test:123",
+	}
+	_, err := m.CreateAgent(agent)
+	assert.NoError(t, err)
+
+	agent.Active = false
+	_, err = m.UpdateAgent(agent)
+	assert.NoError(t, err)
+
+	token := generateTestToken(cfg, agent.ResourceId, 1*time.Hour)
+
+	req := newForwardedRequest("/loki/loki/api/v1/push", http.MethodPost)
+	req.Header.Set("Authorization", "Bearer "+token)
+
+	w := httptest.NewRecorder()
+	server.handleAuth(w, req)
+
+	assert.Equal(t, http.StatusForbidden, w.Code)
+	assert.Empty(t, w.Header().Get("X-Scope-OrgID"))
+	assert.Empty(t, w.Header().Get("X-Finch-Agent-Rid"))
+}
+
+func TestHandleAuth_EnforcesAgentCapabilities(t *testing.T) {
+	server, m, cfg := setupTestServer(t)
+
+	agent := &model.Agent{
+		Hostname:   "test-host",
+		ResourceId: "rid:test:123",
+		Metrics:    true,
+		Tenant:     "team-a",
+	}
+	_, err := m.CreateAgent(agent)
+	assert.NoError(t, err)
+
+	token := generateTestToken(cfg, agent.ResourceId, 1*time.Hour)
+
+	tests := []struct {
+		uri    string
+		method string
+		code   int
+	}{
+		{"/loki/loki/api/v1/push", http.MethodPost, http.StatusOK},
+		{"/mimir/api/v1/push", http.MethodPost, http.StatusOK},
+		{"/pyroscope/ingest?name=test", http.MethodPost, http.StatusForbidden},
+		{"/loki/loki/api/v1/query_range", http.MethodGet, http.StatusForbidden},
+		{"/loki/../pyroscope/ingest", http.MethodPost, http.StatusForbidden},
+		{"/grafana/api/dashboards", http.MethodPost, http.StatusForbidden},
+		{"", "", http.StatusForbidden},
+	}
+	for _, tt := range tests {
+		req := newForwardedRequest(tt.uri, tt.method)
+		req.Header.Set("Authorization", "Bearer "+token)
+
+		w := httptest.NewRecorder()
+		server.handleAuth(w, req)
+
+		assert.Equal(t, tt.code, w.Code, "%s %s", tt.method, tt.uri)
+		if tt.code == http.StatusOK {
+			assert.Equal(t, "team-a", w.Header().Get("X-Scope-OrgID"), "%s %s", tt.method, tt.uri)
+		}
+	}
+}
+
+func TestHandleAuth_RateLimitedAgent(t *testing.T) {
+	server, m, cfg := setupTestServer(t)
+
+	agent := &model.Agent{
+		Hostname:   "test-host",
+		ResourceId: "rid:test:123",
+		Metrics:    true,
+		RateLimits: model.RateLimits{"loki": {Rate: 0.1, Burst: 2}},
+	}
+	_, err := m.CreateAgent(agent)
+	assert.NoError(t, err)
+
+	token := generateTestToken(cfg, agent.ResourceId, 1*time.Hour)
+	handle := func(uri string) *httptest.ResponseRecorder {
+		req := newForwardedRequest(uri, http.MethodPost)
+		req.Header.Set("Authorization", "Bearer "+token)
+
+		w := httptest.NewRecorder()
+		server.handleAuth(w, req)
+		return w
+	}
+
+	assert.Equal(t, http.StatusOK, handle("/loki/loki/api/v1/push").Code)
+	assert.Equal(t, http.StatusOK, handle("/loki/loki/api/v1/push").Code)
+
+	w := handle("/loki/loki/api/v1/push")
+	assert.Equal(t, http.StatusTooManyRequests, w.Code)
+	assert.Equal(t, "10", w.Header().Get("Retry-After"))
+	assert.Empty(t, w.Header().Get("X-Scope-OrgID"))
+
+	assert.Equal(t, http.StatusOK, handle("/mimir/api/v1/push").Code)
+}
+
+func TestHandleAuth_RecordsDecisionMetrics(t *testing.T) {
+	server, m, cfg := setupTestServer(t)
+
+	agent := &model.Agent{
+		Hostname:   "test-host",
+		ResourceId: "rid:test:123",
+	}
+	_, err := m.CreateAgent(agent)
+	assert.NoError(t, err)
+
+	allowed := metrics.AuthDecisions.WithLabelValues(metrics.AuthOutcomeAllowed, "ok")
+	denied := metrics.AuthDecisions.WithLabelValues(metrics.AuthOutcomeDenied, "not_allowed")
+	missing := metrics.AuthDecisions.WithLabelValues(metrics.AuthOutcomeUnauthorized, "missing_authorization")
+	beforeAllowed, beforeDenied, beforeMissing := testutil.ToFloat64(allowed), testutil.ToFloat64(denied), testutil.ToFloat64(missing)
+
+	token := generateTestToken(cfg, agent.ResourceId, 1*time.Hour)
+	for _, uri := range []string{"/loki/loki/api/v1/push", "/mimir/api/v1/push"} {
+		req := newForwardedRequest(uri, http.MethodPost)
+		req.Header.Set("Authorization", "Bearer "+token)
+		server.handleAuth(httptest.NewRecorder(), req)
+	}
+	server.handleAuth(httptest.NewRecorder(), newForwardedRequest("/loki/loki/api/v1/push", http.MethodPost))
+
+	assert.Equal(t, beforeAllowed+1, testutil.ToFloat64(allowed))
+	assert.Equal(t, beforeDenied+1, testutil.ToFloat64(denied))
+	assert.Equal(t, beforeMissing+1, testutil.ToFloat64(missing))
+}
+
+func TestHandleAuth_PropagatesTraceContext(t *testing.T) {
+	exporter := tracetest.NewInMemoryExporter()
+	provider := sdktrace.NewTracerProvider(sdktrace.WithSyncer(exporter))
+	previousProvider, previousPropagator := otel.GetTracerProvider(), otel.GetTextMapPropagator()
+	otel.SetTracerProvider(provider)
+	otel.SetTextMapPropagator(propagation.TraceContext{})
+	t.Cleanup(func() {
+		otel.SetTracerProvider(previousProvider)
+		otel.SetTextMapPropagator(previousPropagator)
+	})
+
+	server, m, cfg := setupTestServer(t)
+
+	agent := &model.Agent{
+		Hostname:   "test-host",
+		ResourceId: "rid:test:123",
+	}
+	_, err := m.CreateAgent(agent)
+	assert.NoError(t, err)
+
+	token := generateTestToken(cfg, agent.ResourceId, 1*time.Hour)
+	req := newForwardedRequest("/loki/loki/api/v1/push", http.MethodPost)
+	req.Header.Set("Authorization", "Bearer "+token)
+	req.Header.Set("Traceparent", "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01")
+	rec := httptest.NewRecorder()
+
+	server.server.Handler.ServeHTTP(rec, req)
+	assert.Equal(t, http.StatusOK, rec.Code)
+
+	spans := exporter.GetSpans()
+	assert.Len(t, spans, 1)
+	span := spans[0]
+	assert.Equal(t, "GET /auth", span.Name)
+	assert.Equal(t, "4bf92f3577b34da6a3ce929d0e0e4736", span.SpanContext.TraceID().String())
+	assert.Equal(t, "00f067aa0ba902b7", span.Parent.SpanID().String())
+	assert.Contains(t, span.Attributes, attribute.String("finch.agent.rid", agent.ResourceId))
+	assert.Contains(t, span.Attributes, attribute.String("finch.backend", controller.BackendLoki))
+	assert.Contains(t, span.Attributes, attribute.String("finch.auth.outcome", metrics.AuthOutcomeAllowed))
+}
+
+func TestHandleAuth_CachedDecisionInvalidatedOnSuspend(t *testing.T) {
+	server, m, cfg := setupTestServer(t)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	server.controller.StartAuthCache(ctx, time.Minute)
+
+	agent := &model.Agent{
+		Hostname:   "test-host",
+		ResourceId: "rid:test:123",
+	}
+	_, err := m.CreateAgent(agent)
+	assert.NoError(t, err)
+
+	token := generateTestToken(cfg, agent.ResourceId, 1*time.Hour)
+	handle := func() int {
+		req := newForwardedRequest("/loki/loki/api/v1/push", http.MethodPost)
+		req.Header.Set("Authorization", "Bearer "+token)
+
+		w := httptest.NewRecorder()
+		server.handleAuth(w, req)
+		return w.Code
+	}
+
+	assert.Equal(t, http.StatusOK, handle())
+	assert.Equal(t, http.StatusOK, handle())
+	assert.Equal(t, uint64(1), server.controller.AuthCacheStats().Hits)
+
+	err = server.controller.SuspendAgent(agent.ResourceId, 0, controller.Actor{Name: "test"})
+	assert.NoError(t, err)
+
+	assert.Eventually(t, func() bool {
+		return handle() == http.StatusForbidden
+	}, time.Second, 10*time.Millisecond)
+}
+
+func BenchmarkHandleAuth(b *testing.B) {
+	for _, bm := range []struct {
+		name string
+		ttl  time.Duration
+	}{
+		{"uncached", 0},
+		{"cached", time.Minute},
+	} {
+		b.Run(bm.name, func(b *testing.B) {
+			server, m, cfg := setupTestServer(b)
+
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+			server.controller.StartAuthCache(ctx, bm.ttl)
+
+			agent := &model.Agent{
+				Hostname:   "test-host",
+				ResourceId: "rid:test:123",
+			}
+			_, err := m.CreateAgent(agent)
+			assert.NoError(b, err)
+
+			token := generateTestToken(cfg, agent.ResourceId, 1*time.Hour)
+
+			b.ReportAllocs()
+			b.ResetTimer()
+			b.RunParallel(func(pb *testing.PB) {
+				for pb.Next() {
+					req := newForwardedRequest("/loki/loki/api/v1/push", http.MethodPost)
+					req.Header.Set("Authorization", "Bearer "+token)
+
+					w := httptest.NewRecorder()
+					server.handleAuth(w, req)
+					if w.Code != http.StatusOK {
+						b.Fatalf("unexpected status %d", w.Code)
+					}
+				}
+			})
+		})
+	}
+}
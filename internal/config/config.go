@@ -8,6 +8,8 @@ import (
 	"encoding/json"
 	"fmt"
 	"log/slog"
+	"math"
+	"net/url"
 	"os"
 	"path"
 	"reflect"
@@ -15,12 +17,25 @@ import (
 )
 
 type Data struct {
-	CreatedAt string `json:"created_at"`
-	Database  string `json:"database"`
-	Profiler  string `json:"profiler"`
-	Hostname  string `json:"hostname"`
-	Id        string `json:"id"`
-	Secret    string `json:"secret"`
+	CreatedAt        string               `json:"created_at"`
+	Database         string               `json:"database"`
+	Profiler         string               `json:"profiler"`
+	Hostname         string               `json:"hostname"`
+	Id               string               `json:"id"`
+	Secret           string               `json:"secret"`
+	SigningAlgorithm string               `json:"signing_algorithm"`
+	RateLimits       map[string]RateLimit `json:"rate_limits,omitempty"`
+	Tracing          *Tracing             `json:"tracing,omitempty"`
+}
+
+type RateLimit struct {
+	Rate  float64 `json:"rate"`
+	Burst int     `json:"burst"`
+}
+
+type Tracing struct {
+	Endpoint    string  `json:"endpoint"`
+	SampleRatio float64 `json:"sample_ratio,omitempty"`
 }
 
 type Config struct {
@@ -100,6 +115,21 @@ func (c *Config) Secret() string {
 	return c.data.Secret
 }
 
+func (c *Config) SigningAlgorithm() string {
+	return c.data.SigningAlgorithm
+}
+
+func (c *Config) RateLimits() map[string]RateLimit {
+	return c.data.RateLimits
+}
+
+func (c *Config) Tracing() Tracing {
+	if c.data.Tracing == nil {
+		return Tracing{}
+	}
+	return *c.data.Tracing
+}
+
 func valid(data *Data) error {
 	fields := []string{
 		"CreatedAt",
@@ -116,6 +146,30 @@ func valid(data *Data) error {
 		}
 	}
 
+	for backend, limit := range data.RateLimits {
+		if math.IsNaN(limit.Rate) || math.IsInf(limit.Rate, 0) {
+			return fmt.Errorf("invalid configuration data, non-finite rate limit: %s", backend)
+		}
+		if limit.Rate < 0 || limit.Burst < 0 {
+			return fmt.Errorf("invalid configuration data, negative rate limit: %s", backend)
+		}
+		if limit.Burst > math.MaxInt32 {
+			return fmt.Errorf("invalid configuration data, rate limit burst out of range: %s", backend)
+		}
+	}
+
+	if tracing := data.Tracing; tracing != nil {
+		if tracing.Endpoint != "" {
+			uri, err := url.Parse(tracing.Endpoint)
+			if err != nil || (uri.Scheme != "http" && uri.Scheme != "https") || uri.Host == "" {
+				return fmt.Errorf("invalid configuration data, invalid tracing endpoint: %s", tracing.Endpoint)
+			}
+		}
+		if tracing.SampleRatio < 0 || tracing.SampleRatio > 1 {
+			return fmt.Errorf("invalid configuration data, tracing sample ratio out of range: %v", tracing.SampleRatio)
+		}
+	}
+
 	return nil
 }
 
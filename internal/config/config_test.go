@@ -5,6 +5,7 @@ Licensed under the MIT License, see LICENSE file in the project root for details
 package config
 
 import (
+	"math"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -61,3 +62,98 @@ func Test_ReadReturnsConfig(t *testing.T) {
 	assert.Equal(t, "12345", cfg.Id(), "id")
 	assert.Equal(t, "secret", cfg.Secret(), "secret")
 }
+
+func Test_ReadReturnsRateLimits(t *testing.T) {
+	cfg, err := NewFromString(`{
+		"created_at": "2023-10-01T00:00:00Z",
+		"database": "testdb",
+		"hostname": "localhost",
+		"id": "12345",
+		"secret": "secret",
+		"rate_limits": {
+			"loki": {"rate": 50, "burst": 100}
+		}
+	}`, "")
+	assert.NoError(t, err, "read config string")
+	assert.Equal(t, map[string]RateLimit{"loki": {Rate: 50, Burst: 100}}, cfg.RateLimits(), "rate limits")
+
+	_, err = NewFromString(`{
+		"created_at": "2023-10-01T00:00:00Z",
+		"database": "testdb",
+		"hostname": "localhost",
+		"id": "12345",
+		"secret": "secret",
+		"rate_limits": {
+			"loki": {"rate": -1}
+		}
+	}`, "")
+	assert.EqualError(t, err, "invalid configuration data, negative rate limit: loki", "negative rate limit")
+
+	_, err = NewFromString(`{
+		"created_at": "2023-10-01T00:00:00Z",
+		"database": "testdb",
+		"hostname": "localhost",
+		"id": "12345",
+		"secret": "secret",
+		"rate_limits": {
+			"loki": {"rate": 1, "burst": 4294967296}
+		}
+	}`, "")
+	assert.EqualError(t, err, "invalid configuration data, rate limit burst out of range: loki", "burst out of range")
+
+	data := &Data{
+		CreatedAt:  "2023-10-01T00:00:00Z",
+		Database:   "testdb",
+		Hostname:   "localhost",
+		Id:         "12345",
+		Secret:     "secret",
+		RateLimits: map[string]RateLimit{"loki": {Rate: math.Inf(1)}},
+	}
+	assert.EqualError(t, valid(data), "invalid configuration data, non-finite rate limit: loki", "infinite rate limit")
+
+	data.RateLimits = map[string]RateLimit{"loki": {Rate: math.NaN()}}
+	assert.EqualError(t, valid(data), "invalid configuration data, non-finite rate limit: loki", "NaN rate limit")
+}
+
+func Test_ReadReturnsTracing(t *testing.T) {
+	cfg, err := NewFromString(`{
+		"created_at": "2023-10-01T00:00:00Z",
+		"database": "testdb",
+		"hostname": "localhost",
+		"id": "12345",
+		"secret": "secret"
+	}`, "")
+	assert.NoError(t, err, "read config string")
+	assert.Equal(t, Tracing{}, cfg.Tracing(), "tracing disabled by default")
+
+	cfg, err = NewFromString(`{
+		"created_at": "2023-10-01T00:00:00Z",
+		"database": "testdb",
+		"hostname": "localhost",
+		"id": "12345",
+		"secret": "secret",
+		"tracing": {"endpoint": "http://localhost:4318", "sample_ratio": 0.5}
+	}`, "")
+	assert.NoError(t, err, "read config string")
+	assert.Equal(t, Tracing{Endpoint: "http://localhost:4318", SampleRatio: 0.5}, cfg.Tracing(), "tracing")
+
+	_, err = NewFromString(`{
+		"created_at": "2023-10-01T00:00:00Z",
+		"database": "testdb",
+		"hostname": "localhost",
+		"id": "12345",
+		"secret": "secret",
+		"tracing": {"endpoint": "localhost:4318"}
+	}`, "")
+	assert.EqualError(t, err, "invalid configuration data, invalid tracing endpoint: localhost:4318", "endpoint without scheme")
+
+	_, err = NewFromString(`{
+		"created_at": "2023-10-01T00:00:00Z",
+		"database": "testdb",
+		"hostname": "localhost",
+		"id": "12345",
+		"secret": "secret",
+		"tracing": {"endpoint": "http://localhost:4318", "sample_ratio": 2}
+	}`, "")
+	assert.EqualError(t, err, "invalid configuration data, tracing sample ratio out of range: 2", "sample ratio out of range")
+}
@@ -0,0 +1,30 @@
+/*
+Copyright (c) Tobias Schäfer. All rights reserved.
+Licensed under the MIT License, see LICENSE file in the project root for details.
+*/
+package controller
+
+import "fmt"
+
+type Actor struct {
+	Name    string
+	Address string
+}
+
+func CertificateActor(commonName string) Actor {
+	if commonName == "" {
+		return Actor{Name: "unknown"}
+	}
+	return Actor{Name: fmt.Sprintf("cert:%s", commonName)}
+}
+
+func DashboardActor(claims *DashboardClaims) Actor {
+	if claims == nil {
+		return Actor{Name: "unknown"}
+	}
+	return Actor{Name: fmt.Sprintf("dashboard:%s:%s", claims.Role, claims.Session)}
+}
+
+func SystemActor(component string) Actor {
+	return Actor{Name: fmt.Sprintf("system:%s", component)}
+}
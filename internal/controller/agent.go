@@ -5,11 +5,11 @@ Licensed under the MIT License, see LICENSE file in the project root for details
 package controller
 
 import (
-	"bytes"
 	"errors"
 	"fmt"
 	"log/slog"
-	"text/template"
+	"strconv"
+	"time"
 
 	"github.com/tschaefer/finch/internal/model"
 )
@@ -17,20 +17,28 @@ import (
 var (
 	ErrAgentNotFound      = errors.New("agent not found")
 	ErrAgentAlreadyExists = errors.New("agent already exists")
+	ErrAgentSuspended     = errors.New("agent suspended")
+	ErrInvalidUpdateMask  = errors.New("invalid update mask")
+	ErrAgentConflict      = errors.New("agent was modified concurrently")
 )
 
+var UpdatableAgentFields = []string{"labels", "log_sources", "metrics", "metrics_targets", "profiles", "rate_limits"}
+
 type Agent struct {
-	Hostname       string   `json:"hostname"`
-	Labels         []string `json:"labels"`
-	LogSources     []string `json:"log_sources"`
-	Metrics        bool     `json:"metrics"`
-	MetricsTargets []string `json:"metrics_targets"`
-	Profiles       bool     `json:"profiles"`
-	Node           string   `json:"node"`
+	Hostname       string           `json:"hostname"`
+	Labels         []string         `json:"labels"`
+	LogSources     []string         `json:"log_sources"`
+	Metrics        bool             `json:"metrics"`
+	MetricsTargets []string         `json:"metrics_targets"`
+	Profiles       bool             `json:"profiles"`
+	Node           string           `json:"node"`
+	Ephemeral      bool             `json:"ephemeral"`
+	Tenant         string           `json:"tenant"`
+	RateLimits     model.RateLimits `json:"rate_limits"`
 }
 
-func (c *Controller) RegisterAgent(data *Agent) (string, error) {
-	slog.Debug("Register Agent", "data", fmt.Sprintf("%+v", data))
+func (c *Controller) RegisterAgent(data *Agent, actor Actor) (string, error) {
+	slog.Debug("Register Agent", "data", fmt.Sprintf("%+v", data), "actor", actor.Name)
 
 	agent, err := c.marshalNewAgent(data)
 	if err != nil {
@@ -45,34 +53,52 @@ func (c *Controller) RegisterAgent(data *Agent) (string, error) {
 		return "", ErrAgentAlreadyExists
 	}
 
-	_, err = c.model.CreateAgent(agent)
+	err = c.model.Transaction(func(tx *model.Model) error {
+		if _, err := tx.CreateAgent(agent); err != nil {
+			return err
+		}
+
+		_, err := c.recordConfigRevision(tx, agent, actor)
+		return err
+	})
 	if err != nil {
 		return "", err
 	}
 
+	c.audit(actor, AuditActionAgentRegister, agent.ResourceId, model.AgentDiff(nil, agent))
 	return agent.ResourceId, nil
 }
 
-func (c *Controller) DeregisterAgent(rid string) error {
-	slog.Debug("Deregister Agent", "rid", rid)
+func (c *Controller) DeregisterAgent(rid string, version uint64, actor Actor) error {
+	slog.Debug("Deregister Agent", "rid", rid, "version", version, "actor", actor.Name)
 
-	agent, err := c.model.GetAgent(&model.Agent{ResourceId: rid})
+	agent, err := c.getAgentVersion(rid, version)
 	if err != nil {
-		if errors.Is(err, model.ErrAgentNotFound) {
-			return ErrAgentNotFound
-		}
 		return err
 	}
 
-	if err := c.model.DeleteAgent(agent); err != nil {
+	err = c.model.Transaction(func(tx *model.Model) error {
+		if err := tx.DeleteAgent(agent); err != nil {
+			return agentWriteError(err)
+		}
+
+		if err := tx.MarkAgentTokensDeregistered(rid, time.Now()); err != nil {
+			return err
+		}
+
+		return tx.DeleteCollectors(rid)
+	})
+	if err != nil {
 		return err
 	}
+	c.forgetCollectorConfig(rid)
 
+	c.audit(actor, AuditActionAgentDeregister, rid, model.AgentDiff(agent, nil))
 	return nil
 }
 
-func (c *Controller) CreateAgentConfig(rid string) ([]byte, error) {
-	slog.Debug("Create Agent Config", "rid", rid)
+func (c *Controller) CreateAgentConfig(rid string, actor Actor) ([]byte, error) {
+	slog.Debug("Create Agent Config", "rid", rid, "actor", actor.Name)
 
 	agent, err := c.model.GetAgent(&model.Agent{ResourceId: rid})
 	if err != nil {
@@ -82,38 +108,54 @@ func (c *Controller) CreateAgentConfig(rid string) ([]byte, error) {
 		return nil, err
 	}
 
-	tmpl, err := template.New("alloy.cfg").Parse(alloyTemplate)
+	data, err := c.generateAlloyConfig(agent, actor)
 	if err != nil {
 		return nil, err
 	}
 
-	data, err := c.generateAlloyConfig(agent)
+	return renderAlloyConfig(data)
+}
+
+func (c *Controller) PullAgentConfig(token string, claims *AgentClaims) ([]byte, string, error) {
+	slog.Debug("Pull Agent Config", "rid", claims.ResourceId, "jti", claims.Jti)
+
+	agent, err := c.model.GetAgent(&model.Agent{ResourceId: claims.ResourceId})
 	if err != nil {
-		return nil, err
+		if errors.Is(err, model.ErrAgentNotFound) {
+			return nil, "", ErrAgentNotFound
+		}
+		return nil, "", err
 	}
 
-	buf := new(bytes.Buffer)
-	if err := tmpl.Execute(buf, data); err != nil {
-		return nil, err
+	config, hash, err := c.renderTokenConfig(agent, token, claims, false)
+	if err != nil {
+		return nil, "", err
 	}
 
-	return buf.Bytes(), nil
+	return config, fmt.Sprintf("%q", hash), nil
 }
 
 func (c *Controller) ListAgents() ([]map[string]string, error) {
 	slog.Debug("List Agents")
 
-	agents := []model.Agent{}
-	_, err := c.model.ListAgents(&agents)
+	agents, _, err := c.QueryAgents(AgentFilter{})
 	if err != nil {
 		return nil, err
 	}
 
 	list := make([]map[string]string, 0, len(agents))
 	for _, agent := range agents {
+		lastSeen := ""
+		if agent.LastSeen != nil {
+			lastSeen = agent.LastSeen.Format(time.RFC3339)
+		}
+
 		entry := map[string]string{
-			"rid":      agent.ResourceId,
-			"hostname": agent.Hostname,
+			"rid":       agent.ResourceId,
+			"hostname":  agent.Hostname,
+			"last_seen": lastSeen,
+			"active":    strconv.FormatBool(agent.Active),
+			"stale":     strconv.FormatBool(agent.Stale),
 		}
 		list = append(list, entry)
 	}
@@ -131,30 +173,103 @@ func (c *Controller) GetAgent(rid string) (*model.Agent, error) {
 		}
 		return nil, err
 	}
+	c.applyPendingLastSeen(agent)
 
 	return agent, nil
 }
 
-func (c *Controller) UpdateAgent(rid string, data *Agent) error {
-	slog.Debug("Update Agent", "rid", rid, "data", fmt.Sprintf("%+v", data))
+func (c *Controller) UpdateAgent(rid string, version uint64, data *Agent, actor Actor, fields ...string) error {
+	slog.Debug("Update Agent", "rid", rid, "version", version, "data", fmt.Sprintf("%+v", data), "actor", actor.Name, "fields", fields)
 
-	agent, err := c.model.GetAgent(&model.Agent{ResourceId: rid})
+	agent, err := c.getAgentVersion(rid, version)
+	if err != nil {
+		return err
+	}
+	before := *agent
+
+	updated, err := c.marshalUpdateAgent(agent, data, fields)
 	if err != nil {
-		if errors.Is(err, model.ErrAgentNotFound) {
-			return ErrAgentNotFound
-		}
 		return err
 	}
 
-	updated, err := c.marshalUpdateAgent(agent, data)
+	err = c.model.Transaction(func(tx *model.Model) error {
+		if _, err := tx.UpdateAgent(updated); err != nil {
+			return agentWriteError(err)
+		}
+
+		if !settingsChanged(&before, updated) {
+			return nil
+		}
+		_, err := c.recordConfigRevision(tx, updated, actor)
+		return err
+	})
 	if err != nil {
 		return err
 	}
 
-	_, err = c.model.UpdateAgent(updated)
+	c.audit(actor, AuditActionAgentUpdate, rid, model.AgentDiff(&before, updated))
+	return nil
+}
+
+func (c *Controller) SuspendAgent(rid string, version uint64, actor Actor) error {
+	slog.Debug("Suspend Agent", "rid", rid, "version", version, "actor", actor.Name)
+
+	return c.setAgentActive(rid, version, false, actor)
+}
+
+func (c *Controller) ResumeAgent(rid string, version uint64, actor Actor) error {
+	slog.Debug("Resume Agent", "rid", rid, "version", version, "actor", actor.Name)
+
+	return c.setAgentActive(rid, version, true, actor)
+}
+
+func (c *Controller) setAgentActive(rid string, version uint64, active bool, actor Actor) error {
+	agent, err := c.getAgentVersion(rid, version)
 	if err != nil {
 		return err
 	}
+	before := *agent
+
+	if agent.Active == active {
+		return nil
+	}
 
+	agent.Active = active
+	if _, err := c.model.UpdateAgent(agent); err != nil {
+		return agentWriteError(err)
+	}
+
+	action := AuditActionAgentSuspend
+	if active {
+		action = AuditActionAgentResume
+	}
+	c.audit(actor, action, rid, model.AgentDiff(&before, agent))
 	return nil
 }
+
+func (c *Controller) getAgentVersion(rid string, version uint64) (*model.Agent, error) {
+	agent, err := c.model.GetAgent(&model.Agent{ResourceId: rid})
+	if err != nil {
+		if errors.Is(err, model.ErrAgentNotFound) {
+			return nil, ErrAgentNotFound
+		}
+		return nil, err
+	}
+
+	if version != 0 && agent.ResourceVersion != version {
+		return nil, fmt.Errorf("%w: expected version %d, current version %d", ErrAgentConflict, version, agent.ResourceVersion)
+	}
+
+	return agent, nil
+}
+
+func agentWriteError(err error) error {
+	switch {
+	case errors.Is(err, model.ErrAgentVersionConflict):
+		return ErrAgentConflict
+	case errors.Is(err, model.ErrAgentNotFound):
+		return ErrAgentNotFound
+	default:
+		return err
+	}
+}
@@ -5,24 +5,32 @@ Licensed under the MIT License, see LICENSE file in the project root for details
 package controller
 
 import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
 	"fmt"
 	"net/url"
 	"os"
 	"path"
 	"strings"
+	"text/template"
 
 	"github.com/tschaefer/finch/internal/model"
 )
 
 const alloyTemplate = `
+{{ if not .Module -}}
 logging {
 	level = "info"
 }
 
+{{ end -}}
 loki.write "default" {
 	endpoint {
 		url = "https://{{ .ServiceName }}/loki/loki/api/v1/push"
+		tenant_id = "{{ .Tenant }}"
 
+		// Token ID: {{ .TokenId }}
 		// Token expires: {{ .TokenExpiry }}
 		bearer_token = "{{ .Token }}"
 {{ if .InsecureSkipVerify }}
@@ -146,7 +154,11 @@ loki.source.windowsevent "event_{{ $index }}" {
 prometheus.remote_write "default" {
 	endpoint {
 		url = "https://{{ .ServiceName }}/mimir/api/v1/push"
+		headers = {
+			"X-Scope-OrgID" = "{{ .Tenant }}",
+		}
 
+		// Token ID: {{ .TokenId }}
 		// Token expires: {{ .TokenExpiry }}
 		bearer_token = "{{ .Token }}"
 {{ if .InsecureSkipVerify }}
@@ -224,7 +236,11 @@ pyroscope.receive_http "default" {
 pyroscope.write "backend" {
 	endpoint {
 		url = "https://{{ .ServiceName }}/pyroscope"
+		headers = {
+			"X-Scope-OrgID" = "{{ .Tenant }}",
+		}
 
+		// Token ID: {{ .TokenId }}
 		// Token expires: {{ .TokenExpiry }}
 		bearer_token = "{{ .Token }}"
 {{ if .InsecureSkipVerify }}
@@ -249,9 +265,12 @@ type alloyConfigData struct {
 	Hostname           string
 	Node               string
 	Token              string
+	TokenId            string
 	TokenExpiry        string
 	ResourceId         string
+	Tenant             string
 	InsecureSkipVerify bool
+	Module             bool
 	LogSources         struct {
 		Journal bool
 		Docker  bool
@@ -267,12 +286,21 @@ type alloyConfigData struct {
 	Labels   []string
 }
 
-func (c *Controller) generateAlloyConfig(agent *model.Agent) (*alloyConfigData, error) {
-	token, expiresAt, err := c.GenerateAgentToken(agent.ResourceId, 0)
+func (c *Controller) generateAlloyConfig(agent *model.Agent, actor Actor) (*alloyConfigData, error) {
+	issued, token, err := c.issueAgentToken(agent.ResourceId, 0, actor, TokenPurposeConfig)
 	if err != nil {
 		return nil, err
 	}
 
+	data := c.alloyConfigData(agent)
+	data.Token = token
+	data.TokenId = issued.Jti
+	data.TokenExpiry = issued.ExpiresAt.Format("2006-01-02 15:04:05 MST")
+
+	return data, nil
+}
+
+func (c *Controller) alloyConfigData(agent *model.Agent) *alloyConfigData {
 	labels := make([]string, 0)
 	for _, label := range agent.Labels {
 		if strings.Contains(label, "=") {
@@ -287,9 +315,8 @@ func (c *Controller) generateAlloyConfig(agent *model.Agent) (*alloyConfigData,
 		Hostname:           agent.Hostname,
 		Node:               agent.Node,
 		ServiceName:        c.config.Hostname(),
-		Token:              token,
-		TokenExpiry:        expiresAt.Format("2006-01-02 15:04:05 MST"),
 		ResourceId:         agent.ResourceId,
+		Tenant:             agent.Tenant,
 		InsecureSkipVerify: true,
 		LogSources: struct {
 			Journal bool
@@ -357,5 +384,35 @@ func (c *Controller) generateAlloyConfig(agent *model.Agent) (*alloyConfigData,
 		}
 	}
 
-	return data, nil
+	return data
+}
+
+func (c *Controller) renderTokenConfig(agent *model.Agent, token string, claims *AgentClaims, module bool) ([]byte, string, error) {
+	data := c.alloyConfigData(agent)
+	data.Token = token
+	data.TokenId = claims.Jti
+	data.TokenExpiry = claims.ExpiresAt.Format("2006-01-02 15:04:05 MST")
+	data.Module = module
+
+	config, err := renderAlloyConfig(data)
+	if err != nil {
+		return nil, "", err
+	}
+	hash := sha256.Sum256(config)
+
+	return config, hex.EncodeToString(hash[:]), nil
+}
+
+func renderAlloyConfig(data *alloyConfigData) ([]byte, error) {
+	tmpl, err := template.New("alloy.cfg").Parse(alloyTemplate)
+	if err != nil {
+		return nil, err
+	}
+
+	buf := new(bytes.Buffer)
+	if err := tmpl.Execute(buf, data); err != nil {
+		return nil, err
+	}
+
+	return buf.Bytes(), nil
 }
@@ -0,0 +1,167 @@
+/*
+Copyright (c) Tobias Schäfer. All rights reserved.
+Licensed under the MIT License, see LICENSE file in the project root for details.
+*/
+package controller
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"log/slog"
+	"reflect"
+
+	"github.com/pmezard/go-difflib/difflib"
+	"github.com/tschaefer/finch/internal/model"
+)
+
+var (
+	ErrConfigRevisionNotFound = errors.New("config revision not found")
+)
+
+func (c *Controller) ListAgentConfigRevisions(rid string) ([]model.AgentConfigRevision, error) {
+	slog.Debug("List Agent Config Revisions", "rid", rid)
+
+	if _, err := c.GetAgent(rid); err != nil {
+		return nil, err
+	}
+
+	revisions := []model.AgentConfigRevision{}
+	if _, err := c.model.ListAgentConfigRevisions(&revisions, rid); err != nil {
+		return nil, err
+	}
+
+	return revisions, nil
+}
+
+func (c *Controller) DiffAgentConfig(rid string, from, to uint64) (string, error) {
+	slog.Debug("Diff Agent Config", "rid", rid, "from", from, "to", to)
+
+	agent, err := c.GetAgent(rid)
+	if err != nil {
+		return "", err
+	}
+
+	fromRevision, err := c.getConfigRevision(rid, from)
+	if err != nil {
+		return "", err
+	}
+	toRevision, err := c.getConfigRevision(rid, to)
+	if err != nil {
+		return "", err
+	}
+
+	fromConfig, err := c.renderConfigRevision(agent, fromRevision)
+	if err != nil {
+		return "", err
+	}
+	toConfig, err := c.renderConfigRevision(agent, toRevision)
+	if err != nil {
+		return "", err
+	}
+
+	return difflib.GetUnifiedDiffString(difflib.UnifiedDiff{
+		A:        difflib.SplitLines(string(fromConfig)),
+		B:        difflib.SplitLines(string(toConfig)),
+		FromFile: fmt.Sprintf("revision %d", fromRevision.Revision),
+		ToFile:   fmt.Sprintf("revision %d", toRevision.Revision),
+		Context:  3,
+	})
+}
+
+func (c *Controller) RollbackAgentConfig(rid string, revision, version uint64, actor Actor) (uint64, error) {
+	slog.Debug("Rollback Agent Config", "rid", rid, "revision", revision, "version", version, "actor", actor.Name)
+
+	agent, err := c.getAgentVersion(rid, version)
+	if err != nil {
+		return 0, err
+	}
+	before := *agent
+
+	target, err := c.getConfigRevision(rid, revision)
+	if err != nil {
+		return 0, err
+	}
+
+	agent.ApplySettings(target.Settings)
+	var created *model.AgentConfigRevision
+	err = c.model.Transaction(func(tx *model.Model) error {
+		if _, err := tx.UpdateAgent(agent); err != nil {
+			return agentWriteError(err)
+		}
+
+		created, err = c.recordConfigRevision(tx, agent, actor)
+		return err
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	c.audit(actor, AuditActionAgentConfigRollback, rid, model.AgentDiff(&before, agent))
+	return created.Revision, nil
+}
+
+func (c *Controller) recordConfigRevision(m *model.Model, agent *model.Agent, actor Actor) (*model.AgentConfigRevision, error) {
+	config, err := renderAlloyConfig(c.alloyConfigData(agent))
+	if err != nil {
+		return nil, err
+	}
+	hash := sha256.Sum256(config)
+
+	return m.CreateAgentConfigRevision(&model.AgentConfigRevision{
+		ResourceId: agent.ResourceId,
+		Settings:   agent.Settings(),
+		ConfigHash: hex.EncodeToString(hash[:]),
+		Author:     actor.Name,
+	})
+}
+
+func (c *Controller) RecordBaselineConfigRevisions() error {
+	slog.Debug("Record Baseline Config Revisions")
+
+	agents := []model.Agent{}
+	if _, err := c.model.ListAgentsWithoutConfigRevisions(&agents); err != nil {
+		return err
+	}
+
+	for i := range agents {
+		slog.Debug("Record baseline config revision", "rid", agents[i].ResourceId)
+		if _, err := c.recordConfigRevision(c.model, &agents[i], SystemActor("baseline")); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (c *Controller) getConfigRevision(rid string, revision uint64) (*model.AgentConfigRevision, error) {
+	var (
+		entry *model.AgentConfigRevision
+		err   error
+	)
+	if revision == 0 {
+		entry, err = c.model.GetLatestAgentConfigRevision(rid)
+	} else {
+		entry, err = c.model.GetAgentConfigRevision(rid, revision)
+	}
+	if err != nil {
+		if errors.Is(err, model.ErrAgentConfigRevisionNotFound) {
+			return nil, fmt.Errorf("%w: %d", ErrConfigRevisionNotFound, revision)
+		}
+		return nil, err
+	}
+
+	return entry, nil
+}
+
+func (c *Controller) renderConfigRevision(agent *model.Agent, revision *model.AgentConfigRevision) ([]byte, error) {
+	snapshot := *agent
+	snapshot.ApplySettings(revision.Settings)
+
+	return renderAlloyConfig(c.alloyConfigData(&snapshot))
+}
+
+func settingsChanged(before, after *model.Agent) bool {
+	return !reflect.DeepEqual(before.Settings(), after.Settings())
+}
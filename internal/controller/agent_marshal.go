@@ -29,6 +29,16 @@ func (c *Controller) marshalNewAgent(data *Agent) (*model.Agent, error) {
 
 	effectiveMetricsTargets := c.__parseMetricsTargets(data)
 
+	tenant, err := c.resolveTenant(data.Tenant)
+	if err != nil {
+		return nil, err
+	}
+
+	rateLimits, err := parseRateLimits(data.RateLimits)
+	if err != nil {
+		return nil, err
+	}
+
 	agent := &model.Agent{
 		Hostname:       data.Hostname,
 		Node:           data.Node,
@@ -37,25 +47,46 @@ func (c *Controller) marshalNewAgent(data *Agent) (*model.Agent, error) {
 		MetricsTargets: effectiveMetricsTargets,
 		Profiles:       data.Profiles,
 		Labels:         data.Labels,
+		Ephemeral:      data.Ephemeral,
+		Tenant:         tenant,
+		RateLimits:     rateLimits,
 		ResourceId:     fmt.Sprintf("rid:finch:%s:agent:%s", c.config.Id(), uuid.New().String()),
 	}
 
 	return agent, nil
 }
 
-func (c *Controller) marshalUpdateAgent(existing *model.Agent, data *Agent) (*model.Agent, error) {
-	effectiveLogSources, err := c.__parseLogSources(data)
-	if err != nil {
-		return nil, err
+func (c *Controller) marshalUpdateAgent(existing *model.Agent, data *Agent, fields []string) (*model.Agent, error) {
+	if len(fields) == 0 {
+		fields = UpdatableAgentFields
 	}
 
-	effectiveMetricsTargets := c.__parseMetricsTargets(data)
-
-	existing.Labels = data.Labels
-	existing.LogSources = effectiveLogSources
-	existing.Metrics = data.Metrics
-	existing.MetricsTargets = effectiveMetricsTargets
-	existing.Profiles = data.Profiles
+	for _, field := range fields {
+		switch field {
+		case "labels":
+			existing.Labels = data.Labels
+		case "log_sources":
+			effectiveLogSources, err := c.__parseLogSources(data)
+			if err != nil {
+				return nil, err
+			}
+			existing.LogSources = effectiveLogSources
+		case "metrics":
+			existing.Metrics = data.Metrics
+		case "metrics_targets":
+			existing.MetricsTargets = c.__parseMetricsTargets(data)
+		case "profiles":
+			existing.Profiles = data.Profiles
+		case "rate_limits":
+			rateLimits, err := parseRateLimits(data.RateLimits)
+			if err != nil {
+				return nil, err
+			}
+			existing.RateLimits = rateLimits
+		default:
+			return nil, fmt.Errorf("%w: %s", ErrInvalidUpdateMask, field)
+		}
+	}
 
 	return existing, nil
 }
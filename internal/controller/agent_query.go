@@ -0,0 +1,136 @@
+/*
+Copyright (c) Tobias Schäfer. All rights reserved.
+Licensed under the MIT License, see LICENSE file in the project root for details.
+*/
+package controller
+
+import (
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strconv"
+	"strings"
+
+	"github.com/tschaefer/finch/internal/model"
+)
+
+var (
+	ErrInvalidLabelSelector = errors.New("invalid label selector")
+	ErrInvalidPageToken     = errors.New("invalid page token")
+)
+
+type AgentFilter struct {
+	LabelSelector   string
+	Node            string
+	Metrics         *bool
+	Profiles        *bool
+	Active          *bool
+	Stale           *bool
+	LogSourceScheme string
+	Tenant          string
+	PageSize        int
+	PageToken       string
+}
+
+func (c *Controller) QueryAgents(filter AgentFilter) ([]model.Agent, string, error) {
+	slog.Debug("Query Agents", "filter", fmt.Sprintf("%+v", filter))
+
+	labels, err := ParseLabelSelector(filter.LabelSelector)
+	if err != nil {
+		return nil, "", err
+	}
+
+	afterId, err := decodePageToken(filter.PageToken)
+	if err != nil {
+		return nil, "", err
+	}
+
+	query := &model.AgentQuery{
+		Labels:          labels,
+		Node:            filter.Node,
+		Metrics:         filter.Metrics,
+		Profiles:        filter.Profiles,
+		Active:          filter.Active,
+		Stale:           filter.Stale,
+		LogSourceScheme: filter.LogSourceScheme,
+		Tenant:          filter.Tenant,
+		AfterId:         afterId,
+	}
+	if filter.PageSize > 0 {
+		query.Limit = filter.PageSize + 1
+	}
+
+	agents := []model.Agent{}
+	if _, err := c.model.QueryAgents(&agents, query); err != nil {
+		return nil, "", err
+	}
+
+	nextPageToken := ""
+	if filter.PageSize > 0 && len(agents) > filter.PageSize {
+		agents = agents[:filter.PageSize]
+		nextPageToken = encodePageToken(agents[len(agents)-1].ID)
+	}
+
+	for i := range agents {
+		c.applyPendingLastSeen(&agents[i])
+	}
+
+	return agents, nextPageToken, nil
+}
+
+func ParseLabelSelector(selector string) ([]model.LabelRequirement, error) {
+	requirements := make([]model.LabelRequirement, 0)
+	if strings.TrimSpace(selector) == "" {
+		return requirements, nil
+	}
+
+	for term := range strings.SplitSeq(selector, ",") {
+		term = strings.TrimSpace(term)
+
+		var req model.LabelRequirement
+		switch {
+		case strings.HasPrefix(term, "!"):
+			req = model.LabelRequirement{Key: strings.TrimSpace(term[1:]), Operator: model.LabelOperatorDoesNotExist}
+		case strings.Contains(term, "!="):
+			key, value, _ := strings.Cut(term, "!=")
+			req = model.LabelRequirement{Key: strings.TrimSpace(key), Operator: model.LabelOperatorNotEquals, Value: strings.TrimSpace(value)}
+		case strings.Contains(term, "=="):
+			key, value, _ := strings.Cut(term, "==")
+			req = model.LabelRequirement{Key: strings.TrimSpace(key), Operator: model.LabelOperatorEquals, Value: strings.TrimSpace(value)}
+		case strings.Contains(term, "="):
+			key, value, _ := strings.Cut(term, "=")
+			req = model.LabelRequirement{Key: strings.TrimSpace(key), Operator: model.LabelOperatorEquals, Value: strings.TrimSpace(value)}
+		default:
+			req = model.LabelRequirement{Key: term, Operator: model.LabelOperatorExists}
+		}
+
+		if req.Key == "" || strings.ContainsAny(req.Key, "=! \t") || strings.ContainsAny(req.Value, "=! \t") {
+			return nil, fmt.Errorf("%w: %q", ErrInvalidLabelSelector, term)
+		}
+		requirements = append(requirements, req)
+	}
+
+	return requirements, nil
+}
+
+func encodePageToken(id uint) string {
+	return base64.RawURLEncoding.EncodeToString([]byte(strconv.FormatUint(uint64(id), 10)))
+}
+
+func decodePageToken(token string) (uint, error) {
+	if token == "" {
+		return 0, nil
+	}
+
+	raw, err := base64.RawURLEncoding.DecodeString(token)
+	if err != nil {
+		return 0, ErrInvalidPageToken
+	}
+	id, err := strconv.ParseUint(string(raw), 10, 64)
+	if err != nil {
+		return 0, ErrInvalidPageToken
+	}
+
+	return uint(id), nil
+}
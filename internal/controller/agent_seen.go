@@ -0,0 +1,81 @@
+/*
+Copyright (c) Tobias Schäfer. All rights reserved.
+Licensed under the MIT License, see LICENSE file in the project root for details.
+*/
+package controller
+
+import (
+	"context"
+	"log/slog"
+	"time"
+
+	"github.com/tschaefer/finch/internal/model"
+)
+
+const DefaultLastSeenFlushInterval = 30 * time.Second
+
+func (c *Controller) markAgentSeen(rid string) {
+	c.lastSeenMu.Lock()
+	defer c.lastSeenMu.Unlock()
+
+	c.lastSeen[rid] = time.Now()
+}
+
+func (c *Controller) applyPendingLastSeen(agent *model.Agent) {
+	c.lastSeenMu.Lock()
+	defer c.lastSeenMu.Unlock()
+
+	seen, ok := c.lastSeen[agent.ResourceId]
+	if !ok {
+		return
+	}
+	if agent.LastSeen == nil || seen.After(*agent.LastSeen) {
+		agent.LastSeen = &seen
+	}
+}
+
+func (c *Controller) FlushLastSeen() error {
+	c.lastSeenMu.Lock()
+	pending := c.lastSeen
+	c.lastSeen = make(map[string]time.Time)
+	c.lastSeenMu.Unlock()
+
+	if len(pending) == 0 {
+		return nil
+	}
+
+	slog.Debug("Flushing agents last seen", "count", len(pending))
+
+	if err := c.model.UpdateAgentsLastSeen(pending); err != nil {
+		c.lastSeenMu.Lock()
+		for rid, seen := range pending {
+			if current, ok := c.lastSeen[rid]; !ok || seen.After(current) {
+				c.lastSeen[rid] = seen
+			}
+		}
+		c.lastSeenMu.Unlock()
+		return err
+	}
+
+	return nil
+}
+
+func (c *Controller) RunLastSeenFlusher(ctx context.Context, interval time.Duration) {
+	if interval <= 0 {
+		interval = DefaultLastSeenFlushInterval
+	}
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if err := c.FlushLastSeen(); err != nil {
+				slog.Error("Failed to flush agents last seen", "error", err)
+			}
+		}
+	}
+}
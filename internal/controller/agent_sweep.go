@@ -0,0 +1,55 @@
+/*
+Copyright (c) Tobias Schäfer. All rights reserved.
+Licensed under the MIT License, see LICENSE file in the project root for details.
+*/
+package controller
+
+import (
+	"log/slog"
+	"time"
+
+	"github.com/tschaefer/finch/internal/model"
+)
+
+func (c *Controller) MarkStaleAgents(staleAfter time.Duration) ([]string, error) {
+	slog.Debug("Mark Stale Agents", "staleAfter", staleAfter)
+
+	if err := c.FlushLastSeen(); err != nil {
+		return nil, err
+	}
+
+	agents, err := c.model.MarkAgentsStale(time.Now().Add(-staleAfter))
+	if err != nil {
+		return nil, err
+	}
+
+	rids := make([]string, 0, len(agents))
+	for _, agent := range agents {
+		rids = append(rids, agent.ResourceId)
+	}
+
+	return rids, nil
+}
+
+func (c *Controller) DeregisterEphemeralAgents(grace time.Duration) ([]string, error) {
+	slog.Debug("Deregister Ephemeral Agents", "grace", grace)
+
+	if err := c.FlushLastSeen(); err != nil {
+		return nil, err
+	}
+
+	agents := []model.Agent{}
+	if _, err := c.model.ListEphemeralAgents(&agents, time.Now().Add(-grace)); err != nil {
+		return nil, err
+	}
+
+	rids := make([]string, 0, len(agents))
+	for _, agent := range agents {
+		if err := c.DeregisterAgent(agent.ResourceId, agent.ResourceVersion, SystemActor("sweeper")); err != nil {
+			return rids, err
+		}
+		rids = append(rids, agent.ResourceId)
+	}
+
+	return rids, nil
+}
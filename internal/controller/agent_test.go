@@ -5,8 +5,10 @@ Licensed under the MIT License, see LICENSE file in the project root for details
 package controller
 
 import (
+	"errors"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/tschaefer/finch/internal/config"
@@ -21,6 +23,8 @@ var cfg = config.NewFromData(&config.Data{
 	Id:     "test-id",
 }, "")
 
+var testActor = Actor{Name: "test"}
+
 func newModel(t *testing.T) *model.Model {
 	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{
 		Logger: logger.Default.LogMode(logger.Silent),
@@ -28,7 +32,7 @@ func newModel(t *testing.T) *model.Model {
 	if err != nil {
 		t.Fatal(err)
 	}
-	err = db.AutoMigrate(&model.Agent{})
+	err = db.AutoMigrate(&model.Agent{}, &model.AgentToken{}, &model.AuditEvent{}, &model.AgentConfigRevision{}, &model.Collector{}, &model.EnrollmentToken{}, &model.Tenant{}, &model.AgentUsage{}, &model.DashboardSession{})
 	if err != nil {
 		t.Fatal(err)
 	}
@@ -52,22 +56,22 @@ func Test_RegisterAgentReturnsError_InvalidParameters(t *testing.T) {
 		Profiles:       false,
 	}
 
-	_, err := ctrl.RegisterAgent(&data)
+	_, err := ctrl.RegisterAgent(&data, testActor)
 	expected := "hostname must not be empty"
 	assert.EqualError(t, err, expected, "register agent with empty hostname")
 
 	data.Hostname = "test-host"
-	_, err = ctrl.RegisterAgent(&data)
+	_, err = ctrl.RegisterAgent(&data, testActor)
 	expected = "at least one log source must be specified"
 	assert.EqualError(t, err, expected, "register agent with no log sources")
 
 	data.LogSources = []string{"invalid://source"}
-	_, err = ctrl.RegisterAgent(&data)
+	_, err = ctrl.RegisterAgent(&data, testActor)
 	expected = "no valid log source specified"
 	assert.EqualError(t, err, expected, "register agent with invalid log source")
 
 	data.Node = "invalid"
-	_, err = ctrl.RegisterAgent(&data)
+	_, err = ctrl.RegisterAgent(&data, testActor)
 	expected = "node must be either 'windows' or 'unix'"
 	assert.EqualError(t, err, expected, "register agent with invalid node type")
 }
@@ -88,7 +92,7 @@ func Test_RegisterAgentReturnsResourceId(t *testing.T) {
 		Profiles:       false,
 	}
 
-	rid, err := ctrl.RegisterAgent(&data)
+	rid, err := ctrl.RegisterAgent(&data, testActor)
 	assert.NoError(t, err, "register agent with valid parameters")
 
 	assert.NotEmpty(t, rid, "resource ID not empty")
@@ -108,7 +112,7 @@ func Test_DeregisterAgentReturnsError_AgentNotFound(t *testing.T) {
 	ctrl := New(model, cfg)
 	assert.NotNil(t, ctrl, "create controller")
 
-	err := ctrl.DeregisterAgent("non-existent-rid")
+	err := ctrl.DeregisterAgent("non-existent-rid", 0, testActor)
 	expected := "agent not found"
 	assert.EqualError(t, err, expected, "deregister non-existent agent")
 }
@@ -129,11 +133,68 @@ func Test_DeregisterAgentSucceeds(t *testing.T) {
 		Profiles:       false,
 	}
 
-	rid, err := ctrl.RegisterAgent(&data)
+	rid, err := ctrl.RegisterAgent(&data, testActor)
 	assert.NoError(t, err, "register agent with valid parameters")
 
-	err = ctrl.DeregisterAgent(rid)
+	_, _, err = ctrl.GenerateAgentToken(rid, time.Hour, testActor, TokenPurposeConfig)
+	assert.NoError(t, err, "generate token")
+
+	err = ctrl.DeregisterAgent(rid, 0, testActor)
 	assert.NoError(t, err, "deregister existing agent")
+
+	tokens, err := ctrl.ListAgentTokens("", 0)
+	assert.NoError(t, err, "list agent tokens")
+	assert.Len(t, tokens, 1, "issued token kept in ledger")
+	assert.True(t, tokens[0].Revoked, "token of deregistered agent revoked")
+
+	kids := []string{""}
+	for _, key := range ctrl.ListSigningKeys() {
+		kids = append(kids, key.Id)
+	}
+	inUse, err := model.CountUnexpiredTokens(kids, time.Now())
+	assert.NoError(t, err, "count unexpired tokens")
+	assert.Zero(t, inUse, "tokens of deregistered agent do not pin signing key")
+}
+
+func Test_DeregisterAgentRollsBackOnFailure(t *testing.T) {
+	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{
+		Logger: logger.Default.LogMode(logger.Silent),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = db.AutoMigrate(&model.Agent{}, &model.AgentToken{}, &model.AuditEvent{}, &model.AgentConfigRevision{}, &model.Collector{}, &model.EnrollmentToken{}, &model.Tenant{}, &model.AgentUsage{}, &model.DashboardSession{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = db.Callback().Delete().Before("gorm:delete").Register("test:fail_collectors", func(tx *gorm.DB) {
+		if tx.Statement.Table == "collectors" {
+			_ = tx.AddError(errors.New("collectors unavailable"))
+		}
+	})
+	assert.NoError(t, err, "register failing callback")
+
+	m := model.New(db)
+	ctrl := New(m, cfg)
+
+	rid, err := ctrl.RegisterAgent(&Agent{Hostname: "test-host", Node: "unix", LogSources: []string{"journal://"}}, testActor)
+	assert.NoError(t, err, "register agent")
+	token, _, err := ctrl.GenerateAgentToken(rid, time.Hour, testActor, TokenPurposeConfig)
+	assert.NoError(t, err, "generate token")
+	revision := m.AgentRevision()
+
+	err = ctrl.DeregisterAgent(rid, 0, testActor)
+	assert.Error(t, err, "deregister agent with failing collector cleanup")
+
+	_, err = ctrl.GetAgent(rid)
+	assert.NoError(t, err, "agent kept")
+	assert.NoError(t, ctrl.ValidateAgentToken(token), "token still valid")
+	assert.Equal(t, revision, m.AgentRevision(), "no delete event published")
+
+	tokens, err := ctrl.ListAgentTokens(rid, 0)
+	assert.NoError(t, err, "list agent tokens")
+	assert.Len(t, tokens, 1, "token kept in ledger")
+	assert.False(t, tokens[0].Revoked, "token not revoked")
 }
 
 func Test_CreateAgentConfigReturnsError_AgentNotFound(t *testing.T) {
@@ -142,7 +203,7 @@ func Test_CreateAgentConfigReturnsError_AgentNotFound(t *testing.T) {
 	ctrl := New(model, cfg)
 	assert.NotNil(t, ctrl, "create controller")
 
-	_, err := ctrl.CreateAgentConfig("non-existent-rid")
+	_, err := ctrl.CreateAgentConfig("non-existent-rid", testActor)
 	expected := "agent not found"
 	assert.EqualError(t, err, expected, "create config for non-existent agent")
 }
@@ -163,10 +224,10 @@ func Test_CreateAgentConfigReturnsConfig(t *testing.T) {
 		Profiles:       false,
 	}
 
-	rid, err := ctrl.RegisterAgent(&data)
+	rid, err := ctrl.RegisterAgent(&data, testActor)
 	assert.NoError(t, err, "register agent with valid parameters")
 
-	agentConfig, err := ctrl.CreateAgentConfig(rid)
+	agentConfig, err := ctrl.CreateAgentConfig(rid, testActor)
 	assert.NoError(t, err, "create config for existing agent")
 	assert.NotEmpty(t, agentConfig, "agent config not empty")
 }
@@ -198,7 +259,7 @@ func Test_GetAgentReturnsAgent(t *testing.T) {
 		Profiles:       false,
 	}
 
-	rid, err := ctrl.RegisterAgent(&data)
+	rid, err := ctrl.RegisterAgent(&data, testActor)
 	assert.NoError(t, err, "register agent with valid parameters")
 
 	agent, err := ctrl.GetAgent(rid)
@@ -233,7 +294,7 @@ func Test_ListAgentsReturnsAgents(t *testing.T) {
 		Profiles:       false,
 	}
 
-	_, err := ctrl.RegisterAgent(&data)
+	_, err := ctrl.RegisterAgent(&data, testActor)
 	assert.NoError(t, err, "register first agent")
 
 	data = Agent{
@@ -246,7 +307,7 @@ func Test_ListAgentsReturnsAgents(t *testing.T) {
 		Profiles:       false,
 	}
 
-	_, err = ctrl.RegisterAgent(&data)
+	_, err = ctrl.RegisterAgent(&data, testActor)
 	assert.NoError(t, err, "register second agent")
 
 	agents, err := ctrl.ListAgents()
@@ -272,7 +333,7 @@ func Test_UpdateAgentReturnsError_AgentNotFound(t *testing.T) {
 		Profiles:       false,
 	}
 
-	err := ctrl.UpdateAgent("non-existent-rid", &data)
+	err := ctrl.UpdateAgent("non-existent-rid", 0, &data, testActor)
 	expected := "agent not found"
 	assert.EqualError(t, err, expected, "update non-existent agent")
 }
@@ -293,7 +354,7 @@ func Test_UpdateAgentSucceeds(t *testing.T) {
 		Profiles:       false,
 	}
 
-	rid, err := ctrl.RegisterAgent(&data)
+	rid, err := ctrl.RegisterAgent(&data, testActor)
 	assert.NoError(t, err, "register agent with valid parameters")
 
 	updatedData := Agent{
@@ -305,7 +366,7 @@ func Test_UpdateAgentSucceeds(t *testing.T) {
 		Profiles:       true,
 	}
 
-	err = ctrl.UpdateAgent(rid, &updatedData)
+	err = ctrl.UpdateAgent(rid, 0, &updatedData, testActor)
 	assert.NoError(t, err, "update existing agent")
 
 	agent, err := ctrl.GetAgent(rid)
@@ -316,3 +377,556 @@ func Test_UpdateAgentSucceeds(t *testing.T) {
 	assert.Equal(t, []string{"http://localhost:9100/metrics"}, agent.MetricsTargets, "updated metrics targets")
 	assert.True(t, agent.Profiles, "updated profiles flag")
 }
+
+func Test_UpdateAgentSucceeds_PartialUpdate(t *testing.T) {
+	model := newModel(t)
+
+	ctrl := New(model, cfg)
+	assert.NotNil(t, ctrl, "create controller")
+
+	data := Agent{
+		Hostname:       "test-host-partial",
+		Node:           "unix",
+		Labels:         []string{"key=value"},
+		LogSources:     []string{"journal://"},
+		Metrics:        true,
+		MetricsTargets: []string{"http://localhost:9100/metrics"},
+		Profiles:       true,
+	}
+
+	rid, err := ctrl.RegisterAgent(&data, testActor)
+	assert.NoError(t, err, "register agent with valid parameters")
+
+	err = ctrl.UpdateAgent(rid, 0, &Agent{Labels: []string{"env=staging"}}, testActor, "labels")
+	assert.NoError(t, err, "update agent labels only")
+
+	agent, err := ctrl.GetAgent(rid)
+	assert.NoError(t, err, "get updated agent")
+	assert.Equal(t, []string{"env=staging"}, agent.Labels, "updated labels")
+	assert.Equal(t, []string{"journal:"}, agent.LogSources, "unchanged log sources")
+	assert.True(t, agent.Metrics, "unchanged metrics flag")
+	assert.Equal(t, []string{"http://localhost:9100/metrics"}, agent.MetricsTargets, "unchanged metrics targets")
+	assert.True(t, agent.Profiles, "unchanged profiles flag")
+
+	err = ctrl.UpdateAgent(rid, 0, &Agent{}, testActor, "hostname")
+	assert.ErrorIs(t, err, ErrInvalidUpdateMask, "update non-updatable field")
+
+	err = ctrl.UpdateAgent(rid, 0, &Agent{}, testActor, "log_sources")
+	assert.EqualError(t, err, "at least one log source must be specified", "update log sources with empty list")
+}
+
+func Test_SuspendAndResumeAgent(t *testing.T) {
+	model := newModel(t)
+
+	ctrl := New(model, cfg)
+	assert.NotNil(t, ctrl, "create controller")
+
+	data := Agent{
+		Hostname:   "test-host",
+		Node:       "unix",
+		LogSources: []string{"journal://"},
+	}
+	rid, err := ctrl.RegisterAgent(&data, testActor)
+	assert.NoError(t, err, "register agent")
+
+	token, _, err := ctrl.GenerateAgentToken(rid, 0, testActor, TokenPurposeConfig)
+	assert.NoError(t, err, "generate token")
+
+	err = ctrl.SuspendAgent(rid, 0, testActor)
+	assert.NoError(t, err, "suspend agent")
+
+	agent, err := ctrl.GetAgent(rid)
+	assert.NoError(t, err, "get agent")
+	assert.False(t, agent.Active, "agent suspended")
+
+	err = ctrl.ValidateAgentToken(token)
+	assert.ErrorIs(t, err, ErrAgentSuspended, "validate token of suspended agent")
+
+	err = ctrl.ResumeAgent(rid, 0, testActor)
+	assert.NoError(t, err, "resume agent")
+
+	agent, err = ctrl.GetAgent(rid)
+	assert.NoError(t, err, "get agent")
+	assert.True(t, agent.Active, "agent resumed")
+
+	err = ctrl.ValidateAgentToken(token)
+	assert.NoError(t, err, "validate token of resumed agent")
+
+	err = ctrl.SuspendAgent("non-existent-rid", 0, testActor)
+	assert.ErrorIs(t, err, ErrAgentNotFound, "suspend non-existent agent")
+}
+
+func Test_DeregisterEphemeralAgentsRemovesSilentAgents(t *testing.T) {
+	model := newModel(t)
+
+	ctrl := New(model, cfg)
+	assert.NotNil(t, ctrl, "create controller")
+
+	data := Agent{
+		Hostname:   "ephemeral-host",
+		Node:       "unix",
+		LogSources: []string{"journal://"},
+		Ephemeral:  true,
+	}
+	ephemeralRid, err := ctrl.RegisterAgent(&data, testActor)
+	assert.NoError(t, err, "register ephemeral agent")
+
+	data.Hostname = "persistent-host"
+	data.Ephemeral = false
+	persistentRid, err := ctrl.RegisterAgent(&data, testActor)
+	assert.NoError(t, err, "register persistent agent")
+
+	rids, err := ctrl.DeregisterEphemeralAgents(time.Hour)
+	assert.NoError(t, err, "deregister ephemeral agents within grace")
+	assert.Empty(t, rids, "no agents deregistered within grace")
+
+	rids, err = ctrl.MarkStaleAgents(-time.Minute)
+	assert.NoError(t, err, "mark stale agents")
+	assert.ElementsMatch(t, []string{ephemeralRid, persistentRid}, rids, "stale agents")
+
+	rids, err = ctrl.DeregisterEphemeralAgents(-time.Minute)
+	assert.NoError(t, err, "deregister ephemeral agents")
+	assert.Equal(t, []string{ephemeralRid}, rids, "deregistered agents")
+
+	_, err = ctrl.GetAgent(ephemeralRid)
+	assert.ErrorIs(t, err, ErrAgentNotFound, "ephemeral agent removed")
+
+	_, err = ctrl.GetAgent(persistentRid)
+	assert.NoError(t, err, "persistent agent kept")
+}
+
+func Test_ParseLabelSelector(t *testing.T) {
+	requirements, err := ParseLabelSelector("env=prod, team!=infra,canary,!legacy,tier==gold")
+	assert.NoError(t, err, "parse label selector")
+	assert.Equal(t, []model.LabelRequirement{
+		{Key: "env", Operator: model.LabelOperatorEquals, Value: "prod"},
+		{Key: "team", Operator: model.LabelOperatorNotEquals, Value: "infra"},
+		{Key: "canary", Operator: model.LabelOperatorExists},
+		{Key: "legacy", Operator: model.LabelOperatorDoesNotExist},
+		{Key: "tier", Operator: model.LabelOperatorEquals, Value: "gold"},
+	}, requirements, "label requirements")
+
+	_, err = ParseLabelSelector("env=prod,,team=web")
+	assert.ErrorIs(t, err, ErrInvalidLabelSelector, "empty term")
+
+	_, err = ParseLabelSelector("env=prod=dev")
+	assert.ErrorIs(t, err, ErrInvalidLabelSelector, "invalid value")
+}
+
+func Test_QueryAgentsPaginatesAgents(t *testing.T) {
+	model := newModel(t)
+
+	ctrl := New(model, cfg)
+	assert.NotNil(t, ctrl, "create controller")
+
+	for _, hostname := range []string{"host-1", "host-2", "host-3"} {
+		_, err := ctrl.RegisterAgent(&Agent{Hostname: hostname, Node: "unix", LogSources: []string{"journal://"}}, testActor)
+		assert.NoError(t, err, "register agent")
+	}
+
+	agents, next, err := ctrl.QueryAgents(AgentFilter{PageSize: 2})
+	assert.NoError(t, err, "query first page")
+	assert.Len(t, agents, 2, "first page size")
+	assert.NotEmpty(t, next, "next page token")
+
+	agents, next, err = ctrl.QueryAgents(AgentFilter{PageSize: 2, PageToken: next})
+	assert.NoError(t, err, "query second page")
+	assert.Len(t, agents, 1, "second page size")
+	assert.Equal(t, "host-3", agents[0].Hostname, "second page agent")
+	assert.Empty(t, next, "no further page")
+
+	_, _, err = ctrl.QueryAgents(AgentFilter{PageToken: "not-a-token"})
+	assert.ErrorIs(t, err, ErrInvalidPageToken, "invalid page token")
+}
+
+func Test_UpdateAgentReturnsError_Conflict(t *testing.T) {
+	model := newModel(t)
+
+	ctrl := New(model, cfg)
+	assert.NotNil(t, ctrl, "create controller")
+
+	rid, err := ctrl.RegisterAgent(&Agent{Hostname: "test-host", Node: "unix", LogSources: []string{"journal://"}}, testActor)
+	assert.NoError(t, err, "register agent")
+
+	agent, err := ctrl.GetAgent(rid)
+	assert.NoError(t, err, "get agent")
+	version := agent.ResourceVersion
+
+	err = ctrl.UpdateAgent(rid, version, &Agent{Labels: []string{"env=prod"}}, testActor, "labels")
+	assert.NoError(t, err, "update with current version")
+
+	err = ctrl.UpdateAgent(rid, version, &Agent{Labels: []string{"env=dev"}}, testActor, "labels")
+	assert.ErrorIs(t, err, ErrAgentConflict, "update with stale version")
+
+	err = ctrl.SuspendAgent(rid, version, testActor)
+	assert.ErrorIs(t, err, ErrAgentConflict, "suspend with stale version")
+
+	err = ctrl.DeregisterAgent(rid, version, testActor)
+	assert.ErrorIs(t, err, ErrAgentConflict, "deregister with stale version")
+
+	err = ctrl.DeregisterAgent(rid, version+1, testActor)
+	assert.NoError(t, err, "deregister with current version")
+}
+
+func Test_AgentOperationsWriteAuditEvents(t *testing.T) {
+	model := newModel(t)
+
+	ctrl := New(model, cfg)
+	assert.NotNil(t, ctrl, "create controller")
+
+	actor := Actor{Name: "cert:admin", Address: "10.0.0.1"}
+	rid, err := ctrl.RegisterAgent(&Agent{Hostname: "test-host", Node: "unix", LogSources: []string{"journal://"}}, actor)
+	assert.NoError(t, err, "register agent")
+
+	err = ctrl.UpdateAgent(rid, 0, &Agent{Labels: []string{"env=prod"}}, actor, "labels")
+	assert.NoError(t, err, "update agent")
+
+	_, _, err = ctrl.GenerateAgentToken(rid, 0, actor, TokenPurposeConfig)
+	assert.NoError(t, err, "generate token")
+
+	err = ctrl.DeregisterAgent(rid, 0, SystemActor("sweeper"))
+	assert.NoError(t, err, "deregister agent")
+
+	events, err := ctrl.ListAuditEvents(AuditFilter{ResourceId: rid})
+	assert.NoError(t, err, "list audit events")
+	assert.Len(t, events, 4, "audit events count")
+
+	actions := []string{}
+	for _, e := range events {
+		actions = append(actions, e.Action)
+	}
+	assert.Equal(t, []string{
+		AuditActionAgentDeregister,
+		AuditActionAgentTokenIssue,
+		AuditActionAgentUpdate,
+		AuditActionAgentRegister,
+	}, actions, "audit event actions")
+
+	assert.Equal(t, "system:sweeper", events[0].Actor, "deregister actor")
+	assert.Equal(t, "cert:admin", events[2].Actor, "update actor")
+	assert.Equal(t, "10.0.0.1", events[2].SourceIp, "update source ip")
+	assert.Contains(t, events[2].Diff, "labels", "update diff labels")
+	assert.Len(t, events[2].Diff, 1, "update diff count")
+
+	events, err = ctrl.ListAuditEvents(AuditFilter{Actor: "system:sweeper"})
+	assert.NoError(t, err, "list audit events by actor")
+	assert.Len(t, events, 1, "audit events by actor count")
+}
+
+func Test_UpdateAgentRecordsConfigRevisions(t *testing.T) {
+	model := newModel(t)
+
+	ctrl := New(model, cfg)
+	assert.NotNil(t, ctrl, "create controller")
+
+	rid, err := ctrl.RegisterAgent(&Agent{Hostname: "test-host", Node: "unix", LogSources: []string{"journal://"}}, testActor)
+	assert.NoError(t, err, "register agent")
+
+	err = ctrl.UpdateAgent(rid, 0, &Agent{Labels: []string{"env=prod"}}, Actor{Name: "cert:admin"}, "labels")
+	assert.NoError(t, err, "update agent")
+
+	err = ctrl.SuspendAgent(rid, 0, testActor)
+	assert.NoError(t, err, "suspend agent")
+
+	revisions, err := ctrl.ListAgentConfigRevisions(rid)
+	assert.NoError(t, err, "list config revisions")
+	assert.Len(t, revisions, 2, "suspend does not create a revision")
+	assert.Equal(t, uint64(2), revisions[0].Revision, "latest revision")
+	assert.Equal(t, "cert:admin", revisions[0].Author, "revision author")
+	assert.Equal(t, []string{"env=prod"}, revisions[0].Settings.Labels, "revision settings")
+	assert.NotEqual(t, revisions[0].ConfigHash, revisions[1].ConfigHash, "revision config hash")
+
+	diff, err := ctrl.DiffAgentConfig(rid, 1, 2)
+	assert.NoError(t, err, "diff config revisions")
+	assert.Contains(t, diff, "--- revision 1", "diff from header")
+	assert.Contains(t, diff, "+++ revision 2", "diff to header")
+	assert.Contains(t, diff, "+\t\t\"env\" = \"prod\",", "diff added label")
+
+	_, err = ctrl.DiffAgentConfig(rid, 1, 5)
+	assert.ErrorIs(t, err, ErrConfigRevisionNotFound, "diff missing revision")
+
+	_, err = ctrl.ListAgentConfigRevisions("non-existent-rid")
+	assert.ErrorIs(t, err, ErrAgentNotFound, "list revisions of non-existent agent")
+}
+
+func Test_RollbackAgentConfigRestoresSettings(t *testing.T) {
+	model := newModel(t)
+
+	ctrl := New(model, cfg)
+	assert.NotNil(t, ctrl, "create controller")
+
+	rid, err := ctrl.RegisterAgent(&Agent{Hostname: "test-host", Node: "unix", LogSources: []string{"journal://"}}, testActor)
+	assert.NoError(t, err, "register agent")
+
+	err = ctrl.UpdateAgent(rid, 0, &Agent{LogSources: []string{"docker://"}, Metrics: true}, testActor, "log_sources", "metrics")
+	assert.NoError(t, err, "update agent")
+
+	revision, err := ctrl.RollbackAgentConfig(rid, 1, 0, testActor)
+	assert.NoError(t, err, "rollback agent config")
+	assert.Equal(t, uint64(3), revision, "rollback creates a new revision")
+
+	agent, err := ctrl.GetAgent(rid)
+	assert.NoError(t, err, "get agent")
+	assert.Equal(t, []string{"journal:"}, agent.LogSources, "log sources restored")
+	assert.False(t, agent.Metrics, "metrics restored")
+
+	diff, err := ctrl.DiffAgentConfig(rid, 1, 3)
+	assert.NoError(t, err, "diff rolled back revision")
+	assert.Empty(t, diff, "rolled back config matches")
+
+	_, err = ctrl.RollbackAgentConfig(rid, 7, 0, testActor)
+	assert.ErrorIs(t, err, ErrConfigRevisionNotFound, "rollback to missing revision")
+
+	_, err = ctrl.RollbackAgentConfig(rid, 1, agent.ResourceVersion+1, testActor)
+	assert.ErrorIs(t, err, ErrAgentConflict, "rollback with stale version")
+}
+
+func Test_RecordBaselineConfigRevisionsForLegacyAgents(t *testing.T) {
+	m := newModel(t)
+
+	ctrl := New(m, cfg)
+	assert.NotNil(t, ctrl, "create controller")
+
+	legacy, err := m.CreateAgent(&model.Agent{Hostname: "legacy-host", ResourceId: "rid:legacy:1", Node: "unix", LogSources: []string{"journal://"}})
+	assert.NoError(t, err, "create agent without revisions")
+
+	revisions, err := ctrl.ListAgentConfigRevisions(legacy.ResourceId)
+	assert.NoError(t, err, "list config revisions")
+	assert.Empty(t, revisions, "listing does not record a baseline")
+
+	_, err = ctrl.DiffAgentConfig(legacy.ResourceId, 0, 0)
+	assert.ErrorIs(t, err, ErrConfigRevisionNotFound, "diff agent without revisions")
+
+	revisions, err = ctrl.ListAgentConfigRevisions(legacy.ResourceId)
+	assert.NoError(t, err, "list config revisions after diff")
+	assert.Empty(t, revisions, "diffing does not record a baseline")
+
+	err = ctrl.RecordBaselineConfigRevisions()
+	assert.NoError(t, err, "record baseline config revisions")
+	err = ctrl.RecordBaselineConfigRevisions()
+	assert.NoError(t, err, "record baseline config revisions again")
+
+	err = ctrl.UpdateAgent(legacy.ResourceId, 0, &Agent{Metrics: true}, testActor, "metrics")
+	assert.NoError(t, err, "update agent")
+
+	revisions, err = ctrl.ListAgentConfigRevisions(legacy.ResourceId)
+	assert.NoError(t, err, "list config revisions")
+	assert.Len(t, revisions, 2, "baseline and update revisions")
+	assert.Equal(t, "system:baseline", revisions[1].Author, "baseline revision author")
+	assert.False(t, revisions[1].Settings.Metrics, "baseline keeps previous settings")
+
+	diff, err := ctrl.DiffAgentConfig(legacy.ResourceId, 1, 2)
+	assert.NoError(t, err, "diff legacy agent config")
+	assert.NotEmpty(t, diff, "diff shows update")
+
+	revision, err := ctrl.RollbackAgentConfig(legacy.ResourceId, 1, 0, testActor)
+	assert.NoError(t, err, "rollback legacy agent config")
+	assert.Equal(t, uint64(3), revision, "rollback creates a new revision")
+}
+
+func Test_UpdateAgentRollsBackOnRevisionFailure(t *testing.T) {
+	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{
+		Logger: logger.Default.LogMode(logger.Silent),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = db.AutoMigrate(&model.Agent{}, &model.AgentToken{}, &model.AuditEvent{}, &model.AgentConfigRevision{}, &model.Collector{}, &model.EnrollmentToken{}, &model.Tenant{}, &model.AgentUsage{}, &model.DashboardSession{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	failRevisions := false
+	err = db.Callback().Create().Before("gorm:create").Register("test:fail_revisions", func(tx *gorm.DB) {
+		if failRevisions && tx.Statement.Table == "agent_config_revisions" {
+			_ = tx.AddError(errors.New("revisions unavailable"))
+		}
+	})
+	assert.NoError(t, err, "register failing callback")
+
+	m := model.New(db)
+	ctrl := New(m, cfg)
+
+	rid, err := ctrl.RegisterAgent(&Agent{Hostname: "test-host", Node: "unix", LogSources: []string{"journal://"}}, testActor)
+	assert.NoError(t, err, "register agent")
+
+	failRevisions = true
+	err = ctrl.UpdateAgent(rid, 0, &Agent{Metrics: true}, testActor, "metrics")
+	assert.Error(t, err, "update agent with failing revision")
+
+	_, err = ctrl.RollbackAgentConfig(rid, 1, 0, testActor)
+	assert.Error(t, err, "rollback agent with failing revision")
+
+	agent, err := ctrl.GetAgent(rid)
+	assert.NoError(t, err, "get agent")
+	assert.False(t, agent.Metrics, "update rolled back")
+	assert.Equal(t, uint64(1), agent.ResourceVersion, "resource version unchanged")
+
+	_, err = ctrl.RegisterAgent(&Agent{Hostname: "other-host", Node: "unix", LogSources: []string{"journal://"}}, testActor)
+	assert.Error(t, err, "register agent with failing revision")
+	agents, err := ctrl.ListAgents()
+	assert.NoError(t, err, "list agents")
+	assert.Len(t, agents, 1, "registration rolled back")
+}
+
+func Test_DeregisterAgentKeepsConfigRevisions(t *testing.T) {
+	m := newModel(t)
+	ctrl := New(m, cfg)
+
+	rid, err := ctrl.RegisterAgent(&Agent{Hostname: "test-host", Node: "unix", LogSources: []string{"journal://"}}, testActor)
+	assert.NoError(t, err, "register agent")
+	err = ctrl.UpdateAgent(rid, 0, &Agent{Metrics: true}, testActor, "metrics")
+	assert.NoError(t, err, "update agent")
+
+	err = ctrl.DeregisterAgent(rid, 0, testActor)
+	assert.NoError(t, err, "deregister agent")
+
+	revisions := []model.AgentConfigRevision{}
+	_, err = m.ListAgentConfigRevisions(&revisions, rid)
+	assert.NoError(t, err, "list config revisions")
+	assert.Len(t, revisions, 2, "config revisions kept as history")
+}
+
+func Test_EnrollAgentRegistersAgent(t *testing.T) {
+	model := newModel(t)
+	ctrl := New(model, cfg)
+
+	token, issued, err := ctrl.CreateEnrollmentToken(EnrollmentTokenSpec{
+		UsageLimit:      2,
+		HostnamePattern: "web-*",
+		Labels:          []string{"env=prod"},
+		LogSources:      []string{"journal://"},
+	}, testActor)
+	assert.NoError(t, err, "create enrollment token")
+	assert.Equal(t, 2, issued.UsageLimit, "usage limit")
+
+	enrollment, err := ctrl.EnrollAgent(token, &Agent{Hostname: "web-1", Node: "unix", Labels: []string{"role=web"}}, "192.0.2.1")
+	assert.NoError(t, err, "enroll agent")
+	assert.NotEmpty(t, enrollment.ResourceId, "resource id")
+	assert.Contains(t, string(enrollment.Config), enrollment.ResourceId, "rendered config")
+
+	agent, err := ctrl.GetAgent(enrollment.ResourceId)
+	assert.NoError(t, err, "get enrolled agent")
+	assert.Equal(t, []string{"env=prod", "role=web"}, agent.Labels, "merged labels")
+	assert.Equal(t, []string{"journal:"}, agent.LogSources, "default log sources")
+
+	_, err = ctrl.EnrollAgent(token, &Agent{Hostname: "db-1", Node: "unix"}, "192.0.2.2")
+	assert.ErrorIs(t, err, ErrHostnameNotAllowed, "hostname mismatch")
+
+	_, err = ctrl.EnrollAgent(token, &Agent{Hostname: "web-1", Node: "unix"}, "192.0.2.2")
+	assert.ErrorIs(t, err, ErrAgentAlreadyExists, "duplicate hostname")
+
+	_, err = ctrl.EnrollAgent(token, &Agent{Hostname: "web-2", Node: "unix"}, "192.0.2.2")
+	assert.NoError(t, err, "enroll second agent")
+
+	_, err = ctrl.EnrollAgent(token, &Agent{Hostname: "web-3", Node: "unix"}, "192.0.2.3")
+	assert.ErrorIs(t, err, ErrEnrollmentTokenExhausted, "usage limit reached")
+
+	_, err = ctrl.EnrollAgent("invalid", &Agent{Hostname: "web-4", Node: "unix"}, "192.0.2.4")
+	assert.ErrorIs(t, err, ErrInvalidEnrollmentToken, "invalid token")
+}
+
+func Test_EnrollAgentRollsBackOnConfigFailure(t *testing.T) {
+	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{
+		Logger: logger.Default.LogMode(logger.Silent),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = db.AutoMigrate(&model.Agent{}, &model.AgentToken{}, &model.AuditEvent{}, &model.AgentConfigRevision{}, &model.Collector{}, &model.EnrollmentToken{}, &model.Tenant{}, &model.AgentUsage{}, &model.DashboardSession{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = db.Callback().Create().Before("gorm:create").Register("test:fail_agent_tokens", func(tx *gorm.DB) {
+		if tx.Statement.Table == "agent_tokens" {
+			_ = tx.AddError(errors.New("agent tokens unavailable"))
+		}
+	})
+	assert.NoError(t, err, "register failing callback")
+
+	m := model.New(db)
+	ctrl := New(m, cfg)
+
+	token, _, err := ctrl.CreateEnrollmentToken(EnrollmentTokenSpec{UsageLimit: 1, LogSources: []string{"journal://"}}, testActor)
+	assert.NoError(t, err, "create enrollment token")
+
+	_, err = ctrl.EnrollAgent(token, &Agent{Hostname: "web-1", Node: "unix"}, "192.0.2.1")
+	assert.Error(t, err, "enroll agent with failing config")
+
+	agents, err := ctrl.ListAgents()
+	assert.NoError(t, err, "list agents")
+	assert.Empty(t, agents, "enrolled agent deregistered")
+
+	tokens, err := ctrl.ListEnrollmentTokens()
+	assert.NoError(t, err, "list enrollment tokens")
+	assert.Len(t, tokens, 1, "enrollment tokens count")
+	assert.Equal(t, 0, tokens[0].Uses, "enrollment token released")
+}
+
+func Test_CreateEnrollmentTokenReturnsError_InvalidSpec(t *testing.T) {
+	model := newModel(t)
+	ctrl := New(model, cfg)
+
+	_, _, err := ctrl.CreateEnrollmentToken(EnrollmentTokenSpec{UsageLimit: -1}, testActor)
+	assert.ErrorIs(t, err, ErrInvalidUsageLimit, "negative usage limit")
+
+	_, _, err = ctrl.CreateEnrollmentToken(EnrollmentTokenSpec{HostnamePattern: "web-["}, testActor)
+	assert.ErrorIs(t, err, ErrInvalidHostnamePattern, "malformed hostname pattern")
+
+	_, _, err = ctrl.CreateEnrollmentToken(EnrollmentTokenSpec{LogSources: []string{"invalid://source"}}, testActor)
+	assert.ErrorIs(t, err, ErrInvalidEnrollment, "invalid log source")
+}
+
+func Test_TenantsAssignAgentsAndRenderConfig(t *testing.T) {
+	model := newModel(t)
+	ctrl := New(model, cfg)
+
+	_, err := ctrl.CreateTenant("Team A", "", testActor)
+	assert.ErrorIs(t, err, ErrInvalidTenantName, "create tenant with invalid name")
+
+	_, err = ctrl.CreateTenant("team-a", "Team A", testActor)
+	assert.NoError(t, err, "create tenant")
+
+	_, err = ctrl.CreateTenant("team-a", "", testActor)
+	assert.ErrorIs(t, err, ErrTenantAlreadyExists, "create duplicate tenant")
+
+	tenants, err := ctrl.ListTenants()
+	assert.NoError(t, err, "list tenants")
+	assert.Len(t, tenants, 2, "tenants count")
+	assert.Equal(t, DefaultTenant, tenants[0].Name, "default tenant listed")
+
+	data := Agent{Hostname: "test-host", Node: "unix", LogSources: []string{"journal://"}, Metrics: true, Tenant: "team-b"}
+	_, err = ctrl.RegisterAgent(&data, testActor)
+	assert.ErrorIs(t, err, ErrTenantNotFound, "register agent in unknown tenant")
+
+	data.Tenant = "team-a"
+	rid, err := ctrl.RegisterAgent(&data, testActor)
+	assert.NoError(t, err, "register agent in tenant")
+
+	agent, err := ctrl.GetAgent(rid)
+	assert.NoError(t, err, "get agent")
+	assert.Equal(t, "team-a", agent.Tenant, "agent tenant")
+
+	config, err := ctrl.CreateAgentConfig(rid, testActor)
+	assert.NoError(t, err, "create agent config")
+	assert.Contains(t, string(config), `tenant_id = "team-a"`, "loki tenant")
+	assert.Contains(t, string(config), `"X-Scope-OrgID" = "team-a"`, "mimir tenant")
+
+	claims := &DashboardClaims{Role: RoleViewer, Scope: []string{"tenant:team-a"}}
+	assert.True(t, ctrl.CanAccessAgent(claims, rid, agent.Hostname, agent.Tenant), "access agent in scoped tenant")
+	assert.False(t, ctrl.CanAccessAgent(claims, "rid-other", "other-host", DefaultTenant), "access agent outside scoped tenant")
+
+	err = ctrl.DeleteTenant("team-a", testActor)
+	assert.ErrorIs(t, err, ErrTenantInUse, "delete tenant with agents")
+
+	err = ctrl.DeleteTenant(DefaultTenant, testActor)
+	assert.ErrorIs(t, err, ErrDefaultTenant, "delete default tenant")
+
+	err = ctrl.DeregisterAgent(rid, 0, testActor)
+	assert.NoError(t, err, "deregister agent")
+
+	err = ctrl.DeleteTenant("team-a", testActor)
+	assert.NoError(t, err, "delete tenant")
+
+	err = ctrl.DeleteTenant("team-a", testActor)
+	assert.ErrorIs(t, err, ErrTenantNotFound, "delete unknown tenant")
+}
@@ -0,0 +1,295 @@
+/*
+Copyright (c) Tobias Schäfer. All rights reserved.
+Licensed under the MIT License, see LICENSE file in the project root for details.
+*/
+package controller
+
+import (
+	"cmp"
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"slices"
+	"time"
+
+	"github.com/tschaefer/finch/internal/model"
+)
+
+const (
+	UsageBucketSize           = time.Hour
+	DefaultUsageFlushInterval = 30 * time.Second
+	DefaultUsageRetention     = 30 * 24 * time.Hour
+	DefaultUsageWindow        = 24 * time.Hour
+	DefaultTopTalkersLimit    = 20
+	usageRetentionPruneEvery  = time.Hour
+)
+
+var (
+	ErrInvalidUsageWindow = errors.New("invalid usage window")
+)
+
+type usageKey struct {
+	ResourceId string
+	Backend    string
+	Bucket     time.Time
+}
+
+type usageCounter struct {
+	Requests int64
+	Bytes    int64
+}
+
+type AgentUsageTotal struct {
+	ResourceId string
+	Hostname   string
+	Tenant     string
+	Requests   int64
+	Bytes      int64
+	Backends   map[string]model.AgentUsage
+}
+
+func (c *Controller) recordAgentUsage(rid, backend string, contentLength int64) {
+	key := usageKey{
+		ResourceId: rid,
+		Backend:    backend,
+		Bucket:     time.Now().UTC().Truncate(UsageBucketSize),
+	}
+
+	c.usageMu.Lock()
+	defer c.usageMu.Unlock()
+
+	counter, ok := c.usage[key]
+	if !ok {
+		counter = &usageCounter{}
+		c.usage[key] = counter
+	}
+	counter.Requests++
+	if contentLength > 0 {
+		counter.Bytes += contentLength
+	}
+}
+
+func (c *Controller) FlushAgentUsage() error {
+	c.usageMu.Lock()
+	pending := c.usage
+	c.usage = make(map[usageKey]*usageCounter)
+	c.usageMu.Unlock()
+
+	if len(pending) == 0 {
+		return nil
+	}
+
+	slog.Debug("Flushing agents usage", "count", len(pending))
+
+	usages := make([]model.AgentUsage, 0, len(pending))
+	for key, counter := range pending {
+		usages = append(usages, model.AgentUsage{
+			ResourceId: key.ResourceId,
+			Backend:    key.Backend,
+			Bucket:     key.Bucket,
+			Requests:   counter.Requests,
+			Bytes:      counter.Bytes,
+		})
+	}
+
+	if err := c.model.AddAgentUsage(usages); err != nil {
+		c.usageMu.Lock()
+		for key, counter := range pending {
+			current, ok := c.usage[key]
+			if !ok {
+				c.usage[key] = counter
+				continue
+			}
+			current.Requests += counter.Requests
+			current.Bytes += counter.Bytes
+		}
+		c.usageMu.Unlock()
+		return err
+	}
+
+	return nil
+}
+
+func (c *Controller) RunUsageFlusher(ctx context.Context, interval, retention time.Duration) {
+	if interval <= 0 {
+		interval = DefaultUsageFlushInterval
+	}
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	var pruned time.Time
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case now := <-ticker.C:
+			if err := c.FlushAgentUsage(); err != nil {
+				slog.Error("Failed to flush agents usage", "error", err)
+			}
+			if retention > 0 && now.Sub(pruned) >= usageRetentionPruneEvery {
+				if _, err := c.model.DeleteAgentUsageBefore(now.Add(-retention)); err != nil {
+					slog.Error("Failed to prune agents usage", "error", err)
+				}
+				pruned = now
+			}
+		}
+	}
+}
+
+func (c *Controller) GetAgentUsage(rid string, since, until time.Time) ([]model.AgentUsage, error) {
+	slog.Debug("Get Agent Usage", "rid", rid, "since", since, "until", until)
+
+	if _, err := c.GetAgent(rid); err != nil {
+		return nil, err
+	}
+
+	query, err := usageQuery(since, until)
+	if err != nil {
+		return nil, err
+	}
+	query.ResourceId = rid
+
+	var usages []model.AgentUsage
+	if _, err := c.model.ListAgentUsage(&usages, query); err != nil {
+		return nil, err
+	}
+
+	usages = c.withPendingUsage(usages, query)
+	slices.SortFunc(usages, func(a, b model.AgentUsage) int {
+		if n := a.Bucket.Compare(b.Bucket); n != 0 {
+			return n
+		}
+		return cmp.Compare(a.Backend, b.Backend)
+	})
+
+	return usages, nil
+}
+
+func (c *Controller) TopAgentUsage(since, until time.Time, limit int, claims *DashboardClaims) ([]AgentUsageTotal, error) {
+	slog.Debug("Top Agent Usage", "since", since, "until", until, "limit", limit)
+
+	query, err := usageQuery(since, until)
+	if err != nil {
+		return nil, err
+	}
+	if limit <= 0 {
+		limit = DefaultTopTalkersLimit
+	}
+
+	var sums []model.AgentUsage
+	if _, err := c.model.SumAgentUsage(&sums, query); err != nil {
+		return nil, err
+	}
+	sums = c.withPendingUsage(sums, query)
+
+	var agents []model.Agent
+	if _, err := c.model.ListAgents(&agents); err != nil {
+		return nil, err
+	}
+	byRid := make(map[string]*model.Agent, len(agents))
+	for i := range agents {
+		byRid[agents[i].ResourceId] = &agents[i]
+	}
+
+	totals := make(map[string]*AgentUsageTotal)
+	for _, sum := range sums {
+		agent, ok := byRid[sum.ResourceId]
+		if !ok {
+			continue
+		}
+		if claims != nil && !c.CanAccessAgent(claims, agent.ResourceId, agent.Hostname, agent.Tenant) {
+			continue
+		}
+
+		total, ok := totals[sum.ResourceId]
+		if !ok {
+			total = &AgentUsageTotal{
+				ResourceId: agent.ResourceId,
+				Hostname:   agent.Hostname,
+				Tenant:     agent.Tenant,
+				Backends:   make(map[string]model.AgentUsage),
+			}
+			totals[sum.ResourceId] = total
+		}
+
+		backend := total.Backends[sum.Backend]
+		backend.Backend = sum.Backend
+		backend.Requests += sum.Requests
+		backend.Bytes += sum.Bytes
+		total.Backends[sum.Backend] = backend
+		total.Requests += sum.Requests
+		total.Bytes += sum.Bytes
+	}
+
+	result := make([]AgentUsageTotal, 0, len(totals))
+	for _, total := range totals {
+		result = append(result, *total)
+	}
+	slices.SortFunc(result, func(a, b AgentUsageTotal) int {
+		if n := cmp.Compare(b.Bytes, a.Bytes); n != 0 {
+			return n
+		}
+		if n := cmp.Compare(b.Requests, a.Requests); n != 0 {
+			return n
+		}
+		return cmp.Compare(a.Hostname, b.Hostname)
+	})
+	if len(result) > limit {
+		result = result[:limit]
+	}
+
+	return result, nil
+}
+
+func (c *Controller) withPendingUsage(usages []model.AgentUsage, query *model.AgentUsageQuery) []model.AgentUsage {
+	c.usageMu.Lock()
+	defer c.usageMu.Unlock()
+
+	for key, counter := range c.usage {
+		if query.ResourceId != "" && key.ResourceId != query.ResourceId {
+			continue
+		}
+		if query.Since != nil && key.Bucket.Before(*query.Since) {
+			continue
+		}
+		if query.Until != nil && !key.Bucket.Before(*query.Until) {
+			continue
+		}
+
+		idx := slices.IndexFunc(usages, func(usage model.AgentUsage) bool {
+			return usage.ResourceId == key.ResourceId && usage.Backend == key.Backend &&
+				(usage.Bucket.IsZero() || usage.Bucket.Equal(key.Bucket))
+		})
+		if idx < 0 {
+			usages = append(usages, model.AgentUsage{
+				ResourceId: key.ResourceId,
+				Backend:    key.Backend,
+				Bucket:     key.Bucket,
+			})
+			idx = len(usages) - 1
+		}
+		usages[idx].Requests += counter.Requests
+		usages[idx].Bytes += counter.Bytes
+	}
+
+	return usages
+}
+
+func usageQuery(since, until time.Time) (*model.AgentUsageQuery, error) {
+	if since.IsZero() {
+		since = time.Now().Add(-DefaultUsageWindow)
+	}
+	since = since.UTC().Truncate(UsageBucketSize)
+
+	query := &model.AgentUsageQuery{Since: &since}
+	if !until.IsZero() {
+		if !until.After(since) {
+			return nil, fmt.Errorf("%w: until must be after since", ErrInvalidUsageWindow)
+		}
+		query.Until = &until
+	}
+
+	return query, nil
+}
@@ -0,0 +1,76 @@
+/*
+Copyright (c) Tobias Schäfer. All rights reserved.
+Licensed under the MIT License, see LICENSE file in the project root for details.
+*/
+package controller
+
+import (
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/tschaefer/finch/internal/model"
+)
+
+func Test_AgentUsageAccountsAuthorizedRequests(t *testing.T) {
+	m := newModel(t)
+	ctrl := New(m, cfg)
+
+	quiet := &model.Agent{Hostname: "quiet-host", ResourceId: "rid:test:quiet"}
+	_, err := m.CreateAgent(quiet)
+	assert.NoError(t, err, "create agent")
+
+	noisy := &model.Agent{Hostname: "noisy-host", ResourceId: "rid:test:noisy", Metrics: true, Tenant: "team-a"}
+	_, err = m.CreateAgent(noisy)
+	assert.NoError(t, err, "create agent")
+
+	push := func(agent *model.Agent, uri string, length int64) {
+		token, _, err := ctrl.GenerateAgentToken(agent.ResourceId, time.Hour, testActor, TokenPurposeConfig)
+		assert.NoError(t, err, "generate token")
+
+		_, err = ctrl.AuthorizeAgentRequest(token, ForwardedRequest{Uri: uri, Method: http.MethodPost, ContentLength: length})
+		assert.NoError(t, err, "authorize request")
+	}
+
+	push(quiet, "/loki/loki/api/v1/push", 100)
+	push(noisy, "/loki/loki/api/v1/push", 1000)
+	push(noisy, "/loki/loki/api/v1/push", -1)
+	push(noisy, "/mimir/api/v1/push", 4000)
+
+	usages, err := ctrl.GetAgentUsage(noisy.ResourceId, time.Time{}, time.Time{})
+	assert.NoError(t, err, "get pending usage")
+	assert.Len(t, usages, 2, "pending usage buckets")
+
+	err = ctrl.FlushAgentUsage()
+	assert.NoError(t, err, "flush usage")
+
+	usages, err = ctrl.GetAgentUsage(noisy.ResourceId, time.Time{}, time.Time{})
+	assert.NoError(t, err, "get stored usage")
+	assert.Len(t, usages, 2, "stored usage buckets")
+	assert.Equal(t, BackendLoki, usages[0].Backend, "loki bucket")
+	assert.Equal(t, int64(2), usages[0].Requests, "loki requests")
+	assert.Equal(t, int64(1000), usages[0].Bytes, "loki bytes")
+	assert.Equal(t, int64(4000), usages[1].Bytes, "mimir bytes")
+
+	_, err = ctrl.GetAgentUsage("rid:test:unknown", time.Time{}, time.Time{})
+	assert.ErrorIs(t, err, ErrAgentNotFound, "unknown agent")
+
+	_, err = ctrl.GetAgentUsage(noisy.ResourceId, time.Now(), time.Now().Add(-time.Hour))
+	assert.ErrorIs(t, err, ErrInvalidUsageWindow, "invalid window")
+
+	push(quiet, "/loki/loki/api/v1/push", 100)
+
+	top, err := ctrl.TopAgentUsage(time.Time{}, time.Time{}, 0, nil)
+	assert.NoError(t, err, "top agent usage")
+	assert.Len(t, top, 2, "top talkers")
+	assert.Equal(t, "noisy-host", top[0].Hostname, "noisiest agent first")
+	assert.Equal(t, int64(5000), top[0].Bytes, "noisy total bytes")
+	assert.Equal(t, int64(4000), top[0].Backends[BackendMimir].Bytes, "noisy mimir bytes")
+	assert.Equal(t, int64(2), top[1].Requests, "quiet requests include pending usage")
+
+	top, err = ctrl.TopAgentUsage(time.Time{}, time.Time{}, 0, &DashboardClaims{Role: RoleViewer, Scope: []string{"tenant:default"}})
+	assert.NoError(t, err, "scoped top agent usage")
+	assert.Len(t, top, 1, "scoped top talkers")
+	assert.Equal(t, "quiet-host", top[0].Hostname, "scoped agent")
+}
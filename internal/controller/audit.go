@@ -0,0 +1,83 @@
+/*
+Copyright (c) Tobias Schäfer. All rights reserved.
+Licensed under the MIT License, see LICENSE file in the project root for details.
+*/
+package controller
+
+import (
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/tschaefer/finch/internal/model"
+)
+
+const (
+	AuditActionAgentRegister        = "agent.register"
+	AuditActionAgentUpdate          = "agent.update"
+	AuditActionAgentDeregister      = "agent.deregister"
+	AuditActionAgentSuspend         = "agent.suspend"
+	AuditActionAgentResume          = "agent.resume"
+	AuditActionAgentConfigRollback  = "agent.config.rollback"
+	AuditActionAgentTokensRevoke    = "agent.tokens.revoke"
+	AuditActionAgentTokenIssue      = "agent.token.issue"
+	AuditActionDashboardTokenIssue  = "dashboard.token.issue"
+	AuditActionEnrollmentTokenIssue = "enrollment.token.issue"
+	AuditActionSigningKeyRotate     = "signing_key.rotate"
+	AuditActionSigningKeyRetire     = "signing_key.retire"
+	AuditActionTenantCreate         = "tenant.create"
+	AuditActionTenantDelete         = "tenant.delete"
+)
+
+const (
+	defaultAuditEventsLimit = 100
+	maxAuditEventsLimit     = 1000
+)
+
+type AuditFilter struct {
+	Since      *time.Time
+	Until      *time.Time
+	Actor      string
+	Action     string
+	ResourceId string
+	Limit      int
+}
+
+func (c *Controller) ListAuditEvents(filter AuditFilter) ([]model.AuditEvent, error) {
+	slog.Debug("List Audit Events", "filter", fmt.Sprintf("%+v", filter))
+
+	limit := filter.Limit
+	if limit <= 0 {
+		limit = defaultAuditEventsLimit
+	}
+	limit = min(limit, maxAuditEventsLimit)
+
+	events := []model.AuditEvent{}
+	_, err := c.model.ListAuditEvents(&events, &model.AuditQuery{
+		Since:      filter.Since,
+		Until:      filter.Until,
+		Actor:      filter.Actor,
+		Action:     filter.Action,
+		ResourceId: filter.ResourceId,
+		Limit:      limit,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return events, nil
+}
+
+func (c *Controller) audit(actor Actor, action, rid string, diff map[string]model.AuditChange) {
+	event := &model.AuditEvent{
+		Actor:      actor.Name,
+		Action:     action,
+		ResourceId: rid,
+		Diff:       diff,
+		SourceIp:   actor.Address,
+	}
+
+	if _, err := c.model.CreateAuditEvent(event); err != nil {
+		slog.Error("Failed to write audit event", "actor", actor.Name, "action", action, "rid", rid, "error", err)
+	}
+}
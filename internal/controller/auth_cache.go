@@ -0,0 +1,236 @@
+/*
+Copyright (c) Tobias Schäfer. All rights reserved.
+Licensed under the MIT License, see LICENSE file in the project root for details.
+*/
+package controller
+
+import (
+	"context"
+	"crypto/sha256"
+	"log/slog"
+	"slices"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/tschaefer/finch/internal/model"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const authCacheMaxEntries = 10000
+
+var authCacheIgnoredFields = []string{"last_seen", "stale"}
+
+type authCacheEntry struct {
+	agent   model.Agent
+	claims  AgentClaims
+	expires time.Time
+}
+
+type authCache struct {
+	ttl     time.Duration
+	mu      sync.RWMutex
+	entries map[[sha256.Size]byte]*authCacheEntry
+	byAgent map[string]map[[sha256.Size]byte]struct{}
+	gen     atomic.Uint64
+	hits    atomic.Uint64
+	misses  atomic.Uint64
+}
+
+type AuthCacheStats struct {
+	Entries int
+	Hits    uint64
+	Misses  uint64
+}
+
+func newAuthCache(ttl time.Duration) *authCache {
+	return &authCache{
+		ttl:     ttl,
+		entries: make(map[[sha256.Size]byte]*authCacheEntry),
+		byAgent: make(map[string]map[[sha256.Size]byte]struct{}),
+	}
+}
+
+func (a *authCache) get(tokenString string, now time.Time) (*authCacheEntry, bool) {
+	key := sha256.Sum256([]byte(tokenString))
+
+	a.mu.RLock()
+	entry, ok := a.entries[key]
+	a.mu.RUnlock()
+
+	if !ok || !now.Before(entry.expires) {
+		a.misses.Add(1)
+		return nil, false
+	}
+
+	a.hits.Add(1)
+	return entry, true
+}
+
+func (a *authCache) generation() uint64 {
+	return a.gen.Load()
+}
+
+func (a *authCache) put(tokenString string, agent *model.Agent, claims *AgentClaims, now time.Time, gen uint64) {
+	expires := now.Add(a.ttl)
+	if !claims.ExpiresAt.IsZero() && claims.ExpiresAt.Before(expires) {
+		expires = claims.ExpiresAt
+	}
+	if !now.Before(expires) {
+		return
+	}
+
+	key := sha256.Sum256([]byte(tokenString))
+	entry := &authCacheEntry{agent: *agent, claims: *claims, expires: expires}
+
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	if a.gen.Load() != gen {
+		return
+	}
+
+	if len(a.entries) >= authCacheMaxEntries {
+		a.evictExpired(now)
+		if len(a.entries) >= authCacheMaxEntries {
+			return
+		}
+	}
+
+	a.entries[key] = entry
+	keys, ok := a.byAgent[agent.ResourceId]
+	if !ok {
+		keys = make(map[[sha256.Size]byte]struct{})
+		a.byAgent[agent.ResourceId] = keys
+	}
+	keys[key] = struct{}{}
+}
+
+func (a *authCache) invalidate(rid string) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	a.gen.Add(1)
+	for key := range a.byAgent[rid] {
+		delete(a.entries, key)
+	}
+	delete(a.byAgent, rid)
+}
+
+func (a *authCache) purge() {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	a.gen.Add(1)
+	a.entries = make(map[[sha256.Size]byte]*authCacheEntry)
+	a.byAgent = make(map[string]map[[sha256.Size]byte]struct{})
+}
+
+func (a *authCache) evictExpired(now time.Time) {
+	for key, entry := range a.entries {
+		if now.Before(entry.expires) {
+			continue
+		}
+		delete(a.entries, key)
+		rid := entry.agent.ResourceId
+		delete(a.byAgent[rid], key)
+		if len(a.byAgent[rid]) == 0 {
+			delete(a.byAgent, rid)
+		}
+	}
+}
+
+func (a *authCache) stats() AuthCacheStats {
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+
+	return AuthCacheStats{
+		Entries: len(a.entries),
+		Hits:    a.hits.Load(),
+		Misses:  a.misses.Load(),
+	}
+}
+
+func (c *Controller) StartAuthCache(ctx context.Context, ttl time.Duration) {
+	if ttl <= 0 {
+		slog.Info("Auth cache disabled")
+		return
+	}
+
+	events := c.model.SubscribeAgentEvents(ctx)
+	cache := newAuthCache(ttl)
+	c.authCache.Store(cache)
+
+	go func() {
+		defer c.authCache.CompareAndSwap(cache, nil)
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case event, ok := <-events:
+				if !ok {
+					return
+				}
+				invalidateAuthCache(cache, event)
+			}
+		}
+	}()
+}
+
+func (c *Controller) AuthCacheStats() AuthCacheStats {
+	cache := c.authCache.Load()
+	if cache == nil {
+		return AuthCacheStats{}
+	}
+
+	return cache.stats()
+}
+
+func (c *Controller) purgeAuthCache() {
+	if cache := c.authCache.Load(); cache != nil {
+		cache.purge()
+	}
+}
+
+func (c *Controller) cachedAuthenticateAgentToken(tokenString string) (*model.Agent, *AgentClaims, error) {
+	cache := c.authCache.Load()
+	if cache == nil {
+		return c.authenticateAgentToken(tokenString)
+	}
+
+	now := time.Now()
+	entry, ok := cache.get(tokenString, now)
+	trace.SpanFromContext(c.ctx).SetAttributes(attribute.Bool("finch.auth.cache_hit", ok))
+	if ok {
+		agent, claims := entry.agent, entry.claims
+		return &agent, &claims, nil
+	}
+
+	gen := cache.generation()
+	agent, claims, err := c.authenticateAgentToken(tokenString)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	cache.put(tokenString, agent, claims, now, gen)
+	return agent, claims, nil
+}
+
+func invalidateAuthCache(cache *authCache, event model.AgentEvent) {
+	switch event.Type {
+	case model.AgentEventLagged:
+		slog.Debug("Purging auth cache", "missed", event.Missed)
+		cache.purge()
+	case model.AgentEventDelete:
+		cache.invalidate(event.ResourceId)
+	case model.AgentEventUpdate, model.AgentEventStale:
+		for _, field := range event.Fields {
+			if !slices.Contains(authCacheIgnoredFields, field) {
+				cache.invalidate(event.ResourceId)
+				return
+			}
+		}
+	}
+}
@@ -0,0 +1,139 @@
+/*
+Copyright (c) Tobias Schäfer. All rights reserved.
+Licensed under the MIT License, see LICENSE file in the project root for details.
+*/
+package controller
+
+import (
+	"context"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/tschaefer/finch/internal/model"
+)
+
+var lokiPush = ForwardedRequest{Uri: "/loki/loki/api/v1/push", Method: http.MethodPost}
+
+func newCachedController(t *testing.T) (*Controller, *model.Model, *model.Agent) {
+	m := newModel(t)
+	ctrl := New(m, cfg)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	t.Cleanup(cancel)
+	ctrl.StartAuthCache(ctx, time.Minute)
+
+	agent := &model.Agent{
+		Hostname:   "test-host",
+		ResourceId: "rid:test:123",
+	}
+	_, err := m.CreateAgent(agent)
+	assert.NoError(t, err, "create agent")
+
+	return ctrl, m, agent
+}
+
+func Test_AuthCacheServesRepeatedRequests(t *testing.T) {
+	ctrl, _, agent := newCachedController(t)
+
+	token, _, err := ctrl.GenerateAgentToken(agent.ResourceId, time.Hour, testActor, TokenPurposeConfig)
+	assert.NoError(t, err, "generate token")
+
+	for range 3 {
+		claims, err := ctrl.AuthorizeAgentRequest(token, lokiPush)
+		assert.NoError(t, err, "authorize request")
+		assert.Equal(t, agent.ResourceId, claims.ResourceId, "claims resource id")
+	}
+
+	stats := ctrl.AuthCacheStats()
+	assert.Equal(t, 1, stats.Entries, "cache entries")
+	assert.Equal(t, uint64(2), stats.Hits, "cache hits")
+	assert.Equal(t, uint64(1), stats.Misses, "cache misses")
+
+	_, err = ctrl.AuthorizeAgentRequest(token, ForwardedRequest{Uri: "/mimir/api/v1/push", Method: http.MethodPost})
+	assert.ErrorIs(t, err, ErrRequestNotAllowed, "capabilities checked on cached agent")
+}
+
+func Test_AuthCacheIgnoresLastSeenUpdates(t *testing.T) {
+	ctrl, m, agent := newCachedController(t)
+
+	marker := &model.Agent{
+		Hostname:   "marker-host",
+		ResourceId: "rid:test:456",
+	}
+	_, err := m.CreateAgent(marker)
+	assert.NoError(t, err, "create marker agent")
+
+	token, _, err := ctrl.GenerateAgentToken(agent.ResourceId, time.Hour, testActor, TokenPurposeConfig)
+	assert.NoError(t, err, "generate token")
+	markerToken, _, err := ctrl.GenerateAgentToken(marker.ResourceId, time.Hour, testActor, TokenPurposeConfig)
+	assert.NoError(t, err, "generate marker token")
+
+	_, err = ctrl.AuthorizeAgentRequest(token, lokiPush)
+	assert.NoError(t, err, "authorize request")
+	_, err = ctrl.AuthorizeAgentRequest(markerToken, lokiPush)
+	assert.NoError(t, err, "authorize marker request")
+
+	err = ctrl.FlushLastSeen()
+	assert.NoError(t, err, "flush last seen")
+
+	// Events are delivered in order, so once the marker suspension has been
+	// applied the last seen event has been handled as well.
+	err = ctrl.SuspendAgent(marker.ResourceId, 0, testActor)
+	assert.NoError(t, err, "suspend marker agent")
+	assert.Eventually(t, func() bool {
+		_, err := ctrl.AuthorizeAgentRequest(markerToken, lokiPush)
+		return err != nil
+	}, time.Second, 10*time.Millisecond, "marker event delivered")
+
+	hits := ctrl.AuthCacheStats().Hits
+	_, err = ctrl.AuthorizeAgentRequest(token, lokiPush)
+	assert.NoError(t, err, "authorize request after last seen update")
+	assert.Equal(t, hits+1, ctrl.AuthCacheStats().Hits, "entry survives last seen update")
+}
+
+func Test_AuthCacheInvalidatedOnAgentChanges(t *testing.T) {
+	tests := []struct {
+		name   string
+		change func(ctrl *Controller, agent *model.Agent) error
+	}{
+		{"suspend", func(ctrl *Controller, agent *model.Agent) error {
+			return ctrl.SuspendAgent(agent.ResourceId, 0, testActor)
+		}},
+		{"revoke", func(ctrl *Controller, agent *model.Agent) error {
+			_, err := ctrl.RevokeAgentTokens(agent.ResourceId, time.Time{}, testActor)
+			return err
+		}},
+		{"deregister", func(ctrl *Controller, agent *model.Agent) error {
+			return ctrl.DeregisterAgent(agent.ResourceId, 0, testActor)
+		}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl, _, agent := newCachedController(t)
+
+			issued := time.Now().Add(-time.Hour)
+			token, _ := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.MapClaims{
+				"iss": "finch",
+				"sub": "agent",
+				"rid": agent.ResourceId,
+				"iat": issued.Unix(),
+				"exp": issued.Add(2 * time.Hour).Unix(),
+			}).SignedString([]byte(cfg.Secret()))
+
+			_, err := ctrl.AuthorizeAgentRequest(token, lokiPush)
+			assert.NoError(t, err, "authorize request")
+
+			err = tt.change(ctrl, agent)
+			assert.NoError(t, err, "change agent")
+
+			assert.Eventually(t, func() bool {
+				_, err := ctrl.AuthorizeAgentRequest(token, lokiPush)
+				return err != nil
+			}, time.Second, 10*time.Millisecond, "cached decision invalidated")
+		})
+	}
+}
@@ -0,0 +1,184 @@
+/*
+Copyright (c) Tobias Schäfer. All rights reserved.
+Licensed under the MIT License, see LICENSE file in the project root for details.
+*/
+package controller
+
+import (
+	"errors"
+	"fmt"
+	"log/slog"
+	"maps"
+	"time"
+
+	"github.com/tschaefer/finch/internal/model"
+)
+
+var (
+	ErrInvalidCollector  = errors.New("invalid collector")
+	ErrCollectorMismatch = errors.New("collector belongs to another agent")
+)
+
+const collectorPollWriteInterval = 30 * time.Second
+
+type renderedCollectorConfig struct {
+	resourceVersion uint64
+	jti             string
+	hash            string
+}
+
+type CollectorConfig struct {
+	Content     string
+	Hash        string
+	NotModified bool
+}
+
+func (c *Controller) RegisterCollector(claims *AgentClaims, collectorId, name string, attributes map[string]string) error {
+	slog.Debug("Register Collector", "rid", claims.ResourceId, "collector", collectorId, "name", name)
+
+	collector, err := c.bindCollector(claims, collectorId)
+	if err != nil {
+		return err
+	}
+
+	collector.Name = name
+	collector.Attributes = attributes
+	_, err = c.model.SaveCollector(collector)
+	return err
+}
+
+func (c *Controller) UnregisterCollector(claims *AgentClaims, collectorId string) error {
+	slog.Debug("Unregister Collector", "rid", claims.ResourceId, "collector", collectorId)
+
+	if _, err := c.bindCollector(claims, collectorId); err != nil {
+		return err
+	}
+
+	return c.model.DeleteCollector(collectorId)
+}
+
+func (c *Controller) GetCollectorConfig(token string, claims *AgentClaims, collectorId string, attributes map[string]string, hash string) (*CollectorConfig, error) {
+	slog.Debug("Get Collector Config", "rid", claims.ResourceId, "collector", collectorId, "hash", hash)
+
+	collector, err := c.bindCollector(claims, collectorId)
+	if err != nil {
+		return nil, err
+	}
+
+	agent, err := c.model.GetAgent(&model.Agent{ResourceId: claims.ResourceId})
+	if err != nil {
+		if errors.Is(err, model.ErrAgentNotFound) {
+			return nil, ErrAgentNotFound
+		}
+		return nil, err
+	}
+
+	configHash, cached := c.cachedCollectorConfig(agent, claims)
+	var content []byte
+	if !cached || hash != configHash {
+		content, configHash, err = c.renderTokenConfig(agent, token, claims, true)
+		if err != nil {
+			return nil, err
+		}
+		c.cacheCollectorConfig(agent, claims, configHash)
+	}
+
+	now := time.Now().UTC()
+	if len(attributes) == 0 {
+		attributes = collector.Attributes
+	}
+	if collectorPollChanged(collector, attributes, configHash, now) {
+		collector.Attributes = attributes
+		collector.ConfigHash = configHash
+		collector.LastPoll = &now
+		if _, err := c.model.SaveCollector(collector); err != nil {
+			return nil, err
+		}
+	}
+
+	if hash == configHash {
+		return &CollectorConfig{Hash: configHash, NotModified: true}, nil
+	}
+
+	return &CollectorConfig{Content: string(content), Hash: configHash}, nil
+}
+
+func (c *Controller) ListCollectors(rid string) ([]model.Collector, error) {
+	slog.Debug("List Collectors", "rid", rid)
+
+	collectors := []model.Collector{}
+	if _, err := c.model.ListCollectors(&collectors, rid); err != nil {
+		return nil, err
+	}
+
+	return collectors, nil
+}
+
+func (c *Controller) bindCollector(claims *AgentClaims, collectorId string) (*model.Collector, error) {
+	if collectorId == "" {
+		return nil, fmt.Errorf("%w: id must not be empty", ErrInvalidCollector)
+	}
+
+	collector, err := c.model.GetCollector(collectorId)
+	if errors.Is(err, model.ErrCollectorNotFound) {
+		return &model.Collector{CollectorId: collectorId, ResourceId: claims.ResourceId}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if collector.ResourceId != claims.ResourceId {
+		return nil, fmt.Errorf("%w: %s", ErrCollectorMismatch, collectorId)
+	}
+
+	return collector, nil
+}
+
+func (c *Controller) cachedCollectorConfig(agent *model.Agent, claims *AgentClaims) (string, bool) {
+	if claims.Jti == "" {
+		return "", false
+	}
+
+	c.collectorConfigsMu.Lock()
+	defer c.collectorConfigsMu.Unlock()
+
+	rendered, ok := c.collectorConfigs[agent.ResourceId]
+	if !ok || rendered.resourceVersion != agent.ResourceVersion || rendered.jti != claims.Jti {
+		return "", false
+	}
+
+	return rendered.hash, true
+}
+
+func (c *Controller) cacheCollectorConfig(agent *model.Agent, claims *AgentClaims, hash string) {
+	if claims.Jti == "" {
+		return
+	}
+
+	c.collectorConfigsMu.Lock()
+	defer c.collectorConfigsMu.Unlock()
+
+	c.collectorConfigs[agent.ResourceId] = renderedCollectorConfig{
+		resourceVersion: agent.ResourceVersion,
+		jti:             claims.Jti,
+		hash:            hash,
+	}
+}
+
+func (c *Controller) forgetCollectorConfig(rid string) {
+	c.collectorConfigsMu.Lock()
+	defer c.collectorConfigsMu.Unlock()
+
+	delete(c.collectorConfigs, rid)
+}
+
+func collectorPollChanged(collector *model.Collector, attributes map[string]string, hash string, now time.Time) bool {
+	if collector.ID == 0 || collector.LastPoll == nil {
+		return true
+	}
+	if collector.ConfigHash != hash || !maps.Equal(collector.Attributes, attributes) {
+		return true
+	}
+
+	return now.Sub(*collector.LastPoll) >= collectorPollWriteInterval
+}
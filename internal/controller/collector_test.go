@@ -0,0 +1,101 @@
+/*
+Copyright (c) Tobias Schäfer. All rights reserved.
+Licensed under the MIT License, see LICENSE file in the project root for details.
+*/
+package controller
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/tschaefer/finch/internal/model"
+)
+
+func Test_GetCollectorConfigThrottlesPollWrites(t *testing.T) {
+	m := newModel(t)
+	ctrl := New(m, cfg)
+	assert.NotNil(t, ctrl, "create controller")
+
+	rid, err := ctrl.RegisterAgent(&Agent{Hostname: "test-host", Node: "unix", LogSources: []string{"journal://"}}, testActor)
+	assert.NoError(t, err, "register agent")
+	token, _, err := ctrl.GenerateAgentToken(rid, time.Hour, testActor, TokenPurposeConfig)
+	assert.NoError(t, err, "generate token")
+	claims, err := ctrl.AuthenticateAgentToken(token)
+	assert.NoError(t, err, "authenticate token")
+
+	first, err := ctrl.GetCollectorConfig(token, claims, "test-host", map[string]string{"os": "linux"}, "")
+	assert.NoError(t, err, "get collector config")
+	assert.NotEmpty(t, first.Content, "config content")
+
+	stored, err := m.GetCollector("test-host")
+	assert.NoError(t, err, "get collector")
+	assert.NotNil(t, stored.LastPoll, "last poll recorded")
+	lastPoll := *stored.LastPoll
+
+	again, err := ctrl.GetCollectorConfig(token, claims, "test-host", map[string]string{"os": "linux"}, first.Hash)
+	assert.NoError(t, err, "poll unchanged collector config")
+	assert.True(t, again.NotModified, "config not modified")
+	assert.Equal(t, first.Hash, again.Hash, "cached config hash")
+
+	stored, err = m.GetCollector("test-host")
+	assert.NoError(t, err, "get collector")
+	assert.True(t, lastPoll.Equal(*stored.LastPoll), "unchanged poll not written")
+
+	_, err = ctrl.GetCollectorConfig(token, claims, "test-host", map[string]string{"os": "windows"}, first.Hash)
+	assert.NoError(t, err, "poll with changed attributes")
+
+	stored, err = m.GetCollector("test-host")
+	assert.NoError(t, err, "get collector")
+	assert.Equal(t, "windows", stored.Attributes["os"], "changed attributes written")
+	assert.True(t, stored.LastPoll.After(lastPoll), "changed poll written")
+
+	err = ctrl.UpdateAgent(rid, 0, &Agent{Metrics: true}, testActor, "metrics")
+	assert.NoError(t, err, "update agent")
+
+	updated, err := ctrl.GetCollectorConfig(token, claims, "test-host", nil, first.Hash)
+	assert.NoError(t, err, "poll after agent update")
+	assert.False(t, updated.NotModified, "updated config served")
+	assert.NotEqual(t, first.Hash, updated.Hash, "updated config hash")
+
+	stored, err = m.GetCollector("test-host")
+	assert.NoError(t, err, "get collector")
+	assert.Equal(t, updated.Hash, stored.ConfigHash, "updated config hash written")
+	assert.Equal(t, map[string]string{"os": "windows"}, stored.Attributes, "attributes kept without update")
+}
+
+func Test_CollectorConfigCacheKeyedByAgent(t *testing.T) {
+	m := newModel(t)
+	ctrl := New(m, cfg)
+	assert.NotNil(t, ctrl, "create controller")
+
+	rid, err := ctrl.RegisterAgent(&Agent{Hostname: "test-host", Node: "unix", LogSources: []string{"journal://"}}, testActor)
+	assert.NoError(t, err, "register agent")
+	token, _, err := ctrl.GenerateAgentToken(rid, time.Hour, testActor, TokenPurposeConfig)
+	assert.NoError(t, err, "generate token")
+	claims, err := ctrl.AuthenticateAgentToken(token)
+	assert.NoError(t, err, "authenticate token")
+
+	for _, collectorId := range []string{"collector-1", "collector-2", "collector-3"} {
+		_, err := ctrl.GetCollectorConfig(token, claims, collectorId, nil, "")
+		assert.NoError(t, err, "get collector config")
+	}
+	assert.Len(t, ctrl.collectorConfigs, 1, "one cached config per agent")
+
+	err = ctrl.DeregisterAgent(rid, 0, testActor)
+	assert.NoError(t, err, "deregister agent")
+	assert.Empty(t, ctrl.collectorConfigs, "cached config dropped on deregister")
+}
+
+func Test_CollectorPollChanged(t *testing.T) {
+	now := time.Now()
+	recent := now.Add(-time.Second)
+	old := now.Add(-2 * collectorPollWriteInterval)
+	attributes := map[string]string{"os": "linux"}
+
+	assert.True(t, collectorPollChanged(&model.Collector{}, attributes, "hash", now), "new collector")
+	assert.False(t, collectorPollChanged(&model.Collector{ID: 1, ConfigHash: "hash", Attributes: attributes, LastPoll: &recent}, attributes, "hash", now), "recent unchanged poll")
+	assert.True(t, collectorPollChanged(&model.Collector{ID: 1, ConfigHash: "other", Attributes: attributes, LastPoll: &recent}, attributes, "hash", now), "changed hash")
+	assert.True(t, collectorPollChanged(&model.Collector{ID: 1, ConfigHash: "hash", LastPoll: &recent}, attributes, "hash", now), "changed attributes")
+	assert.True(t, collectorPollChanged(&model.Collector{ID: 1, ConfigHash: "hash", Attributes: attributes, LastPoll: &old}, attributes, "hash", now), "outdated poll")
+}
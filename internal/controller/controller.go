@@ -5,27 +5,84 @@ Licensed under the MIT License, see LICENSE file in the project root for details
 package controller
 
 import (
+	"context"
+	"errors"
 	"fmt"
 	"log/slog"
+	"sync"
+	"sync/atomic"
+	"time"
 
 	"github.com/tschaefer/finch/internal/config"
+	"github.com/tschaefer/finch/internal/keyring"
 	"github.com/tschaefer/finch/internal/model"
 )
 
 type Controller struct {
-	config *config.Config
-	model  *model.Model
+	*controllerState
+	ctx   context.Context
+	model *model.Model
+}
+
+type controllerState struct {
+	config     *config.Config
+	keys       *keyring.KeyRing
+	lastSeen   map[string]time.Time
+	lastSeenMu sync.Mutex
+	authCache  atomic.Pointer[authCache]
+	limiter    *rateLimiter
+	usage      map[usageKey]*usageCounter
+	usageMu    sync.Mutex
+
+	collectorConfigs   map[string]renderedCollectorConfig
+	collectorConfigsMu sync.Mutex
 }
 
 func New(model *model.Model, cfg *config.Config) *Controller {
 	slog.Debug("Initializing Controller", "model", fmt.Sprintf("%+v", model), "config", fmt.Sprintf("%+v", cfg))
 
 	return &Controller{
-		model:  model,
-		config: cfg,
+		controllerState: &controllerState{
+			config:   cfg,
+			keys:     keyring.New(cfg),
+			lastSeen: make(map[string]time.Time),
+			limiter:  newRateLimiter(),
+			usage:    make(map[usageKey]*usageCounter),
+
+			collectorConfigs: make(map[string]renderedCollectorConfig),
+		},
+		ctx:   context.Background(),
+		model: model,
 	}
 }
 
-func (c *Controller) SubscribeAgentEvents() <-chan model.AgentEvent {
-	return c.model.SubscribeAgentEvents()
+func (c *Controller) WithContext(ctx context.Context) *Controller {
+	return &Controller{
+		controllerState: c.controllerState,
+		ctx:             ctx,
+		model:           c.model.WithContext(ctx),
+	}
+}
+
+var (
+	ErrRevisionTooOld = errors.New("revision is too old")
+)
+
+func (c *Controller) SubscribeAgentEvents(ctx context.Context) <-chan model.AgentEvent {
+	return c.model.SubscribeAgentEvents(ctx)
+}
+
+func (c *Controller) AgentRevision() uint64 {
+	return c.model.AgentRevision()
+}
+
+func (c *Controller) AgentEventsSince(revision uint64) ([]model.AgentEvent, error) {
+	slog.Debug("Agent Events Since", "revision", revision)
+
+	events, ok := c.model.AgentEventsSince(revision)
+	if !ok {
+		return nil, fmt.Errorf("%w: %d", ErrRevisionTooOld, revision)
+	}
+
+	return events, nil
 }
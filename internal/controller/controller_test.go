@@ -0,0 +1,59 @@
+/*
+Copyright (c) Tobias Schäfer. All rights reserved.
+Licensed under the MIT License, see LICENSE file in the project root for details.
+*/
+package controller
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/tschaefer/finch/internal/model"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+type requestKey struct{}
+
+func Test_WithContextBindsModelAndSharesState(t *testing.T) {
+	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{
+		Logger: logger.Default.LogMode(logger.Silent),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = db.AutoMigrate(&model.Agent{}, &model.AgentToken{}, &model.AuditEvent{}, &model.AgentConfigRevision{}, &model.Collector{}, &model.EnrollmentToken{}, &model.Tenant{}, &model.AgentUsage{}, &model.DashboardSession{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var requests []any
+	err = db.Callback().Query().Before("gorm:query").Register("test:capture_context", func(tx *gorm.DB) {
+		if value := tx.Statement.Context.Value(requestKey{}); value != nil {
+			requests = append(requests, value)
+		}
+	})
+	assert.NoError(t, err, "register capturing callback")
+
+	ctrl := New(model.New(db), cfg)
+	rid, err := ctrl.RegisterAgent(&Agent{Hostname: "test-host", Node: "unix", LogSources: []string{"journal://"}}, testActor)
+	assert.NoError(t, err, "register agent")
+	token, _, err := ctrl.GenerateAgentToken(rid, time.Hour, testActor, TokenPurposeConfig)
+	assert.NoError(t, err, "generate token")
+	assert.Empty(t, requests, "background context carries no request")
+
+	ctx := context.WithValue(context.Background(), requestKey{}, "request-1")
+	_, err = ctrl.WithContext(ctx).AuthenticateAgentToken(token)
+	assert.NoError(t, err, "authenticate token with request context")
+	assert.NotEmpty(t, requests, "queries carry request context")
+	for _, request := range requests {
+		assert.Equal(t, "request-1", request, "request context value")
+	}
+
+	pending, err := ctrl.GetAgent(rid)
+	assert.NoError(t, err, "get agent")
+	assert.NotNil(t, pending.LastSeen, "last seen shared with request scoped controller")
+}
@@ -12,6 +12,9 @@ import (
 	"time"
 
 	"github.com/golang-jwt/jwt/v5"
+	"github.com/google/uuid"
+	"github.com/tschaefer/finch/internal/metrics"
+	"github.com/tschaefer/finch/internal/model"
 )
 
 var (
@@ -32,12 +35,13 @@ type DashboardTokenResponse struct {
 }
 
 type DashboardClaims struct {
-	Role  string
-	Scope []string
+	Role    string
+	Scope   []string
+	Session string
 }
 
-func (c *Controller) GenerateDashboardToken(sessionTimeout int, role string, scope []string) (*DashboardTokenResponse, error) {
-	slog.Debug("Generating dashboard token", "sessionTimeout", sessionTimeout, "role", role, "scope", scope)
+func (c *Controller) GenerateDashboardToken(sessionTimeout int, role string, scope []string, actor Actor) (*DashboardTokenResponse, error) {
+	slog.Debug("Generating dashboard token", "sessionTimeout", sessionTimeout, "role", role, "scope", scope, "actor", actor.Name)
 
 	if sessionTimeout <= 0 {
 		sessionTimeout = 1800
@@ -52,21 +56,42 @@ func (c *Controller) GenerateDashboardToken(sessionTimeout int, role string, sco
 		return nil, fmt.Errorf("failed to marshal scope: %w", err)
 	}
 
-	expiresAt := time.Now().Add(time.Duration(sessionTimeout) * time.Second)
-	token := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.MapClaims{
+	now := time.Now()
+	expiresAt := now.Add(time.Duration(sessionTimeout) * time.Second)
+	session := uuid.New().String()
+	tokenString, kid, err := c.keys.Sign(jwt.MapClaims{
 		"iss":   "finch",
 		"sub":   "dashboard",
 		"exp":   expiresAt.Unix(),
-		"iat":   time.Now().Unix(),
+		"iat":   now.Unix(),
+		"jti":   session,
 		"role":  role,
 		"scope": string(scopeJSON),
 	})
+	if err != nil {
+		return nil, err
+	}
 
-	tokenString, err := token.SignedString([]byte(c.config.Secret()))
+	_, err = c.model.CreateDashboardSession(&model.DashboardSession{
+		Session:   session,
+		Role:      role,
+		IssuedAt:  time.Unix(now.Unix(), 0).UTC(),
+		ExpiresAt: time.Unix(expiresAt.Unix(), 0).UTC(),
+		Issuer:    actor.Name,
+		Kid:       kid,
+	})
 	if err != nil {
 		return nil, err
 	}
 
+	metrics.TokensIssued.WithLabelValues("dashboard", role).Inc()
+	c.audit(actor, AuditActionDashboardTokenIssue, "", map[string]model.AuditChange{
+		"session":    {After: session},
+		"role":       {After: role},
+		"scope":      {After: scope},
+		"expires_at": {After: expiresAt},
+	})
+
 	dashboardURL := fmt.Sprintf("https://%s/login", c.config.Hostname())
 
 	return &DashboardTokenResponse{
@@ -77,12 +102,7 @@ func (c *Controller) GenerateDashboardToken(sessionTimeout int, role string, sco
 }
 
 func (c *Controller) ValidateDashboardToken(tokenString string) (*DashboardClaims, error) {
-	token, err := jwt.Parse(tokenString, func(token *jwt.Token) (any, error) {
-		if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
-			return nil, fmt.Errorf("unexpected signing method: %v", token.Header["alg"])
-		}
-		return []byte(c.config.Secret()), nil
-	})
+	token, err := jwt.Parse(tokenString, c.keys.Keyfunc)
 
 	if err != nil {
 		return nil, ErrInvalidToken
@@ -95,6 +115,7 @@ func (c *Controller) ValidateDashboardToken(tokenString string) (*DashboardClaim
 
 		role, _ := claims["role"].(string)
 		scopeStr, _ := claims["scope"].(string)
+		session, _ := claims["jti"].(string)
 
 		var scope []string
 		if scopeStr != "" {
@@ -108,8 +129,9 @@ func (c *Controller) ValidateDashboardToken(tokenString string) (*DashboardClaim
 		}
 
 		return &DashboardClaims{
-			Role:  role,
-			Scope: scope,
+			Role:    role,
+			Scope:   scope,
+			Session: session,
 		}, nil
 	}
 
@@ -124,13 +146,21 @@ func (c *Controller) CanDownloadConfig(claims *DashboardClaims) bool {
 	return claims.Role == RoleAdmin
 }
 
-func (c *Controller) CanAccessAgent(claims *DashboardClaims, agentRID, agentHostname string) bool {
+func (c *Controller) CanSuspendAgents(claims *DashboardClaims) bool {
+	return claims.Role == RoleAdmin || claims.Role == RoleOperator
+}
+
+func (c *Controller) CanViewAudit(claims *DashboardClaims) bool {
+	return claims.Role == RoleAdmin
+}
+
+func (c *Controller) CanAccessAgent(claims *DashboardClaims, agentRID, agentHostname, agentTenant string) bool {
 	if len(claims.Scope) == 0 {
 		return true
 	}
 
 	for _, s := range claims.Scope {
-		if s == agentRID || s == agentHostname {
+		if s == agentRID || s == agentHostname || s == tenantScopePrefix+agentTenant {
 			return true
 		}
 	}
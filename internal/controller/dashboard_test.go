@@ -10,6 +10,7 @@ import (
 
 	"github.com/golang-jwt/jwt/v5"
 	"github.com/stretchr/testify/assert"
+	"github.com/tschaefer/finch/internal/config"
 )
 
 func Test_GenerateDashboardTokenReturnsValidToken(t *testing.T) {
@@ -17,7 +18,7 @@ func Test_GenerateDashboardTokenReturnsValidToken(t *testing.T) {
 	ctrl := New(model, cfg)
 	assert.NotNil(t, ctrl, "create controller")
 
-	response, err := ctrl.GenerateDashboardToken(0, RoleOperator, []string{})
+	response, err := ctrl.GenerateDashboardToken(0, RoleOperator, []string{}, testActor)
 
 	assert.NoError(t, err, "get dashboard token")
 	assert.NotNil(t, response, "response should not be nil")
@@ -31,7 +32,7 @@ func Test_GenerateDashboardTokenUsesDefaultTimeout(t *testing.T) {
 	ctrl := New(model, cfg)
 	assert.NotNil(t, ctrl, "create controller")
 
-	response, err := ctrl.GenerateDashboardToken(0, RoleOperator, []string{})
+	response, err := ctrl.GenerateDashboardToken(0, RoleOperator, []string{}, testActor)
 	assert.NoError(t, err, "get dashboard token")
 
 	expectedExpiration := time.Now().Add(1800 * time.Second)
@@ -45,7 +46,7 @@ func Test_GenerateDashboardTokenUsesCustomTimeout(t *testing.T) {
 	assert.NotNil(t, ctrl, "create controller")
 
 	customTimeout := 3600 // 1 hour
-	response, err := ctrl.GenerateDashboardToken(customTimeout, RoleOperator, []string{})
+	response, err := ctrl.GenerateDashboardToken(customTimeout, RoleOperator, []string{}, testActor)
 	assert.NoError(t, err, "get dashboard token")
 
 	expectedExpiration := time.Now().Add(time.Duration(customTimeout) * time.Second)
@@ -58,7 +59,7 @@ func Test_GenerateDashboardTokenContainsCorrectClaims(t *testing.T) {
 	ctrl := New(model, cfg)
 	assert.NotNil(t, ctrl, "create controller")
 
-	response, err := ctrl.GenerateDashboardToken(900, RoleOperator, []string{})
+	response, err := ctrl.GenerateDashboardToken(900, RoleOperator, []string{}, testActor)
 	assert.NoError(t, err, "get dashboard token")
 
 	token, err := jwt.Parse(response.Token, func(token *jwt.Token) (any, error) {
@@ -87,7 +88,7 @@ func Test_GenerateDashboardTokenSignedWithCorrectSecret(t *testing.T) {
 	ctrl := New(model, cfg)
 	assert.NotNil(t, ctrl, "create controller")
 
-	response, err := ctrl.GenerateDashboardToken(0, RoleOperator, []string{})
+	response, err := ctrl.GenerateDashboardToken(0, RoleOperator, []string{}, testActor)
 	assert.NoError(t, err, "get dashboard token")
 
 	token, err := jwt.Parse(response.Token, func(token *jwt.Token) (any, error) {
@@ -108,7 +109,7 @@ func Test_ValidateDashboardTokenSucceeds_WithValidToken(t *testing.T) {
 	ctrl := New(model, cfg)
 	assert.NotNil(t, ctrl, "create controller")
 
-	response, err := ctrl.GenerateDashboardToken(0, RoleOperator, []string{})
+	response, err := ctrl.GenerateDashboardToken(0, RoleOperator, []string{}, testActor)
 	assert.NoError(t, err, "get dashboard token")
 
 	claims, err := ctrl.ValidateDashboardToken(response.Token)
@@ -233,7 +234,7 @@ func Test_GenerateDashboardTokenReturnsError_WithInvalidRole(t *testing.T) {
 	ctrl := New(model, cfg)
 	assert.NotNil(t, ctrl, "create controller")
 
-	_, err := ctrl.GenerateDashboardToken(1800, "invalid-role", []string{})
+	_, err := ctrl.GenerateDashboardToken(1800, "invalid-role", []string{}, testActor)
 	assert.Error(t, err, "get dashboard token with invalid role should fail")
 	assert.Equal(t, ErrInvalidRole, err, "error should be ErrInvalidRole")
 }
@@ -292,13 +293,23 @@ func Test_CanDownloadConfig_ViewerRole(t *testing.T) {
 	assert.False(t, ctrl.CanDownloadConfig(claims), "viewer should not be able to download config")
 }
 
+func Test_CanSuspendAgents_Roles(t *testing.T) {
+	model := newModel(t)
+	ctrl := New(model, cfg)
+	assert.NotNil(t, ctrl, "create controller")
+
+	assert.True(t, ctrl.CanSuspendAgents(&DashboardClaims{Role: RoleAdmin}), "admin should be able to suspend agents")
+	assert.True(t, ctrl.CanSuspendAgents(&DashboardClaims{Role: RoleOperator}), "operator should be able to suspend agents")
+	assert.False(t, ctrl.CanSuspendAgents(&DashboardClaims{Role: RoleViewer}), "viewer should not be able to suspend agents")
+}
+
 func Test_CanAccessAgent_WithEmptyScope(t *testing.T) {
 	model := newModel(t)
 	ctrl := New(model, cfg)
 	assert.NotNil(t, ctrl, "create controller")
 
 	claims := &DashboardClaims{Role: RoleAdmin, Scope: []string{}}
-	assert.True(t, ctrl.CanAccessAgent(claims, "rid-123", "host.example.com"), "should access any agent with empty scope")
+	assert.True(t, ctrl.CanAccessAgent(claims, "rid-123", "host.example.com", DefaultTenant), "should access any agent with empty scope")
 }
 
 func Test_CanAccessAgent_WithSpecificRID(t *testing.T) {
@@ -307,8 +318,8 @@ func Test_CanAccessAgent_WithSpecificRID(t *testing.T) {
 	assert.NotNil(t, ctrl, "create controller")
 
 	claims := &DashboardClaims{Role: RoleAdmin, Scope: []string{"rid-123", "rid-456"}}
-	assert.True(t, ctrl.CanAccessAgent(claims, "rid-123", "host.example.com"), "should access agent with matching RID")
-	assert.False(t, ctrl.CanAccessAgent(claims, "rid-789", "other.example.com"), "should not access agent without matching RID")
+	assert.True(t, ctrl.CanAccessAgent(claims, "rid-123", "host.example.com", DefaultTenant), "should access agent with matching RID")
+	assert.False(t, ctrl.CanAccessAgent(claims, "rid-789", "other.example.com", DefaultTenant), "should not access agent without matching RID")
 }
 
 func Test_CanAccessAgent_WithSpecificHostname(t *testing.T) {
@@ -317,8 +328,8 @@ func Test_CanAccessAgent_WithSpecificHostname(t *testing.T) {
 	assert.NotNil(t, ctrl, "create controller")
 
 	claims := &DashboardClaims{Role: RoleAdmin, Scope: []string{"host1.example.com", "host2.example.com"}}
-	assert.True(t, ctrl.CanAccessAgent(claims, "rid-123", "host1.example.com"), "should access agent with matching hostname")
-	assert.False(t, ctrl.CanAccessAgent(claims, "rid-456", "host3.example.com"), "should not access agent without matching hostname")
+	assert.True(t, ctrl.CanAccessAgent(claims, "rid-123", "host1.example.com", DefaultTenant), "should access agent with matching hostname")
+	assert.False(t, ctrl.CanAccessAgent(claims, "rid-456", "host3.example.com", DefaultTenant), "should not access agent without matching hostname")
 }
 
 func Test_CanAccessAgent_WithHostnameAll(t *testing.T) {
@@ -327,8 +338,8 @@ func Test_CanAccessAgent_WithHostnameAll(t *testing.T) {
 	assert.NotNil(t, ctrl, "create controller")
 
 	claims := &DashboardClaims{Role: RoleAdmin, Scope: []string{"all"}}
-	assert.True(t, ctrl.CanAccessAgent(claims, "rid-123", "all"), "should access agent with hostname 'all'")
-	assert.False(t, ctrl.CanAccessAgent(claims, "rid-456", "other.example.com"), "should not access agent without matching hostname")
+	assert.True(t, ctrl.CanAccessAgent(claims, "rid-123", "all", DefaultTenant), "should access agent with hostname 'all'")
+	assert.False(t, ctrl.CanAccessAgent(claims, "rid-456", "other.example.com", DefaultTenant), "should not access agent without matching hostname")
 }
 
 func Test_GenerateDashboardTokenEncodesMultipleScopes(t *testing.T) {
@@ -336,7 +347,7 @@ func Test_GenerateDashboardTokenEncodesMultipleScopes(t *testing.T) {
 	ctrl := New(model, cfg)
 	assert.NotNil(t, ctrl, "create controller")
 
-	response, err := ctrl.GenerateDashboardToken(900, RoleAdmin, []string{"host1", "host2", "host3"})
+	response, err := ctrl.GenerateDashboardToken(900, RoleAdmin, []string{"host1", "host2", "host3"}, testActor)
 	assert.NoError(t, err, "get dashboard token")
 
 	token, err := jwt.Parse(response.Token, func(token *jwt.Token) (any, error) {
@@ -356,7 +367,7 @@ func Test_GenerateDashboardTokenWithEmptyScopeArray(t *testing.T) {
 	ctrl := New(model, cfg)
 	assert.NotNil(t, ctrl, "create controller")
 
-	response, err := ctrl.GenerateDashboardToken(900, RoleViewer, []string{})
+	response, err := ctrl.GenerateDashboardToken(900, RoleViewer, []string{}, testActor)
 	assert.NoError(t, err, "get dashboard token")
 
 	token, err := jwt.Parse(response.Token, func(token *jwt.Token) (any, error) {
@@ -368,3 +379,54 @@ func Test_GenerateDashboardTokenWithEmptyScopeArray(t *testing.T) {
 	assert.True(t, ok, "claims should be MapClaims")
 	assert.Equal(t, "[]", claims["scope"], "empty scope array should be JSON empty array in JWT")
 }
+
+func Test_ValidateDashboardTokenSucceeds_AsymmetricSigning(t *testing.T) {
+	signingCfg := config.NewFromData(&config.Data{
+		Secret:           "1suNCrW7sWlPbU+YCfdGQI7z3ZMo9Ru2GNV4h69QzaM=",
+		Id:               "test-id",
+		SigningAlgorithm: "ES256",
+	}, t.TempDir())
+	ctrl := New(newModel(t), signingCfg)
+	assert.NoError(t, ctrl.LoadSigningKeys(), "load signing keys")
+
+	legacy, err := New(newModel(t), cfg).GenerateDashboardToken(60, RoleViewer, nil, testActor)
+	assert.NoError(t, err, "generate secret signed token")
+
+	resp, err := ctrl.GenerateDashboardToken(60, RoleAdmin, nil, testActor)
+	assert.NoError(t, err, "generate token")
+	assert.Len(t, ctrl.JWKS().Keys, 1, "published keys")
+
+	claims, err := ctrl.ValidateDashboardToken(resp.Token)
+	assert.NoError(t, err, "validate token")
+	assert.Equal(t, RoleAdmin, claims.Role, "role")
+
+	claims, err = ctrl.ValidateDashboardToken(legacy.Token)
+	assert.NoError(t, err, "validate secret signed token")
+	assert.Equal(t, RoleViewer, claims.Role, "legacy role")
+}
+
+func Test_GenerateDashboardTokenRecordsSessionInUTC(t *testing.T) {
+	local := time.Local
+	time.Local = time.FixedZone("UTC+5", 5*60*60)
+	t.Cleanup(func() { time.Local = local })
+
+	m := newModel(t)
+	ctrl := New(m, cfg)
+	assert.NotNil(t, ctrl, "create controller")
+
+	_, err := ctrl.GenerateDashboardToken(3600, RoleViewer, nil, testActor)
+	assert.NoError(t, err, "generate dashboard token")
+
+	kids := []string{}
+	for _, key := range ctrl.ListSigningKeys() {
+		kids = append(kids, key.Id)
+	}
+
+	count, err := m.CountUnexpiredTokens(kids, time.Now())
+	assert.NoError(t, err, "count unexpired sessions")
+	assert.Equal(t, int64(1), count, "unexpired session counted")
+
+	count, err = m.CountUnexpiredTokens(kids, time.Now().Add(2*time.Hour))
+	assert.NoError(t, err, "count sessions expired by then")
+	assert.Equal(t, int64(0), count, "expired session not counted")
+}
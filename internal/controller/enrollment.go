@@ -0,0 +1,227 @@
+/*
+Copyright (c) Tobias Schäfer. All rights reserved.
+Licensed under the MIT License, see LICENSE file in the project root for details.
+*/
+package controller
+
+import (
+	"errors"
+	"fmt"
+	"log/slog"
+	"path"
+	"slices"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/google/uuid"
+	"github.com/tschaefer/finch/internal/metrics"
+	"github.com/tschaefer/finch/internal/model"
+)
+
+const defaultEnrollmentTokenExpiration = 24 * time.Hour
+
+var (
+	ErrInvalidEnrollmentToken   = errors.New("invalid enrollment token")
+	ErrEnrollmentTokenExhausted = errors.New("enrollment token expired or exhausted")
+	ErrHostnameNotAllowed       = errors.New("hostname not allowed by enrollment token")
+	ErrInvalidHostnamePattern   = errors.New("invalid hostname pattern")
+	ErrInvalidUsageLimit        = errors.New("usage limit must be positive")
+	ErrInvalidEnrollment        = errors.New("invalid enrollment")
+)
+
+type EnrollmentTokenSpec struct {
+	UsageLimit      int
+	Expiration      time.Duration
+	HostnamePattern string
+	Labels          []string
+	LogSources      []string
+	Ephemeral       bool
+	Tenant          string
+}
+
+type Enrollment struct {
+	ResourceId string
+	Config     []byte
+}
+
+func (c *Controller) CreateEnrollmentToken(spec EnrollmentTokenSpec, actor Actor) (string, *model.EnrollmentToken, error) {
+	slog.Debug("Create Enrollment Token", "spec", fmt.Sprintf("%+v", spec), "actor", actor.Name)
+
+	if spec.UsageLimit == 0 {
+		spec.UsageLimit = 1
+	}
+	if spec.UsageLimit < 0 {
+		return "", nil, ErrInvalidUsageLimit
+	}
+	if spec.Expiration == 0 {
+		spec.Expiration = defaultEnrollmentTokenExpiration
+	}
+	if _, err := path.Match(spec.HostnamePattern, ""); err != nil {
+		return "", nil, fmt.Errorf("%w: %s", ErrInvalidHostnamePattern, spec.HostnamePattern)
+	}
+	if len(spec.LogSources) > 0 {
+		logSources, err := c.__parseLogSources(&Agent{LogSources: spec.LogSources})
+		if err != nil {
+			return "", nil, fmt.Errorf("%w: %v", ErrInvalidEnrollment, err)
+		}
+		spec.LogSources = logSources
+	}
+
+	tenant, err := c.resolveTenant(spec.Tenant)
+	if err != nil {
+		return "", nil, err
+	}
+
+	now := time.Now()
+	expiresAt := now.Add(spec.Expiration)
+	jti := uuid.New().String()
+	claims := jwt.MapClaims{
+		"iss": "finch",
+		"sub": "enrollment",
+		"jti": jti,
+		"iat": now.Unix(),
+		"exp": expiresAt.Unix(),
+	}
+
+	tokenString, kid, err := c.keys.Sign(claims)
+	if err != nil {
+		return "", nil, err
+	}
+
+	issued, err := c.model.CreateEnrollmentToken(&model.EnrollmentToken{
+		Jti:             jti,
+		IssuedAt:        time.Unix(now.Unix(), 0).UTC(),
+		ExpiresAt:       time.Unix(expiresAt.Unix(), 0).UTC(),
+		Issuer:          actor.Name,
+		UsageLimit:      spec.UsageLimit,
+		HostnamePattern: spec.HostnamePattern,
+		Labels:          spec.Labels,
+		LogSources:      spec.LogSources,
+		Ephemeral:       spec.Ephemeral,
+		Tenant:          tenant,
+		Kid:             kid,
+	})
+	if err != nil {
+		return "", nil, err
+	}
+
+	metrics.TokensIssued.WithLabelValues("enrollment", "").Inc()
+	c.audit(actor, AuditActionEnrollmentTokenIssue, "", map[string]model.AuditChange{
+		"jti":              {After: jti},
+		"usage_limit":      {After: spec.UsageLimit},
+		"hostname_pattern": {After: spec.HostnamePattern},
+		"expires_at":       {After: issued.ExpiresAt},
+	})
+	return tokenString, issued, nil
+}
+
+func (c *Controller) ListEnrollmentTokens() ([]model.EnrollmentToken, error) {
+	slog.Debug("List Enrollment Tokens")
+
+	tokens := []model.EnrollmentToken{}
+	if _, err := c.model.ListEnrollmentTokens(&tokens); err != nil {
+		return nil, err
+	}
+
+	return tokens, nil
+}
+
+func (c *Controller) EnrollAgent(tokenString string, facts *Agent, address string) (*Enrollment, error) {
+	slog.Debug("Enroll Agent", "facts", fmt.Sprintf("%+v", facts), "address", address)
+
+	enrollment, err := c.parseEnrollmentToken(tokenString)
+	if err != nil {
+		return nil, err
+	}
+
+	if enrollment.HostnamePattern != "" {
+		if ok, _ := path.Match(enrollment.HostnamePattern, facts.Hostname); !ok {
+			return nil, fmt.Errorf("%w: %s", ErrHostnameNotAllowed, facts.Hostname)
+		}
+	}
+
+	data := *facts
+	data.Labels = mergeLabels(enrollment.Labels, facts.Labels)
+	if len(data.LogSources) == 0 {
+		data.LogSources = enrollment.LogSources
+	}
+	data.Ephemeral = enrollment.Ephemeral
+	data.Tenant = enrollment.Tenant
+	data.RateLimits = nil
+
+	if _, err := c.marshalNewAgent(&data); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidEnrollment, err)
+	}
+
+	if err := c.model.ConsumeEnrollmentToken(enrollment.Jti, time.Now()); err != nil {
+		if errors.Is(err, model.ErrEnrollmentTokenExhausted) {
+			return nil, ErrEnrollmentTokenExhausted
+		}
+		return nil, err
+	}
+
+	actor := Actor{Name: fmt.Sprintf("enrollment:%s", enrollment.Jti), Address: address}
+	rid, err := c.RegisterAgent(&data, actor)
+	if err != nil {
+		c.releaseEnrollmentToken(enrollment.Jti)
+		return nil, err
+	}
+
+	config, err := c.CreateAgentConfig(rid, actor)
+	if err != nil {
+		if deregisterErr := c.DeregisterAgent(rid, 0, actor); deregisterErr != nil {
+			slog.Error("Failed to deregister enrolled agent", "rid", rid, "error", deregisterErr)
+		}
+		c.releaseEnrollmentToken(enrollment.Jti)
+		return nil, err
+	}
+
+	return &Enrollment{ResourceId: rid, Config: config}, nil
+}
+
+func (c *Controller) releaseEnrollmentToken(jti string) {
+	if err := c.model.ReleaseEnrollmentToken(jti); err != nil {
+		slog.Error("Failed to release enrollment token", "jti", jti, "error", err)
+	}
+}
+
+func (c *Controller) parseEnrollmentToken(tokenString string) (*model.EnrollmentToken, error) {
+	token, err := jwt.Parse(tokenString, c.keys.Keyfunc)
+	if err != nil {
+		if errors.Is(err, jwt.ErrTokenExpired) {
+			return nil, ErrEnrollmentTokenExhausted
+		}
+		return nil, fmt.Errorf("%w: %v", ErrInvalidEnrollmentToken, err)
+	}
+
+	claims, ok := token.Claims.(jwt.MapClaims)
+	if !ok || !token.Valid || claims["iss"] != "finch" || claims["sub"] != "enrollment" {
+		return nil, ErrInvalidEnrollmentToken
+	}
+
+	jti, ok := claims["jti"].(string)
+	if !ok {
+		return nil, fmt.Errorf("%w: missing jti claim", ErrInvalidEnrollmentToken)
+	}
+
+	enrollment, err := c.model.GetEnrollmentToken(jti)
+	if err != nil {
+		if errors.Is(err, model.ErrEnrollmentTokenNotFound) {
+			return nil, ErrInvalidEnrollmentToken
+		}
+		return nil, err
+	}
+
+	return enrollment, nil
+}
+
+func mergeLabels(defaults, labels []string) []string {
+	merged := slices.Clone(defaults)
+	for _, label := range labels {
+		if !slices.Contains(merged, label) {
+			merged = append(merged, label)
+		}
+	}
+
+	return merged
+}
@@ -0,0 +1,95 @@
+/*
+Copyright (c) Tobias Schäfer. All rights reserved.
+Licensed under the MIT License, see LICENSE file in the project root for details.
+*/
+package controller
+
+import (
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"path"
+	"strings"
+
+	"github.com/tschaefer/finch/internal/model"
+)
+
+const (
+	BackendLoki      = "loki"
+	BackendMimir     = "mimir"
+	BackendPyroscope = "pyroscope"
+)
+
+var (
+	ErrRequestNotAllowed = errors.New("request not allowed")
+)
+
+type ForwardedRequest struct {
+	Uri           string
+	Method        string
+	ContentLength int64
+}
+
+func (c *Controller) AuthorizeAgentRequest(tokenString string, req ForwardedRequest) (*AgentClaims, error) {
+	slog.Debug("Authorize Agent Request", "uri", req.Uri, "method", req.Method, "contentLength", req.ContentLength)
+
+	agent, claims, err := c.cachedAuthenticateAgentToken(tokenString)
+	if err != nil {
+		return nil, err
+	}
+
+	backend, err := authorizeAgentBackend(agent, req)
+	if err != nil {
+		return nil, err
+	}
+
+	c.markAgentSeen(agent.ResourceId)
+
+	if err := c.limitAgentRequest(agent, backend); err != nil {
+		return nil, err
+	}
+
+	c.recordAgentUsage(agent.ResourceId, backend, req.ContentLength)
+	return claims, nil
+}
+
+func authorizeAgentBackend(agent *model.Agent, req ForwardedRequest) (string, error) {
+	if req.Method != http.MethodPost {
+		return "", fmt.Errorf("%w: method %q", ErrRequestNotAllowed, req.Method)
+	}
+
+	backend := BackendForUri(req.Uri)
+	switch backend {
+	case BackendLoki:
+		return backend, nil
+	case BackendMimir:
+		if !agent.Metrics {
+			return "", fmt.Errorf("%w: metrics disabled for agent %s", ErrRequestNotAllowed, agent.ResourceId)
+		}
+		return backend, nil
+	case BackendPyroscope:
+		if !agent.Profiles {
+			return "", fmt.Errorf("%w: profiles disabled for agent %s", ErrRequestNotAllowed, agent.ResourceId)
+		}
+		return backend, nil
+	}
+
+	return "", fmt.Errorf("%w: unknown backend for uri %q", ErrRequestNotAllowed, req.Uri)
+}
+
+func BackendForUri(uri string) string {
+	parsed, err := url.ParseRequestURI(uri)
+	if err != nil {
+		return ""
+	}
+
+	segment, _, _ := strings.Cut(strings.TrimPrefix(path.Clean(parsed.EscapedPath()), "/"), "/")
+	switch segment {
+	case BackendLoki, BackendMimir, BackendPyroscope:
+		return segment
+	}
+
+	return ""
+}
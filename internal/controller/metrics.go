@@ -0,0 +1,37 @@
+/*
+Copyright (c) Tobias Schäfer. All rights reserved.
+Licensed under the MIT License, see LICENSE file in the project root for details.
+*/
+package controller
+
+import (
+	"github.com/tschaefer/finch/internal/metrics"
+)
+
+func (c *Controller) AgentCounts() ([]metrics.AgentCount, error) {
+	groups, err := c.model.CountAgentGroups()
+	if err != nil {
+		return nil, err
+	}
+
+	counts := make([]metrics.AgentCount, 0, len(groups))
+	for _, group := range groups {
+		state := metrics.AgentStateActive
+		switch {
+		case !group.Active:
+			state = metrics.AgentStateSuspended
+		case group.Stale:
+			state = metrics.AgentStateStale
+		}
+
+		counts = append(counts, metrics.AgentCount{
+			State:    state,
+			Node:     group.Node,
+			Metrics:  group.Metrics,
+			Profiles: group.Profiles,
+			Count:    group.Count,
+		})
+	}
+
+	return counts, nil
+}
@@ -0,0 +1,158 @@
+/*
+Copyright (c) Tobias Schäfer. All rights reserved.
+Licensed under the MIT License, see LICENSE file in the project root for details.
+*/
+package controller
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/tschaefer/finch/internal/model"
+)
+
+const (
+	rateLimitPruneInterval = 5 * time.Minute
+	rateLimitMaxRetryAfter = time.Hour
+)
+
+var (
+	ErrRateLimited      = errors.New("rate limit exceeded")
+	ErrInvalidRateLimit = errors.New("invalid rate limit")
+)
+
+type RateLimitError struct {
+	Backend    string
+	RetryAfter time.Duration
+}
+
+func (e *RateLimitError) Error() string {
+	return fmt.Sprintf("%s for %s, retry after %s", ErrRateLimited, e.Backend, e.RetryAfter)
+}
+
+func (e *RateLimitError) Unwrap() error {
+	return ErrRateLimited
+}
+
+type tokenBucket struct {
+	limit   model.RateLimit
+	tokens  float64
+	updated time.Time
+}
+
+type rateLimiter struct {
+	mu      sync.Mutex
+	buckets map[string]*tokenBucket
+	pruned  time.Time
+}
+
+func newRateLimiter() *rateLimiter {
+	return &rateLimiter{
+		buckets: make(map[string]*tokenBucket),
+		pruned:  time.Now(),
+	}
+}
+
+func bucketSize(limit model.RateLimit) float64 {
+	if limit.Burst > 0 {
+		return float64(limit.Burst)
+	}
+	return math.Max(1, math.Ceil(limit.Rate))
+}
+
+func (b *tokenBucket) refill(now time.Time) {
+	elapsed := now.Sub(b.updated).Seconds()
+	if elapsed > 0 {
+		b.tokens = math.Min(bucketSize(b.limit), b.tokens+elapsed*b.limit.Rate)
+		b.updated = now
+	}
+}
+
+func (r *rateLimiter) take(key string, limit model.RateLimit, now time.Time) (time.Duration, bool) {
+	if limit.Rate <= 0 {
+		return 0, true
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if now.Sub(r.pruned) > rateLimitPruneInterval {
+		r.prune(now)
+	}
+
+	bucket, ok := r.buckets[key]
+	if !ok || bucket.limit != limit {
+		bucket = &tokenBucket{limit: limit, tokens: bucketSize(limit), updated: now}
+		r.buckets[key] = bucket
+	}
+
+	bucket.refill(now)
+	if bucket.tokens >= 1 {
+		bucket.tokens--
+		return 0, true
+	}
+
+	wait := math.Min((1-bucket.tokens)/limit.Rate, rateLimitMaxRetryAfter.Seconds())
+	return time.Duration(wait * float64(time.Second)), false
+}
+
+func (r *rateLimiter) prune(now time.Time) {
+	for key, bucket := range r.buckets {
+		bucket.refill(now)
+		if bucket.tokens >= bucketSize(bucket.limit) {
+			delete(r.buckets, key)
+		}
+	}
+	r.pruned = now
+}
+
+func (c *Controller) limitAgentRequest(agent *model.Agent, backend string) error {
+	limit := c.effectiveRateLimit(agent, backend)
+
+	retry, ok := c.limiter.take(agent.ResourceId+"/"+backend, limit, time.Now())
+	if !ok {
+		return &RateLimitError{Backend: backend, RetryAfter: retry}
+	}
+
+	return nil
+}
+
+func (c *Controller) effectiveRateLimit(agent *model.Agent, backend string) model.RateLimit {
+	if limit, ok := agent.RateLimits[backend]; ok {
+		return limit
+	}
+
+	if limit, ok := c.config.RateLimits()[backend]; ok {
+		return model.RateLimit{Rate: limit.Rate, Burst: limit.Burst}
+	}
+
+	return model.RateLimit{}
+}
+
+func parseRateLimits(limits model.RateLimits) (model.RateLimits, error) {
+	if len(limits) == 0 {
+		return nil, nil
+	}
+
+	backends := []string{BackendLoki, BackendMimir, BackendPyroscope}
+	for backend, limit := range limits {
+		if !slices.Contains(backends, backend) {
+			return nil, fmt.Errorf("%w: unknown backend %q", ErrInvalidRateLimit, backend)
+		}
+		if math.IsNaN(limit.Rate) || math.IsInf(limit.Rate, 0) {
+			return nil, fmt.Errorf("%w: non-finite rate for %s", ErrInvalidRateLimit, backend)
+		}
+		if limit.Rate < 0 || limit.Burst < 0 {
+			return nil, fmt.Errorf("%w: negative limit for %s", ErrInvalidRateLimit, backend)
+		}
+		if limit.Burst > math.MaxInt32 {
+			return nil, fmt.Errorf("%w: burst out of range for %s", ErrInvalidRateLimit, backend)
+		}
+	}
+
+	return limits, nil
+}
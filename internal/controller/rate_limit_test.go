@@ -0,0 +1,184 @@
+/*
+Copyright (c) Tobias Schäfer. All rights reserved.
+Licensed under the MIT License, see LICENSE file in the project root for details.
+*/
+package controller
+
+import (
+	"math"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/tschaefer/finch/internal/config"
+	"github.com/tschaefer/finch/internal/model"
+)
+
+func Test_RateLimiterRefillsTokens(t *testing.T) {
+	limiter := newRateLimiter()
+	limit := model.RateLimit{Rate: 2, Burst: 3}
+	now := time.Now()
+
+	for range 3 {
+		_, ok := limiter.take("rid/loki", limit, now)
+		assert.True(t, ok, "take token within burst")
+	}
+
+	retry, ok := limiter.take("rid/loki", limit, now)
+	assert.False(t, ok, "take token beyond burst")
+	assert.Equal(t, 500*time.Millisecond, retry, "retry after")
+
+	_, ok = limiter.take("rid/loki", limit, now.Add(500*time.Millisecond))
+	assert.True(t, ok, "take refilled token")
+
+	_, ok = limiter.take("rid/mimir", limit, now)
+	assert.True(t, ok, "buckets are per key")
+
+	_, ok = limiter.take("rid/loki", model.RateLimit{}, now)
+	assert.True(t, ok, "zero rate is unlimited")
+}
+
+func Test_AuthorizeAgentRequestEnforcesRateLimits(t *testing.T) {
+	m := newModel(t)
+	ctrl := New(m, config.NewFromData(&config.Data{
+		Secret:     cfg.Secret(),
+		Id:         "test-id",
+		RateLimits: map[string]config.RateLimit{"loki": {Rate: 1, Burst: 1}},
+	}, ""))
+
+	limited := &model.Agent{Hostname: "limited-host", ResourceId: "rid:test:limited"}
+	_, err := m.CreateAgent(limited)
+	assert.NoError(t, err, "create agent")
+
+	override := &model.Agent{
+		Hostname:   "override-host",
+		ResourceId: "rid:test:override",
+		RateLimits: model.RateLimits{"loki": {Rate: 100, Burst: 5}},
+	}
+	_, err = m.CreateAgent(override)
+	assert.NoError(t, err, "create agent")
+
+	push := ForwardedRequest{Uri: "/loki/loki/api/v1/push", Method: http.MethodPost}
+
+	token, _, err := ctrl.GenerateAgentToken(limited.ResourceId, time.Hour, testActor, TokenPurposeConfig)
+	assert.NoError(t, err, "generate token")
+
+	_, err = ctrl.AuthorizeAgentRequest(token, push)
+	assert.NoError(t, err, "first request within stack default")
+
+	_, err = ctrl.AuthorizeAgentRequest(token, push)
+	assert.ErrorIs(t, err, ErrRateLimited, "second request exceeds stack default")
+
+	var limitErr *RateLimitError
+	assert.ErrorAs(t, err, &limitErr, "rate limit error")
+	assert.Equal(t, BackendLoki, limitErr.Backend, "limited backend")
+	assert.Greater(t, limitErr.RetryAfter, time.Duration(0), "retry after")
+
+	token, _, err = ctrl.GenerateAgentToken(override.ResourceId, time.Hour, testActor, TokenPurposeConfig)
+	assert.NoError(t, err, "generate token")
+
+	for range 5 {
+		_, err = ctrl.AuthorizeAgentRequest(token, push)
+		assert.NoError(t, err, "agent override takes precedence")
+	}
+}
+
+func Test_UpdateAgentReturnsError_InvalidRateLimits(t *testing.T) {
+	m := newModel(t)
+	ctrl := New(m, cfg)
+
+	rid, err := ctrl.RegisterAgent(&Agent{
+		Hostname:   "test-host",
+		Node:       "unix",
+		LogSources: []string{"journal://"},
+	}, testActor)
+	assert.NoError(t, err, "register agent")
+
+	err = ctrl.UpdateAgent(rid, 0, &Agent{RateLimits: model.RateLimits{"loki": {Rate: -1}}}, testActor, "rate_limits")
+	assert.ErrorIs(t, err, ErrInvalidRateLimit, "negative rate")
+
+	err = ctrl.UpdateAgent(rid, 0, &Agent{RateLimits: model.RateLimits{"grafana": {Rate: 1}}}, testActor, "rate_limits")
+	assert.ErrorIs(t, err, ErrInvalidRateLimit, "unknown backend")
+
+	err = ctrl.UpdateAgent(rid, 0, &Agent{RateLimits: model.RateLimits{"loki": {Rate: math.NaN()}}}, testActor, "rate_limits")
+	assert.ErrorIs(t, err, ErrInvalidRateLimit, "NaN rate")
+
+	err = ctrl.UpdateAgent(rid, 0, &Agent{RateLimits: model.RateLimits{"loki": {Rate: math.Inf(1)}}}, testActor, "rate_limits")
+	assert.ErrorIs(t, err, ErrInvalidRateLimit, "infinite rate")
+
+	err = ctrl.UpdateAgent(rid, 0, &Agent{RateLimits: model.RateLimits{"loki": {Rate: 1, Burst: math.MaxInt32 + 1}}}, testActor, "rate_limits")
+	assert.ErrorIs(t, err, ErrInvalidRateLimit, "burst out of range")
+
+	err = ctrl.UpdateAgent(rid, 0, &Agent{RateLimits: model.RateLimits{"mimir": {Rate: 5, Burst: 10}}}, testActor, "rate_limits")
+	assert.NoError(t, err, "valid rate limits")
+
+	agent, err := ctrl.GetAgent(rid)
+	assert.NoError(t, err, "get agent")
+	assert.Equal(t, model.RateLimits{"mimir": {Rate: 5, Burst: 10}}, agent.RateLimits, "stored rate limits")
+}
+
+func Test_EnrollAgentIgnoresRateLimitFacts(t *testing.T) {
+	m := newModel(t)
+	ctrl := New(m, config.NewFromData(&config.Data{
+		Secret:     cfg.Secret(),
+		Id:         "test-id",
+		RateLimits: map[string]config.RateLimit{"loki": {Rate: 1, Burst: 1}},
+	}, ""))
+
+	token, _, err := ctrl.CreateEnrollmentToken(EnrollmentTokenSpec{LogSources: []string{"journal://"}}, testActor)
+	assert.NoError(t, err, "create enrollment token")
+
+	enrollment, err := ctrl.EnrollAgent(token, &Agent{
+		Hostname:   "hostile-host",
+		Node:       "unix",
+		RateLimits: model.RateLimits{"loki": {Rate: 0}},
+	}, "192.0.2.1")
+	assert.NoError(t, err, "enroll agent with rate limit facts")
+
+	agent, err := ctrl.GetAgent(enrollment.ResourceId)
+	assert.NoError(t, err, "get enrolled agent")
+	assert.Empty(t, agent.RateLimits, "rate limit facts dropped")
+
+	agentToken, _, err := ctrl.GenerateAgentToken(enrollment.ResourceId, time.Hour, testActor, TokenPurposeConfig)
+	assert.NoError(t, err, "generate token")
+
+	push := ForwardedRequest{Uri: "/loki/loki/api/v1/push", Method: http.MethodPost}
+	_, err = ctrl.AuthorizeAgentRequest(agentToken, push)
+	assert.NoError(t, err, "first request within stack default")
+	_, err = ctrl.AuthorizeAgentRequest(agentToken, push)
+	assert.ErrorIs(t, err, ErrRateLimited, "stack default enforced")
+}
+
+func Test_EffectiveRateLimitPrefersAgentOverride(t *testing.T) {
+	ctrl := New(newModel(t), config.NewFromData(&config.Data{
+		Secret:     cfg.Secret(),
+		Id:         "test-id",
+		RateLimits: map[string]config.RateLimit{"loki": {Rate: 1, Burst: 1}},
+	}, ""))
+
+	agent := &model.Agent{}
+	assert.Equal(t, model.RateLimit{Rate: 1, Burst: 1}, ctrl.effectiveRateLimit(agent, BackendLoki), "stack default without override")
+
+	agent.RateLimits = model.RateLimits{"loki": {Rate: 0}}
+	assert.Equal(t, model.RateLimit{}, ctrl.effectiveRateLimit(agent, BackendLoki), "zero rate exempts agent")
+	for range 3 {
+		assert.NoError(t, ctrl.limitAgentRequest(agent, BackendLoki), "exempt agent not limited")
+	}
+
+	agent.RateLimits = model.RateLimits{"loki": {Rate: 10, Burst: 50}}
+	assert.Equal(t, model.RateLimit{Rate: 10, Burst: 50}, ctrl.effectiveRateLimit(agent, BackendLoki), "agent override")
+}
+
+func Test_RateLimiterClampsRetryAfter(t *testing.T) {
+	limiter := newRateLimiter()
+	limit := model.RateLimit{Rate: 1e-300, Burst: 1}
+	now := time.Now()
+
+	_, ok := limiter.take("agent/loki", limit, now)
+	assert.True(t, ok, "first request within burst")
+
+	retry, ok := limiter.take("agent/loki", limit, now)
+	assert.False(t, ok, "second request limited")
+	assert.Equal(t, rateLimitMaxRetryAfter, retry, "retry after clamped")
+}
@@ -0,0 +1,121 @@
+/*
+Copyright (c) Tobias Schäfer. All rights reserved.
+Licensed under the MIT License, see LICENSE file in the project root for details.
+*/
+package controller
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"slices"
+	"time"
+
+	"github.com/tschaefer/finch/internal/keyring"
+	"github.com/tschaefer/finch/internal/model"
+)
+
+const DefaultSigningKeyReloadInterval = time.Minute
+
+var (
+	ErrSigningKeyNotFound = errors.New("signing key not found")
+	ErrSigningKeyActive   = errors.New("signing key is active")
+	ErrSigningKeyInUse    = errors.New("signing key is still in use")
+)
+
+func (c *Controller) LoadSigningKeys() error {
+	return c.keys.Load()
+}
+
+func (c *Controller) JWKS() keyring.JSONWebKeySet {
+	return c.keys.JWKS()
+}
+
+func (c *Controller) ListSigningKeys() []keyring.Key {
+	slog.Debug("List Signing Keys")
+
+	return c.keys.Keys()
+}
+
+func (c *Controller) RotateSigningKey(actor Actor) (*keyring.Key, error) {
+	slog.Debug("Rotate Signing Key", "actor", actor.Name)
+
+	key, err := c.keys.Rotate()
+	if err != nil {
+		return nil, err
+	}
+
+	c.audit(actor, AuditActionSigningKeyRotate, "", map[string]model.AuditChange{
+		"kid":       {After: key.Id},
+		"algorithm": {After: key.Algorithm},
+	})
+	return key, nil
+}
+
+func (c *Controller) RetireSigningKey(kid string, force bool, actor Actor) error {
+	slog.Debug("Retire Signing Key", "kid", kid, "force", force, "actor", actor.Name)
+
+	keys := c.keys.Keys()
+	idx := slices.IndexFunc(keys, func(key keyring.Key) bool { return key.Id == kid })
+	if idx < 0 {
+		return ErrSigningKeyNotFound
+	}
+	if keys[idx].State == keyring.KeyStateActive {
+		return ErrSigningKeyActive
+	}
+
+	kids := []string{kid}
+	if kid == keyring.ConfigKeyId {
+		kids = append(kids, "")
+	}
+
+	inUse, err := c.model.CountUnexpiredTokens(kids, time.Now())
+	if err != nil {
+		return err
+	}
+	if inUse > 0 && !force {
+		return fmt.Errorf("%w: %d unexpired tokens", ErrSigningKeyInUse, inUse)
+	}
+
+	if err := c.keys.Retire(kid); err != nil {
+		switch {
+		case errors.Is(err, keyring.ErrUnknownKey):
+			return ErrSigningKeyNotFound
+		case errors.Is(err, keyring.ErrActiveKey):
+			return ErrSigningKeyActive
+		}
+		return err
+	}
+	c.purgeAuthCache()
+
+	c.audit(actor, AuditActionSigningKeyRetire, "", map[string]model.AuditChange{
+		"kid":    {Before: kid},
+		"tokens": {Before: inUse},
+	})
+	return nil
+}
+
+func (c *Controller) RunSigningKeyReloader(ctx context.Context, interval time.Duration) {
+	if interval <= 0 {
+		interval = DefaultSigningKeyReloadInterval
+	}
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			before := len(c.keys.Keys())
+			if err := c.keys.Load(); err != nil {
+				slog.Error("Failed to reload signing keys", "error", err)
+			}
+			if len(c.keys.Keys()) < before {
+				c.purgeAuthCache()
+			}
+		}
+	}
+}
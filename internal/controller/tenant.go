@@ -0,0 +1,117 @@
+/*
+Copyright (c) Tobias Schäfer. All rights reserved.
+Licensed under the MIT License, see LICENSE file in the project root for details.
+*/
+package controller
+
+import (
+	"errors"
+	"fmt"
+	"log/slog"
+	"regexp"
+
+	"github.com/tschaefer/finch/internal/model"
+)
+
+const DefaultTenant = "default"
+
+const tenantScopePrefix = "tenant:"
+
+var (
+	ErrTenantNotFound      = errors.New("tenant not found")
+	ErrTenantAlreadyExists = errors.New("tenant already exists")
+	ErrInvalidTenantName   = errors.New("invalid tenant name")
+	ErrTenantInUse         = errors.New("tenant has agents assigned")
+	ErrDefaultTenant       = errors.New("default tenant cannot be deleted")
+)
+
+var tenantNamePattern = regexp.MustCompile(`^[a-z0-9][a-z0-9_-]{0,62}$`)
+
+func (c *Controller) CreateTenant(name, description string, actor Actor) (*model.Tenant, error) {
+	slog.Debug("Create Tenant", "name", name, "actor", actor.Name)
+
+	if !tenantNamePattern.MatchString(name) {
+		return nil, fmt.Errorf("%w: %s", ErrInvalidTenantName, name)
+	}
+	if name == DefaultTenant {
+		return nil, ErrTenantAlreadyExists
+	}
+
+	exists, err := c.model.GetTenant(name)
+	if err != nil && !errors.Is(err, model.ErrTenantNotFound) {
+		return nil, err
+	}
+	if exists != nil {
+		return nil, ErrTenantAlreadyExists
+	}
+
+	tenant, err := c.model.CreateTenant(&model.Tenant{Name: name, Description: description})
+	if err != nil {
+		return nil, err
+	}
+
+	c.audit(actor, AuditActionTenantCreate, "", map[string]model.AuditChange{
+		"tenant":      {After: name},
+		"description": {After: description},
+	})
+	return tenant, nil
+}
+
+func (c *Controller) ListTenants() ([]model.Tenant, error) {
+	slog.Debug("List Tenants")
+
+	tenants := []model.Tenant{}
+	if _, err := c.model.ListTenants(&tenants); err != nil {
+		return nil, err
+	}
+
+	return append([]model.Tenant{{Name: DefaultTenant}}, tenants...), nil
+}
+
+func (c *Controller) DeleteTenant(name string, actor Actor) error {
+	slog.Debug("Delete Tenant", "name", name, "actor", actor.Name)
+
+	if name == DefaultTenant {
+		return ErrDefaultTenant
+	}
+
+	tenant, err := c.model.GetTenant(name)
+	if err != nil {
+		if errors.Is(err, model.ErrTenantNotFound) {
+			return ErrTenantNotFound
+		}
+		return err
+	}
+
+	agents, err := c.model.CountTenantAgents(name)
+	if err != nil {
+		return err
+	}
+	if agents > 0 {
+		return fmt.Errorf("%w: %d agents", ErrTenantInUse, agents)
+	}
+
+	if err := c.model.DeleteTenant(tenant); err != nil {
+		return err
+	}
+
+	c.audit(actor, AuditActionTenantDelete, "", map[string]model.AuditChange{
+		"tenant": {Before: name},
+	})
+	return nil
+}
+
+func (c *Controller) resolveTenant(name string) (string, error) {
+	if name == "" || name == DefaultTenant {
+		return DefaultTenant, nil
+	}
+
+	if _, err := c.model.GetTenant(name); err != nil {
+		if errors.Is(err, model.ErrTenantNotFound) {
+			return "", fmt.Errorf("%w: %s", ErrTenantNotFound, name)
+		}
+		return "", err
+	}
+
+	return name, nil
+}
@@ -5,68 +5,270 @@ Licensed under the MIT License, see LICENSE file in the project root for details
 package controller
 
 import (
+	"errors"
 	"fmt"
 	"log/slog"
+	"math"
 	"time"
 
 	"github.com/golang-jwt/jwt/v5"
+	"github.com/google/uuid"
+	"github.com/tschaefer/finch/internal/metrics"
 	"github.com/tschaefer/finch/internal/model"
 )
 
 const defaultTokenExpiration = 365 * 24 * time.Hour
 
-func (c *Controller) GenerateAgentToken(resourceId string, expiration time.Duration) (string, time.Time, error) {
-	slog.Debug("Generating agent token", "resourceId", resourceId, "expiration", expiration)
+const (
+	TokenPurposeConfig    = "config"
+	TokenPurposeDashboard = "dashboard"
+)
+
+var (
+	ErrInvalidRevocationTime = errors.New("revocation time must not be in the future")
+)
+
+type AgentClaims struct {
+	ResourceId string
+	Hostname   string
+	Tenant     string
+	Jti        string
+	IssuedAt   time.Time
+	ExpiresAt  time.Time
+}
+
+type AgentToken struct {
+	Jti        string
+	ResourceId string
+	IssuedAt   time.Time
+	ExpiresAt  time.Time
+	Issuer     string
+	Purpose    string
+	Revoked    bool
+}
+
+func (c *Controller) GenerateAgentToken(resourceId string, expiration time.Duration, actor Actor, purpose string) (string, time.Time, error) {
+	token, tokenString, err := c.issueAgentToken(resourceId, expiration, actor, purpose)
+	if err != nil {
+		return "", time.Time{}, err
+	}
+
+	return tokenString, token.ExpiresAt, nil
+}
+
+func (c *Controller) issueAgentToken(resourceId string, expiration time.Duration, actor Actor, purpose string) (*model.AgentToken, string, error) {
+	slog.Debug("Generating agent token", "resourceId", resourceId, "expiration", expiration, "issuer", actor.Name, "purpose", purpose)
 
 	if expiration == 0 {
 		expiration = defaultTokenExpiration
 	}
 
-	now := time.Now()
+	now := time.Now().Truncate(time.Microsecond)
 	expiresAt := now.Add(expiration)
+	jti := uuid.New().String()
 	claims := jwt.MapClaims{
 		"iss": "finch",
 		"sub": "agent",
 		"rid": resourceId,
-		"iat": now.Unix(),
+		"jti": jti,
+		"iat": float64(now.UnixMicro()) / 1e6,
 		"exp": expiresAt.Unix(),
 	}
 
-	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
-	tokenString, err := token.SignedString([]byte(c.config.Secret()))
-	return tokenString, expiresAt, err
+	tokenString, kid, err := c.keys.Sign(claims)
+	if err != nil {
+		return nil, "", err
+	}
+
+	issued, err := c.model.CreateAgentToken(&model.AgentToken{
+		Jti:        jti,
+		ResourceId: resourceId,
+		IssuedAt:   now.UTC(),
+		ExpiresAt:  time.Unix(expiresAt.Unix(), 0).UTC(),
+		Issuer:     actor.Name,
+		Purpose:    purpose,
+		Kid:        kid,
+	})
+	if err != nil {
+		return nil, "", err
+	}
+
+	metrics.TokensIssued.WithLabelValues("agent", purpose).Inc()
+	c.audit(actor, AuditActionAgentTokenIssue, resourceId, map[string]model.AuditChange{
+		"jti":        {After: jti},
+		"purpose":    {After: purpose},
+		"expires_at": {After: issued.ExpiresAt},
+	})
+	return issued, tokenString, nil
 }
 
 func (c *Controller) ValidateAgentToken(tokenString string) error {
-	token, err := jwt.Parse(tokenString, func(token *jwt.Token) (any, error) {
-		if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
-			return nil, fmt.Errorf("unexpected signing method: %v", token.Header["alg"])
-		}
-		return []byte(c.config.Secret()), nil
-	})
+	_, err := c.AuthenticateAgentToken(tokenString)
+	return err
+}
 
+func (c *Controller) AuthenticateAgentToken(tokenString string) (*AgentClaims, error) {
+	agent, claims, err := c.authenticateAgentToken(tokenString)
 	if err != nil {
-		return fmt.Errorf("failed to parse token: %w", err)
+		return nil, err
+	}
+
+	c.markAgentSeen(agent.ResourceId)
+	return claims, nil
+}
+
+func (c *Controller) authenticateAgentToken(tokenString string) (*model.Agent, *AgentClaims, error) {
+	token, err := jwt.Parse(tokenString, c.keys.Keyfunc)
+
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to parse token: %w", err)
 	}
 
 	if claims, ok := token.Claims.(jwt.MapClaims); ok && token.Valid {
 		if claims["iss"] != "finch" || claims["sub"] != "agent" {
-			return fmt.Errorf("invalid token claims")
+			return nil, nil, fmt.Errorf("invalid token claims")
 		}
 
 		resourceId, ok := claims["rid"].(string)
 		if !ok {
-			return fmt.Errorf("missing rid claim")
+			return nil, nil, fmt.Errorf("missing rid claim")
 		}
 
 		agent := &model.Agent{ResourceId: resourceId}
 		_, err := c.model.GetAgent(agent)
 		if err != nil {
-			return fmt.Errorf("unknown agent: %s", resourceId)
+			return nil, nil, fmt.Errorf("unknown agent: %s", resourceId)
+		}
+
+		if !agent.Active {
+			return nil, nil, fmt.Errorf("%w: %s", ErrAgentSuspended, resourceId)
+		}
+
+		issuedAt, hasIssuedAt := issuedAtClaim(claims)
+		if agent.TokensRevoked != nil {
+			if !hasIssuedAt {
+				return nil, nil, fmt.Errorf("missing iat claim")
+			}
+			if issuedAt.Before(*agent.TokensRevoked) {
+				return nil, nil, fmt.Errorf("revoked token for agent: %s", resourceId)
+			}
+		}
+
+		agentClaims := &AgentClaims{
+			ResourceId: resourceId,
+			Hostname:   agent.Hostname,
+			Tenant:     agent.Tenant,
+		}
+		if jti, ok := claims["jti"].(string); ok {
+			agentClaims.Jti = jti
+		}
+		if hasIssuedAt {
+			agentClaims.IssuedAt = issuedAt
+		}
+		if expiresAt, err := claims.GetExpirationTime(); err == nil && expiresAt != nil {
+			agentClaims.ExpiresAt = expiresAt.Time
+		}
+
+		return agent, agentClaims, nil
+	}
+
+	return nil, nil, fmt.Errorf("invalid token")
+}
+
+func issuedAtClaim(claims jwt.MapClaims) (time.Time, bool) {
+	iat, ok := claims["iat"].(float64)
+	if !ok {
+		return time.Time{}, false
+	}
+
+	return time.UnixMicro(int64(math.Round(iat * 1e6))), true
+}
+
+func (c *Controller) RevokeAgentTokens(rid string, before time.Time, actor Actor) (time.Time, error) {
+	slog.Debug("Revoking agent tokens", "rid", rid, "before", before, "actor", actor.Name)
+
+	now := time.Now()
+	if before.IsZero() {
+		before = now
+	}
+	if before.After(now) {
+		return time.Time{}, ErrInvalidRevocationTime
+	}
+	before = before.Truncate(time.Microsecond)
+
+	agent, err := c.model.GetAgent(&model.Agent{ResourceId: rid})
+	if err != nil {
+		if errors.Is(err, model.ErrAgentNotFound) {
+			return time.Time{}, ErrAgentNotFound
+		}
+		return time.Time{}, err
+	}
+
+	previous := *agent
+	agent.TokensRevoked = &before
+	if _, err := c.model.UpdateAgent(agent); err != nil {
+		return time.Time{}, agentWriteError(err)
+	}
+
+	c.audit(actor, AuditActionAgentTokensRevoke, rid, model.AgentDiff(&previous, agent))
+	return before, nil
+}
+
+func (c *Controller) ListAgentTokens(rid string, expiringWithin time.Duration) ([]AgentToken, error) {
+	slog.Debug("List Agent Tokens", "rid", rid, "expiringWithin", expiringWithin)
+
+	agents := make(map[string]*model.Agent)
+	if rid != "" {
+		agent, err := c.model.GetAgent(&model.Agent{ResourceId: rid})
+		if err != nil {
+			if errors.Is(err, model.ErrAgentNotFound) {
+				return nil, ErrAgentNotFound
+			}
+			return nil, err
+		}
+		agents[rid] = agent
+	}
+
+	var expiresAfter, expiresBefore *time.Time
+	if expiringWithin > 0 {
+		now := time.Now()
+		until := now.Add(expiringWithin)
+		expiresAfter = &now
+		expiresBefore = &until
+	}
+
+	tokens := []model.AgentToken{}
+	_, err := c.model.ListAgentTokens(&tokens, rid, expiresAfter, expiresBefore)
+	if err != nil {
+		return nil, err
+	}
+
+	list := make([]AgentToken, 0, len(tokens))
+	for _, token := range tokens {
+		agent, ok := agents[token.ResourceId]
+		if !ok {
+			agent, err = c.model.GetAgent(&model.Agent{ResourceId: token.ResourceId})
+			if err != nil && !errors.Is(err, model.ErrAgentNotFound) {
+				return nil, err
+			}
+			agents[token.ResourceId] = agent
+		}
+
+		revoked := agent == nil
+		if agent != nil && agent.TokensRevoked != nil {
+			revoked = token.IssuedAt.Before(*agent.TokensRevoked)
 		}
 
-		return nil
+		list = append(list, AgentToken{
+			Jti:        token.Jti,
+			ResourceId: token.ResourceId,
+			IssuedAt:   token.IssuedAt,
+			ExpiresAt:  token.ExpiresAt,
+			Issuer:     token.Issuer,
+			Purpose:    token.Purpose,
+			Revoked:    revoked,
+		})
 	}
 
-	return fmt.Errorf("invalid token")
+	return list, nil
 }
@@ -10,6 +10,7 @@ import (
 
 	"github.com/golang-jwt/jwt/v5"
 	"github.com/stretchr/testify/assert"
+	"github.com/tschaefer/finch/internal/config"
 	"github.com/tschaefer/finch/internal/model"
 )
 
@@ -19,7 +20,7 @@ func Test_GenerateAgentTokenReturnsValidToken(t *testing.T) {
 	assert.NotNil(t, ctrl, "create controller")
 
 	resourceId := "rid:finch:test-id:agent:abc-123"
-	tokenString, expiresAt, err := ctrl.GenerateAgentToken(resourceId, 0)
+	tokenString, expiresAt, err := ctrl.GenerateAgentToken(resourceId, 0, testActor, TokenPurposeConfig)
 
 	assert.NoError(t, err, "generate token")
 	assert.NotEmpty(t, tokenString, "token string should not be empty")
@@ -32,7 +33,7 @@ func Test_GenerateAgentTokenUsesDefaultExpiration(t *testing.T) {
 	assert.NotNil(t, ctrl, "create controller")
 
 	resourceId := "rid:finch:test-id:agent:abc-123"
-	_, expiresAt, err := ctrl.GenerateAgentToken(resourceId, 0)
+	_, expiresAt, err := ctrl.GenerateAgentToken(resourceId, 0, testActor, TokenPurposeConfig)
 
 	assert.NoError(t, err, "generate token")
 
@@ -48,7 +49,7 @@ func Test_GenerateAgentTokenUsesCustomExpiration(t *testing.T) {
 
 	resourceId := "rid:finch:test-id:agent:abc-123"
 	customExpiration := 24 * time.Hour
-	_, expiresAt, err := ctrl.GenerateAgentToken(resourceId, customExpiration)
+	_, expiresAt, err := ctrl.GenerateAgentToken(resourceId, customExpiration, testActor, TokenPurposeConfig)
 
 	assert.NoError(t, err, "generate token")
 
@@ -63,7 +64,7 @@ func Test_GenerateAgentTokenContainsCorrectClaims(t *testing.T) {
 	assert.NotNil(t, ctrl, "create controller")
 
 	resourceId := "rid:finch:test-id:agent:abc-123"
-	tokenString, expiresAt, err := ctrl.GenerateAgentToken(resourceId, 1*time.Hour)
+	tokenString, expiresAt, err := ctrl.GenerateAgentToken(resourceId, 1*time.Hour, testActor, TokenPurposeConfig)
 
 	assert.NoError(t, err, "generate token")
 
@@ -93,7 +94,7 @@ func Test_GenerateAgentTokenSignedWithCorrectSecret(t *testing.T) {
 	assert.NotNil(t, ctrl, "create controller")
 
 	resourceId := "rid:finch:test-id:agent:abc-123"
-	tokenString, _, err := ctrl.GenerateAgentToken(resourceId, 1*time.Hour)
+	tokenString, _, err := ctrl.GenerateAgentToken(resourceId, 1*time.Hour, testActor, TokenPurposeConfig)
 
 	assert.NoError(t, err, "generate token")
 
@@ -116,7 +117,7 @@ func Test_GenerateAgentTokenUsesHS256Algorithm(t *testing.T) {
 	assert.NotNil(t, ctrl, "create controller")
 
 	resourceId := "rid:finch:test-id:agent:abc-123"
-	tokenString, _, err := ctrl.GenerateAgentToken(resourceId, 1*time.Hour)
+	tokenString, _, err := ctrl.GenerateAgentToken(resourceId, 1*time.Hour, testActor, TokenPurposeConfig)
 
 	assert.NoError(t, err, "generate token")
 
@@ -143,7 +144,7 @@ func Test_ValidateAgentTokenSucceeds_WithValidToken(t *testing.T) {
 	_, err := m.CreateAgent(agent)
 	assert.NoError(t, err, "create agent")
 
-	tokenString, _, err := ctrl.GenerateAgentToken(agent.ResourceId, 1*time.Hour)
+	tokenString, _, err := ctrl.GenerateAgentToken(agent.ResourceId, 1*time.Hour, testActor, TokenPurposeConfig)
 	assert.NoError(t, err, "generate token")
 
 	err = ctrl.ValidateAgentToken(tokenString)
@@ -162,7 +163,7 @@ func Test_ValidateAgentTokenFails_WithExpiredToken(t *testing.T) {
 	_, err := m.CreateAgent(agent)
 	assert.NoError(t, err, "create agent")
 
-	tokenString, _, err := ctrl.GenerateAgentToken(agent.ResourceId, -1*time.Hour)
+	tokenString, _, err := ctrl.GenerateAgentToken(agent.ResourceId, -1*time.Hour, testActor, TokenPurposeConfig)
 	assert.NoError(t, err, "generate token")
 
 	err = ctrl.ValidateAgentToken(tokenString)
@@ -274,9 +275,276 @@ func Test_ValidateAgentTokenFails_WithUnknownAgent(t *testing.T) {
 	ctrl := New(m, cfg)
 	assert.NotNil(t, ctrl, "create controller")
 
-	tokenString, _, err := ctrl.GenerateAgentToken("rid:unknown:999", 1*time.Hour)
+	tokenString, _, err := ctrl.GenerateAgentToken("rid:unknown:999", 1*time.Hour, testActor, TokenPurposeConfig)
 	assert.NoError(t, err, "generate token")
 
 	err = ctrl.ValidateAgentToken(tokenString)
 	assert.Error(t, err, "token for unknown agent should fail")
 }
+
+func Test_ValidateAgentTokenRecordsLastSeen(t *testing.T) {
+	m := newModel(t)
+	ctrl := New(m, cfg)
+	assert.NotNil(t, ctrl, "create controller")
+
+	agent := &model.Agent{
+		Hostname:   "test-host",
+		ResourceId: "rid:test:123",
+	}
+	_, err := m.CreateAgent(agent)
+	assert.NoError(t, err, "create agent")
+
+	tokenString, _, err := ctrl.GenerateAgentToken(agent.ResourceId, 1*time.Hour, testActor, TokenPurposeConfig)
+	assert.NoError(t, err, "generate token")
+
+	err = ctrl.ValidateAgentToken(tokenString)
+	assert.NoError(t, err, "validate token")
+
+	pending, err := ctrl.GetAgent(agent.ResourceId)
+	assert.NoError(t, err, "get agent")
+	assert.NotNil(t, pending.LastSeen, "pending last seen")
+
+	err = ctrl.FlushLastSeen()
+	assert.NoError(t, err, "flush last seen")
+
+	stored, err := m.GetAgent(&model.Agent{ResourceId: agent.ResourceId})
+	assert.NoError(t, err, "get stored agent")
+	assert.NotNil(t, stored.LastSeen, "stored last seen")
+}
+
+func Test_ValidateAgentTokenFails_WithRevokedToken(t *testing.T) {
+	m := newModel(t)
+	ctrl := New(m, cfg)
+	assert.NotNil(t, ctrl, "create controller")
+
+	agent := &model.Agent{
+		Hostname:   "test-host",
+		ResourceId: "rid:test:123",
+	}
+	_, err := m.CreateAgent(agent)
+	assert.NoError(t, err, "create agent")
+
+	issued := time.Now().Add(-1 * time.Hour)
+	claims := jwt.MapClaims{
+		"iss": "finch",
+		"sub": "agent",
+		"rid": agent.ResourceId,
+		"iat": issued.Unix(),
+		"exp": issued.Add(2 * time.Hour).Unix(),
+	}
+	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
+	oldToken, _ := token.SignedString([]byte(cfg.Secret()))
+
+	err = ctrl.ValidateAgentToken(oldToken)
+	assert.NoError(t, err, "validate token before revocation")
+
+	_, err = ctrl.RevokeAgentTokens(agent.ResourceId, time.Time{}, testActor)
+	assert.NoError(t, err, "revoke agent tokens")
+
+	err = ctrl.ValidateAgentToken(oldToken)
+	assert.Error(t, err, "revoked token should fail validation")
+
+	newToken, _, err := ctrl.GenerateAgentToken(agent.ResourceId, 1*time.Hour, testActor, TokenPurposeConfig)
+	assert.NoError(t, err, "generate token")
+
+	err = ctrl.ValidateAgentToken(newToken)
+	assert.NoError(t, err, "token issued after revocation should pass validation")
+}
+
+func Test_ValidateAgentTokenFails_WithTokenIssuedInRevocationSecond(t *testing.T) {
+	m := newModel(t)
+	ctrl := New(m, cfg)
+	assert.NotNil(t, ctrl, "create controller")
+
+	agent := &model.Agent{
+		Hostname:   "test-host",
+		ResourceId: "rid:test:123",
+	}
+	_, err := m.CreateAgent(agent)
+	assert.NoError(t, err, "create agent")
+
+	second := time.Now().Add(-1 * time.Hour).Truncate(time.Second)
+	sign := func(issued time.Time) string {
+		claims := jwt.MapClaims{
+			"iss": "finch",
+			"sub": "agent",
+			"rid": agent.ResourceId,
+			"iat": float64(issued.UnixMicro()) / 1e6,
+			"exp": issued.Add(2 * time.Hour).Unix(),
+		}
+		token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
+		tokenString, _ := token.SignedString([]byte(cfg.Secret()))
+		return tokenString
+	}
+	earlier := sign(second.Add(250 * time.Millisecond))
+	later := sign(second.Add(750 * time.Millisecond))
+
+	revoked, err := ctrl.RevokeAgentTokens(agent.ResourceId, second.Add(500*time.Millisecond), testActor)
+	assert.NoError(t, err, "revoke agent tokens")
+	assert.Equal(t, second.Add(500*time.Millisecond), revoked, "revocation keeps sub-second precision")
+
+	err = ctrl.ValidateAgentToken(earlier)
+	assert.Error(t, err, "token issued earlier in the revocation second should fail validation")
+
+	err = ctrl.ValidateAgentToken(later)
+	assert.NoError(t, err, "token issued later in the revocation second should pass validation")
+}
+
+func Test_RevokeAgentTokensReturnsError(t *testing.T) {
+	m := newModel(t)
+	ctrl := New(m, cfg)
+	assert.NotNil(t, ctrl, "create controller")
+
+	_, err := ctrl.RevokeAgentTokens("non-existent-rid", time.Time{}, testActor)
+	assert.ErrorIs(t, err, ErrAgentNotFound, "revoke tokens of non-existent agent")
+
+	_, err = ctrl.RevokeAgentTokens("non-existent-rid", time.Now().Add(1*time.Hour), testActor)
+	assert.ErrorIs(t, err, ErrInvalidRevocationTime, "revoke tokens in the future")
+}
+
+func Test_ListAgentTokensReturnsIssuedTokens(t *testing.T) {
+	m := newModel(t)
+	ctrl := New(m, cfg)
+	assert.NotNil(t, ctrl, "create controller")
+
+	agent := &model.Agent{
+		Hostname:   "test-host",
+		ResourceId: "rid:test:123",
+	}
+	_, err := m.CreateAgent(agent)
+	assert.NoError(t, err, "create agent")
+
+	_, err = ctrl.CreateAgentConfig(agent.ResourceId, testActor)
+	assert.NoError(t, err, "create agent config")
+
+	_, _, err = ctrl.GenerateAgentToken(agent.ResourceId, 24*time.Hour, Actor{Name: "dashboard:admin:session"}, TokenPurposeDashboard)
+	assert.NoError(t, err, "generate token")
+
+	tokens, err := ctrl.ListAgentTokens(agent.ResourceId, 0)
+	assert.NoError(t, err, "list agent tokens")
+	assert.Len(t, tokens, 2, "number of issued tokens")
+	assert.Equal(t, "dashboard:admin:session", tokens[0].Issuer, "issuer of first expiring token")
+	assert.Equal(t, TokenPurposeDashboard, tokens[0].Purpose, "purpose of first expiring token")
+	assert.Equal(t, testActor.Name, tokens[1].Issuer, "issuer of config token")
+	assert.Equal(t, TokenPurposeConfig, tokens[1].Purpose, "purpose of config token")
+	assert.NotEmpty(t, tokens[1].Jti, "token ID")
+	assert.False(t, tokens[0].Revoked, "token not revoked")
+
+	expiring, err := ctrl.ListAgentTokens(agent.ResourceId, 7*24*time.Hour)
+	assert.NoError(t, err, "list expiring agent tokens")
+	assert.Len(t, expiring, 1, "number of expiring tokens")
+
+	_, err = ctrl.RevokeAgentTokens(agent.ResourceId, time.Now().Add(time.Second), testActor)
+	assert.ErrorIs(t, err, ErrInvalidRevocationTime, "revoke tokens in the future")
+
+	_, err = ctrl.RevokeAgentTokens(agent.ResourceId, time.Time{}, testActor)
+	assert.NoError(t, err, "revoke agent tokens")
+
+	tokens, err = ctrl.ListAgentTokens(agent.ResourceId, 0)
+	assert.NoError(t, err, "list revoked agent tokens")
+	for _, token := range tokens {
+		assert.True(t, token.Revoked, "token revoked")
+	}
+
+	_, err = ctrl.ListAgentTokens("non-existent-rid", 0)
+	assert.ErrorIs(t, err, ErrAgentNotFound, "list tokens of non-existent agent")
+}
+
+func Test_TokenLedgerComparesExpiryInUTC(t *testing.T) {
+	local := time.Local
+	time.Local = time.FixedZone("UTC+5", 5*60*60)
+	t.Cleanup(func() { time.Local = local })
+
+	m := newModel(t)
+	ctrl := New(m, cfg)
+	assert.NotNil(t, ctrl, "create controller")
+
+	rid, err := ctrl.RegisterAgent(&Agent{Hostname: "test-host", Node: "unix", LogSources: []string{"journal://"}}, testActor)
+	assert.NoError(t, err, "register agent")
+
+	expired, _, err := ctrl.issueAgentToken(rid, -time.Hour, testActor, TokenPurposeConfig)
+	assert.NoError(t, err, "generate expired token")
+	assert.Equal(t, time.UTC, expired.ExpiresAt.Location(), "expiry stored in UTC")
+	_, _, err = ctrl.GenerateAgentToken(rid, time.Hour, testActor, TokenPurposeConfig)
+	assert.NoError(t, err, "generate token")
+
+	count, err := m.CountUnexpiredTokens([]string{expired.Kid}, time.Now())
+	assert.NoError(t, err, "count unexpired tokens")
+	assert.Equal(t, int64(1), count, "expired token not counted")
+
+	expiring, err := ctrl.ListAgentTokens(rid, 2*time.Hour)
+	assert.NoError(t, err, "list expiring agent tokens")
+	assert.Len(t, expiring, 1, "number of expiring tokens")
+}
+
+func Test_AuthenticateAgentTokenReturnsClaims(t *testing.T) {
+	model := newModel(t)
+
+	ctrl := New(model, cfg)
+	assert.NotNil(t, ctrl, "create controller")
+
+	rid, err := ctrl.RegisterAgent(&Agent{Hostname: "test-host", Node: "unix", LogSources: []string{"journal://"}}, testActor)
+	assert.NoError(t, err, "register agent")
+
+	token, expiresAt, err := ctrl.GenerateAgentToken(rid, time.Hour, testActor, TokenPurposeConfig)
+	assert.NoError(t, err, "generate token")
+
+	claims, err := ctrl.AuthenticateAgentToken(token)
+	assert.NoError(t, err, "authenticate token")
+	assert.Equal(t, rid, claims.ResourceId, "claims resource id")
+	assert.NotEmpty(t, claims.Jti, "claims jti")
+	assert.Equal(t, expiresAt.Unix(), claims.ExpiresAt.Unix(), "claims expiry")
+
+	config, etag, err := ctrl.PullAgentConfig(token, claims)
+	assert.NoError(t, err, "pull agent config")
+	assert.Contains(t, string(config), claims.Jti, "config token id")
+
+	_, again, err := ctrl.PullAgentConfig(token, claims)
+	assert.NoError(t, err, "pull agent config again")
+	assert.Equal(t, etag, again, "stable etag")
+}
+
+func Test_RetireSigningKeyChecksTokenLedger(t *testing.T) {
+	model := newModel(t)
+	signingCfg := config.NewFromData(&config.Data{
+		Secret: "1suNCrW7sWlPbU+YCfdGQI7z3ZMo9Ru2GNV4h69QzaM=",
+		Id:     "test-id",
+	}, t.TempDir())
+	ctrl := New(model, signingCfg)
+	assert.NoError(t, ctrl.LoadSigningKeys(), "load signing keys")
+
+	rid, err := ctrl.RegisterAgent(&Agent{Hostname: "test-host", Node: "unix", LogSources: []string{"journal://"}}, testActor)
+	assert.NoError(t, err, "register agent")
+	token, _, err := ctrl.GenerateAgentToken(rid, time.Hour, testActor, TokenPurposeConfig)
+	assert.NoError(t, err, "generate token")
+
+	key, err := ctrl.RotateSigningKey(testActor)
+	assert.NoError(t, err, "rotate signing key")
+	assert.Len(t, ctrl.ListSigningKeys(), 2, "signing keys")
+	assert.NoError(t, ctrl.ValidateAgentToken(token), "validate token signed by previous key")
+
+	err = ctrl.RetireSigningKey(key.Id, false, testActor)
+	assert.ErrorIs(t, err, ErrSigningKeyActive, "retire active key")
+
+	err = ctrl.RetireSigningKey("config", false, testActor)
+	assert.ErrorIs(t, err, ErrSigningKeyInUse, "retire key with unexpired tokens")
+
+	err = ctrl.RetireSigningKey("config", true, testActor)
+	assert.NoError(t, err, "force retire key")
+	assert.Error(t, ctrl.ValidateAgentToken(token), "validate token signed by retired key")
+
+	err = ctrl.RetireSigningKey("config", false, testActor)
+	assert.ErrorIs(t, err, ErrSigningKeyNotFound, "retire unknown key")
+
+	dashboard, err := ctrl.GenerateDashboardToken(3600, RoleViewer, nil, testActor)
+	assert.NoError(t, err, "generate dashboard token")
+
+	_, err = ctrl.RotateSigningKey(testActor)
+	assert.NoError(t, err, "rotate signing key")
+
+	err = ctrl.RetireSigningKey(key.Id, false, testActor)
+	assert.ErrorIs(t, err, ErrSigningKeyInUse, "retire key with unexpired dashboard sessions")
+
+	_, err = ctrl.ValidateDashboardToken(dashboard.Token)
+	assert.NoError(t, err, "validate dashboard token signed by previous key")
+}
@@ -73,6 +73,10 @@ func New(config *config.Config) (*Database, error) {
 		return nil, err
 	}
 
+	if err := connection.Use(tracingPlugin{}); err != nil {
+		return nil, err
+	}
+
 	return &Database{
 		connection: connection,
 	}, nil
@@ -127,7 +131,7 @@ func (d *Database) Migrate() error {
 		}
 	}
 
-	if err := d.connection.AutoMigrate(&model.Agent{}); err != nil {
+	if err := d.connection.AutoMigrate(&model.Agent{}, &model.AgentToken{}, &model.AuditEvent{}, &model.AgentConfigRevision{}, &model.Collector{}, &model.EnrollmentToken{}, &model.Tenant{}, &model.AgentUsage{}, &model.DashboardSession{}); err != nil {
 		return err
 	}
 
@@ -12,6 +12,10 @@ import (
 	"github.com/stretchr/testify/assert"
 	"github.com/tschaefer/finch/internal/config"
 	"github.com/tschaefer/finch/internal/model"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
 )
 
 func Test_NewReturnsError_InvalidUrlSchema(t *testing.T) {
@@ -82,6 +86,12 @@ func Test_MigrateSucceeds(t *testing.T) {
 		"resource_id",
 		"updated_at",
 		"node",
+		"tokens_revoked",
+		"stale",
+		"ephemeral",
+		"resource_version",
+		"tenant",
+		"rate_limits",
 	}
 
 	assert.Equal(t, len(results), len(columns), "agents table should have correct number of columns")
@@ -183,3 +193,36 @@ func Test_PingReturnsError_WritePermissionDenied(t *testing.T) {
 	err = db.Ping(context.Background())
 	assert.Error(t, err, "ping database with read-only permissions")
 }
+
+func Test_TracingRecordsQuerySpans(t *testing.T) {
+	exporter := tracetest.NewInMemoryExporter()
+	provider := sdktrace.NewTracerProvider(sdktrace.WithSyncer(exporter))
+	previous := otel.GetTracerProvider()
+	otel.SetTracerProvider(provider)
+	t.Cleanup(func() { otel.SetTracerProvider(previous) })
+
+	cfg := config.NewFromData(&config.Data{
+		Database: "sqlite:///:memory:",
+	}, "")
+
+	db, err := New(cfg)
+	assert.NoError(t, err, "new database instance")
+	assert.NoError(t, db.Migrate(), "migrate database")
+
+	var agents []model.Agent
+	err = db.Connection().Find(&agents).Error
+	assert.NoError(t, err, "query without parent span")
+	assert.Empty(t, exporter.GetSpans(), "no spans without parent span")
+
+	ctx, parent := provider.Tracer("test").Start(context.Background(), "parent")
+	err = db.Connection().WithContext(ctx).Find(&agents).Error
+	assert.NoError(t, err, "query with parent span")
+	parent.End()
+
+	spans := exporter.GetSpans()
+	assert.Len(t, spans, 2, "query and parent span")
+	assert.Equal(t, "gorm.query", spans[0].Name, "query span name")
+	assert.Equal(t, parent.SpanContext().SpanID(), spans[0].Parent.SpanID(), "query span parent")
+	assert.Contains(t, spans[0].Attributes, attribute.String("db.collection.name", "agents"), "query span table")
+	assert.Contains(t, spans[0].Attributes, attribute.String("db.system.name", "sqlite"), "query span system")
+}
@@ -0,0 +1,112 @@
+/*
+Copyright (c) Tobias Schäfer. All rights reserved.
+Licensed under the MIT License, see LICENSE file in the project root for details.
+*/
+package database
+
+import (
+	"errors"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+	"gorm.io/gorm"
+)
+
+const (
+	tracerName     = "github.com/tschaefer/finch/internal/database"
+	tracingSpanKey = "finch:tracing_span"
+)
+
+type tracingPlugin struct{}
+
+func (tracingPlugin) Name() string {
+	return "finch:tracing"
+}
+
+func (p tracingPlugin) Initialize(db *gorm.DB) error {
+	callback := db.Callback()
+
+	if err := callback.Create().Before("gorm:create").Register("finch:tracing:before_create", p.before("create")); err != nil {
+		return err
+	}
+	if err := callback.Create().After("gorm:create").Register("finch:tracing:after_create", p.after); err != nil {
+		return err
+	}
+	if err := callback.Query().Before("gorm:query").Register("finch:tracing:before_query", p.before("query")); err != nil {
+		return err
+	}
+	if err := callback.Query().After("gorm:query").Register("finch:tracing:after_query", p.after); err != nil {
+		return err
+	}
+	if err := callback.Update().Before("gorm:update").Register("finch:tracing:before_update", p.before("update")); err != nil {
+		return err
+	}
+	if err := callback.Update().After("gorm:update").Register("finch:tracing:after_update", p.after); err != nil {
+		return err
+	}
+	if err := callback.Delete().Before("gorm:delete").Register("finch:tracing:before_delete", p.before("delete")); err != nil {
+		return err
+	}
+	if err := callback.Delete().After("gorm:delete").Register("finch:tracing:after_delete", p.after); err != nil {
+		return err
+	}
+	if err := callback.Row().Before("gorm:row").Register("finch:tracing:before_row", p.before("row")); err != nil {
+		return err
+	}
+	if err := callback.Row().After("gorm:row").Register("finch:tracing:after_row", p.after); err != nil {
+		return err
+	}
+	if err := callback.Raw().Before("gorm:raw").Register("finch:tracing:before_raw", p.before("raw")); err != nil {
+		return err
+	}
+	if err := callback.Raw().After("gorm:raw").Register("finch:tracing:after_raw", p.after); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (tracingPlugin) before(operation string) func(*gorm.DB) {
+	return func(db *gorm.DB) {
+		ctx := db.Statement.Context
+		if ctx == nil || !trace.SpanContextFromContext(ctx).IsValid() {
+			return
+		}
+
+		_, span := otel.Tracer(tracerName).Start(ctx, "gorm."+operation,
+			trace.WithSpanKind(trace.SpanKindClient),
+			trace.WithAttributes(
+				attribute.String("db.system.name", db.Dialector.Name()),
+				attribute.String("db.operation.name", operation),
+			),
+		)
+		db.InstanceSet(tracingSpanKey, span)
+	}
+}
+
+func (tracingPlugin) after(db *gorm.DB) {
+	value, ok := db.InstanceGet(tracingSpanKey)
+	if !ok {
+		return
+	}
+	span, ok := value.(trace.Span)
+	if !ok {
+		return
+	}
+	defer span.End()
+
+	if db.Statement.Table != "" {
+		span.SetAttributes(attribute.String("db.collection.name", db.Statement.Table))
+	}
+	span.SetAttributes(
+		attribute.String("db.query.text", db.Statement.SQL.String()),
+		attribute.Int64("db.response.returned_rows", db.Statement.RowsAffected),
+	)
+
+	if err := db.Error; err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+}
@@ -16,6 +16,7 @@ import (
 	"time"
 
 	"github.com/tschaefer/finch/internal/config"
+	"github.com/tschaefer/finch/internal/controller"
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/metadata"
@@ -33,6 +34,15 @@ type AuthInterceptor struct {
 	config *config.Config
 }
 
+type contextKey string
+
+type authenticatedStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+const clientCommonNameKey contextKey = "clientCommonName"
+
 func NewAuthInterceptor(cfg *config.Config) *AuthInterceptor {
 	return &AuthInterceptor{
 		config: cfg,
@@ -46,24 +56,46 @@ func (a *AuthInterceptor) Unary() grpc.UnaryServerInterceptor {
 		info *grpc.UnaryServerInfo,
 		handler grpc.UnaryHandler,
 	) (any, error) {
-		if err := a.authenticate(ctx); err != nil {
+		commonName, err := a.authenticate(ctx)
+		if err != nil {
 			return nil, err
 		}
+		ctx = context.WithValue(ctx, clientCommonNameKey, commonName)
 		return handler(ctx, req)
 	}
 }
 
-func (a *AuthInterceptor) authenticate(ctx context.Context) error {
+func (a *AuthInterceptor) Stream() grpc.StreamServerInterceptor {
+	return func(
+		srv any,
+		ss grpc.ServerStream,
+		info *grpc.StreamServerInfo,
+		handler grpc.StreamHandler,
+	) error {
+		commonName, err := a.authenticate(ss.Context())
+		if err != nil {
+			return err
+		}
+		ctx := context.WithValue(ss.Context(), clientCommonNameKey, commonName)
+		return handler(srv, &authenticatedStream{ServerStream: ss, ctx: ctx})
+	}
+}
+
+func (s *authenticatedStream) Context() context.Context {
+	return s.ctx
+}
+
+func (a *AuthInterceptor) authenticate(ctx context.Context) (string, error) {
 	md, ok := metadata.FromIncomingContext(ctx)
 	if !ok {
 		slog.Warn("no metadata in context")
-		return status.Error(codes.InvalidArgument, "no metadata in context")
+		return "", status.Error(codes.InvalidArgument, "no metadata in context")
 	}
 
 	values := md.Get(AuthHeader)
 	if len(values) == 0 {
 		slog.Warn("no client certificate in metadata")
-		return status.Error(codes.Unauthenticated, "permission denied")
+		return "", status.Error(codes.Unauthenticated, "permission denied")
 	}
 
 	certPem := fmt.Sprintf("%s%s%s", PEMHeader, values[0], PEMFooter)
@@ -72,7 +104,7 @@ func (a *AuthInterceptor) authenticate(ctx context.Context) error {
 	x509Cert, err := a.parseCertFromPEM([]byte(certPem))
 	if err != nil {
 		slog.Error("failed to parse client certificate", "error", err)
-		return status.Error(codes.Unauthenticated, "permission denied")
+		return "", status.Error(codes.Unauthenticated, "permission denied")
 	}
 	rid := x509Cert.Subject.CommonName
 	caFile := filepath.Join(caDirPath, fmt.Sprintf("%s.pem", rid))
@@ -80,18 +112,18 @@ func (a *AuthInterceptor) authenticate(ctx context.Context) error {
 	caPem, err := os.ReadFile(caFile)
 	if err != nil {
 		slog.Warn("failed to read CA certificate for client", "rid", rid, "error", err)
-		return status.Error(codes.Unauthenticated, "permission denied")
+		return "", status.Error(codes.Unauthenticated, "permission denied")
 	}
 	valid, err := a.clientCertIsValid([]byte(certPem), caPem)
 	if err != nil {
 		slog.Warn("client certificate is not valid", "rid", rid, "error", err)
-		return status.Error(codes.Unauthenticated, "permission denied")
+		return "", status.Error(codes.Unauthenticated, "permission denied")
 	}
 	if !valid {
-		return status.Error(codes.Unauthenticated, "permission denied")
+		return "", status.Error(codes.Unauthenticated, "permission denied")
 	}
 
-	return nil
+	return rid, nil
 }
 
 func (a *AuthInterceptor) parseCertFromPEM(bytes []byte) (*x509.Certificate, error) {
@@ -134,3 +166,10 @@ func (a *AuthInterceptor) clientCertIsValid(clientPEM, caPEM []byte) (bool, erro
 
 	return true, nil
 }
+
+func actorFromContext(ctx context.Context) controller.Actor {
+	commonName, _ := ctx.Value(clientCommonNameKey).(string)
+	actor := controller.CertificateActor(commonName)
+	actor.Address = remoteAddrFromContext(ctx)
+	return actor
+}
@@ -159,6 +159,7 @@ func TestAuthInterceptorSucceeds(t *testing.T) {
 	called := false
 	handler := func(ctx context.Context, req any) (any, error) {
 		called = true
+		assert.Equal(t, "cert:rid:finchctl:47110815", actorFromContext(ctx).Name)
 		return "ok", nil
 	}
 
@@ -168,6 +169,67 @@ func TestAuthInterceptorSucceeds(t *testing.T) {
 	assert.True(t, called, "handler should have been called")
 }
 
+type fakeServerStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+func (f *fakeServerStream) Context() context.Context {
+	return f.ctx
+}
+
+func TestAuthInterceptorStreamSucceeds(t *testing.T) {
+	ts := setup(t)
+	defer func() {
+		_ = os.RemoveAll(ts.library)
+	}()
+
+	cfg := config.NewFromData(&config.Data{}, ts.library)
+	interceptor := NewAuthInterceptor(cfg)
+	stream := interceptor.Stream()
+
+	md := metadata.Pairs(AuthHeader, ts.clientCertBody)
+	ctx := metadata.NewIncomingContext(context.Background(), md)
+
+	called := false
+	handler := func(srv any, ss grpc.ServerStream) error {
+		called = true
+		assert.Equal(t, "cert:rid:finchctl:47110815", actorFromContext(ss.Context()).Name)
+		return nil
+	}
+
+	err := stream(nil, &fakeServerStream{ctx: ctx}, &grpc.StreamServerInfo{FullMethod: "/test"}, handler)
+	assert.NoError(t, err)
+	assert.True(t, called, "handler should have been called")
+}
+
+func TestAuthInterceptorStreamReturnsError_InvalidCert(t *testing.T) {
+	ts := setup(t)
+	defer func() {
+		_ = os.RemoveAll(ts.library)
+	}()
+
+	cfg := config.NewFromData(&config.Data{}, ts.library)
+	interceptor := NewAuthInterceptor(cfg)
+	stream := interceptor.Stream()
+
+	md := metadata.Pairs(AuthHeader, ts.invalidCertBody)
+	ctx := metadata.NewIncomingContext(context.Background(), md)
+
+	handlerCalled := false
+	handler := func(srv any, ss grpc.ServerStream) error {
+		handlerCalled = true
+		return nil
+	}
+
+	err := stream(nil, &fakeServerStream{ctx: ctx}, &grpc.StreamServerInfo{FullMethod: "/test"}, handler)
+	assert.Error(t, err)
+	st, ok := status.FromError(err)
+	assert.True(t, ok)
+	assert.Equal(t, codes.Unauthenticated, st.Code())
+	assert.False(t, handlerCalled, "handler must not be called on auth failure")
+}
+
 func TestAuthInterceptorReturnsError_MissingMetadata(t *testing.T) {
 	interceptor := NewAuthInterceptor(&config.Config{})
 	unary := interceptor.Unary()
@@ -32,6 +32,19 @@ func (h *HeadersInterceptor) Unary() grpc.UnaryServerInterceptor {
 	}
 }
 
+func (h *HeadersInterceptor) Stream() grpc.StreamServerInterceptor {
+	return func(
+		srv any,
+		ss grpc.ServerStream,
+		info *grpc.StreamServerInfo,
+		handler grpc.StreamHandler,
+	) error {
+		h.setHeaders(ss.Context())
+
+		return handler(srv, ss)
+	}
+}
+
 func (h *HeadersInterceptor) setHeaders(ctx context.Context) {
 	if err := grpc.SetHeader(ctx, metadata.Pairs(
 		"x-finch-commit", version.Commit(),
@@ -7,7 +7,10 @@ package grpc
 import (
 	"context"
 	"log/slog"
+	"time"
 
+	"github.com/tschaefer/finch/internal/metrics"
+	"github.com/tschaefer/finch/internal/tracing"
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/metadata"
@@ -28,42 +31,51 @@ func (l *LoggingInterceptor) Unary() grpc.UnaryServerInterceptor {
 		info *grpc.UnaryServerInfo,
 		handler grpc.UnaryHandler,
 	) (any, error) {
+		start := time.Now()
 		resp, err := handler(ctx, req)
 
-		go l.log(ctx, info, err)
+		l.observe(info.FullMethod, err, time.Since(start))
+		go l.log(ctx, info.FullMethod, err)
 
 		return resp, err
 	}
 }
 
-func (l *LoggingInterceptor) log(ctx context.Context, info *grpc.UnaryServerInfo, err error) {
-	md, _ := metadata.FromIncomingContext(ctx)
+func (l *LoggingInterceptor) Stream() grpc.StreamServerInterceptor {
+	return func(
+		srv any,
+		ss grpc.ServerStream,
+		info *grpc.StreamServerInfo,
+		handler grpc.StreamHandler,
+	) error {
+		start := time.Now()
+		err := handler(srv, ss)
 
-	remoteAddr := ""
-	for _, h := range []string{"x-forwarded-for", "x-real-ip"} {
-		if v := md.Get(h); len(v) > 0 && v[0] != "" {
-			remoteAddr = v[0]
-			break
-		}
-	}
-	if remoteAddr == "" {
-		if p, ok := peer.FromContext(ctx); ok && p.Addr != nil {
-			remoteAddr = p.Addr.String()
-			for i := len(remoteAddr) - 1; i >= 0; i-- {
-				if remoteAddr[i] == ':' {
-					remoteAddr = remoteAddr[:i]
-					break
-				}
-			}
-		}
+		l.observe(info.FullMethod, err, time.Since(start))
+		go l.log(ss.Context(), info.FullMethod, err)
+
+		return err
 	}
+}
+
+func (l *LoggingInterceptor) observe(fullMethod string, err error, elapsed time.Duration) {
+	code := status.Code(err).String()
+
+	metrics.GRPCRequests.WithLabelValues(fullMethod, code).Inc()
+	metrics.GRPCRequestDuration.WithLabelValues(fullMethod, code).Observe(elapsed.Seconds())
+}
+
+func (l *LoggingInterceptor) log(ctx context.Context, fullMethod string, err error) {
+	md, _ := metadata.FromIncomingContext(ctx)
+
+	remoteAddr := remoteAddrFromContext(ctx)
 
 	userAgent := ""
 	if v := md.Get("user-agent"); len(v) > 0 {
 		userAgent = v[0]
 	}
 
-	requestPath := info.FullMethod
+	requestPath := fullMethod
 
 	var code codes.Code
 	var msg string
@@ -86,6 +98,7 @@ func (l *LoggingInterceptor) log(ctx context.Context, info *grpc.UnaryServerInfo
 		slog.String("remote_addr", remoteAddr),
 		slog.String("user_agent", userAgent),
 	}
+	args = append(args, tracing.LogAttrs(ctx)...)
 
 	switch code {
 	case codes.OK:
@@ -100,3 +113,28 @@ func (l *LoggingInterceptor) log(ctx context.Context, info *grpc.UnaryServerInfo
 		slog.Info(msg, args...)
 	}
 }
+
+func remoteAddrFromContext(ctx context.Context) string {
+	md, _ := metadata.FromIncomingContext(ctx)
+
+	remoteAddr := ""
+	for _, h := range []string{"x-forwarded-for", "x-real-ip"} {
+		if v := md.Get(h); len(v) > 0 && v[0] != "" {
+			remoteAddr = v[0]
+			break
+		}
+	}
+	if remoteAddr == "" {
+		if p, ok := peer.FromContext(ctx); ok && p.Addr != nil {
+			remoteAddr = p.Addr.String()
+			for i := len(remoteAddr) - 1; i >= 0; i-- {
+				if remoteAddr[i] == ':' {
+					remoteAddr = remoteAddr[:i]
+					break
+				}
+			}
+		}
+	}
+
+	return remoteAddr
+}
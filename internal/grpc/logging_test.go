@@ -12,7 +12,10 @@ import (
 	"testing"
 	"time"
 
+	"github.com/prometheus/client_golang/prometheus/testutil"
 	"github.com/stretchr/testify/assert"
+	"github.com/tschaefer/finch/internal/metrics"
+	"go.opentelemetry.io/otel/trace"
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/status"
@@ -158,3 +161,55 @@ func TestLoggingInterceptorLogsWarning(t *testing.T) {
 	assert.Equal(t, "/test", content["request_path"])
 	assert.Equal(t, "WARN", content["level"])
 }
+
+func TestLoggingInterceptorRecordsMetrics(t *testing.T) {
+	ch := setupLogger()
+
+	interceptor := NewLoggingInterceptor()
+	unary := interceptor.Unary()
+
+	handler := func(ctx context.Context, req any) (any, error) {
+		return nil, status.Error(codes.NotFound, "missing")
+	}
+
+	requests := metrics.GRPCRequests.WithLabelValues("/metrics-test", codes.NotFound.String())
+	before := testutil.ToFloat64(requests)
+
+	_, err := unary(context.Background(), nil, &grpc.UnaryServerInfo{FullMethod: "/metrics-test"}, handler)
+	assert.Error(t, err)
+	waitForLog(t, ch)
+
+	assert.Equal(t, before+1, testutil.ToFloat64(requests))
+	assert.GreaterOrEqual(t, testutil.CollectAndCount(metrics.GRPCRequestDuration, "finch_grpc_request_duration_seconds"), 1)
+}
+
+func TestLoggingInterceptorLogsTraceContext(t *testing.T) {
+	ch := setupLogger()
+
+	interceptor := NewLoggingInterceptor()
+	unary := interceptor.Unary()
+
+	traceId, _ := trace.TraceIDFromHex("4bf92f3577b34da6a3ce929d0e0e4736")
+	spanId, _ := trace.SpanIDFromHex("00f067aa0ba902b7")
+	ctx := trace.ContextWithSpanContext(context.Background(), trace.NewSpanContext(trace.SpanContextConfig{
+		TraceID:    traceId,
+		SpanID:     spanId,
+		TraceFlags: trace.FlagsSampled,
+	}))
+
+	handler := func(ctx context.Context, req any) (any, error) {
+		return "ok", nil
+	}
+
+	_, err := unary(ctx, nil, &grpc.UnaryServerInfo{FullMethod: "/test"}, handler)
+	assert.NoError(t, err)
+
+	waitForLog(t, ch)
+
+	var content map[string]any
+	err = json.Unmarshal(record.Bytes(), &content)
+	assert.NoError(t, err)
+
+	assert.Equal(t, traceId.String(), content["trace_id"])
+	assert.Equal(t, spanId.String(), content["span_id"])
+}
@@ -6,18 +6,31 @@ package grpc
 
 import (
 	"context"
+	"encoding/json"
 	"errors"
 	"log/slog"
+	"slices"
+	"strings"
 	"time"
 
 	"github.com/tschaefer/finch/api"
 	"github.com/tschaefer/finch/internal/config"
 	"github.com/tschaefer/finch/internal/controller"
+	"github.com/tschaefer/finch/internal/model"
 	"github.com/tschaefer/finch/internal/version"
+	"google.golang.org/grpc"
 	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/reflect/protoreflect"
 )
 
+const (
+	maxAgentListPageSize  = 1000
+	maxExpiringWithinDays = 36500
+)
+
+var defaultAgentListPaths = []string{"rid", "hostname", "last_seen", "active", "stale", "resource_version"}
+
 type AgentServer struct {
 	api.UnimplementedAgentServiceServer
 	controller *controller.Controller
@@ -34,6 +47,21 @@ type DashboardServer struct {
 	controller *controller.Controller
 }
 
+type AuditServer struct {
+	api.UnimplementedAuditServiceServer
+	controller *controller.Controller
+}
+
+type TenantServer struct {
+	api.UnimplementedTenantServiceServer
+	controller *controller.Controller
+}
+
+type EnrollmentServer struct {
+	api.UnimplementedEnrollmentServiceServer
+	controller *controller.Controller
+}
+
 func NewAgentServer(ctrl *controller.Controller, cfg *config.Config) *AgentServer {
 	slog.Debug("Initializing gRPC AgentServer")
 	return &AgentServer{
@@ -56,6 +84,27 @@ func NewDashboardServer(ctrl *controller.Controller) *DashboardServer {
 	}
 }
 
+func NewAuditServer(ctrl *controller.Controller) *AuditServer {
+	slog.Debug("Initializing gRPC AuditServer")
+	return &AuditServer{
+		controller: ctrl,
+	}
+}
+
+func NewTenantServer(ctrl *controller.Controller) *TenantServer {
+	slog.Debug("Initializing gRPC TenantServer")
+	return &TenantServer{
+		controller: ctrl,
+	}
+}
+
+func NewEnrollmentServer(ctrl *controller.Controller) *EnrollmentServer {
+	slog.Debug("Initializing gRPC EnrollmentServer")
+	return &EnrollmentServer{
+		controller: ctrl,
+	}
+}
+
 func (s *AgentServer) RegisterAgent(ctx context.Context, req *api.RegisterAgentRequest) (*api.RegisterAgentResponse, error) {
 	if req.Hostname == "" {
 		return nil, status.Error(codes.InvalidArgument, "hostname is required")
@@ -72,13 +121,22 @@ func (s *AgentServer) RegisterAgent(ctx context.Context, req *api.RegisterAgentR
 		MetricsTargets: req.MetricsTargets,
 		Profiles:       req.Profiles,
 		Node:           req.Node,
+		Ephemeral:      req.Ephemeral,
+		Tenant:         req.Tenant,
+		RateLimits:     rateLimitsFromApi(req.RateLimits),
 	}
 
-	rid, err := s.controller.RegisterAgent(agent)
+	rid, err := s.controller.WithContext(ctx).RegisterAgent(agent, actorFromContext(ctx))
 	if err != nil {
 		if errors.Is(err, controller.ErrAgentAlreadyExists) {
 			return nil, status.Error(codes.AlreadyExists, err.Error())
 		}
+		if errors.Is(err, controller.ErrTenantNotFound) {
+			return nil, status.Error(codes.FailedPrecondition, err.Error())
+		}
+		if errors.Is(err, controller.ErrInvalidRateLimit) {
+			return nil, status.Error(codes.InvalidArgument, err.Error())
+		}
 		return nil, status.Error(codes.Internal, err.Error())
 	}
 
@@ -90,11 +148,14 @@ func (s *AgentServer) DeregisterAgent(ctx context.Context, req *api.DeregisterAg
 		return nil, status.Error(codes.InvalidArgument, "resource ID is required")
 	}
 
-	err := s.controller.DeregisterAgent(req.Rid)
+	err := s.controller.WithContext(ctx).DeregisterAgent(req.Rid, req.GetResourceVersion(), actorFromContext(ctx))
 	if err != nil {
 		if errors.Is(err, controller.ErrAgentNotFound) {
 			return nil, status.Error(codes.NotFound, err.Error())
 		}
+		if errors.Is(err, controller.ErrAgentConflict) {
+			return nil, status.Error(codes.Aborted, err.Error())
+		}
 		return nil, status.Error(codes.Internal, err.Error())
 	}
 
@@ -102,11 +163,12 @@ func (s *AgentServer) DeregisterAgent(ctx context.Context, req *api.DeregisterAg
 }
 
 func (s *AgentServer) GetAgent(ctx context.Context, req *api.GetAgentRequest) (*api.GetAgentResponse, error) {
+	ctrl := s.controller.WithContext(ctx)
 	if req.Rid == "" {
 		return nil, status.Error(codes.InvalidArgument, "resource ID is required")
 	}
 
-	agent, err := s.controller.GetAgent(req.Rid)
+	agent, err := ctrl.GetAgent(req.Rid)
 	if err != nil {
 		if errors.Is(err, controller.ErrAgentNotFound) {
 			return nil, status.Error(codes.NotFound, err.Error())
@@ -114,34 +176,126 @@ func (s *AgentServer) GetAgent(ctx context.Context, req *api.GetAgentRequest) (*
 		return nil, status.Error(codes.Internal, err.Error())
 	}
 
+	lastSeen := ""
+	if agent.LastSeen != nil {
+		lastSeen = agent.LastSeen.Format(time.RFC3339)
+	}
+
+	tokensRevoked := ""
+	if agent.TokensRevoked != nil {
+		tokensRevoked = agent.TokensRevoked.Format(time.RFC3339Nano)
+	}
+
+	collectorList, err := ctrl.ListCollectors(agent.ResourceId)
+	if err != nil {
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+
+	collectors := make([]*api.CollectorItem, 0, len(collectorList))
+	for _, c := range collectorList {
+		lastPoll := ""
+		if c.LastPoll != nil {
+			lastPoll = c.LastPoll.Format(time.RFC3339)
+		}
+		collectors = append(collectors, &api.CollectorItem{
+			Id:         c.CollectorId,
+			Name:       c.Name,
+			Attributes: c.Attributes,
+			ConfigHash: c.ConfigHash,
+			LastPoll:   lastPoll,
+		})
+	}
+
 	return &api.GetAgentResponse{
-		ResourceId:     agent.ResourceId,
-		Hostname:       agent.Hostname,
-		Labels:         agent.Labels,
-		LogSources:     agent.LogSources,
-		Metrics:        agent.Metrics,
-		MetricsTargets: agent.MetricsTargets,
-		Profiles:       agent.Profiles,
-		CreatedAt:      agent.CreatedAt.Format(time.RFC3339),
-		Node:           agent.Node,
+		ResourceId:      agent.ResourceId,
+		Hostname:        agent.Hostname,
+		Labels:          agent.Labels,
+		LogSources:      agent.LogSources,
+		Metrics:         agent.Metrics,
+		MetricsTargets:  agent.MetricsTargets,
+		Profiles:        agent.Profiles,
+		CreatedAt:       agent.CreatedAt.Format(time.RFC3339),
+		Node:            agent.Node,
+		LastSeen:        lastSeen,
+		Active:          agent.Active,
+		TokensRevoked:   tokensRevoked,
+		Stale:           agent.Stale,
+		Ephemeral:       agent.Ephemeral,
+		ResourceVersion: agent.ResourceVersion,
+		Collectors:      collectors,
+		Tenant:          agent.Tenant,
+		RateLimits:      rateLimitsToApi(agent.RateLimits),
 	}, nil
 }
 
 func (s *AgentServer) ListAgents(ctx context.Context, req *api.ListAgentsRequest) (*api.ListAgentsResponse, error) {
-	agentList, err := s.controller.ListAgents()
+	if req.PageSize < 0 {
+		return nil, status.Error(codes.InvalidArgument, "page_size must not be negative")
+	}
+
+	paths := defaultAgentListPaths
+	if len(req.FieldMask.GetPaths()) > 0 {
+		if !req.FieldMask.IsValid(&api.AgentListItem{}) {
+			return nil, status.Error(codes.InvalidArgument, "field_mask contains unknown fields")
+		}
+		paths = req.FieldMask.GetPaths()
+	}
+
+	filter := controller.AgentFilter{
+		LabelSelector:   req.LabelSelector,
+		Node:            req.GetNode(),
+		Metrics:         req.Metrics,
+		Profiles:        req.Profiles,
+		Active:          req.Active,
+		Stale:           req.Stale,
+		LogSourceScheme: req.GetLogSourceScheme(),
+		Tenant:          req.GetTenant(),
+		PageSize:        min(int(req.PageSize), maxAgentListPageSize),
+		PageToken:       req.PageToken,
+	}
+
+	agentList, nextPageToken, err := s.controller.WithContext(ctx).QueryAgents(filter)
 	if err != nil {
+		if errors.Is(err, controller.ErrInvalidLabelSelector) || errors.Is(err, controller.ErrInvalidPageToken) {
+			return nil, status.Error(codes.InvalidArgument, err.Error())
+		}
 		return nil, status.Error(codes.Internal, err.Error())
 	}
 
 	agents := make([]*api.AgentListItem, 0, len(agentList))
-	for _, a := range agentList {
-		agents = append(agents, &api.AgentListItem{
-			Rid:      a["rid"],
-			Hostname: a["hostname"],
-		})
+	for _, agent := range agentList {
+		lastSeen := ""
+		if agent.LastSeen != nil {
+			lastSeen = agent.LastSeen.Format(time.RFC3339)
+		}
+
+		tokensRevoked := ""
+		if agent.TokensRevoked != nil {
+			tokensRevoked = agent.TokensRevoked.Format(time.RFC3339Nano)
+		}
+
+		item := &api.AgentListItem{
+			Rid:             agent.ResourceId,
+			Hostname:        agent.Hostname,
+			LastSeen:        lastSeen,
+			Active:          agent.Active,
+			Stale:           agent.Stale,
+			Labels:          agent.Labels,
+			LogSources:      agent.LogSources,
+			Metrics:         agent.Metrics,
+			MetricsTargets:  agent.MetricsTargets,
+			Profiles:        agent.Profiles,
+			CreatedAt:       agent.CreatedAt.Format(time.RFC3339),
+			Node:            agent.Node,
+			TokensRevoked:   tokensRevoked,
+			Ephemeral:       agent.Ephemeral,
+			ResourceVersion: agent.ResourceVersion,
+			Tenant:          agent.Tenant,
+		}
+		agents = append(agents, maskAgentListItem(item, paths))
 	}
 
-	return &api.ListAgentsResponse{Agents: agents}, nil
+	return &api.ListAgentsResponse{Agents: agents, NextPageToken: nextPageToken}, nil
 }
 
 func (s *AgentServer) GetAgentConfig(ctx context.Context, req *api.GetAgentConfigRequest) (*api.GetAgentConfigResponse, error) {
@@ -149,7 +303,7 @@ func (s *AgentServer) GetAgentConfig(ctx context.Context, req *api.GetAgentConfi
 		return nil, status.Error(codes.InvalidArgument, "resource ID is required")
 	}
 
-	config, err := s.controller.CreateAgentConfig(req.Rid)
+	config, err := s.controller.WithContext(ctx).CreateAgentConfig(req.Rid, actorFromContext(ctx))
 	if err != nil {
 		if errors.Is(err, controller.ErrAgentNotFound) {
 			return nil, status.Error(codes.NotFound, err.Error())
@@ -171,19 +325,222 @@ func (s *AgentServer) UpdateAgent(ctx context.Context, req *api.UpdateAgentReque
 		Metrics:        req.Metrics,
 		MetricsTargets: req.MetricsTargets,
 		Profiles:       req.Profiles,
+		RateLimits:     rateLimitsFromApi(req.RateLimits),
 	}
 
-	err := s.controller.UpdateAgent(req.Rid, agent)
+	err := s.controller.WithContext(ctx).UpdateAgent(req.Rid, req.GetResourceVersion(), agent, actorFromContext(ctx), req.UpdateMask.GetPaths()...)
 	if err != nil {
 		if errors.Is(err, controller.ErrAgentNotFound) {
 			return nil, status.Error(codes.NotFound, err.Error())
 		}
+		if errors.Is(err, controller.ErrAgentConflict) {
+			return nil, status.Error(codes.Aborted, err.Error())
+		}
+		if errors.Is(err, controller.ErrInvalidUpdateMask) || errors.Is(err, controller.ErrInvalidRateLimit) {
+			return nil, status.Error(codes.InvalidArgument, err.Error())
+		}
 		return nil, status.Error(codes.Internal, err.Error())
 	}
 
 	return &api.UpdateAgentResponse{}, nil
 }
 
+func (s *AgentServer) RevokeAgentTokens(ctx context.Context, req *api.RevokeAgentTokensRequest) (*api.RevokeAgentTokensResponse, error) {
+	if req.Rid == "" {
+		return nil, status.Error(codes.InvalidArgument, "resource ID is required")
+	}
+
+	var before time.Time
+	if req.Before != nil {
+		var err error
+		before, err = time.Parse(time.RFC3339, *req.Before)
+		if err != nil {
+			return nil, status.Error(codes.InvalidArgument, "before must be an RFC3339 timestamp")
+		}
+	}
+
+	revokedBefore, err := s.controller.WithContext(ctx).RevokeAgentTokens(req.Rid, before, actorFromContext(ctx))
+	if err != nil {
+		if errors.Is(err, controller.ErrAgentNotFound) {
+			return nil, status.Error(codes.NotFound, err.Error())
+		}
+		if errors.Is(err, controller.ErrInvalidRevocationTime) {
+			return nil, status.Error(codes.InvalidArgument, err.Error())
+		}
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+
+	return &api.RevokeAgentTokensResponse{RevokedBefore: revokedBefore.Format(time.RFC3339Nano)}, nil
+}
+
+func (s *AgentServer) ListAgentTokens(ctx context.Context, req *api.ListAgentTokensRequest) (*api.ListAgentTokensResponse, error) {
+	var expiringWithin time.Duration
+	if req.ExpiringWithinDays != nil {
+		if *req.ExpiringWithinDays <= 0 {
+			return nil, status.Error(codes.InvalidArgument, "expiring_within_days must be positive")
+		}
+		if *req.ExpiringWithinDays > maxExpiringWithinDays {
+			return nil, status.Errorf(codes.InvalidArgument, "expiring_within_days must not exceed %d", maxExpiringWithinDays)
+		}
+		expiringWithin = time.Duration(*req.ExpiringWithinDays) * 24 * time.Hour
+	}
+
+	tokenList, err := s.controller.WithContext(ctx).ListAgentTokens(req.Rid, expiringWithin)
+	if err != nil {
+		if errors.Is(err, controller.ErrAgentNotFound) {
+			return nil, status.Error(codes.NotFound, err.Error())
+		}
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+
+	tokens := make([]*api.AgentTokenItem, 0, len(tokenList))
+	for _, t := range tokenList {
+		tokens = append(tokens, &api.AgentTokenItem{
+			Jti:       t.Jti,
+			Rid:       t.ResourceId,
+			IssuedAt:  t.IssuedAt.Format(time.RFC3339),
+			ExpiresAt: t.ExpiresAt.Format(time.RFC3339),
+			Issuer:    t.Issuer,
+			Purpose:   t.Purpose,
+			Revoked:   t.Revoked,
+		})
+	}
+
+	return &api.ListAgentTokensResponse{Tokens: tokens}, nil
+}
+
+func (s *AgentServer) SuspendAgent(ctx context.Context, req *api.SuspendAgentRequest) (*api.SuspendAgentResponse, error) {
+	if req.Rid == "" {
+		return nil, status.Error(codes.InvalidArgument, "resource ID is required")
+	}
+
+	err := s.controller.WithContext(ctx).SuspendAgent(req.Rid, req.GetResourceVersion(), actorFromContext(ctx))
+	if err != nil {
+		if errors.Is(err, controller.ErrAgentNotFound) {
+			return nil, status.Error(codes.NotFound, err.Error())
+		}
+		if errors.Is(err, controller.ErrAgentConflict) {
+			return nil, status.Error(codes.Aborted, err.Error())
+		}
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+
+	return &api.SuspendAgentResponse{}, nil
+}
+
+func (s *AgentServer) ResumeAgent(ctx context.Context, req *api.ResumeAgentRequest) (*api.ResumeAgentResponse, error) {
+	if req.Rid == "" {
+		return nil, status.Error(codes.InvalidArgument, "resource ID is required")
+	}
+
+	err := s.controller.WithContext(ctx).ResumeAgent(req.Rid, req.GetResourceVersion(), actorFromContext(ctx))
+	if err != nil {
+		if errors.Is(err, controller.ErrAgentNotFound) {
+			return nil, status.Error(codes.NotFound, err.Error())
+		}
+		if errors.Is(err, controller.ErrAgentConflict) {
+			return nil, status.Error(codes.Aborted, err.Error())
+		}
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+
+	return &api.ResumeAgentResponse{}, nil
+}
+
+func (s *AgentServer) WatchAgents(req *api.WatchAgentsRequest, stream grpc.ServerStreamingServer[api.WatchAgentsResponse]) error {
+	ctx, cancel := context.WithCancel(stream.Context())
+	defer cancel()
+
+	events := s.controller.SubscribeAgentEvents(ctx)
+
+	revision := s.controller.AgentRevision()
+	if req.SinceRevision != nil {
+		revision = *req.SinceRevision
+		if err := s.replayAgentEvents(stream, &revision); err != nil {
+			return err
+		}
+	}
+
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case event, ok := <-events:
+			if !ok {
+				return nil
+			}
+			if event.Type == model.AgentEventLagged {
+				if err := s.replayAgentEvents(stream, &revision); err != nil {
+					return err
+				}
+				continue
+			}
+			if event.Type == model.AgentEventSeen || event.Revision <= revision {
+				continue
+			}
+			if err := stream.Send(watchAgentsResponse(event)); err != nil {
+				return err
+			}
+			revision = event.Revision
+		}
+	}
+}
+
+func (s *AgentServer) replayAgentEvents(stream grpc.ServerStreamingServer[api.WatchAgentsResponse], revision *uint64) error {
+	history, err := s.controller.AgentEventsSince(*revision)
+	if err != nil {
+		if errors.Is(err, controller.ErrRevisionTooOld) {
+			return status.Error(codes.OutOfRange, err.Error())
+		}
+		return status.Error(codes.Internal, err.Error())
+	}
+
+	for _, event := range history {
+		if err := stream.Send(watchAgentsResponse(event)); err != nil {
+			return err
+		}
+		*revision = event.Revision
+	}
+
+	return nil
+}
+
+func rateLimitsFromApi(limits map[string]*api.RateLimit) model.RateLimits {
+	if len(limits) == 0 {
+		return nil
+	}
+
+	result := make(model.RateLimits, len(limits))
+	for backend, limit := range limits {
+		result[backend] = model.RateLimit{Rate: limit.GetRate(), Burst: int(limit.GetBurst())}
+	}
+
+	return result
+}
+
+func rateLimitsToApi(limits model.RateLimits) map[string]*api.RateLimit {
+	if len(limits) == 0 {
+		return nil
+	}
+
+	result := make(map[string]*api.RateLimit, len(limits))
+	for backend, limit := range limits {
+		result[backend] = &api.RateLimit{Rate: limit.Rate, Burst: int32(limit.Burst)}
+	}
+
+	return result
+}
+
+func watchAgentsResponse(event model.AgentEvent) *api.WatchAgentsResponse {
+	return &api.WatchAgentsResponse{
+		Revision:      event.Revision,
+		Type:          event.Type,
+		Rid:           event.ResourceId,
+		Hostname:      event.Hostname,
+		ChangedFields: event.Fields,
+	}
+}
+
 func (s *InfoServer) GetServiceInfo(ctx context.Context, req *api.GetServiceInfoRequest) (*api.GetServiceInfoResponse, error) {
 	return &api.GetServiceInfoResponse{
 		Id:        s.config.Id(),
@@ -194,6 +551,141 @@ func (s *InfoServer) GetServiceInfo(ctx context.Context, req *api.GetServiceInfo
 	}, nil
 }
 
+func (s *AgentServer) ListAgentConfigRevisions(ctx context.Context, req *api.ListAgentConfigRevisionsRequest) (*api.ListAgentConfigRevisionsResponse, error) {
+	if req.Rid == "" {
+		return nil, status.Error(codes.InvalidArgument, "resource ID is required")
+	}
+
+	revisionList, err := s.controller.WithContext(ctx).ListAgentConfigRevisions(req.Rid)
+	if err != nil {
+		if errors.Is(err, controller.ErrAgentNotFound) {
+			return nil, status.Error(codes.NotFound, err.Error())
+		}
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+
+	revisions := make([]*api.AgentConfigRevisionItem, 0, len(revisionList))
+	for _, r := range revisionList {
+		revisions = append(revisions, &api.AgentConfigRevisionItem{
+			Revision:       r.Revision,
+			CreatedAt:      r.CreatedAt.Format(time.RFC3339),
+			Author:         r.Author,
+			ConfigHash:     r.ConfigHash,
+			Labels:         r.Settings.Labels,
+			LogSources:     r.Settings.LogSources,
+			Metrics:        r.Settings.Metrics,
+			MetricsTargets: r.Settings.MetricsTargets,
+			Profiles:       r.Settings.Profiles,
+		})
+	}
+
+	return &api.ListAgentConfigRevisionsResponse{Revisions: revisions}, nil
+}
+
+func (s *AgentServer) DiffAgentConfig(ctx context.Context, req *api.DiffAgentConfigRequest) (*api.DiffAgentConfigResponse, error) {
+	if req.Rid == "" {
+		return nil, status.Error(codes.InvalidArgument, "resource ID is required")
+	}
+	if req.FromRevision == 0 {
+		return nil, status.Error(codes.InvalidArgument, "from_revision is required")
+	}
+	if req.ToRevision != nil && *req.ToRevision == 0 {
+		return nil, status.Error(codes.InvalidArgument, "to_revision must be positive")
+	}
+
+	diff, err := s.controller.WithContext(ctx).DiffAgentConfig(req.Rid, req.FromRevision, req.GetToRevision())
+	if err != nil {
+		if errors.Is(err, controller.ErrAgentNotFound) || errors.Is(err, controller.ErrConfigRevisionNotFound) {
+			return nil, status.Error(codes.NotFound, err.Error())
+		}
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+
+	return &api.DiffAgentConfigResponse{Diff: diff}, nil
+}
+
+func (s *AgentServer) RollbackAgentConfig(ctx context.Context, req *api.RollbackAgentConfigRequest) (*api.RollbackAgentConfigResponse, error) {
+	if req.Rid == "" {
+		return nil, status.Error(codes.InvalidArgument, "resource ID is required")
+	}
+	if req.Revision == 0 {
+		return nil, status.Error(codes.InvalidArgument, "revision is required")
+	}
+
+	revision, err := s.controller.WithContext(ctx).RollbackAgentConfig(req.Rid, req.Revision, req.GetResourceVersion(), actorFromContext(ctx))
+	if err != nil {
+		if errors.Is(err, controller.ErrAgentNotFound) || errors.Is(err, controller.ErrConfigRevisionNotFound) {
+			return nil, status.Error(codes.NotFound, err.Error())
+		}
+		if errors.Is(err, controller.ErrAgentConflict) {
+			return nil, status.Error(codes.Aborted, err.Error())
+		}
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+
+	return &api.RollbackAgentConfigResponse{Revision: revision}, nil
+}
+
+func (s *AgentServer) GetAgentUsage(ctx context.Context, req *api.GetAgentUsageRequest) (*api.GetAgentUsageResponse, error) {
+	if req.Rid == "" {
+		return nil, status.Error(codes.InvalidArgument, "resource ID is required")
+	}
+
+	var since, until time.Time
+	if req.Since != nil {
+		var err error
+		since, err = time.Parse(time.RFC3339, *req.Since)
+		if err != nil {
+			return nil, status.Error(codes.InvalidArgument, "since must be an RFC3339 timestamp")
+		}
+	}
+	if req.Until != nil {
+		var err error
+		until, err = time.Parse(time.RFC3339, *req.Until)
+		if err != nil {
+			return nil, status.Error(codes.InvalidArgument, "until must be an RFC3339 timestamp")
+		}
+	}
+
+	usages, err := s.controller.WithContext(ctx).GetAgentUsage(req.Rid, since, until)
+	if err != nil {
+		if errors.Is(err, controller.ErrAgentNotFound) {
+			return nil, status.Error(codes.NotFound, err.Error())
+		}
+		if errors.Is(err, controller.ErrInvalidUsageWindow) {
+			return nil, status.Error(codes.InvalidArgument, err.Error())
+		}
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+
+	resp := &api.GetAgentUsageResponse{
+		Buckets: make([]*api.AgentUsageItem, 0, len(usages)),
+	}
+	totals := make(map[string]*api.AgentUsageItem)
+	for _, usage := range usages {
+		resp.Buckets = append(resp.Buckets, &api.AgentUsageItem{
+			Backend:  usage.Backend,
+			Bucket:   usage.Bucket.UTC().Format(time.RFC3339),
+			Requests: usage.Requests,
+			Bytes:    usage.Bytes,
+		})
+
+		total, ok := totals[usage.Backend]
+		if !ok {
+			total = &api.AgentUsageItem{Backend: usage.Backend}
+			totals[usage.Backend] = total
+			resp.Totals = append(resp.Totals, total)
+		}
+		total.Requests += usage.Requests
+		total.Bytes += usage.Bytes
+	}
+	slices.SortFunc(resp.Totals, func(a, b *api.AgentUsageItem) int {
+		return strings.Compare(a.Backend, b.Backend)
+	})
+
+	return resp, nil
+}
+
 func (s *DashboardServer) GetDashboardToken(ctx context.Context, req *api.GetDashboardTokenRequest) (*api.GetDashboardTokenResponse, error) {
 	sessionTimeout := int(1800)
 	if req.SessionTimeout != nil {
@@ -207,7 +699,7 @@ func (s *DashboardServer) GetDashboardToken(ctx context.Context, req *api.GetDas
 		req.Role = controller.RoleViewer
 	}
 
-	tokenResp, err := s.controller.GenerateDashboardToken(sessionTimeout, req.Role, req.Scope)
+	tokenResp, err := s.controller.WithContext(ctx).GenerateDashboardToken(sessionTimeout, req.Role, req.Scope, actorFromContext(ctx))
 	if err != nil {
 		if errors.Is(err, controller.ErrInvalidRole) {
 			return nil, status.Error(codes.InvalidArgument, err.Error())
@@ -221,3 +713,201 @@ func (s *DashboardServer) GetDashboardToken(ctx context.Context, req *api.GetDas
 		DashboardUrl: tokenResp.DashboardURL,
 	}, nil
 }
+
+func (s *AuditServer) ListAuditEvents(ctx context.Context, req *api.ListAuditEventsRequest) (*api.ListAuditEventsResponse, error) {
+	filter := controller.AuditFilter{
+		Actor:      req.Actor,
+		Action:     req.Action,
+		ResourceId: req.Rid,
+	}
+	if req.Since != nil {
+		since, err := time.Parse(time.RFC3339, *req.Since)
+		if err != nil {
+			return nil, status.Error(codes.InvalidArgument, "since must be an RFC3339 timestamp")
+		}
+		filter.Since = &since
+	}
+	if req.Until != nil {
+		until, err := time.Parse(time.RFC3339, *req.Until)
+		if err != nil {
+			return nil, status.Error(codes.InvalidArgument, "until must be an RFC3339 timestamp")
+		}
+		filter.Until = &until
+	}
+	if req.Limit != nil {
+		if *req.Limit <= 0 {
+			return nil, status.Error(codes.InvalidArgument, "limit must be positive")
+		}
+		filter.Limit = int(*req.Limit)
+	}
+
+	eventList, err := s.controller.WithContext(ctx).ListAuditEvents(filter)
+	if err != nil {
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+
+	events := make([]*api.AuditEventItem, 0, len(eventList))
+	for _, e := range eventList {
+		diff := ""
+		if len(e.Diff) > 0 {
+			data, err := json.Marshal(e.Diff)
+			if err != nil {
+				return nil, status.Error(codes.Internal, err.Error())
+			}
+			diff = string(data)
+		}
+
+		events = append(events, &api.AuditEventItem{
+			Id:        uint64(e.ID),
+			Timestamp: e.CreatedAt.Format(time.RFC3339),
+			Actor:     e.Actor,
+			Action:    e.Action,
+			Rid:       e.ResourceId,
+			Diff:      diff,
+			SourceIp:  e.SourceIp,
+		})
+	}
+
+	return &api.ListAuditEventsResponse{Events: events}, nil
+}
+
+func (s *TenantServer) CreateTenant(ctx context.Context, req *api.CreateTenantRequest) (*api.CreateTenantResponse, error) {
+	if req.Name == "" {
+		return nil, status.Error(codes.InvalidArgument, "tenant name is required")
+	}
+
+	tenant, err := s.controller.WithContext(ctx).CreateTenant(req.Name, req.Description, actorFromContext(ctx))
+	if err != nil {
+		if errors.Is(err, controller.ErrInvalidTenantName) {
+			return nil, status.Error(codes.InvalidArgument, err.Error())
+		}
+		if errors.Is(err, controller.ErrTenantAlreadyExists) {
+			return nil, status.Error(codes.AlreadyExists, err.Error())
+		}
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+
+	return &api.CreateTenantResponse{Name: tenant.Name}, nil
+}
+
+func (s *TenantServer) ListTenants(ctx context.Context, req *api.ListTenantsRequest) (*api.ListTenantsResponse, error) {
+	tenantList, err := s.controller.WithContext(ctx).ListTenants()
+	if err != nil {
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+
+	tenants := make([]*api.TenantItem, 0, len(tenantList))
+	for _, t := range tenantList {
+		createdAt := ""
+		if !t.CreatedAt.IsZero() {
+			createdAt = t.CreatedAt.Format(time.RFC3339)
+		}
+		tenants = append(tenants, &api.TenantItem{
+			Name:        t.Name,
+			Description: t.Description,
+			CreatedAt:   createdAt,
+		})
+	}
+
+	return &api.ListTenantsResponse{Tenants: tenants}, nil
+}
+
+func (s *TenantServer) DeleteTenant(ctx context.Context, req *api.DeleteTenantRequest) (*api.DeleteTenantResponse, error) {
+	if req.Name == "" {
+		return nil, status.Error(codes.InvalidArgument, "tenant name is required")
+	}
+
+	err := s.controller.WithContext(ctx).DeleteTenant(req.Name, actorFromContext(ctx))
+	if err != nil {
+		switch {
+		case errors.Is(err, controller.ErrTenantNotFound):
+			return nil, status.Error(codes.NotFound, err.Error())
+		case errors.Is(err, controller.ErrDefaultTenant):
+			return nil, status.Error(codes.InvalidArgument, err.Error())
+		case errors.Is(err, controller.ErrTenantInUse):
+			return nil, status.Error(codes.FailedPrecondition, err.Error())
+		}
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+
+	return &api.DeleteTenantResponse{}, nil
+}
+
+func (s *EnrollmentServer) CreateEnrollmentToken(ctx context.Context, req *api.CreateEnrollmentTokenRequest) (*api.CreateEnrollmentTokenResponse, error) {
+	spec := controller.EnrollmentTokenSpec{
+		HostnamePattern: req.HostnamePattern,
+		Labels:          req.Labels,
+		LogSources:      req.LogSources,
+		Ephemeral:       req.Ephemeral,
+		Tenant:          req.Tenant,
+	}
+	if req.UsageLimit != nil {
+		if *req.UsageLimit <= 0 {
+			return nil, status.Error(codes.InvalidArgument, "usage limit must be positive")
+		}
+		spec.UsageLimit = int(*req.UsageLimit)
+	}
+	if req.ExpiresIn != nil {
+		if *req.ExpiresIn <= 0 {
+			return nil, status.Error(codes.InvalidArgument, "expires in must be positive")
+		}
+		spec.Expiration = time.Duration(*req.ExpiresIn) * time.Second
+	}
+
+	token, enrollment, err := s.controller.WithContext(ctx).CreateEnrollmentToken(spec, actorFromContext(ctx))
+	if err != nil {
+		if errors.Is(err, controller.ErrInvalidHostnamePattern) ||
+			errors.Is(err, controller.ErrInvalidUsageLimit) ||
+			errors.Is(err, controller.ErrInvalidEnrollment) {
+			return nil, status.Error(codes.InvalidArgument, err.Error())
+		}
+		if errors.Is(err, controller.ErrTenantNotFound) {
+			return nil, status.Error(codes.FailedPrecondition, err.Error())
+		}
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+
+	return &api.CreateEnrollmentTokenResponse{
+		Token:     token,
+		Jti:       enrollment.Jti,
+		ExpiresAt: enrollment.ExpiresAt.Format(time.RFC3339),
+	}, nil
+}
+
+func (s *EnrollmentServer) ListEnrollmentTokens(ctx context.Context, req *api.ListEnrollmentTokensRequest) (*api.ListEnrollmentTokensResponse, error) {
+	tokenList, err := s.controller.WithContext(ctx).ListEnrollmentTokens()
+	if err != nil {
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+
+	tokens := make([]*api.EnrollmentTokenItem, 0, len(tokenList))
+	for _, t := range tokenList {
+		tokens = append(tokens, &api.EnrollmentTokenItem{
+			Jti:             t.Jti,
+			IssuedAt:        t.IssuedAt.Format(time.RFC3339),
+			ExpiresAt:       t.ExpiresAt.Format(time.RFC3339),
+			Issuer:          t.Issuer,
+			UsageLimit:      int32(t.UsageLimit),
+			Uses:            int32(t.Uses),
+			HostnamePattern: t.HostnamePattern,
+			Labels:          t.Labels,
+			LogSources:      t.LogSources,
+			Ephemeral:       t.Ephemeral,
+			Tenant:          t.Tenant,
+		})
+	}
+
+	return &api.ListEnrollmentTokensResponse{Tokens: tokens}, nil
+}
+
+func maskAgentListItem(item *api.AgentListItem, paths []string) *api.AgentListItem {
+	msg := item.ProtoReflect()
+	msg.Range(func(fd protoreflect.FieldDescriptor, _ protoreflect.Value) bool {
+		if !slices.Contains(paths, string(fd.Name())) {
+			msg.Clear(fd)
+		}
+		return true
+	})
+
+	return item
+}
@@ -7,7 +7,9 @@ package grpc
 import (
 	"context"
 	"fmt"
+	"math"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/tschaefer/finch/api"
@@ -15,8 +17,10 @@ import (
 	"github.com/tschaefer/finch/internal/controller"
 	"github.com/tschaefer/finch/internal/database"
 	"github.com/tschaefer/finch/internal/model"
+	"google.golang.org/grpc"
 	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/fieldmaskpb"
 )
 
 var testServerCfg = config.NewFromData(&config.Data{
@@ -205,6 +209,57 @@ func TestListAgentsReturnsAgentList(t *testing.T) {
 	assert.Len(t, resp.Agents, 2)
 }
 
+func TestListAgentsReturnsFilteredAgentList(t *testing.T) {
+	server := NewAgentServer(newController(t), testServerCfg)
+
+	for i, labels := range [][]string{{"env=prod"}, {"env=prod", "canary"}, {"env=dev"}} {
+		req := &api.RegisterAgentRequest{
+			Hostname:   fmt.Sprintf("node%d", i+1),
+			Node:       "unix",
+			LogSources: []string{"journal://"},
+			Labels:     labels,
+		}
+		_, err := server.RegisterAgent(context.Background(), req)
+		assert.NoError(t, err)
+	}
+
+	req := &api.ListAgentsRequest{
+		LabelSelector: "env=prod",
+		PageSize:      1,
+		FieldMask:     &fieldmaskpb.FieldMask{Paths: []string{"rid", "labels"}},
+	}
+	resp, err := server.ListAgents(context.Background(), req)
+	assert.NoError(t, err)
+	assert.Len(t, resp.Agents, 1)
+	assert.NotEmpty(t, resp.Agents[0].Rid)
+	assert.Equal(t, []string{"env=prod"}, resp.Agents[0].Labels)
+	assert.Empty(t, resp.Agents[0].Hostname)
+	assert.NotEmpty(t, resp.NextPageToken)
+
+	req.PageToken = resp.NextPageToken
+	resp, err = server.ListAgents(context.Background(), req)
+	assert.NoError(t, err)
+	assert.Len(t, resp.Agents, 1)
+	assert.Equal(t, []string{"env=prod", "canary"}, resp.Agents[0].Labels)
+	assert.Empty(t, resp.NextPageToken)
+}
+
+func TestListAgentsReturnsError_InvalidArguments(t *testing.T) {
+	server := NewAgentServer(newController(t), testServerCfg)
+
+	for _, req := range []*api.ListAgentsRequest{
+		{LabelSelector: "env=prod,,"},
+		{PageToken: "invalid"},
+		{PageSize: -1},
+		{FieldMask: &fieldmaskpb.FieldMask{Paths: []string{"unknown"}}},
+	} {
+		_, err := server.ListAgents(context.Background(), req)
+		assert.Error(t, err)
+		st, _ := status.FromError(err)
+		assert.Equal(t, codes.InvalidArgument, st.Code())
+	}
+}
+
 func TestGetAgentConfigReturnsConfig(t *testing.T) {
 	server := NewAgentServer(newController(t), testServerCfg)
 
@@ -295,3 +350,516 @@ func TestUpdateAgentSucceeds(t *testing.T) {
 	assert.NoError(t, err)
 	assert.NotNil(t, resp)
 }
+
+func TestUpdateAgentSucceeds_UpdateMask(t *testing.T) {
+	server := NewAgentServer(newController(t), testServerCfg)
+
+	agent := registerAgent(t, server, "to-be-patched")
+
+	req := &api.UpdateAgentRequest{
+		Rid:        agent.Rid,
+		Labels:     []string{"env=production"},
+		UpdateMask: &fieldmaskpb.FieldMask{Paths: []string{"labels"}},
+	}
+	resp, err := server.UpdateAgent(context.Background(), req)
+	assert.NoError(t, err)
+	assert.NotNil(t, resp)
+
+	updated, err := server.GetAgent(context.Background(), &api.GetAgentRequest{Rid: agent.Rid})
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"env=production"}, updated.Labels)
+	assert.Equal(t, []string{"journal:"}, updated.LogSources)
+}
+
+func TestUpdateAgentSucceeds_RateLimits(t *testing.T) {
+	server := NewAgentServer(newController(t), testServerCfg)
+
+	agent := registerAgent(t, server, "to-be-limited")
+
+	req := &api.UpdateAgentRequest{
+		Rid:        agent.Rid,
+		RateLimits: map[string]*api.RateLimit{"loki": {Rate: 10, Burst: 20}},
+		UpdateMask: &fieldmaskpb.FieldMask{Paths: []string{"rate_limits"}},
+	}
+	_, err := server.UpdateAgent(context.Background(), req)
+	assert.NoError(t, err)
+
+	updated, err := server.GetAgent(context.Background(), &api.GetAgentRequest{Rid: agent.Rid})
+	assert.NoError(t, err)
+	assert.Equal(t, 10.0, updated.RateLimits["loki"].GetRate())
+	assert.Equal(t, int32(20), updated.RateLimits["loki"].GetBurst())
+
+	req.RateLimits = map[string]*api.RateLimit{"grafana": {Rate: 10}}
+	_, err = server.UpdateAgent(context.Background(), req)
+	st, ok := status.FromError(err)
+	assert.True(t, ok)
+	assert.Equal(t, codes.InvalidArgument, st.Code())
+
+	_, err = server.UpdateAgent(context.Background(), &api.UpdateAgentRequest{
+		Rid:        agent.Rid,
+		LogSources: []string{"journal://"},
+		RateLimits: map[string]*api.RateLimit{"mimir": {Rate: 5, Burst: 10}},
+	})
+	assert.NoError(t, err)
+
+	updated, err = server.GetAgent(context.Background(), &api.GetAgentRequest{Rid: agent.Rid})
+	assert.NoError(t, err)
+	assert.Len(t, updated.RateLimits, 1)
+	assert.Equal(t, 5.0, updated.RateLimits["mimir"].GetRate())
+	assert.Equal(t, int32(10), updated.RateLimits["mimir"].GetBurst())
+}
+
+func TestUpdateAgentReturnsError_InvalidUpdateMask(t *testing.T) {
+	server := NewAgentServer(newController(t), testServerCfg)
+
+	agent := registerAgent(t, server, "to-be-patched")
+
+	req := &api.UpdateAgentRequest{
+		Rid:        agent.Rid,
+		UpdateMask: &fieldmaskpb.FieldMask{Paths: []string{"hostname"}},
+	}
+	_, err := server.UpdateAgent(context.Background(), req)
+	assert.Error(t, err)
+	st, ok := status.FromError(err)
+	assert.True(t, ok)
+	assert.Equal(t, codes.InvalidArgument, st.Code())
+}
+
+func TestUpdateAgentReturnsError_Conflict(t *testing.T) {
+	server := NewAgentServer(newController(t), testServerCfg)
+
+	agent := registerAgent(t, server, "to-be-conflicted")
+
+	current, err := server.GetAgent(context.Background(), &api.GetAgentRequest{Rid: agent.Rid})
+	assert.NoError(t, err)
+
+	stale := current.ResourceVersion
+	req := &api.UpdateAgentRequest{
+		Rid:             agent.Rid,
+		Labels:          []string{"env=production"},
+		UpdateMask:      &fieldmaskpb.FieldMask{Paths: []string{"labels"}},
+		ResourceVersion: &stale,
+	}
+	_, err = server.UpdateAgent(context.Background(), req)
+	assert.NoError(t, err)
+
+	_, err = server.UpdateAgent(context.Background(), req)
+	assert.Error(t, err)
+	st, ok := status.FromError(err)
+	assert.True(t, ok)
+	assert.Equal(t, codes.Aborted, st.Code())
+
+	_, err = server.DeregisterAgent(context.Background(), &api.DeregisterAgentRequest{Rid: agent.Rid, ResourceVersion: &stale})
+	st, _ = status.FromError(err)
+	assert.Equal(t, codes.Aborted, st.Code())
+}
+
+func TestRevokeAgentTokensSucceeds(t *testing.T) {
+	server := NewAgentServer(newController(t), testServerCfg)
+
+	agent := registerAgent(t, server, "to-be-revoked")
+
+	req := &api.RevokeAgentTokensRequest{
+		Rid: agent.Rid,
+	}
+	resp, err := server.RevokeAgentTokens(context.Background(), req)
+	assert.NoError(t, err)
+	assert.NotNil(t, resp)
+	assert.NotEmpty(t, resp.RevokedBefore)
+
+	getResp, err := server.GetAgent(context.Background(), &api.GetAgentRequest{Rid: agent.Rid})
+	assert.NoError(t, err)
+	assert.Equal(t, resp.RevokedBefore, getResp.TokensRevoked)
+}
+
+func TestRevokeAgentTokensReturnsError_InvalidArguments(t *testing.T) {
+	server := NewAgentServer(newController(t), testServerCfg)
+
+	agent := registerAgent(t, server, "to-be-revoked")
+
+	invalid := "yesterday"
+	future := time.Now().Add(1 * time.Hour).Format(time.RFC3339)
+	for _, req := range []*api.RevokeAgentTokensRequest{
+		{Rid: ""},
+		{Rid: agent.Rid, Before: &invalid},
+		{Rid: agent.Rid, Before: &future},
+	} {
+		resp, err := server.RevokeAgentTokens(context.Background(), req)
+		assert.Error(t, err)
+		assert.Nil(t, resp)
+		st, ok := status.FromError(err)
+		assert.True(t, ok)
+		assert.Equal(t, codes.InvalidArgument, st.Code())
+	}
+}
+
+func TestRevokeAgentTokensReturnsError_AgentNotFound(t *testing.T) {
+	server := NewAgentServer(newController(t), testServerCfg)
+
+	req := &api.RevokeAgentTokensRequest{
+		Rid: "rid:notfound",
+	}
+	resp, err := server.RevokeAgentTokens(context.Background(), req)
+	assert.Error(t, err)
+	assert.Nil(t, resp)
+	st, ok := status.FromError(err)
+	assert.True(t, ok)
+	assert.Equal(t, codes.NotFound, st.Code())
+}
+
+func TestListAgentTokensReturnsTokenList(t *testing.T) {
+	server := NewAgentServer(newController(t), testServerCfg)
+
+	agent := registerAgent(t, server, "node-tokens")
+
+	_, err := server.GetAgentConfig(context.Background(), &api.GetAgentConfigRequest{Rid: agent.Rid})
+	assert.NoError(t, err)
+
+	req := &api.ListAgentTokensRequest{
+		Rid: agent.Rid,
+	}
+	resp, err := server.ListAgentTokens(context.Background(), req)
+	assert.NoError(t, err)
+	assert.NotNil(t, resp)
+	assert.Len(t, resp.Tokens, 1)
+	assert.Equal(t, agent.Rid, resp.Tokens[0].Rid)
+	assert.Equal(t, "config", resp.Tokens[0].Purpose)
+
+	days := int32(30)
+	req = &api.ListAgentTokensRequest{
+		ExpiringWithinDays: &days,
+	}
+	resp, err = server.ListAgentTokens(context.Background(), req)
+	assert.NoError(t, err)
+	assert.Len(t, resp.Tokens, 0)
+}
+
+func TestListAgentTokensReturnsError_InvalidArguments(t *testing.T) {
+	server := NewAgentServer(newController(t), testServerCfg)
+
+	days := int32(0)
+	req := &api.ListAgentTokensRequest{
+		ExpiringWithinDays: &days,
+	}
+	resp, err := server.ListAgentTokens(context.Background(), req)
+	assert.Error(t, err)
+	assert.Nil(t, resp)
+	st, ok := status.FromError(err)
+	assert.True(t, ok)
+	assert.Equal(t, codes.InvalidArgument, st.Code())
+
+	days = math.MaxInt32
+	resp, err = server.ListAgentTokens(context.Background(), req)
+	assert.Error(t, err)
+	assert.Nil(t, resp)
+	st, ok = status.FromError(err)
+	assert.True(t, ok)
+	assert.Equal(t, codes.InvalidArgument, st.Code())
+}
+
+func TestSuspendAndResumeAgentSucceeds(t *testing.T) {
+	server := NewAgentServer(newController(t), testServerCfg)
+
+	agent := registerAgent(t, server, "to-be-suspended")
+
+	_, err := server.SuspendAgent(context.Background(), &api.SuspendAgentRequest{Rid: agent.Rid})
+	assert.NoError(t, err)
+
+	resp, err := server.GetAgent(context.Background(), &api.GetAgentRequest{Rid: agent.Rid})
+	assert.NoError(t, err)
+	assert.False(t, resp.Active)
+
+	_, err = server.ResumeAgent(context.Background(), &api.ResumeAgentRequest{Rid: agent.Rid})
+	assert.NoError(t, err)
+
+	resp, err = server.GetAgent(context.Background(), &api.GetAgentRequest{Rid: agent.Rid})
+	assert.NoError(t, err)
+	assert.True(t, resp.Active)
+}
+
+func TestSuspendAgentReturnsError(t *testing.T) {
+	server := NewAgentServer(newController(t), testServerCfg)
+
+	_, err := server.SuspendAgent(context.Background(), &api.SuspendAgentRequest{Rid: ""})
+	st, ok := status.FromError(err)
+	assert.True(t, ok)
+	assert.Equal(t, codes.InvalidArgument, st.Code())
+
+	_, err = server.ResumeAgent(context.Background(), &api.ResumeAgentRequest{Rid: "rid:notfound"})
+	st, ok = status.FromError(err)
+	assert.True(t, ok)
+	assert.Equal(t, codes.NotFound, st.Code())
+}
+
+type fakeWatchAgentsStream struct {
+	grpc.ServerStream
+	ctx    context.Context
+	events chan *api.WatchAgentsResponse
+}
+
+func (f *fakeWatchAgentsStream) Context() context.Context {
+	return f.ctx
+}
+
+func (f *fakeWatchAgentsStream) Send(resp *api.WatchAgentsResponse) error {
+	f.events <- resp
+	return nil
+}
+
+func TestWatchAgentsStreamsEvents(t *testing.T) {
+	server := NewAgentServer(newController(t), testServerCfg)
+	registered := registerAgent(t, server, "test-host")
+
+	ctx, cancel := context.WithCancel(context.Background())
+	stream := &fakeWatchAgentsStream{ctx: ctx, events: make(chan *api.WatchAgentsResponse, 10)}
+
+	since := uint64(0)
+	done := make(chan error)
+	go func() {
+		done <- server.WatchAgents(&api.WatchAgentsRequest{SinceRevision: &since}, stream)
+	}()
+
+	event := <-stream.events
+	assert.Equal(t, "create", event.Type)
+	assert.Equal(t, registered.Rid, event.Rid)
+	assert.Equal(t, "test-host", event.Hostname)
+
+	_, err := server.SuspendAgent(context.Background(), &api.SuspendAgentRequest{Rid: registered.Rid})
+	assert.NoError(t, err)
+
+	select {
+	case event = <-stream.events:
+		assert.Equal(t, "update", event.Type)
+		assert.Equal(t, []string{"active"}, event.ChangedFields)
+	case <-time.After(time.Second):
+		t.Fatal("timeout waiting for agent event")
+	}
+
+	cancel()
+	assert.NoError(t, <-done)
+}
+
+func TestWatchAgentsReturnsError_RevisionTooOld(t *testing.T) {
+	server := NewAgentServer(newController(t), testServerCfg)
+
+	since := uint64(42)
+	stream := &fakeWatchAgentsStream{ctx: context.Background()}
+	err := server.WatchAgents(&api.WatchAgentsRequest{SinceRevision: &since}, stream)
+	assert.Error(t, err)
+	st, _ := status.FromError(err)
+	assert.Equal(t, codes.OutOfRange, st.Code())
+}
+
+func TestListAuditEventsReturnsEvents(t *testing.T) {
+	ctrl := newController(t)
+	agentServer := NewAgentServer(ctrl, testServerCfg)
+	auditServer := NewAuditServer(ctrl)
+
+	agent := registerAgent(t, agentServer, "audited-host")
+
+	_, err := agentServer.DeregisterAgent(context.Background(), &api.DeregisterAgentRequest{Rid: agent.Rid})
+	assert.NoError(t, err)
+
+	resp, err := auditServer.ListAuditEvents(context.Background(), &api.ListAuditEventsRequest{Rid: agent.Rid})
+	assert.NoError(t, err)
+	assert.Len(t, resp.Events, 2)
+	assert.Equal(t, controller.AuditActionAgentDeregister, resp.Events[0].Action)
+	assert.Equal(t, controller.AuditActionAgentRegister, resp.Events[1].Action)
+	assert.Contains(t, resp.Events[1].Diff, "audited-host")
+
+	action := controller.AuditActionAgentRegister
+	limit := int32(1)
+	resp, err = auditServer.ListAuditEvents(context.Background(), &api.ListAuditEventsRequest{Action: action, Limit: &limit})
+	assert.NoError(t, err)
+	assert.Len(t, resp.Events, 1)
+	assert.Equal(t, agent.Rid, resp.Events[0].Rid)
+}
+
+func TestListAuditEventsReturnsError_InvalidArguments(t *testing.T) {
+	server := NewAuditServer(newController(t))
+
+	since := "yesterday"
+	_, err := server.ListAuditEvents(context.Background(), &api.ListAuditEventsRequest{Since: &since})
+	assert.Error(t, err)
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+
+	limit := int32(0)
+	_, err = server.ListAuditEvents(context.Background(), &api.ListAuditEventsRequest{Limit: &limit})
+	assert.Error(t, err)
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+}
+
+func TestGetAgentUsage(t *testing.T) {
+	ctrl := newController(t)
+	server := NewAgentServer(ctrl, testServerCfg)
+
+	agent := registerAgent(t, server, "usage-host")
+
+	token, _, err := ctrl.GenerateAgentToken(agent.Rid, time.Hour, controller.Actor{Name: "test"}, controller.TokenPurposeConfig)
+	assert.NoError(t, err)
+	for _, length := range []int64{512, 1536} {
+		_, err = ctrl.AuthorizeAgentRequest(token, controller.ForwardedRequest{
+			Uri:           "/loki/loki/api/v1/push",
+			Method:        "POST",
+			ContentLength: length,
+		})
+		assert.NoError(t, err)
+	}
+	assert.NoError(t, ctrl.FlushAgentUsage())
+
+	resp, err := server.GetAgentUsage(context.Background(), &api.GetAgentUsageRequest{Rid: agent.Rid})
+	assert.NoError(t, err)
+	assert.Len(t, resp.Buckets, 1)
+	assert.Len(t, resp.Totals, 1)
+	assert.Equal(t, "loki", resp.Totals[0].Backend)
+	assert.Equal(t, int64(2), resp.Totals[0].Requests)
+	assert.Equal(t, int64(2048), resp.Totals[0].Bytes)
+
+	_, err = server.GetAgentUsage(context.Background(), &api.GetAgentUsageRequest{Rid: "rid:notfound"})
+	assert.Equal(t, codes.NotFound, status.Code(err))
+
+	since := "yesterday"
+	_, err = server.GetAgentUsage(context.Background(), &api.GetAgentUsageRequest{Rid: agent.Rid, Since: &since})
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+}
+
+func TestAgentConfigRevisionsDiffAndRollback(t *testing.T) {
+	server := NewAgentServer(newController(t), testServerCfg)
+
+	agent := registerAgent(t, server, "revisioned-host")
+
+	_, err := server.UpdateAgent(context.Background(), &api.UpdateAgentRequest{
+		Rid:        agent.Rid,
+		Labels:     []string{"env=production"},
+		UpdateMask: &fieldmaskpb.FieldMask{Paths: []string{"labels"}},
+	})
+	assert.NoError(t, err)
+
+	list, err := server.ListAgentConfigRevisions(context.Background(), &api.ListAgentConfigRevisionsRequest{Rid: agent.Rid})
+	assert.NoError(t, err)
+	assert.Len(t, list.Revisions, 2)
+	assert.Equal(t, uint64(2), list.Revisions[0].Revision)
+	assert.Equal(t, []string{"env=production"}, list.Revisions[0].Labels)
+
+	diff, err := server.DiffAgentConfig(context.Background(), &api.DiffAgentConfigRequest{Rid: agent.Rid, FromRevision: 1})
+	assert.NoError(t, err)
+	assert.Contains(t, diff.Diff, "production")
+
+	rollback, err := server.RollbackAgentConfig(context.Background(), &api.RollbackAgentConfigRequest{Rid: agent.Rid, Revision: 1})
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(3), rollback.Revision)
+
+	updated, err := server.GetAgent(context.Background(), &api.GetAgentRequest{Rid: agent.Rid})
+	assert.NoError(t, err)
+	assert.Empty(t, updated.Labels)
+}
+
+func TestAgentConfigRevisionsReturnsError_InvalidArguments(t *testing.T) {
+	server := NewAgentServer(newController(t), testServerCfg)
+
+	agent := registerAgent(t, server, "revisioned-host")
+
+	_, err := server.ListAgentConfigRevisions(context.Background(), &api.ListAgentConfigRevisionsRequest{})
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+
+	_, err = server.DiffAgentConfig(context.Background(), &api.DiffAgentConfigRequest{Rid: agent.Rid})
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+
+	_, err = server.DiffAgentConfig(context.Background(), &api.DiffAgentConfigRequest{Rid: agent.Rid, FromRevision: 9})
+	assert.Equal(t, codes.NotFound, status.Code(err))
+
+	_, err = server.RollbackAgentConfig(context.Background(), &api.RollbackAgentConfigRequest{Rid: agent.Rid})
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+
+	_, err = server.RollbackAgentConfig(context.Background(), &api.RollbackAgentConfigRequest{Rid: "non-existent-rid", Revision: 1})
+	assert.Equal(t, codes.NotFound, status.Code(err))
+}
+
+func TestCreateEnrollmentTokenReturnsToken(t *testing.T) {
+	server := NewEnrollmentServer(newController(t))
+
+	limit := int32(3)
+	resp, err := server.CreateEnrollmentToken(context.Background(), &api.CreateEnrollmentTokenRequest{
+		UsageLimit:      &limit,
+		HostnamePattern: "web-*",
+		Labels:          []string{"env=prod"},
+	})
+	assert.NoError(t, err)
+	assert.NotEmpty(t, resp.Token)
+	assert.NotEmpty(t, resp.Jti)
+
+	list, err := server.ListEnrollmentTokens(context.Background(), &api.ListEnrollmentTokensRequest{})
+	assert.NoError(t, err)
+	assert.Len(t, list.Tokens, 1)
+	assert.Equal(t, resp.Jti, list.Tokens[0].Jti)
+	assert.Equal(t, int32(3), list.Tokens[0].UsageLimit)
+	assert.Equal(t, "web-*", list.Tokens[0].HostnamePattern)
+}
+
+func TestCreateEnrollmentTokenReturnsError_InvalidArguments(t *testing.T) {
+	server := NewEnrollmentServer(newController(t))
+
+	limit := int32(0)
+	_, err := server.CreateEnrollmentToken(context.Background(), &api.CreateEnrollmentTokenRequest{UsageLimit: &limit})
+	assert.Error(t, err)
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+
+	_, err = server.CreateEnrollmentToken(context.Background(), &api.CreateEnrollmentTokenRequest{HostnamePattern: "web-["})
+	assert.Error(t, err)
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+}
+
+func TestTenantServiceManagesTenants(t *testing.T) {
+	ctrl := newController(t)
+	tenantServer := NewTenantServer(ctrl)
+	agentServer := NewAgentServer(ctrl, testServerCfg)
+
+	resp, err := tenantServer.CreateTenant(context.Background(), &api.CreateTenantRequest{Name: "team-a", Description: "Team A"})
+	assert.NoError(t, err)
+	assert.Equal(t, "team-a", resp.Name)
+
+	_, err = tenantServer.CreateTenant(context.Background(), &api.CreateTenantRequest{Name: "team-a"})
+	assert.Equal(t, codes.AlreadyExists, status.Code(err))
+
+	_, err = tenantServer.CreateTenant(context.Background(), &api.CreateTenantRequest{Name: "Team A"})
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+
+	list, err := tenantServer.ListTenants(context.Background(), &api.ListTenantsRequest{})
+	assert.NoError(t, err)
+	assert.Len(t, list.Tenants, 2)
+	assert.Equal(t, "team-a", list.Tenants[1].Name)
+
+	agent, err := agentServer.RegisterAgent(context.Background(), &api.RegisterAgentRequest{
+		Hostname:   "tenant-host",
+		LogSources: []string{"journal://"},
+		Node:       "unix",
+		Tenant:     "team-a",
+	})
+	assert.NoError(t, err)
+
+	got, err := agentServer.GetAgent(context.Background(), &api.GetAgentRequest{Rid: agent.Rid})
+	assert.NoError(t, err)
+	assert.Equal(t, "team-a", got.Tenant)
+
+	tenant := "team-a"
+	agents, err := agentServer.ListAgents(context.Background(), &api.ListAgentsRequest{Tenant: &tenant})
+	assert.NoError(t, err)
+	assert.Len(t, agents.Agents, 1)
+
+	_, err = agentServer.RegisterAgent(context.Background(), &api.RegisterAgentRequest{
+		Hostname:   "unknown-tenant-host",
+		LogSources: []string{"journal://"},
+		Node:       "unix",
+		Tenant:     "team-b",
+	})
+	assert.Equal(t, codes.FailedPrecondition, status.Code(err))
+
+	_, err = tenantServer.DeleteTenant(context.Background(), &api.DeleteTenantRequest{Name: "team-a"})
+	assert.Equal(t, codes.FailedPrecondition, status.Code(err))
+
+	_, err = tenantServer.DeleteTenant(context.Background(), &api.DeleteTenantRequest{Name: "default"})
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+
+	_, err = tenantServer.DeleteTenant(context.Background(), &api.DeleteTenantRequest{Name: "team-b"})
+	assert.Equal(t, codes.NotFound, status.Code(err))
+}
@@ -12,6 +12,7 @@ import (
 	"time"
 
 	"github.com/tschaefer/finch/internal/database"
+	"github.com/tschaefer/finch/internal/metrics"
 	"github.com/tschaefer/finch/internal/version"
 )
 
@@ -27,6 +28,7 @@ func NewServer(addr string, db *database.Database) *Server {
 
 	mux := http.NewServeMux()
 	mux.HandleFunc("/healthz", s.handleHealthz)
+	mux.Handle("/metrics", metrics.Handler())
 
 	s.server = &http.Server{
 		Addr:         addr,
@@ -79,3 +79,16 @@ func Test_HealthzHandler_MethodNotAllowed(t *testing.T) {
 
 	assert.Equal(t, http.StatusMethodNotAllowed, rec.Code)
 }
+
+func Test_MetricsHandler_ExposesFinchMetrics(t *testing.T) {
+	s := NewServer("127.0.0.1:0", newTestDB(t))
+
+	req := httptest.NewRequest(http.MethodGet, "/metrics", nil)
+	rec := httptest.NewRecorder()
+
+	s.server.Handler.ServeHTTP(rec, req)
+
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Contains(t, rec.Body.String(), "finch_dashboard_websocket_connections")
+	assert.Contains(t, rec.Body.String(), "go_goroutines")
+}
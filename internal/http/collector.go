@@ -0,0 +1,98 @@
+/*
+Copyright (c) Tobias Schäfer. All rights reserved.
+Licensed under the MIT License, see LICENSE file in the project root for details.
+*/
+package http
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"net/http"
+
+	"connectrpc.com/connect"
+
+	collectorv1 "github.com/tschaefer/finch/api/collector/v1"
+	"github.com/tschaefer/finch/internal/controller"
+)
+
+type collectorServer struct {
+	controller *controller.Controller
+}
+
+func (s *collectorServer) GetConfig(ctx context.Context, req *connect.Request[collectorv1.GetConfigRequest]) (*connect.Response[collectorv1.GetConfigResponse], error) {
+	token, claims, err := s.authenticate(ctx, req.Header(), req.Peer())
+	if err != nil {
+		return nil, err
+	}
+
+	config, err := s.controller.WithContext(ctx).GetCollectorConfig(token, claims, req.Msg.Id, req.Msg.LocalAttributes, req.Msg.Hash)
+	if err != nil {
+		return nil, collectorError(err)
+	}
+
+	return connect.NewResponse(&collectorv1.GetConfigResponse{
+		Content:     config.Content,
+		Hash:        config.Hash,
+		NotModified: config.NotModified,
+	}), nil
+}
+
+func (s *collectorServer) RegisterCollector(ctx context.Context, req *connect.Request[collectorv1.RegisterCollectorRequest]) (*connect.Response[collectorv1.RegisterCollectorResponse], error) {
+	_, claims, err := s.authenticate(ctx, req.Header(), req.Peer())
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.controller.WithContext(ctx).RegisterCollector(claims, req.Msg.Id, req.Msg.Name, req.Msg.LocalAttributes); err != nil {
+		return nil, collectorError(err)
+	}
+
+	return connect.NewResponse(&collectorv1.RegisterCollectorResponse{}), nil
+}
+
+func (s *collectorServer) UnregisterCollector(ctx context.Context, req *connect.Request[collectorv1.UnregisterCollectorRequest]) (*connect.Response[collectorv1.UnregisterCollectorResponse], error) {
+	_, claims, err := s.authenticate(ctx, req.Header(), req.Peer())
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.controller.WithContext(ctx).UnregisterCollector(claims, req.Msg.Id); err != nil {
+		return nil, collectorError(err)
+	}
+
+	return connect.NewResponse(&collectorv1.UnregisterCollectorResponse{}), nil
+}
+
+func (s *collectorServer) authenticate(ctx context.Context, header http.Header, peer connect.Peer) (string, *controller.AgentClaims, error) {
+	token, ok := bearerToken(header)
+	if !ok {
+		slog.Warn("Collector request missing bearer token", "remote_addr", peer.Addr)
+		return "", nil, connect.NewError(connect.CodeUnauthenticated, errors.New("missing bearer token"))
+	}
+
+	claims, err := s.controller.WithContext(ctx).AuthenticateAgentToken(token)
+	if errors.Is(err, controller.ErrAgentSuspended) {
+		slog.Warn("Collector request rejected for suspended agent", "remote_addr", peer.Addr, "error", err)
+		return "", nil, connect.NewError(connect.CodePermissionDenied, err)
+	}
+	if err != nil {
+		slog.Warn("Collector request failed validation", "remote_addr", peer.Addr, "error", err)
+		return "", nil, connect.NewError(connect.CodeUnauthenticated, errors.New("invalid token"))
+	}
+
+	return token, claims, nil
+}
+
+func collectorError(err error) error {
+	switch {
+	case errors.Is(err, controller.ErrInvalidCollector):
+		return connect.NewError(connect.CodeInvalidArgument, err)
+	case errors.Is(err, controller.ErrCollectorMismatch):
+		return connect.NewError(connect.CodePermissionDenied, err)
+	case errors.Is(err, controller.ErrAgentNotFound):
+		return connect.NewError(connect.CodeNotFound, err)
+	default:
+		return connect.NewError(connect.CodeInternal, err)
+	}
+}
@@ -0,0 +1,118 @@
+/*
+Copyright (c) Tobias Schäfer. All rights reserved.
+Licensed under the MIT License, see LICENSE file in the project root for details.
+*/
+package http
+
+import (
+	"context"
+	"net/http/httptest"
+	"testing"
+
+	"connectrpc.com/connect"
+	"github.com/stretchr/testify/assert"
+
+	collectorv1 "github.com/tschaefer/finch/api/collector/v1"
+	"github.com/tschaefer/finch/api/collector/v1/collectorv1connect"
+	"github.com/tschaefer/finch/internal/controller"
+)
+
+func newCollectorClient(t *testing.T, server *Server) collectorv1connect.CollectorServiceClient {
+	testServer := httptest.NewServer(server.server.Handler)
+	t.Cleanup(testServer.Close)
+
+	return collectorv1connect.NewCollectorServiceClient(testServer.Client(), testServer.URL)
+}
+
+func TestCollectorServiceServesAgentConfig(t *testing.T) {
+	ctrl := newTestController(t)
+	server := NewServer("127.0.0.1:0", ctrl, testCfg)
+	client := newCollectorClient(t, server)
+
+	rid, err := ctrl.RegisterAgent(&controller.Agent{Hostname: "test-host", Node: "unix", LogSources: []string{"journal://"}}, testActor)
+	assert.NoError(t, err)
+	token, _, err := ctrl.GenerateAgentToken(rid, 0, testActor, controller.TokenPurposeConfig)
+	assert.NoError(t, err)
+
+	register := connect.NewRequest(&collectorv1.RegisterCollectorRequest{
+		Id:              "test-host",
+		Name:            "alloy",
+		LocalAttributes: map[string]string{"os": "linux"},
+	})
+	register.Header().Set("Authorization", "Bearer "+token)
+	_, err = client.RegisterCollector(context.Background(), register)
+	assert.NoError(t, err)
+
+	get := connect.NewRequest(&collectorv1.GetConfigRequest{Id: "test-host"})
+	get.Header().Set("Authorization", "Bearer "+token)
+	resp, err := client.GetConfig(context.Background(), get)
+	assert.NoError(t, err)
+	assert.False(t, resp.Msg.NotModified)
+	assert.NotEmpty(t, resp.Msg.Hash)
+	assert.Contains(t, resp.Msg.Content, rid)
+	assert.NotContains(t, resp.Msg.Content, "logging {")
+
+	again := connect.NewRequest(&collectorv1.GetConfigRequest{Id: "test-host", Hash: resp.Msg.Hash})
+	again.Header().Set("Authorization", "Bearer "+token)
+	resp, err = client.GetConfig(context.Background(), again)
+	assert.NoError(t, err)
+	assert.True(t, resp.Msg.NotModified)
+	assert.Empty(t, resp.Msg.Content)
+
+	collectors, err := ctrl.ListCollectors(rid)
+	assert.NoError(t, err)
+	assert.Len(t, collectors, 1)
+	assert.Equal(t, "alloy", collectors[0].Name)
+	assert.Equal(t, "linux", collectors[0].Attributes["os"])
+	assert.NotNil(t, collectors[0].LastPoll)
+
+	unregister := connect.NewRequest(&collectorv1.UnregisterCollectorRequest{Id: "test-host"})
+	unregister.Header().Set("Authorization", "Bearer "+token)
+	_, err = client.UnregisterCollector(context.Background(), unregister)
+	assert.NoError(t, err)
+
+	collectors, err = ctrl.ListCollectors(rid)
+	assert.NoError(t, err)
+	assert.Empty(t, collectors)
+}
+
+func TestCollectorServiceRejectsInvalidRequests(t *testing.T) {
+	ctrl := newTestController(t)
+	server := NewServer("127.0.0.1:0", ctrl, testCfg)
+	client := newCollectorClient(t, server)
+
+	first, err := ctrl.RegisterAgent(&controller.Agent{Hostname: "first-host", Node: "unix", LogSources: []string{"journal://"}}, testActor)
+	assert.NoError(t, err)
+	second, err := ctrl.RegisterAgent(&controller.Agent{Hostname: "second-host", Node: "unix", LogSources: []string{"journal://"}}, testActor)
+	assert.NoError(t, err)
+	firstToken, _, err := ctrl.GenerateAgentToken(first, 0, testActor, controller.TokenPurposeConfig)
+	assert.NoError(t, err)
+	secondToken, _, err := ctrl.GenerateAgentToken(second, 0, testActor, controller.TokenPurposeConfig)
+	assert.NoError(t, err)
+
+	_, err = client.GetConfig(context.Background(), connect.NewRequest(&collectorv1.GetConfigRequest{Id: "first-host"}))
+	assert.Equal(t, connect.CodeUnauthenticated, connect.CodeOf(err))
+
+	req := connect.NewRequest(&collectorv1.GetConfigRequest{})
+	req.Header().Set("Authorization", "Bearer "+firstToken)
+	_, err = client.GetConfig(context.Background(), req)
+	assert.Equal(t, connect.CodeInvalidArgument, connect.CodeOf(err))
+
+	req = connect.NewRequest(&collectorv1.GetConfigRequest{Id: "first-host"})
+	req.Header().Set("Authorization", "Bearer "+firstToken)
+	_, err = client.GetConfig(context.Background(), req)
+	assert.NoError(t, err)
+
+	req = connect.NewRequest(&collectorv1.GetConfigRequest{Id: "first-host"})
+	req.Header().Set("Authorization", "Bearer "+secondToken)
+	_, err = client.GetConfig(context.Background(), req)
+	assert.Equal(t, connect.CodePermissionDenied, connect.CodeOf(err))
+
+	err = ctrl.SuspendAgent(first, 0, testActor)
+	assert.NoError(t, err)
+
+	req = connect.NewRequest(&collectorv1.GetConfigRequest{Id: "first-host"})
+	req.Header().Set("Authorization", "Bearer "+firstToken)
+	_, err = client.GetConfig(context.Background(), req)
+	assert.Equal(t, connect.CodePermissionDenied, connect.CodeOf(err))
+}
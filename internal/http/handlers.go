@@ -6,19 +6,28 @@ package http
 
 import (
 	"bytes"
+	"context"
 	"embed"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"html/template"
 	"log/slog"
 	"math"
 	"net/http"
+	"slices"
+	"strconv"
 	"strings"
 	"time"
 
 	"github.com/gorilla/websocket"
 	"github.com/tschaefer/finch/internal/controller"
+	"github.com/tschaefer/finch/internal/metrics"
+	"github.com/tschaefer/finch/internal/model"
+	"github.com/tschaefer/finch/internal/tracing"
 	"github.com/tschaefer/finch/internal/version"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
 )
 
 //go:embed templates/*
@@ -54,9 +63,13 @@ type AgentData struct {
 	MetricsTargets    []string
 	Profiles          bool
 	RegisteredAt      string
+	LastSeen          string
 	Active            bool
+	Stale             bool
+	ResourceVersion   uint64
 	CanViewToken      bool
 	CanDownloadConfig bool
+	CanSuspend        bool
 }
 
 type AgentListData struct {
@@ -73,12 +86,14 @@ type StatsData struct {
 	TotalAgents     int
 	MetricsEnabled  int
 	ProfilesEnabled int
+	StaleAgents     int
 }
 
 type ServiceInfoData struct {
-	Hostname string
-	Release  string
-	Commit   string
+	Hostname     string
+	Release      string
+	Commit       string
+	CanViewAudit bool
 }
 
 type TokenData struct {
@@ -87,6 +102,40 @@ type TokenData struct {
 	ResourceID string
 }
 
+type AuditChangeData struct {
+	Field  string
+	Before string
+	After  string
+}
+
+type AuditEventData struct {
+	Timestamp  string
+	Actor      string
+	Action     string
+	ResourceID string
+	SourceIP   string
+	Changes    []AuditChangeData
+}
+
+type AuditData struct {
+	Events []AuditEventData
+}
+
+type UsageAgentData struct {
+	ResourceID string
+	Hostname   string
+	Requests   int64
+	Bytes      string
+	Logs       string
+	Metrics    string
+	Profiles   string
+}
+
+type UsageData struct {
+	Window string
+	Agents []UsageAgentData
+}
+
 func (s *Server) handleLogin(w http.ResponseWriter, r *http.Request) {
 	var token, errorMsg string
 	switch r.Method {
@@ -169,10 +218,16 @@ func (s *Server) handleDashboard(w http.ResponseWriter, r *http.Request) {
 		commit = commit[:7]
 	}
 
+	claims, ok := r.Context().Value(dashboardClaimsKey).(*controller.DashboardClaims)
+	if !ok {
+		claims = &controller.DashboardClaims{Role: controller.RoleViewer, Scope: []string{}}
+	}
+
 	data := ServiceInfoData{
-		Hostname: s.config.Hostname(),
-		Release:  version.Version,
-		Commit:   commit,
+		Hostname:     s.config.Hostname(),
+		Release:      version.Version,
+		Commit:       commit,
+		CanViewAudit: s.controller.CanViewAudit(claims),
 	}
 
 	if err := templates.ExecuteTemplate(w, "dashboard.html", data); err != nil {
@@ -204,14 +259,23 @@ func (s *Server) handleWebSocket(w http.ResponseWriter, r *http.Request) {
 		_ = conn.Close()
 	}()
 
+	metrics.WebSocketConnections.Inc()
+	defer metrics.WebSocketConnections.Dec()
+
+	actor := controller.DashboardActor(claims)
+	actor.Address = remoteAddr(r)
+
 	currentPage := 1
 	currentSearch := ""
 
-	s.sendStatsUpdate(conn, claims)
+	ctx, cancel := context.WithCancel(r.Context())
+	defer cancel()
+
+	s.sendStatsUpdate(ctx, conn, claims)
 	s.sendEndpointsUpdate(conn)
-	s.sendAgentsUpdate(conn, currentPage, currentSearch, claims)
+	s.sendAgentsUpdate(ctx, conn, currentPage, currentSearch, claims)
 
-	agentEvents := s.controller.SubscribeAgentEvents()
+	agentEvents := s.controller.SubscribeAgentEvents(ctx)
 
 	done := make(chan struct{})
 
@@ -238,7 +302,17 @@ func (s *Server) handleWebSocket(w http.ResponseWriter, r *http.Request) {
 				}
 			}
 
-			s.handleWSMessage(conn, msg, claims)
+			spanCtx, span := tracing.StartSpan(ctx, "websocket.message",
+				trace.WithNewRoot(),
+				trace.WithLinks(trace.LinkFromContext(r.Context())),
+				trace.WithSpanKind(trace.SpanKindServer),
+				trace.WithAttributes(
+					attribute.String("finch.websocket.message_type", msg.Type),
+					attribute.String("finch.actor", actor.Name),
+				),
+			)
+			s.handleWSMessage(spanCtx, conn, msg, claims, actor)
+			span.End()
 		}
 	}()
 
@@ -252,16 +326,22 @@ func (s *Server) handleWebSocket(w http.ResponseWriter, r *http.Request) {
 				_ = conn.WriteMessage(websocket.CloseMessage, websocket.FormatCloseMessage(websocket.CloseNormalClosure, "Token expired"))
 				return
 			}
-		case <-agentEvents:
-			s.sendAgentsUpdate(conn, currentPage, currentSearch, claims)
-			s.sendStatsUpdate(conn, claims)
+		case event, ok := <-agentEvents:
+			if !ok {
+				return
+			}
+			if event.Type == model.AgentEventSeen {
+				continue
+			}
+			s.sendAgentsUpdate(ctx, conn, currentPage, currentSearch, claims)
+			s.sendStatsUpdate(ctx, conn, claims)
 		case <-done:
 			return
 		}
 	}
 }
 
-func (s *Server) handleWSMessage(conn *websocket.Conn, msg WSMessage, claims *controller.DashboardClaims) {
+func (s *Server) handleWSMessage(ctx context.Context, conn *websocket.Conn, msg WSMessage, claims *controller.DashboardClaims, actor controller.Actor) {
 	switch msg.Type {
 	case "get_agents":
 		var params struct {
@@ -272,27 +352,182 @@ func (s *Server) handleWSMessage(conn *websocket.Conn, msg WSMessage, claims *co
 			if params.Page < 1 {
 				params.Page = 1
 			}
-			s.sendAgentsUpdate(conn, params.Page, params.Search, claims)
+			s.sendAgentsUpdate(ctx, conn, params.Page, params.Search, claims)
 		}
 	case "get_token":
 		var params struct {
 			RID string `json:"rid"`
 		}
 		if err := json.Unmarshal(msg.Data, &params); err == nil {
-			s.sendToken(conn, params.RID, claims)
+			s.sendToken(ctx, conn, params.RID, claims, actor)
 		}
 	case "download_config":
 		var params struct {
 			RID string `json:"rid"`
 		}
 		if err := json.Unmarshal(msg.Data, &params); err == nil {
-			s.sendConfig(conn, params.RID, claims)
+			s.sendConfig(ctx, conn, params.RID, claims, actor)
+		}
+	case "suspend_agent", "resume_agent":
+		var params struct {
+			RID             string `json:"rid"`
+			ResourceVersion uint64 `json:"resource_version"`
+		}
+		if err := json.Unmarshal(msg.Data, &params); err == nil {
+			s.setAgentSuspended(ctx, conn, params.RID, params.ResourceVersion, msg.Type == "suspend_agent", claims, actor)
 		}
+	case "get_audit":
+		s.sendAudit(ctx, conn, claims)
+	case "get_usage":
+		s.sendUsage(ctx, conn, claims)
 	}
 }
 
-func (s *Server) sendAgentsUpdate(conn *websocket.Conn, page int, search string, claims *controller.DashboardClaims) {
-	agentList, err := s.controller.ListAgents()
+func (s *Server) handleAgentConfig(w http.ResponseWriter, r *http.Request) {
+	ctrl := s.controller.WithContext(r.Context())
+	if r.Method != http.MethodGet && r.Method != http.MethodHead {
+		w.Header().Set("Allow", "GET, HEAD")
+		http.Error(w, "Method Not Allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	token, ok := bearerToken(r.Header)
+	if !ok {
+		s.log(r, slog.LevelWarn, "Agent config request missing bearer token")
+		w.Header().Set("WWW-Authenticate", "Bearer")
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	claims, err := ctrl.AuthenticateAgentToken(token)
+	if errors.Is(err, controller.ErrAgentSuspended) {
+		s.log(r, slog.LevelWarn, "Agent config request rejected for suspended agent", "error", err)
+		http.Error(w, "Forbidden", http.StatusForbidden)
+		return
+	}
+	if err != nil {
+		s.log(r, slog.LevelWarn, "Agent config request failed validation", "error", err)
+		w.Header().Set("WWW-Authenticate", "Bearer")
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	config, etag, err := ctrl.PullAgentConfig(token, claims)
+	if errors.Is(err, controller.ErrAgentNotFound) {
+		http.Error(w, "Not Found", http.StatusNotFound)
+		return
+	}
+	if err != nil {
+		s.log(r, slog.LevelError, "Failed to render agent config", "rid", claims.ResourceId, "error", err)
+		http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("ETag", etag)
+	w.Header().Set("Cache-Control", "no-cache")
+
+	if etagMatches(r.Header.Get("If-None-Match"), etag) {
+		w.WriteHeader(http.StatusNotModified)
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+	w.Header().Set("Content-Length", strconv.Itoa(len(config)))
+	w.WriteHeader(http.StatusOK)
+	if r.Method == http.MethodGet {
+		_, _ = w.Write(config)
+	}
+}
+
+func (s *Server) handleAgentEnroll(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		w.Header().Set("Allow", "POST")
+		http.Error(w, "Method Not Allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	token, ok := bearerToken(r.Header)
+	if !ok {
+		s.log(r, slog.LevelWarn, "Enrollment request missing bearer token")
+		w.Header().Set("WWW-Authenticate", "Bearer")
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	var facts controller.Agent
+	if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, 1<<20)).Decode(&facts); err != nil {
+		s.log(r, slog.LevelWarn, "Enrollment request has invalid body", "error", err)
+		http.Error(w, "Bad Request", http.StatusBadRequest)
+		return
+	}
+
+	enrollment, err := s.controller.WithContext(r.Context()).EnrollAgent(token, &facts, remoteAddr(r))
+	if err != nil {
+		s.log(r, slog.LevelWarn, "Enrollment request rejected", "hostname", facts.Hostname, "error", err)
+		switch {
+		case errors.Is(err, controller.ErrInvalidEnrollmentToken):
+			w.Header().Set("WWW-Authenticate", "Bearer")
+			http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		case errors.Is(err, controller.ErrEnrollmentTokenExhausted), errors.Is(err, controller.ErrHostnameNotAllowed):
+			http.Error(w, err.Error(), http.StatusForbidden)
+		case errors.Is(err, controller.ErrAgentAlreadyExists):
+			http.Error(w, err.Error(), http.StatusConflict)
+		case errors.Is(err, controller.ErrInvalidEnrollment):
+			http.Error(w, err.Error(), http.StatusBadRequest)
+		default:
+			http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+		}
+		return
+	}
+
+	s.log(r, slog.LevelInfo, "Agent enrolled", "hostname", facts.Hostname, "rid", enrollment.ResourceId)
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	_ = json.NewEncoder(w).Encode(map[string]string{
+		"rid":    enrollment.ResourceId,
+		"config": string(enrollment.Config),
+	})
+}
+
+func (s *Server) handleJWKS(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet && r.Method != http.MethodHead {
+		w.Header().Set("Allow", "GET, HEAD")
+		http.Error(w, "Method Not Allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", "public, max-age=300")
+	if r.Method == http.MethodHead {
+		return
+	}
+	_ = json.NewEncoder(w).Encode(s.controller.JWKS())
+}
+
+func bearerToken(header http.Header) (string, bool) {
+	authHeader := header.Get("Authorization")
+	if !strings.HasPrefix(authHeader, "Bearer ") {
+		return "", false
+	}
+
+	return strings.TrimPrefix(authHeader, "Bearer "), true
+}
+
+func etagMatches(header, etag string) bool {
+	for candidate := range strings.SplitSeq(header, ",") {
+		candidate = strings.TrimSpace(candidate)
+		if candidate == "*" || strings.TrimPrefix(candidate, "W/") == etag {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (s *Server) sendAgentsUpdate(ctx context.Context, conn *websocket.Conn, page int, search string, claims *controller.DashboardClaims) {
+	ctrl := s.controller.WithContext(ctx)
+	agentList, err := ctrl.ListAgents()
 	if err != nil {
 		slog.Error("Failed to list agents", "error", err)
 		return
@@ -300,15 +535,20 @@ func (s *Server) sendAgentsUpdate(conn *websocket.Conn, page int, search string,
 
 	filtered := []AgentData{}
 	for _, a := range agentList {
-		agent, err := s.controller.GetAgent(a["rid"])
+		agent, err := ctrl.GetAgent(a["rid"])
 		if err != nil {
 			continue
 		}
 
-		if !s.controller.CanAccessAgent(claims, agent.ResourceId, agent.Hostname) {
+		if !ctrl.CanAccessAgent(claims, agent.ResourceId, agent.Hostname, agent.Tenant) {
 			continue
 		}
 
+		lastSeen := ""
+		if agent.LastSeen != nil {
+			lastSeen = agent.LastSeen.Format("2006-01-02 15:04:05")
+		}
+
 		agentData := AgentData{
 			ResourceID:        agent.ResourceId,
 			Hostname:          agent.Hostname,
@@ -318,9 +558,13 @@ func (s *Server) sendAgentsUpdate(conn *websocket.Conn, page int, search string,
 			MetricsTargets:    agent.MetricsTargets,
 			Profiles:          agent.Profiles,
 			RegisteredAt:      agent.RegisteredAt.Format("2006-01-02 15:04:05"),
-			Active:            true,
-			CanViewToken:      s.controller.CanViewTokens(claims),
-			CanDownloadConfig: s.controller.CanDownloadConfig(claims),
+			LastSeen:          lastSeen,
+			Active:            agent.Active,
+			Stale:             agent.Stale,
+			ResourceVersion:   agent.ResourceVersion,
+			CanViewToken:      ctrl.CanViewTokens(claims),
+			CanDownloadConfig: ctrl.CanDownloadConfig(claims),
+			CanSuspend:        ctrl.CanSuspendAgents(claims),
 		}
 
 		if search == "" {
@@ -386,8 +630,9 @@ func (s *Server) sendAgentsUpdate(conn *websocket.Conn, page int, search string,
 	conn.WriteJSON(response)
 }
 
-func (s *Server) sendStatsUpdate(conn *websocket.Conn, claims *controller.DashboardClaims) {
-	agentList, err := s.controller.ListAgents()
+func (s *Server) sendStatsUpdate(ctx context.Context, conn *websocket.Conn, claims *controller.DashboardClaims) {
+	ctrl := s.controller.WithContext(ctx)
+	agentList, err := ctrl.ListAgents()
 	if err != nil {
 		slog.Error("Failed to list agents", "error", err)
 		return
@@ -398,11 +643,11 @@ func (s *Server) sendStatsUpdate(conn *websocket.Conn, claims *controller.Dashbo
 	}
 
 	for _, a := range agentList {
-		agent, err := s.controller.GetAgent(a["rid"])
+		agent, err := ctrl.GetAgent(a["rid"])
 		if err != nil {
 			continue
 		}
-		if !s.controller.CanAccessAgent(claims, agent.ResourceId, agent.Hostname) {
+		if !ctrl.CanAccessAgent(claims, agent.ResourceId, agent.Hostname, agent.Tenant) {
 			continue
 		}
 		stats.TotalAgents++
@@ -412,6 +657,9 @@ func (s *Server) sendStatsUpdate(conn *websocket.Conn, claims *controller.Dashbo
 		if agent.Profiles {
 			stats.ProfilesEnabled++
 		}
+		if agent.Stale {
+			stats.StaleAgents++
+		}
 	}
 
 	var buf bytes.Buffer
@@ -445,8 +693,9 @@ func (s *Server) sendEndpointsUpdate(conn *websocket.Conn) {
 	conn.WriteJSON(response)
 }
 
-func (s *Server) sendToken(conn *websocket.Conn, rid string, claims *controller.DashboardClaims) {
-	if !s.controller.CanViewTokens(claims) {
+func (s *Server) sendToken(ctx context.Context, conn *websocket.Conn, rid string, claims *controller.DashboardClaims, actor controller.Actor) {
+	ctrl := s.controller.WithContext(ctx)
+	if !ctrl.CanViewTokens(claims) {
 		slog.Warn("Unauthorized token access attempt", "rid", rid, "role", claims.Role)
 		response := map[string]string{
 			"type":  "token_error",
@@ -456,18 +705,18 @@ func (s *Server) sendToken(conn *websocket.Conn, rid string, claims *controller.
 		return
 	}
 
-	agent, err := s.controller.GetAgent(rid)
+	agent, err := ctrl.GetAgent(rid)
 	if err != nil {
 		slog.Error("Failed to get agent", "rid", rid, "error", err)
 		return
 	}
 
-	if !s.controller.CanAccessAgent(claims, agent.ResourceId, agent.Hostname) {
+	if !ctrl.CanAccessAgent(claims, agent.ResourceId, agent.Hostname, agent.Tenant) {
 		slog.Warn("Unauthorized agent access attempt", "rid", rid, "scope", claims.Scope)
 		return
 	}
 
-	token, expiresAt, err := s.controller.GenerateAgentToken(agent.ResourceId, 0)
+	token, expiresAt, err := ctrl.GenerateAgentToken(agent.ResourceId, 0, actor, controller.TokenPurposeDashboard)
 	if err != nil {
 		slog.Error("Failed to generate token", "rid", rid, "error", err)
 		return
@@ -493,8 +742,9 @@ func (s *Server) sendToken(conn *websocket.Conn, rid string, claims *controller.
 	conn.WriteJSON(response)
 }
 
-func (s *Server) sendConfig(conn *websocket.Conn, rid string, claims *controller.DashboardClaims) {
-	if !s.controller.CanDownloadConfig(claims) {
+func (s *Server) sendConfig(ctx context.Context, conn *websocket.Conn, rid string, claims *controller.DashboardClaims, actor controller.Actor) {
+	ctrl := s.controller.WithContext(ctx)
+	if !ctrl.CanDownloadConfig(claims) {
 		slog.Warn("Unauthorized config download attempt", "rid", rid, "role", claims.Role)
 		response := map[string]string{
 			"type":  "config_error",
@@ -504,7 +754,7 @@ func (s *Server) sendConfig(conn *websocket.Conn, rid string, claims *controller
 		return
 	}
 
-	agent, err := s.controller.GetAgent(rid)
+	agent, err := ctrl.GetAgent(rid)
 	if err != nil {
 		slog.Error("Failed to get agent", "rid", rid, "error", err)
 		response := map[string]string{
@@ -515,7 +765,7 @@ func (s *Server) sendConfig(conn *websocket.Conn, rid string, claims *controller
 		return
 	}
 
-	if !s.controller.CanAccessAgent(claims, agent.ResourceId, agent.Hostname) {
+	if !ctrl.CanAccessAgent(claims, agent.ResourceId, agent.Hostname, agent.Tenant) {
 		slog.Warn("Unauthorized config access attempt", "rid", rid, "scope", claims.Scope)
 		response := map[string]string{
 			"type":  "config_error",
@@ -525,7 +775,7 @@ func (s *Server) sendConfig(conn *websocket.Conn, rid string, claims *controller
 		return
 	}
 
-	config, err := s.controller.CreateAgentConfig(rid)
+	config, err := ctrl.CreateAgentConfig(rid, actor)
 	if err != nil {
 		slog.Error("Failed to create agent config", "rid", rid, "error", err)
 		response := map[string]string{
@@ -544,3 +794,192 @@ func (s *Server) sendConfig(conn *websocket.Conn, rid string, claims *controller
 	}
 	conn.WriteJSON(response)
 }
+
+func (s *Server) setAgentSuspended(ctx context.Context, conn *websocket.Conn, rid string, version uint64, suspend bool, claims *controller.DashboardClaims, actor controller.Actor) {
+	ctrl := s.controller.WithContext(ctx)
+	if !ctrl.CanSuspendAgents(claims) {
+		slog.Warn("Unauthorized agent suspend attempt", "rid", rid, "role", claims.Role)
+		response := map[string]string{
+			"type":  "suspend_error",
+			"error": "Unauthorized",
+		}
+		conn.WriteJSON(response)
+		return
+	}
+
+	agent, err := ctrl.GetAgent(rid)
+	if err != nil {
+		slog.Error("Failed to get agent", "rid", rid, "error", err)
+		response := map[string]string{
+			"type":  "suspend_error",
+			"error": "Failed to get agent",
+		}
+		conn.WriteJSON(response)
+		return
+	}
+
+	if !ctrl.CanAccessAgent(claims, agent.ResourceId, agent.Hostname, agent.Tenant) {
+		slog.Warn("Unauthorized agent suspend attempt", "rid", rid, "scope", claims.Scope)
+		response := map[string]string{
+			"type":  "suspend_error",
+			"error": "Unauthorized",
+		}
+		conn.WriteJSON(response)
+		return
+	}
+
+	if suspend {
+		err = ctrl.SuspendAgent(rid, version, actor)
+	} else {
+		err = ctrl.ResumeAgent(rid, version, actor)
+	}
+	if errors.Is(err, controller.ErrAgentConflict) {
+		slog.Warn("Agent state change conflict", "rid", rid, "suspend", suspend, "error", err)
+		response := map[string]string{
+			"type":  "suspend_error",
+			"error": "Agent was modified by someone else, please review and retry",
+		}
+		conn.WriteJSON(response)
+		return
+	}
+	if err != nil {
+		slog.Error("Failed to change agent state", "rid", rid, "suspend", suspend, "error", err)
+		response := map[string]string{
+			"type":  "suspend_error",
+			"error": "Failed to change agent state",
+		}
+		conn.WriteJSON(response)
+		return
+	}
+}
+
+func (s *Server) sendAudit(ctx context.Context, conn *websocket.Conn, claims *controller.DashboardClaims) {
+	if !s.controller.CanViewAudit(claims) {
+		slog.Warn("Unauthorized audit log access attempt", "role", claims.Role)
+		response := map[string]string{
+			"type":  "audit_error",
+			"error": "Unauthorized",
+		}
+		conn.WriteJSON(response)
+		return
+	}
+
+	events, err := s.controller.WithContext(ctx).ListAuditEvents(controller.AuditFilter{})
+	if err != nil {
+		slog.Error("Failed to list audit events", "error", err)
+		response := map[string]string{
+			"type":  "audit_error",
+			"error": "Failed to list audit events",
+		}
+		conn.WriteJSON(response)
+		return
+	}
+
+	data := AuditData{
+		Events: make([]AuditEventData, 0, len(events)),
+	}
+	for _, e := range events {
+		changes := make([]AuditChangeData, 0, len(e.Diff))
+		for field, change := range e.Diff {
+			changes = append(changes, AuditChangeData{
+				Field:  field,
+				Before: formatAuditValue(change.Before),
+				After:  formatAuditValue(change.After),
+			})
+		}
+		slices.SortFunc(changes, func(a, b AuditChangeData) int {
+			return strings.Compare(a.Field, b.Field)
+		})
+
+		data.Events = append(data.Events, AuditEventData{
+			Timestamp:  e.CreatedAt.Format("2006-01-02 15:04:05"),
+			Actor:      e.Actor,
+			Action:     e.Action,
+			ResourceID: e.ResourceId,
+			SourceIP:   e.SourceIp,
+			Changes:    changes,
+		})
+	}
+
+	var buf bytes.Buffer
+	if err := templates.ExecuteTemplate(&buf, "audit.html", data); err != nil {
+		slog.Error("Failed to render audit template", "error", err)
+		return
+	}
+
+	response := WSResponse{
+		Type: "audit",
+		HTML: buf.String(),
+	}
+	conn.WriteJSON(response)
+}
+
+func (s *Server) sendUsage(ctx context.Context, conn *websocket.Conn, claims *controller.DashboardClaims) {
+	totals, err := s.controller.WithContext(ctx).TopAgentUsage(time.Time{}, time.Time{}, controller.DefaultTopTalkersLimit, claims)
+	if err != nil {
+		slog.Error("Failed to list agent usage", "error", err)
+		response := map[string]string{
+			"type":  "usage_error",
+			"error": "Failed to list agent usage",
+		}
+		conn.WriteJSON(response)
+		return
+	}
+
+	data := UsageData{
+		Window: fmt.Sprintf("%.0f hours", controller.DefaultUsageWindow.Hours()),
+		Agents: make([]UsageAgentData, 0, len(totals)),
+	}
+	for _, total := range totals {
+		data.Agents = append(data.Agents, UsageAgentData{
+			ResourceID: total.ResourceId,
+			Hostname:   total.Hostname,
+			Requests:   total.Requests,
+			Bytes:      formatBytes(total.Bytes),
+			Logs:       formatBytes(total.Backends[controller.BackendLoki].Bytes),
+			Metrics:    formatBytes(total.Backends[controller.BackendMimir].Bytes),
+			Profiles:   formatBytes(total.Backends[controller.BackendPyroscope].Bytes),
+		})
+	}
+
+	var buf bytes.Buffer
+	if err := templates.ExecuteTemplate(&buf, "usage.html", data); err != nil {
+		slog.Error("Failed to render usage template", "error", err)
+		return
+	}
+
+	response := WSResponse{
+		Type: "usage",
+		HTML: buf.String(),
+	}
+	conn.WriteJSON(response)
+}
+
+func formatBytes(n int64) string {
+	const unit = 1024
+	if n < unit {
+		return fmt.Sprintf("%d B", n)
+	}
+
+	div, exp := int64(unit), 0
+	for m := n / unit; m >= unit; m /= unit {
+		div *= unit
+		exp++
+	}
+	return fmt.Sprintf("%.1f %ciB", float64(n)/float64(div), "KMGTPE"[exp])
+}
+
+func formatAuditValue(value any) string {
+	if value == nil {
+		return ""
+	}
+	if s, ok := value.(string); ok {
+		return s
+	}
+
+	data, err := json.Marshal(value)
+	if err != nil {
+		return fmt.Sprintf("%v", value)
+	}
+	return string(data)
+}
@@ -5,15 +5,20 @@ Licensed under the MIT License, see LICENSE file in the project root for details
 package http
 
 import (
+	"context"
 	"encoding/json"
 	"net/http"
 	"net/http/httptest"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/gorilla/websocket"
 	"github.com/stretchr/testify/assert"
+	"github.com/tschaefer/finch/internal/config"
 	"github.com/tschaefer/finch/internal/controller"
+	"github.com/tschaefer/finch/internal/database"
+	"github.com/tschaefer/finch/internal/model"
 )
 
 var upgrader = websocket.Upgrader{
@@ -28,7 +33,7 @@ func TestHandleDashboardRendersTemplate(t *testing.T) {
 	ctrl := newTestController(t)
 	server := NewServer("127.0.0.1:0", ctrl, testCfg)
 
-	resp, err := ctrl.GenerateDashboardToken(1800, controller.RoleOperator, []string{})
+	resp, err := ctrl.GenerateDashboardToken(1800, controller.RoleOperator, []string{}, testActor)
 	assert.NoError(t, err)
 
 	req := httptest.NewRequest(http.MethodGet, "/dashboard", nil)
@@ -63,7 +68,7 @@ func TestHandleWebSocketUpgrade(t *testing.T) {
 	ctrl := newTestController(t)
 	server := NewServer("127.0.0.1:0", ctrl, testCfg)
 
-	resp, err := ctrl.GenerateDashboardToken(1800, controller.RoleOperator, []string{})
+	resp, err := ctrl.GenerateDashboardToken(1800, controller.RoleOperator, []string{}, testActor)
 	assert.NoError(t, err)
 
 	testServer := httptest.NewServer(server.server.Handler)
@@ -133,7 +138,7 @@ func TestWebSocketSendsStatsUpdate(t *testing.T) {
 			_ = conn.Close()
 		}()
 
-		server.sendStatsUpdate(conn, &controller.DashboardClaims{Role: controller.RoleOperator, Scope: []string{}})
+		server.sendStatsUpdate(context.Background(), conn, &controller.DashboardClaims{Role: controller.RoleOperator, Scope: []string{}})
 	}))
 	defer testServer.Close()
 
@@ -195,7 +200,7 @@ func TestWebSocketSendsAgentsUpdate(t *testing.T) {
 		}
 		defer func() { _ = conn.Close() }()
 
-		server.sendAgentsUpdate(conn, 1, "", &controller.DashboardClaims{Role: controller.RoleOperator, Scope: []string{}})
+		server.sendAgentsUpdate(context.Background(), conn, 1, "", &controller.DashboardClaims{Role: controller.RoleOperator, Scope: []string{}})
 	}))
 	defer testServer.Close()
 
@@ -229,7 +234,7 @@ func TestWebSocketHandlesGetAgentsMessage(t *testing.T) {
 			Type: "get_agents",
 			Data: json.RawMessage(`{"page": 1, "search": ""}`),
 		}
-		server.handleWSMessage(conn, msg, &controller.DashboardClaims{Role: controller.RoleOperator, Scope: []string{}})
+		server.handleWSMessage(context.Background(), conn, msg, &controller.DashboardClaims{Role: controller.RoleOperator, Scope: []string{}}, testActor)
 	}))
 	defer testServer.Close()
 
@@ -253,7 +258,7 @@ func TestWebSocketHandlesDownloadConfigMessage(t *testing.T) {
 		Node:       "unix",
 		LogSources: []string{"journal://"},
 	}
-	rid, err := ctrl.RegisterAgent(agentData)
+	rid, err := ctrl.RegisterAgent(agentData, testActor)
 	assert.NoError(t, err)
 
 	testServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
@@ -269,7 +274,7 @@ func TestWebSocketHandlesDownloadConfigMessage(t *testing.T) {
 			Type: "download_config",
 			Data: json.RawMessage(`{"rid": "` + rid + `"}`),
 		}
-		server.handleWSMessage(conn, msg, &controller.DashboardClaims{Role: controller.RoleAdmin, Scope: []string{}})
+		server.handleWSMessage(context.Background(), conn, msg, &controller.DashboardClaims{Role: controller.RoleAdmin, Scope: []string{}}, testActor)
 	}))
 	defer testServer.Close()
 
@@ -296,7 +301,7 @@ func TestWebSocketHandlesGetTokenMessage(t *testing.T) {
 		Node:       "unix",
 		LogSources: []string{"journal://"},
 	}
-	rid, err := ctrl.RegisterAgent(agentData)
+	rid, err := ctrl.RegisterAgent(agentData, testActor)
 	assert.NoError(t, err)
 
 	testServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
@@ -312,7 +317,7 @@ func TestWebSocketHandlesGetTokenMessage(t *testing.T) {
 			Type: "get_token",
 			Data: json.RawMessage(`{"rid": "` + rid + `"}`),
 		}
-		server.handleWSMessage(conn, msg, &controller.DashboardClaims{Role: controller.RoleOperator, Scope: []string{}})
+		server.handleWSMessage(context.Background(), conn, msg, &controller.DashboardClaims{Role: controller.RoleOperator, Scope: []string{}}, testActor)
 	}))
 	defer testServer.Close()
 
@@ -341,7 +346,7 @@ func TestAgentListDataPagination(t *testing.T) {
 			Node:       "unix",
 			LogSources: []string{"journal://"},
 		}
-		_, err := ctrl.RegisterAgent(agentData)
+		_, err := ctrl.RegisterAgent(agentData, testActor)
 		assert.NoError(t, err)
 	}
 
@@ -354,7 +359,7 @@ func TestAgentListDataPagination(t *testing.T) {
 			_ = conn.Close()
 		}()
 
-		server.sendAgentsUpdate(conn, 1, "", &controller.DashboardClaims{Role: controller.RoleOperator, Scope: []string{}})
+		server.sendAgentsUpdate(context.Background(), conn, 1, "", &controller.DashboardClaims{Role: controller.RoleOperator, Scope: []string{}})
 	}))
 	defer testServer.Close()
 
@@ -380,7 +385,7 @@ func TestAgentListDataSearch(t *testing.T) {
 		Node:       "unix",
 		LogSources: []string{"journal://"},
 	}
-	_, err := ctrl.RegisterAgent(prodAgent)
+	_, err := ctrl.RegisterAgent(prodAgent, testActor)
 	assert.NoError(t, err)
 
 	devAgent := &controller.Agent{
@@ -388,7 +393,7 @@ func TestAgentListDataSearch(t *testing.T) {
 		Node:       "unix",
 		LogSources: []string{"journal://"},
 	}
-	_, err = ctrl.RegisterAgent(devAgent)
+	_, err = ctrl.RegisterAgent(devAgent, testActor)
 	assert.NoError(t, err)
 
 	testServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
@@ -400,7 +405,7 @@ func TestAgentListDataSearch(t *testing.T) {
 			_ = conn.Close()
 		}()
 
-		server.sendAgentsUpdate(conn, 1, "prod", &controller.DashboardClaims{Role: controller.RoleOperator, Scope: []string{}})
+		server.sendAgentsUpdate(context.Background(), conn, 1, "prod", &controller.DashboardClaims{Role: controller.RoleOperator, Scope: []string{}})
 	}))
 	defer testServer.Close()
 
@@ -418,3 +423,381 @@ func TestAgentListDataSearch(t *testing.T) {
 	assert.Contains(t, msg.HTML, "prod-server")
 	assert.NotContains(t, msg.HTML, "dev-server")
 }
+
+func TestWebSocketHandlesSuspendAgentMessage(t *testing.T) {
+	ctrl := newTestController(t)
+	server := NewServer("127.0.0.1:0", ctrl, testCfg)
+
+	agentData := &controller.Agent{
+		Hostname:   "test-host",
+		Node:       "unix",
+		LogSources: []string{"journal://"},
+	}
+	rid, err := ctrl.RegisterAgent(agentData, testActor)
+	assert.NoError(t, err)
+
+	testServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		conn, err := upgrader.Upgrade(w, r, nil)
+		if err != nil {
+			return
+		}
+		defer func() {
+			_ = conn.Close()
+		}()
+
+		msg := WSMessage{
+			Type: "suspend_agent",
+			Data: json.RawMessage(`{"rid": "` + rid + `"}`),
+		}
+		server.handleWSMessage(context.Background(), conn, msg, &controller.DashboardClaims{Role: controller.RoleViewer, Scope: []string{}}, testActor)
+		server.handleWSMessage(context.Background(), conn, msg, &controller.DashboardClaims{Role: controller.RoleOperator, Scope: []string{}}, testActor)
+	}))
+	defer testServer.Close()
+
+	wsURL := "ws" + strings.TrimPrefix(testServer.URL, "http")
+	ws, _, err := websocket.DefaultDialer.Dial(wsURL, nil)
+	assert.NoError(t, err)
+	defer func() {
+		_ = ws.Close()
+	}()
+
+	var msg map[string]string
+	err = ws.ReadJSON(&msg)
+	assert.NoError(t, err)
+	assert.Equal(t, "suspend_error", msg["type"])
+	assert.Equal(t, "Unauthorized", msg["error"])
+
+	assert.Eventually(t, func() bool {
+		agent, err := ctrl.GetAgent(rid)
+		return err == nil && !agent.Active
+	}, time.Second, 10*time.Millisecond)
+}
+
+func TestWebSocketHandlesSuspendAgentMessage_Conflict(t *testing.T) {
+	ctrl := newTestController(t)
+	server := NewServer("127.0.0.1:0", ctrl, testCfg)
+
+	agentData := &controller.Agent{
+		Hostname:   "test-host",
+		Node:       "unix",
+		LogSources: []string{"journal://"},
+	}
+	rid, err := ctrl.RegisterAgent(agentData, testActor)
+	assert.NoError(t, err)
+
+	err = ctrl.UpdateAgent(rid, 0, &controller.Agent{Labels: []string{"env=prod"}}, testActor, "labels")
+	assert.NoError(t, err)
+
+	testServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		conn, err := upgrader.Upgrade(w, r, nil)
+		if err != nil {
+			return
+		}
+		defer func() {
+			_ = conn.Close()
+		}()
+
+		msg := WSMessage{
+			Type: "suspend_agent",
+			Data: json.RawMessage(`{"rid": "` + rid + `", "resource_version": 1}`),
+		}
+		server.handleWSMessage(context.Background(), conn, msg, &controller.DashboardClaims{Role: controller.RoleOperator, Scope: []string{}}, testActor)
+	}))
+	defer testServer.Close()
+
+	wsURL := "ws" + strings.TrimPrefix(testServer.URL, "http")
+	ws, _, err := websocket.DefaultDialer.Dial(wsURL, nil)
+	assert.NoError(t, err)
+	defer func() {
+		_ = ws.Close()
+	}()
+
+	var msg map[string]string
+	err = ws.ReadJSON(&msg)
+	assert.NoError(t, err)
+	assert.Equal(t, "suspend_error", msg["type"])
+	assert.Contains(t, msg["error"], "modified by someone else")
+
+	agent, err := ctrl.GetAgent(rid)
+	assert.NoError(t, err)
+	assert.True(t, agent.Active)
+}
+
+func TestWebSocketHandlesGetAuditMessage(t *testing.T) {
+	ctrl := newTestController(t)
+	server := NewServer("127.0.0.1:0", ctrl, testCfg)
+
+	agentData := &controller.Agent{
+		Hostname:   "audited-host",
+		Node:       "unix",
+		LogSources: []string{"journal://"},
+	}
+	rid, err := ctrl.RegisterAgent(agentData, testActor)
+	assert.NoError(t, err)
+
+	testServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		conn, err := upgrader.Upgrade(w, r, nil)
+		if err != nil {
+			return
+		}
+		defer func() {
+			_ = conn.Close()
+		}()
+
+		msg := WSMessage{Type: "get_audit"}
+		server.handleWSMessage(context.Background(), conn, msg, &controller.DashboardClaims{Role: controller.RoleOperator, Scope: []string{}}, testActor)
+		server.handleWSMessage(context.Background(), conn, msg, &controller.DashboardClaims{Role: controller.RoleAdmin, Scope: []string{}}, testActor)
+	}))
+	defer testServer.Close()
+
+	wsURL := "ws" + strings.TrimPrefix(testServer.URL, "http")
+	ws, _, err := websocket.DefaultDialer.Dial(wsURL, nil)
+	assert.NoError(t, err)
+	defer func() {
+		_ = ws.Close()
+	}()
+
+	var denied map[string]string
+	err = ws.ReadJSON(&denied)
+	assert.NoError(t, err)
+	assert.Equal(t, "audit_error", denied["type"])
+	assert.Equal(t, "Unauthorized", denied["error"])
+
+	var msg WSResponse
+	err = ws.ReadJSON(&msg)
+	assert.NoError(t, err)
+	assert.Equal(t, "audit", msg.Type)
+	assert.Contains(t, msg.HTML, controller.AuditActionAgentRegister)
+	assert.Contains(t, msg.HTML, rid)
+}
+
+func TestWebSocketHandlesGetUsageMessage(t *testing.T) {
+	ctrl := newTestController(t)
+	server := NewServer("127.0.0.1:0", ctrl, testCfg)
+
+	agentData := &controller.Agent{
+		Hostname:   "noisy-host",
+		Node:       "unix",
+		LogSources: []string{"journal://"},
+	}
+	rid, err := ctrl.RegisterAgent(agentData, testActor)
+	assert.NoError(t, err)
+
+	token, _, err := ctrl.GenerateAgentToken(rid, time.Hour, testActor, controller.TokenPurposeConfig)
+	assert.NoError(t, err)
+	_, err = ctrl.AuthorizeAgentRequest(token, controller.ForwardedRequest{
+		Uri:           "/loki/loki/api/v1/push",
+		Method:        http.MethodPost,
+		ContentLength: 2048,
+	})
+	assert.NoError(t, err)
+
+	testServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		conn, err := upgrader.Upgrade(w, r, nil)
+		if err != nil {
+			return
+		}
+		defer func() {
+			_ = conn.Close()
+		}()
+
+		msg := WSMessage{Type: "get_usage"}
+		server.handleWSMessage(context.Background(), conn, msg, &controller.DashboardClaims{Role: controller.RoleViewer, Scope: []string{}}, testActor)
+	}))
+	defer testServer.Close()
+
+	wsURL := "ws" + strings.TrimPrefix(testServer.URL, "http")
+	ws, _, err := websocket.DefaultDialer.Dial(wsURL, nil)
+	assert.NoError(t, err)
+	defer func() {
+		_ = ws.Close()
+	}()
+
+	var msg WSResponse
+	err = ws.ReadJSON(&msg)
+	assert.NoError(t, err)
+	assert.Equal(t, "usage", msg.Type)
+	assert.Contains(t, msg.HTML, "noisy-host")
+	assert.Contains(t, msg.HTML, rid)
+	assert.Contains(t, msg.HTML, "2.0 KiB")
+}
+
+func TestHandleAgentConfigReturnsConfigWithETag(t *testing.T) {
+	ctrl := newTestController(t)
+	server := NewServer("127.0.0.1:0", ctrl, testCfg)
+
+	rid, err := ctrl.RegisterAgent(&controller.Agent{Hostname: "test-host", Node: "unix", LogSources: []string{"journal://"}}, testActor)
+	assert.NoError(t, err)
+	token, _, err := ctrl.GenerateAgentToken(rid, 0, testActor, controller.TokenPurposeConfig)
+	assert.NoError(t, err)
+
+	req := httptest.NewRequest(http.MethodGet, "/agent/config", nil)
+	req.Header.Set("Authorization", "Bearer "+token)
+	rec := httptest.NewRecorder()
+	server.server.Handler.ServeHTTP(rec, req)
+
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Contains(t, rec.Body.String(), rid)
+	assert.Contains(t, rec.Body.String(), token)
+	etag := rec.Header().Get("ETag")
+	assert.NotEmpty(t, etag)
+
+	req = httptest.NewRequest(http.MethodGet, "/agent/config", nil)
+	req.Header.Set("Authorization", "Bearer "+token)
+	req.Header.Set("If-None-Match", etag)
+	rec = httptest.NewRecorder()
+	server.server.Handler.ServeHTTP(rec, req)
+
+	assert.Equal(t, http.StatusNotModified, rec.Code)
+	assert.Empty(t, rec.Body.String())
+
+	err = ctrl.UpdateAgent(rid, 0, &controller.Agent{Labels: []string{"env=prod"}}, testActor, "labels")
+	assert.NoError(t, err)
+
+	req = httptest.NewRequest(http.MethodGet, "/agent/config", nil)
+	req.Header.Set("Authorization", "Bearer "+token)
+	req.Header.Set("If-None-Match", etag)
+	rec = httptest.NewRecorder()
+	server.server.Handler.ServeHTTP(rec, req)
+
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.NotEqual(t, etag, rec.Header().Get("ETag"))
+	assert.Contains(t, rec.Body.String(), `"env" = "prod"`)
+}
+
+func TestHandleAgentConfigRejectsInvalidRequests(t *testing.T) {
+	ctrl := newTestController(t)
+	server := NewServer("127.0.0.1:0", ctrl, testCfg)
+
+	rid, err := ctrl.RegisterAgent(&controller.Agent{Hostname: "test-host", Node: "unix", LogSources: []string{"journal://"}}, testActor)
+	assert.NoError(t, err)
+	token, _, err := ctrl.GenerateAgentToken(rid, 0, testActor, controller.TokenPurposeConfig)
+	assert.NoError(t, err)
+
+	req := httptest.NewRequest(http.MethodGet, "/agent/config", nil)
+	rec := httptest.NewRecorder()
+	server.server.Handler.ServeHTTP(rec, req)
+	assert.Equal(t, http.StatusUnauthorized, rec.Code)
+	assert.Equal(t, "Bearer", rec.Header().Get("WWW-Authenticate"))
+
+	req = httptest.NewRequest(http.MethodGet, "/agent/config", nil)
+	req.Header.Set("Authorization", "Bearer invalid")
+	rec = httptest.NewRecorder()
+	server.server.Handler.ServeHTTP(rec, req)
+	assert.Equal(t, http.StatusUnauthorized, rec.Code)
+
+	req = httptest.NewRequest(http.MethodPost, "/agent/config", nil)
+	req.Header.Set("Authorization", "Bearer "+token)
+	rec = httptest.NewRecorder()
+	server.server.Handler.ServeHTTP(rec, req)
+	assert.Equal(t, http.StatusMethodNotAllowed, rec.Code)
+
+	err = ctrl.SuspendAgent(rid, 0, testActor)
+	assert.NoError(t, err)
+
+	req = httptest.NewRequest(http.MethodGet, "/agent/config", nil)
+	req.Header.Set("Authorization", "Bearer "+token)
+	rec = httptest.NewRecorder()
+	server.server.Handler.ServeHTTP(rec, req)
+	assert.Equal(t, http.StatusForbidden, rec.Code)
+}
+
+func TestHandleAgentEnrollRegistersAgent(t *testing.T) {
+	ctrl := newTestController(t)
+	server := NewServer("127.0.0.1:0", ctrl, testCfg)
+
+	token, _, err := ctrl.CreateEnrollmentToken(controller.EnrollmentTokenSpec{LogSources: []string{"journal://"}}, testActor)
+	assert.NoError(t, err)
+
+	req := httptest.NewRequest(http.MethodPost, "/agent/enroll", strings.NewReader(`{"hostname":"test-host","node":"unix"}`))
+	req.Header.Set("Authorization", "Bearer "+token)
+	rec := httptest.NewRecorder()
+	server.server.Handler.ServeHTTP(rec, req)
+
+	assert.Equal(t, http.StatusCreated, rec.Code)
+	var body map[string]string
+	assert.NoError(t, json.Unmarshal(rec.Body.Bytes(), &body))
+	assert.NotEmpty(t, body["rid"])
+	assert.Contains(t, body["config"], body["rid"])
+
+	req = httptest.NewRequest(http.MethodPost, "/agent/enroll", strings.NewReader(`{"hostname":"other-host","node":"unix"}`))
+	req.Header.Set("Authorization", "Bearer "+token)
+	rec = httptest.NewRecorder()
+	server.server.Handler.ServeHTTP(rec, req)
+
+	assert.Equal(t, http.StatusForbidden, rec.Code)
+}
+
+func TestHandleAgentEnrollRejectsInvalidRequests(t *testing.T) {
+	ctrl := newTestController(t)
+	server := NewServer("127.0.0.1:0", ctrl, testCfg)
+
+	token, _, err := ctrl.CreateEnrollmentToken(controller.EnrollmentTokenSpec{}, testActor)
+	assert.NoError(t, err)
+
+	req := httptest.NewRequest(http.MethodPost, "/agent/enroll", strings.NewReader(`{"hostname":"test-host","node":"unix"}`))
+	rec := httptest.NewRecorder()
+	server.server.Handler.ServeHTTP(rec, req)
+	assert.Equal(t, http.StatusUnauthorized, rec.Code)
+
+	req = httptest.NewRequest(http.MethodPost, "/agent/enroll", strings.NewReader(`{"hostname":"test-host","node":"unix"}`))
+	req.Header.Set("Authorization", "Bearer invalid")
+	rec = httptest.NewRecorder()
+	server.server.Handler.ServeHTTP(rec, req)
+	assert.Equal(t, http.StatusUnauthorized, rec.Code)
+
+	req = httptest.NewRequest(http.MethodGet, "/agent/enroll", nil)
+	req.Header.Set("Authorization", "Bearer "+token)
+	rec = httptest.NewRecorder()
+	server.server.Handler.ServeHTTP(rec, req)
+	assert.Equal(t, http.StatusMethodNotAllowed, rec.Code)
+
+	req = httptest.NewRequest(http.MethodPost, "/agent/enroll", strings.NewReader(`{"hostname":`))
+	req.Header.Set("Authorization", "Bearer "+token)
+	rec = httptest.NewRecorder()
+	server.server.Handler.ServeHTTP(rec, req)
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+
+	req = httptest.NewRequest(http.MethodPost, "/agent/enroll", strings.NewReader(`{"hostname":"test-host","node":"unix"}`))
+	req.Header.Set("Authorization", "Bearer "+token)
+	rec = httptest.NewRecorder()
+	server.server.Handler.ServeHTTP(rec, req)
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+}
+
+func TestHandleJWKSReturnsKeySet(t *testing.T) {
+	cfg := config.NewFromData(&config.Data{
+		Secret:           "1suNCrW7sWlPbU+YCfdGQI7z3ZMo9Ru2GNV4h69QzaM=",
+		Id:               "test-id",
+		Hostname:         "127.0.0.1",
+		Database:         "sqlite:///:memory:",
+		SigningAlgorithm: "EdDSA",
+	}, t.TempDir())
+	db, err := database.New(cfg)
+	assert.NoError(t, err)
+	assert.NoError(t, db.Migrate())
+	ctrl := controller.New(model.New(db.Connection()), cfg)
+	assert.NoError(t, ctrl.LoadSigningKeys())
+	server := NewServer("127.0.0.1:0", ctrl, cfg)
+
+	req := httptest.NewRequest(http.MethodGet, "/.well-known/jwks.json", nil)
+	rec := httptest.NewRecorder()
+	server.server.Handler.ServeHTTP(rec, req)
+
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, "application/json", rec.Header().Get("Content-Type"))
+
+	var set struct {
+		Keys []map[string]string `json:"keys"`
+	}
+	assert.NoError(t, json.Unmarshal(rec.Body.Bytes(), &set))
+	assert.Len(t, set.Keys, 1)
+	assert.Equal(t, "OKP", set.Keys[0]["kty"])
+	assert.Equal(t, "EdDSA", set.Keys[0]["alg"])
+
+	rid, err := ctrl.RegisterAgent(&controller.Agent{Hostname: "test-host", Node: "unix", LogSources: []string{"journal://"}}, testActor)
+	assert.NoError(t, err)
+	token, _, err := ctrl.GenerateAgentToken(rid, 0, testActor, controller.TokenPurposeConfig)
+	assert.NoError(t, err)
+	assert.NoError(t, ctrl.ValidateAgentToken(token))
+}
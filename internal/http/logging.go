@@ -5,12 +5,21 @@ Licensed under the MIT License, see LICENSE file in the project root for details
 package http
 
 import (
-	"context"
 	"log/slog"
 	"net/http"
+
+	"github.com/tschaefer/finch/internal/tracing"
 )
 
 func (s *Server) log(r *http.Request, level slog.Level, msg string, args ...any) {
+	userAgent := r.Header.Get("User-Agent")
+	args = append(args, "remote_addr", remoteAddr(r), "user_agent", userAgent)
+	args = append(args, tracing.LogAttrs(r.Context())...)
+
+	slog.Log(r.Context(), level, msg, args...)
+}
+
+func remoteAddr(r *http.Request) string {
 	remoteAddr := ""
 	for _, h := range []string{"X-Forwarded-For", "X-Real-Ip"} {
 		if v := r.Header.Get(h); len(v) > 0 && v != "" {
@@ -27,9 +36,5 @@ func (s *Server) log(r *http.Request, level slog.Level, msg string, args ...any)
 			}
 		}
 	}
-	userAgent := r.Header.Get("User-Agent")
-	args = append(args, "remote_addr", remoteAddr, "user_agent", userAgent)
-
-	ctx := context.Background()
-	slog.Log(ctx, level, msg, args...)
+	return remoteAddr
 }
@@ -57,7 +57,7 @@ func TestAuthMiddlewareSucceeds_WhenValidCookie(t *testing.T) {
 	ctrl := newTestController(t)
 	server := NewServer("127.0.0.1:0", ctrl, testCfg)
 
-	resp, err := ctrl.GenerateDashboardToken(1800, controller.RoleOperator, []string{})
+	resp, err := ctrl.GenerateDashboardToken(1800, controller.RoleOperator, []string{}, testActor)
 	assert.NoError(t, err)
 
 	handlerCalled := false
@@ -14,8 +14,10 @@ import (
 
 	"github.com/gorilla/websocket"
 
+	"github.com/tschaefer/finch/api/collector/v1/collectorv1connect"
 	"github.com/tschaefer/finch/internal/config"
 	"github.com/tschaefer/finch/internal/controller"
+	"github.com/tschaefer/finch/internal/tracing"
 )
 
 type Server struct {
@@ -35,7 +37,7 @@ func NewServer(addr string, ctrl *controller.Controller, cfg *config.Config) *Se
 		config:     cfg,
 		server: &http.Server{
 			Addr:         addr,
-			Handler:      mux,
+			Handler:      tracing.HTTPHandler(mux, "finch.http"),
 			ReadTimeout:  10 * time.Second,
 			WriteTimeout: 10 * time.Second,
 			IdleTimeout:  60 * time.Second,
@@ -74,6 +76,18 @@ func NewServer(addr string, ctrl *controller.Controller, cfg *config.Config) *Se
 	secureWS := s.responseHeaders(s.authMiddleware(http.HandlerFunc(s.handleWebSocket)))
 	mux.Handle("/ws", secureWS)
 
+	agentConfig := s.responseHeaders(http.HandlerFunc(s.handleAgentConfig))
+	mux.Handle("/agent/config", agentConfig)
+
+	agentEnroll := s.responseHeaders(http.HandlerFunc(s.handleAgentEnroll))
+	mux.Handle("/agent/enroll", agentEnroll)
+
+	jwks := s.responseHeaders(http.HandlerFunc(s.handleJWKS))
+	mux.Handle("/.well-known/jwks.json", jwks)
+
+	collectorPath, collectorHandler := collectorv1connect.NewCollectorServiceHandler(&collectorServer{controller: ctrl})
+	mux.Handle(collectorPath, collectorHandler)
+
 	return s
 }
 
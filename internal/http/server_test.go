@@ -26,6 +26,8 @@ var testCfg = config.NewFromData(&config.Data{
 	Secret:    "gpFb8WTh5iELimbX3YfuvRYRh2Z2PHa8Lmoog0a25QQ=",
 }, "")
 
+var testActor = controller.Actor{Name: "test"}
+
 func newTestController(t *testing.T) *controller.Controller {
 	db, err := database.New(testCfg)
 	if err != nil {
@@ -110,7 +112,7 @@ func TestLogoutRouteExists(t *testing.T) {
 	ctrl := newTestController(t)
 	server := NewServer("127.0.0.1:0", ctrl, testCfg)
 
-	resp, err := ctrl.GenerateDashboardToken(1800, controller.RoleOperator, []string{})
+	resp, err := ctrl.GenerateDashboardToken(1800, controller.RoleOperator, []string{}, testActor)
 	assert.NoError(t, err)
 
 	req := httptest.NewRequest(http.MethodPost, "/logout", nil)
@@ -154,7 +156,7 @@ func TestLogoutRequiresPost(t *testing.T) {
 	ctrl := newTestController(t)
 	server := NewServer("127.0.0.1:0", ctrl, testCfg)
 
-	resp, err := ctrl.GenerateDashboardToken(1800, controller.RoleOperator, []string{})
+	resp, err := ctrl.GenerateDashboardToken(1800, controller.RoleOperator, []string{}, testActor)
 	assert.NoError(t, err)
 
 	req := httptest.NewRequest(http.MethodGet, "/logout", nil)
@@ -0,0 +1,528 @@
+/*
+Copyright (c) Tobias Schäfer. All rights reserved.
+Licensed under the MIT License, see LICENSE file in the project root for details.
+*/
+package keyring
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/tschaefer/finch/internal/config"
+)
+
+const (
+	AlgorithmHS256 = "HS256"
+	AlgorithmEdDSA = "EdDSA"
+	AlgorithmES256 = "ES256"
+	AlgorithmRS256 = "RS256"
+)
+
+const (
+	KeyStateActive       = "active"
+	KeyStateVerification = "verification"
+)
+
+const ConfigKeyId = "config"
+
+const ringFile = "keyring.json"
+
+const pemCreatedAt = "Created-At"
+
+var (
+	ErrUnsupportedAlgorithm = errors.New("unsupported signing algorithm")
+	ErrUnknownKey           = errors.New("unknown signing key")
+	ErrNoSigningKey         = errors.New("no signing key loaded")
+	ErrActiveKey            = errors.New("active signing key cannot be retired")
+)
+
+var signingMethods = map[string]jwt.SigningMethod{
+	AlgorithmHS256: jwt.SigningMethodHS256,
+	AlgorithmEdDSA: jwt.SigningMethodEdDSA,
+	AlgorithmES256: jwt.SigningMethodES256,
+	AlgorithmRS256: jwt.SigningMethodRS256,
+}
+
+type Key struct {
+	Id        string    `json:"kid"`
+	Algorithm string    `json:"algorithm"`
+	State     string    `json:"state"`
+	CreatedAt time.Time `json:"created_at"`
+	signer    crypto.Signer
+	secret    []byte
+}
+
+type KeyRing struct {
+	algorithm string
+	secret    []byte
+	createdAt time.Time
+	dir       string
+	mu        sync.RWMutex
+	keys      map[string]*Key
+	active    *Key
+}
+
+func New(cfg *config.Config) *KeyRing {
+	algorithm := cfg.SigningAlgorithm()
+	if algorithm == "" {
+		algorithm = AlgorithmHS256
+	}
+
+	createdAt, _ := time.Parse(time.RFC3339, cfg.CreatedAt())
+	k := &KeyRing{
+		algorithm: algorithm,
+		secret:    []byte(cfg.Secret()),
+		createdAt: createdAt.UTC(),
+		dir:       filepath.Join(cfg.Library(), "keys"),
+		keys:      make(map[string]*Key),
+	}
+
+	configKey := k.configKey(KeyStateVerification)
+	k.keys[configKey.Id] = configKey
+	if algorithm == AlgorithmHS256 {
+		configKey.State = KeyStateActive
+		k.active = configKey
+	}
+
+	return k
+}
+
+func (k *KeyRing) Algorithm() string {
+	return k.algorithm
+}
+
+func (k *KeyRing) Load() error {
+	slog.Debug("Loading signing keys", "algorithm", k.algorithm, "dir", k.dir)
+
+	if _, ok := signingMethods[k.algorithm]; !ok {
+		return fmt.Errorf("%w: %s", ErrUnsupportedAlgorithm, k.algorithm)
+	}
+
+	k.mu.Lock()
+	defer k.mu.Unlock()
+
+	if err := os.MkdirAll(k.dir, 0700); err != nil {
+		return fmt.Errorf("failed to create key directory %s: %v", k.dir, err)
+	}
+
+	keys, err := k.readRing()
+	if errors.Is(err, os.ErrNotExist) {
+		keys, err = k.bootstrap()
+	}
+	if err != nil {
+		return err
+	}
+
+	var active *Key
+	for _, key := range keys {
+		if key.State == KeyStateActive {
+			active = key
+		}
+	}
+	if active == nil {
+		return fmt.Errorf("%w: %s", ErrNoSigningKey, filepath.Join(k.dir, ringFile))
+	}
+
+	k.keys = keys
+	k.active = active
+
+	if active.Algorithm != k.algorithm {
+		slog.Info("Signing algorithm changed, rotating signing key", "from", active.Algorithm, "to", k.algorithm)
+		if _, err := k.rotate(); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (k *KeyRing) Rotate() (*Key, error) {
+	k.mu.Lock()
+	defer k.mu.Unlock()
+
+	return k.rotate()
+}
+
+func (k *KeyRing) Retire(kid string) error {
+	k.mu.Lock()
+	defer k.mu.Unlock()
+
+	key, ok := k.keys[kid]
+	if !ok {
+		return fmt.Errorf("%w: %s", ErrUnknownKey, kid)
+	}
+	if key == k.active {
+		return fmt.Errorf("%w: %s", ErrActiveKey, kid)
+	}
+
+	keys := make(map[string]*Key, len(k.keys))
+	for id, key := range k.keys {
+		if id != kid {
+			keys[id] = key
+		}
+	}
+	if err := k.writeRing(keys); err != nil {
+		return err
+	}
+	k.keys = keys
+
+	if kid != ConfigKeyId {
+		file := filepath.Join(k.dir, kid+".pem")
+		if err := os.Remove(file); err != nil && !errors.Is(err, os.ErrNotExist) {
+			return fmt.Errorf("failed to remove signing key %s: %v", file, err)
+		}
+	}
+
+	return nil
+}
+
+func (k *KeyRing) Sign(claims jwt.Claims) (string, string, error) {
+	k.mu.RLock()
+	active := k.active
+	k.mu.RUnlock()
+	if active == nil {
+		return "", "", ErrNoSigningKey
+	}
+
+	token := jwt.NewWithClaims(signingMethods[active.Algorithm], claims)
+	token.Header["kid"] = active.Id
+
+	var key any = active.signer
+	if active.secret != nil {
+		key = active.secret
+	}
+	tokenString, err := token.SignedString(key)
+	if err != nil {
+		return "", "", err
+	}
+
+	return tokenString, active.Id, nil
+}
+
+func (k *KeyRing) Keyfunc(token *jwt.Token) (any, error) {
+	kid, _ := token.Header["kid"].(string)
+	if kid == "" {
+		kid = ConfigKeyId
+	}
+
+	k.mu.RLock()
+	key, ok := k.keys[kid]
+	k.mu.RUnlock()
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", ErrUnknownKey, kid)
+	}
+	if token.Method.Alg() != key.Algorithm {
+		return nil, fmt.Errorf("unexpected signing method: %v", token.Header["alg"])
+	}
+
+	if key.secret != nil {
+		return key.secret, nil
+	}
+	return key.signer.Public(), nil
+}
+
+func (k *KeyRing) Keys() []Key {
+	k.mu.RLock()
+	defer k.mu.RUnlock()
+
+	keys := make([]Key, 0, len(k.keys))
+	for _, key := range k.keys {
+		keys = append(keys, *key)
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		return keys[i].CreatedAt.Before(keys[j].CreatedAt)
+	})
+
+	return keys
+}
+
+func (k *KeyRing) rotate() (*Key, error) {
+	key, err := k.generate()
+	if err != nil {
+		return nil, err
+	}
+
+	keys := make(map[string]*Key, len(k.keys)+1)
+	for id, existing := range k.keys {
+		verification := *existing
+		verification.State = KeyStateVerification
+		keys[id] = &verification
+	}
+	keys[key.Id] = key
+
+	if err := k.writeRing(keys); err != nil {
+		return nil, err
+	}
+	k.keys = keys
+	k.active = key
+
+	slog.Info("Rotated signing key", "kid", key.Id, "algorithm", key.Algorithm)
+	return key, nil
+}
+
+func (k *KeyRing) bootstrap() (map[string]*Key, error) {
+	files, err := filepath.Glob(filepath.Join(k.dir, "*.pem"))
+	if err != nil {
+		return nil, err
+	}
+
+	keys := make(map[string]*Key)
+	configKey := k.configKey(KeyStateVerification)
+	keys[configKey.Id] = configKey
+
+	var active *Key
+	if k.algorithm == AlgorithmHS256 {
+		active = configKey
+	}
+	for _, file := range files {
+		key, err := readKey(file)
+		if err != nil {
+			return nil, err
+		}
+		if key.CreatedAt.IsZero() {
+			info, err := os.Stat(file)
+			if err != nil {
+				return nil, err
+			}
+			key.CreatedAt = info.ModTime().UTC()
+		}
+		key.State = KeyStateVerification
+		keys[key.Id] = key
+
+		if active != configKey && key.Algorithm == k.algorithm && (active == nil || key.CreatedAt.After(active.CreatedAt)) {
+			active = key
+		}
+	}
+
+	if active == nil {
+		active, err = k.generate()
+		if err != nil {
+			return nil, err
+		}
+		keys[active.Id] = active
+		slog.Info("Generated signing key", "kid", active.Id, "algorithm", active.Algorithm)
+	}
+	active.State = KeyStateActive
+
+	if err := k.writeRing(keys); err != nil {
+		return nil, err
+	}
+
+	return keys, nil
+}
+
+func (k *KeyRing) readRing() (map[string]*Key, error) {
+	data, err := os.ReadFile(filepath.Join(k.dir, ringFile))
+	if err != nil {
+		return nil, err
+	}
+
+	var entries []Key
+	if err := json.Unmarshal(data, &entries); err != nil {
+		return nil, fmt.Errorf("failed to parse key ring %s: %v", filepath.Join(k.dir, ringFile), err)
+	}
+
+	keys := make(map[string]*Key, len(entries))
+	for _, entry := range entries {
+		if entry.Id == ConfigKeyId {
+			keys[entry.Id] = k.configKey(entry.State)
+			continue
+		}
+
+		key, err := readKey(filepath.Join(k.dir, entry.Id+".pem"))
+		if err != nil {
+			return nil, err
+		}
+		key.State = entry.State
+		key.CreatedAt = entry.CreatedAt
+		keys[key.Id] = key
+	}
+
+	return keys, nil
+}
+
+func (k *KeyRing) writeRing(keys map[string]*Key) error {
+	entries := make([]Key, 0, len(keys))
+	for _, key := range keys {
+		entries = append(entries, *key)
+	}
+	sort.Slice(entries, func(i, j int) bool {
+		return entries[i].CreatedAt.Before(entries[j].CreatedAt)
+	})
+
+	data, err := json.MarshalIndent(entries, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	file := filepath.Join(k.dir, ringFile)
+	tmp := file + ".tmp"
+	if err := os.WriteFile(tmp, data, 0600); err != nil {
+		return fmt.Errorf("failed to write key ring %s: %v", file, err)
+	}
+	if err := os.Rename(tmp, file); err != nil {
+		return fmt.Errorf("failed to write key ring %s: %v", file, err)
+	}
+
+	return nil
+}
+
+func (k *KeyRing) configKey(state string) *Key {
+	return &Key{
+		Id:        ConfigKeyId,
+		Algorithm: AlgorithmHS256,
+		State:     state,
+		CreatedAt: k.createdAt,
+		secret:    k.secret,
+	}
+}
+
+func (k *KeyRing) generate() (*Key, error) {
+	key := &Key{
+		Algorithm: k.algorithm,
+		State:     KeyStateActive,
+		CreatedAt: time.Now().UTC(),
+	}
+
+	var block *pem.Block
+	var err error
+	switch k.algorithm {
+	case AlgorithmHS256:
+		key.secret = make([]byte, 32)
+		if _, err := rand.Read(key.secret); err != nil {
+			return nil, err
+		}
+		block = &pem.Block{Type: "HMAC KEY", Bytes: key.secret}
+	case AlgorithmEdDSA:
+		_, key.signer, err = ed25519.GenerateKey(rand.Reader)
+	case AlgorithmES256:
+		key.signer, err = ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	case AlgorithmRS256:
+		key.signer, err = rsa.GenerateKey(rand.Reader, 3072)
+	default:
+		return nil, fmt.Errorf("%w: %s", ErrUnsupportedAlgorithm, k.algorithm)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if key.signer != nil {
+		der, err := x509.MarshalPKCS8PrivateKey(key.signer)
+		if err != nil {
+			return nil, err
+		}
+		block = &pem.Block{Type: "PRIVATE KEY", Bytes: der}
+	}
+
+	key.Id, err = keyId(key)
+	if err != nil {
+		return nil, err
+	}
+	block.Headers = map[string]string{pemCreatedAt: key.CreatedAt.Format(time.RFC3339Nano)}
+
+	file := filepath.Join(k.dir, key.Id+".pem")
+	if err := os.WriteFile(file, pem.EncodeToMemory(block), 0600); err != nil {
+		return nil, fmt.Errorf("failed to write signing key %s: %v", file, err)
+	}
+
+	return key, nil
+}
+
+func readKey(file string) (*Key, error) {
+	data, err := os.ReadFile(file)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read signing key %s: %v", file, err)
+	}
+
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, fmt.Errorf("failed to decode signing key %s", file)
+	}
+
+	key := &Key{Id: strings.TrimSuffix(filepath.Base(file), ".pem")}
+	if createdAt, ok := block.Headers[pemCreatedAt]; ok {
+		key.CreatedAt, err = time.Parse(time.RFC3339Nano, createdAt)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse signing key %s: %v", file, err)
+		}
+	}
+	switch block.Type {
+	case "HMAC KEY":
+		key.Algorithm = AlgorithmHS256
+		key.secret = block.Bytes
+		return key, nil
+	case "PRIVATE KEY":
+	default:
+		return nil, fmt.Errorf("failed to decode signing key %s", file)
+	}
+
+	parsed, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse signing key %s: %v", file, err)
+	}
+
+	signer, ok := parsed.(crypto.Signer)
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", ErrUnsupportedAlgorithm, file)
+	}
+
+	key.Algorithm, err = keyAlgorithm(signer)
+	if err != nil {
+		return nil, fmt.Errorf("%v: %s", err, file)
+	}
+	key.signer = signer
+
+	return key, nil
+}
+
+func keyAlgorithm(signer crypto.Signer) (string, error) {
+	switch key := signer.(type) {
+	case ed25519.PrivateKey:
+		return AlgorithmEdDSA, nil
+	case *ecdsa.PrivateKey:
+		if key.Curve == elliptic.P256() {
+			return AlgorithmES256, nil
+		}
+	case *rsa.PrivateKey:
+		return AlgorithmRS256, nil
+	}
+
+	return "", ErrUnsupportedAlgorithm
+}
+
+func keyId(key *Key) (string, error) {
+	if key.signer == nil {
+		id := make([]byte, 12)
+		if _, err := rand.Read(id); err != nil {
+			return "", err
+		}
+		return base64.RawURLEncoding.EncodeToString(id), nil
+	}
+
+	der, err := x509.MarshalPKIXPublicKey(key.signer.Public())
+	if err != nil {
+		return "", err
+	}
+
+	sum := sha256.Sum256(der)
+	return base64.RawURLEncoding.EncodeToString(sum[:12]), nil
+}
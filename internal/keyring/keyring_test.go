@@ -0,0 +1,159 @@
+/*
+Copyright (c) Tobias Schäfer. All rights reserved.
+Licensed under the MIT License, see LICENSE file in the project root for details.
+*/
+package keyring
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/tschaefer/finch/internal/config"
+)
+
+func newKeyRing(t *testing.T, algorithm string) *KeyRing {
+	cfg := config.NewFromData(&config.Data{
+		Secret:           "1suNCrW7sWlPbU+YCfdGQI7z3ZMo9Ru2GNV4h69QzaM=",
+		SigningAlgorithm: algorithm,
+	}, t.TempDir())
+
+	return New(cfg)
+}
+
+func Test_LoadGeneratesSigningKey(t *testing.T) {
+	for _, algorithm := range []string{AlgorithmEdDSA, AlgorithmES256, AlgorithmRS256} {
+		t.Run(algorithm, func(t *testing.T) {
+			ring := newKeyRing(t, algorithm)
+			assert.NoError(t, ring.Load(), "load key ring")
+
+			claims := jwt.MapClaims{"sub": "agent", "exp": time.Now().Add(time.Hour).Unix()}
+			tokenString, kid, err := ring.Sign(claims)
+			assert.NoError(t, err, "sign token")
+
+			token, err := jwt.Parse(tokenString, ring.Keyfunc)
+			assert.NoError(t, err, "verify token")
+			assert.Equal(t, algorithm, token.Method.Alg(), "token algorithm")
+
+			set := ring.JWKS()
+			assert.Len(t, set.Keys, 1, "published keys")
+			assert.Equal(t, kid, token.Header["kid"], "token kid")
+			assert.Equal(t, kid, set.Keys[0].Kid, "published kid")
+			assert.Equal(t, algorithm, set.Keys[0].Alg, "published algorithm")
+
+			reloaded := newKeyRing(t, algorithm)
+			reloaded.dir = ring.dir
+			assert.NoError(t, reloaded.Load(), "reload key ring")
+			_, err = jwt.Parse(tokenString, reloaded.Keyfunc)
+			assert.NoError(t, err, "verify token with reloaded key ring")
+		})
+	}
+}
+
+func Test_KeyfuncAcceptsSecretSignedTokens(t *testing.T) {
+	legacy := newKeyRing(t, "")
+	assert.NoError(t, legacy.Load(), "load legacy key ring")
+	assert.Empty(t, legacy.JWKS().Keys, "no published keys")
+
+	token := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.MapClaims{"sub": "agent"})
+	tokenString, err := token.SignedString(legacy.secret)
+	assert.NoError(t, err, "sign token without kid")
+
+	ring := newKeyRing(t, AlgorithmEdDSA)
+	assert.NoError(t, ring.Load(), "load key ring")
+	_, err = jwt.Parse(tokenString, ring.Keyfunc)
+	assert.NoError(t, err, "verify secret signed token")
+}
+
+func Test_KeyfuncReturnsError_UnknownKey(t *testing.T) {
+	signer := newKeyRing(t, AlgorithmES256)
+	assert.NoError(t, signer.Load(), "load signing key ring")
+	tokenString, _, err := signer.Sign(jwt.MapClaims{"sub": "agent"})
+	assert.NoError(t, err, "sign token")
+
+	verifier := newKeyRing(t, AlgorithmES256)
+	assert.NoError(t, verifier.Load(), "load verifying key ring")
+	_, err = jwt.Parse(tokenString, verifier.Keyfunc)
+	assert.ErrorIs(t, err, ErrUnknownKey, "verify token signed by foreign key")
+}
+
+func Test_LoadReturnsError_UnsupportedAlgorithm(t *testing.T) {
+	ring := newKeyRing(t, "HS512")
+	assert.ErrorIs(t, ring.Load(), ErrUnsupportedAlgorithm, "load key ring")
+}
+
+func Test_RotateKeepsPreviousKeysForVerification(t *testing.T) {
+	ring := newKeyRing(t, "")
+	assert.NoError(t, ring.Load(), "load key ring")
+
+	oldToken, oldKid, err := ring.Sign(jwt.MapClaims{"sub": "agent"})
+	assert.NoError(t, err, "sign token with config key")
+	assert.Equal(t, ConfigKeyId, oldKid, "config key signs")
+
+	key, err := ring.Rotate()
+	assert.NoError(t, err, "rotate key ring")
+	assert.Equal(t, AlgorithmHS256, key.Algorithm, "rotated algorithm")
+	assert.Equal(t, KeyStateActive, key.State, "rotated key state")
+
+	newToken, newKid, err := ring.Sign(jwt.MapClaims{"sub": "agent"})
+	assert.NoError(t, err, "sign token with rotated key")
+	assert.Equal(t, key.Id, newKid, "rotated key signs")
+
+	reloaded := newKeyRing(t, "")
+	reloaded.dir = ring.dir
+	assert.NoError(t, reloaded.Load(), "reload key ring")
+	assert.Len(t, reloaded.Keys(), 2, "key ring size")
+
+	_, err = jwt.Parse(oldToken, reloaded.Keyfunc)
+	assert.NoError(t, err, "verify token signed by previous key")
+	_, err = jwt.Parse(newToken, reloaded.Keyfunc)
+	assert.NoError(t, err, "verify token signed by active key")
+
+	assert.ErrorIs(t, reloaded.Retire(newKid), ErrActiveKey, "retire active key")
+	assert.NoError(t, reloaded.Retire(oldKid), "retire previous key")
+	_, err = jwt.Parse(oldToken, reloaded.Keyfunc)
+	assert.ErrorIs(t, err, ErrUnknownKey, "verify token signed by retired key")
+	assert.ErrorIs(t, reloaded.Retire(oldKid), ErrUnknownKey, "retire unknown key")
+}
+
+func Test_LoadRotatesOnAlgorithmChange(t *testing.T) {
+	ring := newKeyRing(t, "")
+	assert.NoError(t, ring.Load(), "load key ring")
+
+	changed := newKeyRing(t, AlgorithmEdDSA)
+	changed.dir = ring.dir
+	assert.NoError(t, changed.Load(), "load key ring with changed algorithm")
+
+	_, kid, err := changed.Sign(jwt.MapClaims{"sub": "agent"})
+	assert.NoError(t, err, "sign token")
+	assert.NotEqual(t, ConfigKeyId, kid, "rotated key signs")
+	assert.Len(t, changed.JWKS().Keys, 1, "published keys")
+}
+
+func Test_BootstrapReadsCreationTimeFromKeyFile(t *testing.T) {
+	ring := newKeyRing(t, AlgorithmEdDSA)
+	assert.NoError(t, ring.Load(), "load key ring")
+	older := ring.active
+
+	newer, err := ring.Rotate()
+	assert.NoError(t, err, "rotate key ring")
+
+	assert.NoError(t, os.Remove(filepath.Join(ring.dir, ringFile)), "remove key ring")
+	future := time.Now().Add(time.Hour)
+	assert.NoError(t, os.Chtimes(filepath.Join(ring.dir, older.Id+".pem"), future, future), "touch previous key")
+
+	reloaded := newKeyRing(t, AlgorithmEdDSA)
+	reloaded.dir = ring.dir
+	assert.NoError(t, reloaded.Load(), "bootstrap key ring")
+
+	keys := make(map[string]Key)
+	for _, key := range reloaded.Keys() {
+		keys[key.Id] = key
+	}
+	assert.True(t, older.CreatedAt.Equal(keys[older.Id].CreatedAt), "previous key creation time")
+	assert.True(t, newer.CreatedAt.Equal(keys[newer.Id].CreatedAt), "rotated key creation time")
+	assert.Equal(t, KeyStateActive, keys[newer.Id].State, "newest key stays active")
+}
@@ -12,6 +12,7 @@ import (
 	"os"
 	"time"
 
+	"github.com/prometheus/client_golang/prometheus"
 	"github.com/tschaefer/finch/api"
 	"github.com/tschaefer/finch/internal/auth"
 	"github.com/tschaefer/finch/internal/config"
@@ -20,9 +21,12 @@ import (
 	grpcserver "github.com/tschaefer/finch/internal/grpc"
 	healthzserver "github.com/tschaefer/finch/internal/healthz"
 	httpserver "github.com/tschaefer/finch/internal/http"
+	"github.com/tschaefer/finch/internal/metrics"
 	"github.com/tschaefer/finch/internal/model"
 	"github.com/tschaefer/finch/internal/profiler"
+	"github.com/tschaefer/finch/internal/tracing"
 	"github.com/tschaefer/finch/internal/version"
+	"go.opentelemetry.io/contrib/instrumentation/google.golang.org/grpc/otelgrpc"
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/reflection"
 )
@@ -33,6 +37,11 @@ type Manager struct {
 	model      *model.Model
 	controller *controller.Controller
 	profiler   *profiler.Profiler
+	tracer     *tracing.Tracer
+}
+
+type AuthCache struct {
+	TTL time.Duration
 }
 
 type Addresses struct {
@@ -55,6 +64,11 @@ func New(cfgFile string) (*Manager, error) {
 		slog.Warn("Failed to start Pyroscope profiler", "error", err)
 	}
 
+	tracer := tracing.New(cfg)
+	if err := tracer.Start(context.Background()); err != nil {
+		slog.Warn("Failed to start OpenTelemetry tracer", "error", err)
+	}
+
 	db, err := database.New(cfg)
 	if err != nil {
 		return nil, err
@@ -66,6 +80,12 @@ func New(cfgFile string) (*Manager, error) {
 
 	model := model.New(db.Connection())
 	ctrl := controller.New(model, cfg)
+	if err := ctrl.LoadSigningKeys(); err != nil {
+		return nil, err
+	}
+	if err := ctrl.RecordBaselineConfigRevisions(); err != nil {
+		return nil, err
+	}
 
 	return &Manager{
 		config:     cfg,
@@ -73,11 +93,12 @@ func New(cfgFile string) (*Manager, error) {
 		model:      model,
 		controller: ctrl,
 		profiler:   profiler,
+		tracer:     tracer,
 	}, nil
 }
 
-func (m *Manager) Run(ctx context.Context, addrs Addresses) {
-	slog.Debug("Running Manager", "addrs", fmt.Sprintf("%+v", addrs))
+func (m *Manager) Run(ctx context.Context, addrs Addresses, sweep Sweep, authCache AuthCache) {
+	slog.Debug("Running Manager", "addrs", fmt.Sprintf("%+v", addrs), "sweep", fmt.Sprintf("%+v", sweep), "authCache", fmt.Sprintf("%+v", authCache))
 
 	ctx, cancel := context.WithCancel(ctx)
 	defer cancel()
@@ -88,6 +109,17 @@ func (m *Manager) Run(ctx context.Context, addrs Addresses) {
 	slog.Info("Listening on " + addrs.Auth + " (Auth)")
 	slog.Info("Listening on " + addrs.Healthz + " (Healthz)")
 
+	m.controller.StartAuthCache(ctx, authCache.TTL)
+
+	collectors := []prometheus.Collector{
+		metrics.NewAgentCollector(m.controller.AgentCounts),
+		metrics.NewDatabaseCollector(m.database.Ping),
+	}
+	if err := metrics.Register(collectors...); err != nil {
+		slog.Warn("Failed to register metrics collectors", "error", err)
+	}
+	defer metrics.Unregister(collectors...)
+
 	grpcServer, err := m.runGRPCServer(addrs.GRPC)
 	if err != nil {
 		slog.Error("Failed to start gRPC server", "error", err)
@@ -112,6 +144,11 @@ func (m *Manager) Run(ctx context.Context, addrs Addresses) {
 		os.Exit(1)
 	}
 
+	go m.controller.RunLastSeenFlusher(ctx, controller.DefaultLastSeenFlushInterval)
+	go m.runSweeper(ctx, sweep)
+	go m.controller.RunSigningKeyReloader(ctx, controller.DefaultSigningKeyReloadInterval)
+	go m.controller.RunUsageFlusher(ctx, controller.DefaultUsageFlushInterval, controller.DefaultUsageRetention)
+
 	<-ctx.Done()
 	slog.Info("Shutting down servers...")
 
@@ -130,7 +167,26 @@ func (m *Manager) Run(ctx context.Context, addrs Addresses) {
 		slog.Error("HTTP server shutdown error", "error", err)
 	}
 
-	grpcServer.GracefulStop()
+	stopped := make(chan struct{})
+	go func() {
+		grpcServer.GracefulStop()
+		close(stopped)
+	}()
+	select {
+	case <-stopped:
+	case <-shutdownCtx.Done():
+		grpcServer.Stop()
+	}
+
+	if err := m.controller.FlushLastSeen(); err != nil {
+		slog.Error("Failed to flush agents last seen", "error", err)
+	}
+	if err := m.controller.FlushAgentUsage(); err != nil {
+		slog.Error("Failed to flush agents usage", "error", err)
+	}
+	if err := m.tracer.Stop(shutdownCtx); err != nil {
+		slog.Error("Failed to stop OpenTelemetry tracer", "error", err)
+	}
 	slog.Info("Servers stopped")
 }
 
@@ -145,11 +201,17 @@ func (m *Manager) runGRPCServer(listenAddr string) (*grpc.Server, error) {
 	headersInterceptor := grpcserver.NewHeadersInterceptor()
 	loggingInterceptor := grpcserver.NewLoggingInterceptor()
 	grpcServer := grpc.NewServer(
+		grpc.StatsHandler(otelgrpc.NewServerHandler()),
 		grpc.ChainUnaryInterceptor(
 			loggingInterceptor.Unary(),
 			authInterceptor.Unary(),
 			headersInterceptor.Unary(),
 		),
+		grpc.ChainStreamInterceptor(
+			loggingInterceptor.Stream(),
+			authInterceptor.Stream(),
+			headersInterceptor.Stream(),
+		),
 	)
 
 	agentServer := grpcserver.NewAgentServer(m.controller, m.config)
@@ -161,6 +223,15 @@ func (m *Manager) runGRPCServer(listenAddr string) (*grpc.Server, error) {
 	dashboardServer := grpcserver.NewDashboardServer(m.controller)
 	api.RegisterDashboardServiceServer(grpcServer, dashboardServer)
 
+	auditServer := grpcserver.NewAuditServer(m.controller)
+	api.RegisterAuditServiceServer(grpcServer, auditServer)
+
+	tenantServer := grpcserver.NewTenantServer(m.controller)
+	api.RegisterTenantServiceServer(grpcServer, tenantServer)
+
+	enrollmentServer := grpcserver.NewEnrollmentServer(m.controller)
+	api.RegisterEnrollmentServiceServer(grpcServer, enrollmentServer)
+
 	reflection.Register(grpcServer)
 
 	go func() {
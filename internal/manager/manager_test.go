@@ -113,7 +113,7 @@ func Test_RunSucceeds(t *testing.T) {
 		HTTP:    httpAddr,
 		Auth:    authAddr,
 		Healthz: healthzAddr,
-	})
+	}, Sweep{}, AuthCache{})
 
 	var conn net.Conn
 	for range 50 {
@@ -0,0 +1,59 @@
+/*
+Copyright (c) Tobias Schäfer. All rights reserved.
+Licensed under the MIT License, see LICENSE file in the project root for details.
+*/
+package manager
+
+import (
+	"context"
+	"log/slog"
+	"time"
+)
+
+const sweepInterval = 5 * time.Minute
+
+type Sweep struct {
+	StaleAfter     time.Duration
+	EphemeralGrace time.Duration
+}
+
+func (m *Manager) runSweeper(ctx context.Context, sweep Sweep) {
+	if sweep.StaleAfter <= 0 && sweep.EphemeralGrace <= 0 {
+		slog.Info("Agent sweeper disabled")
+		return
+	}
+
+	ticker := time.NewTicker(sweepInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			m.sweepAgents(sweep)
+		}
+	}
+}
+
+func (m *Manager) sweepAgents(sweep Sweep) {
+	if sweep.StaleAfter > 0 {
+		rids, err := m.controller.MarkStaleAgents(sweep.StaleAfter)
+		if err != nil {
+			slog.Error("Failed to mark stale agents", "error", err)
+		}
+		for _, rid := range rids {
+			slog.Info("Agent marked stale", "rid", rid, "staleAfter", sweep.StaleAfter)
+		}
+	}
+
+	if sweep.EphemeralGrace > 0 {
+		rids, err := m.controller.DeregisterEphemeralAgents(sweep.EphemeralGrace)
+		if err != nil {
+			slog.Error("Failed to deregister ephemeral agents", "error", err)
+		}
+		for _, rid := range rids {
+			slog.Info("Ephemeral agent deregistered", "rid", rid, "grace", sweep.EphemeralGrace)
+		}
+	}
+}
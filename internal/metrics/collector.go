@@ -0,0 +1,132 @@
+/*
+Copyright (c) Tobias Schäfer. All rights reserved.
+Licensed under the MIT License, see LICENSE file in the project root for details.
+*/
+package metrics
+
+import (
+	"context"
+	"log/slog"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+const (
+	AgentStateActive    = "active"
+	AgentStateSuspended = "suspended"
+	AgentStateStale     = "stale"
+)
+
+const databasePingTimeout = time.Second
+
+type AgentCount struct {
+	State    string
+	Node     string
+	Metrics  bool
+	Profiles bool
+	Count    int64
+}
+
+type agentCollector struct {
+	count        func() ([]AgentCount, error)
+	agents       *prometheus.Desc
+	capabilities *prometheus.Desc
+}
+
+func NewAgentCollector(count func() ([]AgentCount, error)) prometheus.Collector {
+	return &agentCollector{
+		count: count,
+		agents: prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, "", "agents"),
+			"Number of registered agents by state and node type.",
+			[]string{"state", "node"}, nil,
+		),
+		capabilities: prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, "", "agents_capability"),
+			"Number of registered agents by enabled capability and node type.",
+			[]string{"capability", "node"}, nil,
+		),
+	}
+}
+
+func (c *agentCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- c.agents
+	ch <- c.capabilities
+}
+
+func (c *agentCollector) Collect(ch chan<- prometheus.Metric) {
+	counts, err := c.count()
+	if err != nil {
+		slog.Error("Failed to count agents for metrics", "error", err)
+		ch <- prometheus.NewInvalidMetric(c.agents, err)
+		return
+	}
+
+	type stateKey struct{ state, node string }
+	type capabilityKey struct{ capability, node string }
+	states := make(map[stateKey]int64)
+	capabilities := make(map[capabilityKey]int64)
+	for _, count := range counts {
+		states[stateKey{count.State, count.Node}] += count.Count
+		capabilities[capabilityKey{"logs", count.Node}] += count.Count
+		if count.Metrics {
+			capabilities[capabilityKey{"metrics", count.Node}] += count.Count
+		}
+		if count.Profiles {
+			capabilities[capabilityKey{"profiles", count.Node}] += count.Count
+		}
+	}
+
+	for key, value := range states {
+		ch <- prometheus.MustNewConstMetric(c.agents, prometheus.GaugeValue, float64(value), key.state, key.node)
+	}
+	for key, value := range capabilities {
+		ch <- prometheus.MustNewConstMetric(c.capabilities, prometheus.GaugeValue, float64(value), key.capability, key.node)
+	}
+}
+
+type databaseCollector struct {
+	ping     func(ctx context.Context) error
+	duration *prometheus.Desc
+	up       *prometheus.Desc
+}
+
+func NewDatabaseCollector(ping func(ctx context.Context) error) prometheus.Collector {
+	return &databaseCollector{
+		ping: ping,
+		duration: prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, "database", "ping_duration_seconds"),
+			"Latency of a database ping taken at scrape time.",
+			nil, nil,
+		),
+		up: prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, "database", "up"),
+			"Whether the database answered the last ping.",
+			nil, nil,
+		),
+	}
+}
+
+func (c *databaseCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- c.duration
+	ch <- c.up
+}
+
+func (c *databaseCollector) Collect(ch chan<- prometheus.Metric) {
+	ctx, cancel := context.WithTimeout(context.Background(), databasePingTimeout)
+	defer cancel()
+
+	start := time.Now()
+	err := c.ping(ctx)
+	elapsed := time.Since(start)
+
+	up := 1.0
+	if err != nil {
+		slog.Error("Database ping for metrics failed", "error", err)
+		up = 0
+	}
+
+	ch <- prometheus.MustNewConstMetric(c.duration, prometheus.GaugeValue, elapsed.Seconds())
+	ch <- prometheus.MustNewConstMetric(c.up, prometheus.GaugeValue, up)
+}
@@ -0,0 +1,59 @@
+/*
+Copyright (c) Tobias Schäfer. All rights reserved.
+Licensed under the MIT License, see LICENSE file in the project root for details.
+*/
+package metrics
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAgentCollectorCountsByStateAndCapability(t *testing.T) {
+	collector := NewAgentCollector(func() ([]AgentCount, error) {
+		return []AgentCount{
+			{State: AgentStateActive, Node: "unix", Metrics: true, Count: 3},
+			{State: AgentStateActive, Node: "unix", Profiles: true, Count: 1},
+			{State: AgentStateSuspended, Node: "windows", Count: 2},
+		}, nil
+	})
+
+	expected := `
+# HELP finch_agents Number of registered agents by state and node type.
+# TYPE finch_agents gauge
+finch_agents{node="unix",state="active"} 4
+finch_agents{node="windows",state="suspended"} 2
+# HELP finch_agents_capability Number of registered agents by enabled capability and node type.
+# TYPE finch_agents_capability gauge
+finch_agents_capability{capability="logs",node="unix"} 4
+finch_agents_capability{capability="logs",node="windows"} 2
+finch_agents_capability{capability="metrics",node="unix"} 3
+finch_agents_capability{capability="profiles",node="unix"} 1
+`
+	err := testutil.CollectAndCompare(collector, strings.NewReader(expected))
+	assert.NoError(t, err)
+}
+
+func TestDatabaseCollectorReportsPing(t *testing.T) {
+	healthy := NewDatabaseCollector(func(ctx context.Context) error { return nil })
+	err := testutil.CollectAndCompare(healthy, strings.NewReader(`
+# HELP finch_database_up Whether the database answered the last ping.
+# TYPE finch_database_up gauge
+finch_database_up 1
+`), "finch_database_up")
+	assert.NoError(t, err)
+	assert.Equal(t, 2, testutil.CollectAndCount(healthy))
+
+	failing := NewDatabaseCollector(func(ctx context.Context) error { return errors.New("down") })
+	err = testutil.CollectAndCompare(failing, strings.NewReader(`
+# HELP finch_database_up Whether the database answered the last ping.
+# TYPE finch_database_up gauge
+finch_database_up 0
+`), "finch_database_up")
+	assert.NoError(t, err)
+}
@@ -0,0 +1,94 @@
+/*
+Copyright (c) Tobias Schäfer. All rights reserved.
+Licensed under the MIT License, see LICENSE file in the project root for details.
+*/
+package metrics
+
+import (
+	"net/http"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+const namespace = "finch"
+
+const (
+	AuthOutcomeAllowed      = "allowed"
+	AuthOutcomeDenied       = "denied"
+	AuthOutcomeUnauthorized = "unauthorized"
+	AuthOutcomeRateLimited  = "rate_limited"
+)
+
+var registry = prometheus.NewRegistry()
+
+var (
+	GRPCRequests = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Subsystem: "grpc",
+		Name:      "requests_total",
+		Help:      "Total number of gRPC requests by method and status code.",
+	}, []string{"method", "code"})
+
+	GRPCRequestDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Subsystem: "grpc",
+		Name:      "request_duration_seconds",
+		Help:      "Latency of gRPC requests by method and status code.",
+		Buckets:   prometheus.DefBuckets,
+	}, []string{"method", "code"})
+
+	AuthDecisions = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Subsystem: "auth",
+		Name:      "decisions_total",
+		Help:      "Total number of forward auth decisions by outcome and reason.",
+	}, []string{"outcome", "reason"})
+
+	WebSocketConnections = prometheus.NewGauge(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Subsystem: "dashboard",
+		Name:      "websocket_connections",
+		Help:      "Number of open dashboard WebSocket connections.",
+	})
+
+	TokensIssued = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Subsystem: "tokens",
+		Name:      "issued_total",
+		Help:      "Total number of issued tokens by kind and purpose.",
+	}, []string{"kind", "purpose"})
+)
+
+func init() {
+	registry.MustRegister(
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+		GRPCRequests,
+		GRPCRequestDuration,
+		AuthDecisions,
+		WebSocketConnections,
+		TokensIssued,
+	)
+}
+
+func Register(cs ...prometheus.Collector) error {
+	for _, c := range cs {
+		if err := registry.Register(c); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func Unregister(cs ...prometheus.Collector) {
+	for _, c := range cs {
+		registry.Unregister(c)
+	}
+}
+
+func Handler() http.Handler {
+	return promhttp.HandlerFor(registry, promhttp.HandlerOpts{Registry: registry})
+}
@@ -9,44 +9,64 @@ import (
 	"time"
 
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
 type Agent struct {
-	ID             uint       `gorm:"primarykey" json:"-"`
-	CreatedAt      time.Time  `json:"-"`
-	UpdatedAt      time.Time  `json:"-"`
-	Active         bool       `gorm:"not null;default:true" json:"active"`
-	Hostname       string     `gorm:"not null;unique" json:"hostname"`
-	LastSeen       *time.Time `gorm:"default:NULL" json:"last_seen"`
-	LogSources     []string   `gorm:"not null;default:'[]';serializer:json" json:"log_sources"`
-	Metrics        bool       `gorm:"not null;default:false" json:"metrics"`
-	MetricsTargets []string   `gorm:"not null;default:'[]';serializer:json" json:"metrics_targets"`
-	Profiles       bool       `gorm:"not null;default:false" json:"profiles"`
-	RegisteredAt   time.Time  `gorm:"not null;default:CURRENT_TIMESTAMP" json:"registered_at"`
-	ResourceId     string     `gorm:"not null;unique;uniqueIndex:uidx_agents_resource_id" json:"resource_id"`
-	Labels         []string   `gorm:"serializer:json" json:"labels"`
-	Node           string     `gorm:"not null;default:'unix'" json:"node"`
+	ID              uint       `gorm:"primarykey" json:"-"`
+	CreatedAt       time.Time  `json:"-"`
+	UpdatedAt       time.Time  `json:"-"`
+	Active          bool       `gorm:"not null;default:true" json:"active"`
+	Hostname        string     `gorm:"not null;unique" json:"hostname"`
+	LastSeen        *time.Time `gorm:"default:NULL" json:"last_seen"`
+	LogSources      []string   `gorm:"not null;default:'[]';serializer:json" json:"log_sources"`
+	Metrics         bool       `gorm:"not null;default:false" json:"metrics"`
+	MetricsTargets  []string   `gorm:"not null;default:'[]';serializer:json" json:"metrics_targets"`
+	Profiles        bool       `gorm:"not null;default:false" json:"profiles"`
+	RegisteredAt    time.Time  `gorm:"not null;default:CURRENT_TIMESTAMP" json:"registered_at"`
+	ResourceId      string     `gorm:"not null;unique;uniqueIndex:uidx_agents_resource_id" json:"resource_id"`
+	Labels          []string   `gorm:"serializer:json" json:"labels"`
+	Node            string     `gorm:"not null;default:'unix'" json:"node"`
+	TokensRevoked   *time.Time `gorm:"default:NULL" json:"tokens_revoked"`
+	Stale           bool       `gorm:"not null;default:false" json:"stale"`
+	Ephemeral       bool       `gorm:"not null;default:false" json:"ephemeral"`
+	ResourceVersion uint64     `gorm:"not null;default:1" json:"resource_version"`
+	Tenant          string     `gorm:"not null;default:'default';index:idx_agents_tenant" json:"tenant"`
+	RateLimits      RateLimits `gorm:"serializer:json" json:"rate_limits"`
 }
 
+type RateLimit struct {
+	Rate  float64 `json:"rate"`
+	Burst int     `json:"burst"`
+}
+
+type RateLimits map[string]RateLimit
+
 var (
-	ErrAgentNotFound = errors.New("agent not found")
+	ErrAgentNotFound        = errors.New("agent not found")
+	ErrAgentVersionConflict = errors.New("agent version conflict")
 )
 
 func (m *Model) CreateAgent(agent *Agent) (*Agent, error) {
+	agent.ResourceVersion = 1
 	if err := m.db.Create(agent).Error; err != nil {
 		return nil, err
 	}
 
-	m.notifyAgentEvent("create")
+	m.notifyAgentEvent(AgentEventCreate, nil, agent)
 	return agent, nil
 }
 
 func (m *Model) DeleteAgent(agent *Agent) error {
-	if err := m.db.Delete(agent).Error; err != nil {
-		return err
+	result := m.db.Where("resource_version = ?", agent.ResourceVersion).Delete(agent)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return ErrAgentVersionConflict
 	}
 
-	m.notifyAgentEvent("delete")
+	m.notifyAgentEvent(AgentEventDelete, agent, nil)
 	return nil
 }
 
@@ -69,11 +89,164 @@ func (m *Model) ListAgents(agents *[]Agent) (*[]Agent, error) {
 	return agents, nil
 }
 
+type AgentGroupCount struct {
+	Active   bool
+	Stale    bool
+	Node     string
+	Metrics  bool
+	Profiles bool
+	Count    int64
+}
+
+func (m *Model) CountAgentGroups() ([]AgentGroupCount, error) {
+	var counts []AgentGroupCount
+	err := m.db.Model(&Agent{}).
+		Select("active, stale, node, metrics, profiles, COUNT(*) AS count").
+		Group("active, stale, node, metrics, profiles").
+		Scan(&counts).Error
+	if err != nil {
+		return nil, err
+	}
+
+	return counts, nil
+}
+
+func (m *Model) QueryAgents(agents *[]Agent, query *AgentQuery) (*[]Agent, error) {
+	tx := m.db.Order("id")
+
+	for _, req := range query.Labels {
+		tx = req.apply(tx)
+	}
+	if query.Node != "" {
+		tx = tx.Where("node = ?", query.Node)
+	}
+	if query.Metrics != nil {
+		tx = tx.Where("metrics = ?", *query.Metrics)
+	}
+	if query.Profiles != nil {
+		tx = tx.Where("profiles = ?", *query.Profiles)
+	}
+	if query.Active != nil {
+		tx = tx.Where("active = ?", *query.Active)
+	}
+	if query.Stale != nil {
+		tx = tx.Where("stale = ?", *query.Stale)
+	}
+	if query.LogSourceScheme != "" {
+		tx = tx.Where("log_sources LIKE ? ESCAPE '\\'", "%\""+escapeLike(query.LogSourceScheme)+"://%")
+	}
+	if query.Tenant != "" {
+		tx = tx.Where("tenant = ?", query.Tenant)
+	}
+	if query.AfterId > 0 {
+		tx = tx.Where("id > ?", query.AfterId)
+	}
+	if query.Limit > 0 {
+		tx = tx.Limit(query.Limit)
+	}
+
+	if err := tx.Find(agents).Error; err != nil {
+		return nil, err
+	}
+
+	return agents, nil
+}
+
 func (m *Model) UpdateAgent(agent *Agent) (*Agent, error) {
-	if err := m.db.Save(agent).Error; err != nil {
+	var before Agent
+	if err := m.db.First(&before, agent.ID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrAgentNotFound
+		}
 		return nil, err
 	}
 
-	m.notifyAgentEvent("update")
+	version := agent.ResourceVersion
+	agent.ResourceVersion = version + 1
+	agent.LastSeen, agent.Stale = before.LastSeen, before.Stale
+	result := m.db.Model(agent).Where("resource_version = ?", version).Select("*").Omit("last_seen", "stale").Updates(agent)
+	if result.Error != nil {
+		agent.ResourceVersion = version
+		return nil, result.Error
+	}
+	if result.RowsAffected == 0 {
+		agent.ResourceVersion = version
+		return nil, ErrAgentVersionConflict
+	}
+
+	m.notifyAgentEvent(AgentEventUpdate, &before, agent)
 	return agent, nil
 }
+
+func (m *Model) UpdateAgentsLastSeen(seen map[string]time.Time) error {
+	if len(seen) == 0 {
+		return nil
+	}
+
+	rids := make([]string, 0, len(seen))
+	for rid := range seen {
+		rids = append(rids, rid)
+	}
+
+	var agents []Agent
+	err := m.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("resource_id IN ?", rids).Find(&agents).Error; err != nil {
+			return err
+		}
+		for rid, lastSeen := range seen {
+			err := tx.Model(&Agent{}).Where("resource_id = ?", rid).UpdateColumns(map[string]any{
+				"last_seen": lastSeen.UTC(),
+				"stale":     false,
+			}).Error
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	alive := make([]string, 0, len(agents))
+	for i := range agents {
+		if !agents[i].Stale {
+			alive = append(alive, agents[i].ResourceId)
+			continue
+		}
+
+		after := agents[i]
+		lastSeen := seen[after.ResourceId].UTC()
+		after.LastSeen = &lastSeen
+		after.Stale = false
+		m.notifyAgentEvent(AgentEventUpdate, &agents[i], &after)
+	}
+	m.notifyAgentsSeen(alive)
+
+	return nil
+}
+
+func (m *Model) MarkAgentsStale(before time.Time) ([]Agent, error) {
+	var agents []Agent
+	err := m.db.Model(&agents).Clauses(clause.Returning{}).
+		Where("stale = ? AND COALESCE(last_seen, registered_at) < ?", false, before.UTC()).
+		UpdateColumn("stale", true).Error
+	if err != nil {
+		return nil, err
+	}
+
+	for i := range agents {
+		before := agents[i]
+		before.Stale = false
+		m.notifyAgentEvent(AgentEventStale, &before, &agents[i])
+	}
+	return agents, nil
+}
+
+func (m *Model) ListEphemeralAgents(agents *[]Agent, before time.Time) (*[]Agent, error) {
+	if err := m.db.Where("ephemeral = ? AND COALESCE(last_seen, registered_at) < ?", true, before.UTC()).Find(agents).Error; err != nil {
+		return nil, err
+	}
+
+	return agents, nil
+}
@@ -0,0 +1,114 @@
+/*
+Copyright (c) Tobias Schäfer. All rights reserved.
+Licensed under the MIT License, see LICENSE file in the project root for details.
+*/
+package model
+
+import (
+	"errors"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+type AgentSettings struct {
+	Labels         []string `json:"labels"`
+	LogSources     []string `json:"log_sources"`
+	Metrics        bool     `json:"metrics"`
+	MetricsTargets []string `json:"metrics_targets"`
+	Profiles       bool     `json:"profiles"`
+}
+
+type AgentConfigRevision struct {
+	ID         uint          `gorm:"primarykey" json:"-"`
+	CreatedAt  time.Time     `json:"created_at"`
+	ResourceId string        `gorm:"not null;uniqueIndex:uidx_agent_config_revisions_resource_id_revision" json:"resource_id"`
+	Revision   uint64        `gorm:"not null;uniqueIndex:uidx_agent_config_revisions_resource_id_revision" json:"revision"`
+	Settings   AgentSettings `gorm:"not null;serializer:json" json:"settings"`
+	ConfigHash string        `gorm:"not null" json:"config_hash"`
+	Author     string        `gorm:"not null" json:"author"`
+}
+
+var (
+	ErrAgentConfigRevisionNotFound = errors.New("agent config revision not found")
+)
+
+func (a *Agent) Settings() AgentSettings {
+	return AgentSettings{
+		Labels:         a.Labels,
+		LogSources:     a.LogSources,
+		Metrics:        a.Metrics,
+		MetricsTargets: a.MetricsTargets,
+		Profiles:       a.Profiles,
+	}
+}
+
+func (a *Agent) ApplySettings(settings AgentSettings) {
+	a.Labels = settings.Labels
+	a.LogSources = settings.LogSources
+	a.Metrics = settings.Metrics
+	a.MetricsTargets = settings.MetricsTargets
+	a.Profiles = settings.Profiles
+}
+
+func (m *Model) CreateAgentConfigRevision(revision *AgentConfigRevision) (*AgentConfigRevision, error) {
+	err := m.db.Transaction(func(tx *gorm.DB) error {
+		var latest uint64
+		err := tx.Model(&AgentConfigRevision{}).
+			Where("resource_id = ?", revision.ResourceId).
+			Select("COALESCE(MAX(revision), 0)").
+			Scan(&latest).Error
+		if err != nil {
+			return err
+		}
+
+		revision.Revision = latest + 1
+		return tx.Create(revision).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return revision, nil
+}
+
+func (m *Model) GetAgentConfigRevision(rid string, revision uint64) (*AgentConfigRevision, error) {
+	var entry AgentConfigRevision
+	if err := m.db.Where("resource_id = ? AND revision = ?", rid, revision).First(&entry).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrAgentConfigRevisionNotFound
+		}
+		return nil, err
+	}
+
+	return &entry, nil
+}
+
+func (m *Model) GetLatestAgentConfigRevision(rid string) (*AgentConfigRevision, error) {
+	var entry AgentConfigRevision
+	if err := m.db.Where("resource_id = ?", rid).Order("revision DESC").First(&entry).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrAgentConfigRevisionNotFound
+		}
+		return nil, err
+	}
+
+	return &entry, nil
+}
+
+func (m *Model) ListAgentConfigRevisions(revisions *[]AgentConfigRevision, rid string) (*[]AgentConfigRevision, error) {
+	if err := m.db.Where("resource_id = ?", rid).Order("revision DESC").Find(revisions).Error; err != nil {
+		return nil, err
+	}
+
+	return revisions, nil
+}
+
+func (m *Model) ListAgentsWithoutConfigRevisions(agents *[]Agent) (*[]Agent, error) {
+	revisions := m.db.Model(&AgentConfigRevision{}).Select("resource_id")
+	if err := m.db.Where("resource_id NOT IN (?)", revisions).Order("id").Find(agents).Error; err != nil {
+		return nil, err
+	}
+
+	return agents, nil
+}
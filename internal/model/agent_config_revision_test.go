@@ -0,0 +1,40 @@
+/*
+Copyright (c) Tobias Schäfer. All rights reserved.
+Licensed under the MIT License, see LICENSE file in the project root for details.
+*/
+package model
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_CreateAgentConfigRevisionIncrementsPerAgent(t *testing.T) {
+	db := newDatabase(t)
+	m := New(db)
+
+	for _, rid := range []string{"resource-1", "resource-1", "resource-2"} {
+		_, err := m.CreateAgentConfigRevision(&AgentConfigRevision{
+			ResourceId: rid,
+			Settings:   AgentSettings{LogSources: []string{"journal://"}},
+			ConfigHash: "hash",
+			Author:     "test",
+		})
+		assert.NoError(t, err, "create config revision")
+	}
+
+	var revisions []AgentConfigRevision
+	_, err := m.ListAgentConfigRevisions(&revisions, "resource-1")
+	assert.NoError(t, err, "list config revisions")
+	assert.Len(t, revisions, 2, "config revisions count")
+	assert.Equal(t, uint64(2), revisions[0].Revision, "latest revision first")
+	assert.Equal(t, []string{"journal://"}, revisions[0].Settings.LogSources, "revision settings")
+
+	latest, err := m.GetLatestAgentConfigRevision("resource-2")
+	assert.NoError(t, err, "get latest config revision")
+	assert.Equal(t, uint64(1), latest.Revision, "revision numbered per agent")
+
+	_, err = m.GetAgentConfigRevision("resource-2", 2)
+	assert.ErrorIs(t, err, ErrAgentConfigRevisionNotFound, "get missing config revision")
+}
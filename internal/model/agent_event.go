@@ -0,0 +1,248 @@
+/*
+Copyright (c) Tobias Schäfer. All rights reserved.
+Licensed under the MIT License, see LICENSE file in the project root for details.
+*/
+package model
+
+import (
+	"context"
+	"reflect"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	AgentEventCreate = "create"
+	AgentEventUpdate = "update"
+	AgentEventDelete = "delete"
+	AgentEventStale  = "stale"
+	AgentEventLagged = "lagged"
+	AgentEventSeen   = "seen"
+)
+
+const (
+	agentEventHistorySize = 1024
+	agentEventBufferSize  = 64
+)
+
+type AgentEvent struct {
+	Type       string
+	Revision   uint64
+	Time       time.Time
+	ResourceId string
+	Hostname   string
+	Fields     []string
+	Before     *Agent
+	After      *Agent
+	Missed     uint64
+	Seen       []string
+}
+
+type agentSubscriber struct {
+	ch     chan AgentEvent
+	missed uint64
+}
+
+type agentEventBus struct {
+	mu          sync.Mutex
+	revision    uint64
+	history     []AgentEvent
+	subscribers map[*agentSubscriber]struct{}
+}
+
+func newAgentEventBus() *agentEventBus {
+	return &agentEventBus{
+		history:     make([]AgentEvent, 0, agentEventHistorySize),
+		subscribers: make(map[*agentSubscriber]struct{}),
+	}
+}
+
+func (m *Model) SubscribeAgentEvents(ctx context.Context) <-chan AgentEvent {
+	return m.agentEvents.subscribe(ctx)
+}
+
+func (m *Model) AgentRevision() uint64 {
+	m.agentEvents.mu.Lock()
+	defer m.agentEvents.mu.Unlock()
+
+	return m.agentEvents.revision
+}
+
+func (m *Model) AgentEventsSince(revision uint64) ([]AgentEvent, bool) {
+	return m.agentEvents.since(revision)
+}
+
+func (m *Model) notifyAgentEvent(eventType string, before, after *Agent) {
+	if m.pending != nil {
+		before, after = snapshotAgent(before), snapshotAgent(after)
+		*m.pending = append(*m.pending, func() { m.agentEvents.publish(eventType, before, after) })
+		return
+	}
+
+	m.agentEvents.publish(eventType, before, after)
+}
+
+func (m *Model) notifyAgentsSeen(rids []string) {
+	if m.pending != nil {
+		*m.pending = append(*m.pending, func() { m.agentEvents.publishSeen(rids) })
+		return
+	}
+
+	m.agentEvents.publishSeen(rids)
+}
+
+func (b *agentEventBus) subscribe(ctx context.Context) <-chan AgentEvent {
+	sub := &agentSubscriber{ch: make(chan AgentEvent, agentEventBufferSize)}
+
+	b.mu.Lock()
+	b.subscribers[sub] = struct{}{}
+	b.mu.Unlock()
+
+	go func() {
+		<-ctx.Done()
+
+		b.mu.Lock()
+		delete(b.subscribers, sub)
+		close(sub.ch)
+		b.mu.Unlock()
+	}()
+
+	return sub.ch
+}
+
+func (b *agentEventBus) since(revision uint64) ([]AgentEvent, bool) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if revision == b.revision {
+		return []AgentEvent{}, true
+	}
+	if revision > b.revision || b.history[0].Revision > revision+1 {
+		return nil, false
+	}
+
+	events := make([]AgentEvent, 0)
+	for _, event := range b.history {
+		if event.Revision > revision {
+			events = append(events, event)
+		}
+	}
+	return events, true
+}
+
+func (b *agentEventBus) publish(eventType string, before, after *Agent) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.revision++
+	event := AgentEvent{
+		Type:     eventType,
+		Revision: b.revision,
+		Time:     time.Now(),
+		Before:   snapshotAgent(before),
+		After:    snapshotAgent(after),
+	}
+	switch {
+	case after != nil:
+		event.ResourceId = after.ResourceId
+		event.Hostname = after.Hostname
+	case before != nil:
+		event.ResourceId = before.ResourceId
+		event.Hostname = before.Hostname
+	}
+	if before != nil && after != nil {
+		event.Fields = changedAgentFields(before, after)
+	}
+
+	if len(b.history) == agentEventHistorySize {
+		b.history = append(b.history[:0], b.history[1:]...)
+	}
+	b.history = append(b.history, event)
+
+	b.deliver(event)
+}
+
+func (b *agentEventBus) publishSeen(rids []string) {
+	if len(rids) == 0 {
+		return
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.deliver(AgentEvent{
+		Type:     AgentEventSeen,
+		Revision: b.revision,
+		Time:     time.Now(),
+		Seen:     rids,
+	})
+}
+
+func (b *agentEventBus) deliver(event AgentEvent) {
+	for sub := range b.subscribers {
+		if sub.missed > 0 {
+			lagged := AgentEvent{
+				Type:     AgentEventLagged,
+				Revision: event.Revision - 1,
+				Time:     event.Time,
+				Missed:   sub.missed,
+			}
+			select {
+			case sub.ch <- lagged:
+				sub.missed = 0
+			default:
+				sub.missed++
+				continue
+			}
+		}
+
+		select {
+		case sub.ch <- event:
+		default:
+			sub.missed++
+		}
+	}
+}
+
+func snapshotAgent(agent *Agent) *Agent {
+	if agent == nil {
+		return nil
+	}
+
+	snapshot := *agent
+	return &snapshot
+}
+
+func changedAgentFields(before, after *Agent) []string {
+	fields := make([]string, 0)
+
+	b := reflect.ValueOf(before).Elem()
+	a := reflect.ValueOf(after).Elem()
+	for i := 0; i < b.NumField(); i++ {
+		name, _, _ := strings.Cut(b.Type().Field(i).Tag.Get("json"), ",")
+		if name == "" || name == "-" || name == "resource_version" {
+			continue
+		}
+		if !agentFieldEqual(b.Field(i).Interface(), a.Field(i).Interface()) {
+			fields = append(fields, name)
+		}
+	}
+
+	return fields
+}
+
+func agentFieldEqual(before, after any) bool {
+	switch b := before.(type) {
+	case time.Time:
+		return b.Equal(after.(time.Time))
+	case *time.Time:
+		a := after.(*time.Time)
+		if b == nil || a == nil {
+			return b == a
+		}
+		return b.Equal(*a)
+	default:
+		return reflect.DeepEqual(before, after)
+	}
+}
@@ -0,0 +1,188 @@
+/*
+Copyright (c) Tobias Schäfer. All rights reserved.
+Licensed under the MIT License, see LICENSE file in the project root for details.
+*/
+package model
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_SubscribeAgentEventsDeliversPayload(t *testing.T) {
+	db := newDatabase(t)
+	m := New(db)
+	assert.NotNil(t, m, "create model")
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	events := m.SubscribeAgentEvents(ctx)
+
+	agent, err := m.CreateAgent(&Agent{Hostname: "test-agent", ResourceId: "resource-123"})
+	assert.NoError(t, err, "create agent")
+
+	agent.Profiles = true
+	_, err = m.UpdateAgent(agent)
+	assert.NoError(t, err, "update agent")
+
+	event := <-events
+	assert.Equal(t, AgentEventCreate, event.Type, "create event type")
+	assert.Nil(t, event.Before, "create event before state")
+	assert.Equal(t, "resource-123", event.After.ResourceId, "create event after state")
+	assert.False(t, event.Time.IsZero(), "create event timestamp")
+
+	next := <-events
+	assert.Equal(t, AgentEventUpdate, next.Type, "update event type")
+	assert.Equal(t, "resource-123", next.ResourceId, "update event resource ID")
+	assert.False(t, next.Before.Profiles, "update event before state")
+	assert.True(t, next.After.Profiles, "update event after state")
+	assert.Equal(t, []string{"profiles"}, next.Fields, "update event changed fields")
+	assert.Greater(t, next.Revision, event.Revision, "monotonic revision")
+}
+
+func Test_SubscribeAgentEventsUnsubscribesOnCancel(t *testing.T) {
+	db := newDatabase(t)
+	m := New(db)
+	assert.NotNil(t, m, "create model")
+
+	ctx, cancel := context.WithCancel(context.Background())
+	events := m.SubscribeAgentEvents(ctx)
+	cancel()
+
+	select {
+	case _, ok := <-events:
+		assert.False(t, ok, "channel closed after cancel")
+	case <-time.After(time.Second):
+		t.Fatal("timeout waiting for channel close")
+	}
+
+	m.agentEvents.mu.Lock()
+	assert.Empty(t, m.agentEvents.subscribers, "subscriber removed")
+	m.agentEvents.mu.Unlock()
+
+	_, err := m.CreateAgent(&Agent{Hostname: "test-agent", ResourceId: "resource-123"})
+	assert.NoError(t, err, "create agent after unsubscribe")
+}
+
+func Test_SubscribeAgentEventsSignalsLag(t *testing.T) {
+	db := newDatabase(t)
+	m := New(db)
+	assert.NotNil(t, m, "create model")
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	events := m.SubscribeAgentEvents(ctx)
+
+	for i := range agentEventBufferSize + 5 {
+		_, err := m.CreateAgent(&Agent{Hostname: fmt.Sprintf("agent-%d", i), ResourceId: fmt.Sprintf("resource-%d", i)})
+		assert.NoError(t, err, "create agent")
+	}
+
+	for range agentEventBufferSize {
+		event := <-events
+		assert.Equal(t, AgentEventCreate, event.Type, "buffered event type")
+	}
+
+	_, err := m.CreateAgent(&Agent{Hostname: "late-agent", ResourceId: "resource-late"})
+	assert.NoError(t, err, "create agent after drain")
+
+	lagged := <-events
+	assert.Equal(t, AgentEventLagged, lagged.Type, "lag signal")
+	assert.Equal(t, uint64(5), lagged.Missed, "number of missed events")
+
+	event := <-events
+	assert.Equal(t, "resource-late", event.ResourceId, "event after lag signal")
+}
+
+func Test_SubscribeAgentEventsIsConcurrencySafe(t *testing.T) {
+	db := newDatabase(t)
+	m := New(db)
+	assert.NotNil(t, m, "create model")
+
+	var wg sync.WaitGroup
+	for i := range 20 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			ctx, cancel := context.WithCancel(context.Background())
+			m.SubscribeAgentEvents(ctx)
+			m.agentEvents.publish(AgentEventUpdate, nil, &Agent{ResourceId: fmt.Sprintf("resource-%d", i)})
+			cancel()
+		}()
+	}
+	wg.Wait()
+
+	assert.Equal(t, uint64(20), m.AgentRevision(), "all events published")
+}
+
+func Test_UpdateAgentsLastSeenPublishesBatchedLiveness(t *testing.T) {
+	db := newDatabase(t)
+	m := New(db)
+	assert.NotNil(t, m, "create model")
+
+	for i := range 3 {
+		_, err := m.CreateAgent(&Agent{Hostname: fmt.Sprintf("agent-%d", i), ResourceId: fmt.Sprintf("resource-%d", i)})
+		assert.NoError(t, err, "create agent")
+	}
+	_, err := m.MarkAgentsStale(time.Now().Add(time.Hour))
+	assert.NoError(t, err, "mark agents stale")
+	err = m.UpdateAgentsLastSeen(map[string]time.Time{"resource-0": time.Now(), "resource-1": time.Now()})
+	assert.NoError(t, err, "revive stale agents")
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	events := m.SubscribeAgentEvents(ctx)
+	revision := m.AgentRevision()
+
+	err = m.UpdateAgentsLastSeen(map[string]time.Time{
+		"resource-0": time.Now(),
+		"resource-1": time.Now(),
+		"resource-2": time.Now(),
+	})
+	assert.NoError(t, err, "update agents last seen")
+
+	update := <-events
+	assert.Equal(t, AgentEventUpdate, update.Type, "stale agent revived")
+	assert.Equal(t, "resource-2", update.ResourceId, "revived agent")
+	assert.ElementsMatch(t, []string{"last_seen", "stale"}, update.Fields, "revived agent fields")
+
+	seen := <-events
+	assert.Equal(t, AgentEventSeen, seen.Type, "batched liveness event")
+	assert.ElementsMatch(t, []string{"resource-0", "resource-1"}, seen.Seen, "seen agents")
+	assert.Equal(t, update.Revision, seen.Revision, "liveness does not advance revision")
+
+	history, ok := m.AgentEventsSince(revision)
+	assert.True(t, ok, "revision available")
+	assert.Len(t, history, 1, "liveness kept out of history")
+}
+
+func Test_TransactionPublishesAgentEventsOnCommit(t *testing.T) {
+	db := newDatabase(t)
+	m := New(db)
+	assert.NotNil(t, m, "create model")
+
+	revision := m.AgentRevision()
+	err := m.Transaction(func(tx *Model) error {
+		_, err := tx.CreateAgent(&Agent{Hostname: "rolled-back", ResourceId: "resource-1"})
+		assert.NoError(t, err, "create agent in transaction")
+		assert.Equal(t, revision, m.AgentRevision(), "event deferred until commit")
+		return errors.New("abort")
+	})
+	assert.Error(t, err, "aborted transaction")
+	assert.Equal(t, revision, m.AgentRevision(), "no event for rolled back write")
+	_, err = m.GetAgent(&Agent{ResourceId: "resource-1"})
+	assert.ErrorIs(t, err, ErrAgentNotFound, "write rolled back")
+
+	err = m.Transaction(func(tx *Model) error {
+		_, err := tx.CreateAgent(&Agent{Hostname: "committed", ResourceId: "resource-2"})
+		return err
+	})
+	assert.NoError(t, err, "committed transaction")
+	assert.Equal(t, revision+1, m.AgentRevision(), "event published after commit")
+}
@@ -0,0 +1,77 @@
+/*
+Copyright (c) Tobias Schäfer. All rights reserved.
+Licensed under the MIT License, see LICENSE file in the project root for details.
+*/
+package model
+
+import (
+	"encoding/json"
+	"strings"
+
+	"gorm.io/gorm"
+)
+
+const (
+	LabelOperatorEquals       = "="
+	LabelOperatorNotEquals    = "!="
+	LabelOperatorExists       = "exists"
+	LabelOperatorDoesNotExist = "!exists"
+)
+
+type LabelRequirement struct {
+	Key      string
+	Operator string
+	Value    string
+}
+
+type AgentQuery struct {
+	Labels          []LabelRequirement
+	Node            string
+	Metrics         *bool
+	Profiles        *bool
+	Active          *bool
+	Stale           *bool
+	LogSourceScheme string
+	Tenant          string
+	AfterId         uint
+	Limit           int
+}
+
+func (r LabelRequirement) apply(tx *gorm.DB) *gorm.DB {
+	labels := "COALESCE(labels, '[]')"
+	bare := "%" + escapeLike(jsonLabel(r.Key)) + "%"
+
+	switch r.Operator {
+	case LabelOperatorEquals, LabelOperatorNotEquals:
+		pattern := "%" + escapeLike(jsonLabel(r.Key+"="+r.Value)) + "%"
+		condition := labels + " LIKE ? ESCAPE '\\'"
+		args := []any{pattern}
+		if r.Value == "true" {
+			condition = "(" + condition + " OR " + labels + " LIKE ? ESCAPE '\\')"
+			args = append(args, bare)
+		}
+		if r.Operator == LabelOperatorNotEquals {
+			condition = "NOT " + condition
+		}
+		return tx.Where(condition, args...)
+	case LabelOperatorExists, LabelOperatorDoesNotExist:
+		prefix := "%" + escapeLike(strings.TrimSuffix(jsonLabel(r.Key+"="), "\"")) + "%"
+		condition := "(" + labels + " LIKE ? ESCAPE '\\' OR " + labels + " LIKE ? ESCAPE '\\')"
+		if r.Operator == LabelOperatorDoesNotExist {
+			condition = "NOT " + condition
+		}
+		return tx.Where(condition, bare, prefix)
+	default:
+		return tx
+	}
+}
+
+func jsonLabel(label string) string {
+	data, _ := json.Marshal(label)
+	return string(data)
+}
+
+func escapeLike(value string) string {
+	replacer := strings.NewReplacer("\\", "\\\\", "%", "\\%", "_", "\\_")
+	return replacer.Replace(value)
+}
@@ -0,0 +1,67 @@
+/*
+Copyright (c) Tobias Schäfer. All rights reserved.
+Licensed under the MIT License, see LICENSE file in the project root for details.
+*/
+package model
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_QueryAgentsFiltersAgents(t *testing.T) {
+	db := newDatabase(t)
+	m := New(db)
+	assert.NotNil(t, m, "create model")
+
+	agentsData := []Agent{
+		{Hostname: "prod-web", ResourceId: "resource-1", Node: "unix", Metrics: true, LogSources: []string{"journal://"}, Labels: []string{"env=prod", "team=web", "canary"}},
+		{Hostname: "prod-infra", ResourceId: "resource-2", Node: "unix", LogSources: []string{"docker://"}, Labels: []string{"env=prod", "team=infra"}},
+		{Hostname: "dev-win", ResourceId: "resource-3", Node: "windows", LogSources: []string{"event://Application"}, Labels: []string{"env=dev"}},
+		{Hostname: "unlabeled", ResourceId: "resource-4", Node: "unix", LogSources: []string{"journal://"}},
+		{Hostname: "escaped", ResourceId: "resource-5", Node: "unix", LogSources: []string{"docker://"}, Labels: []string{"owner=a&b", "path=C:\\logs", "<tag>"}},
+	}
+	for i := range agentsData {
+		_, err := m.CreateAgent(&agentsData[i])
+		assert.NoError(t, err, "create agent")
+	}
+
+	hostnames := func(query *AgentQuery) []string {
+		var agents []Agent
+		_, err := m.QueryAgents(&agents, query)
+		assert.NoError(t, err, "query agents")
+		names := make([]string, 0, len(agents))
+		for _, agent := range agents {
+			names = append(names, agent.Hostname)
+		}
+		return names
+	}
+
+	assert.Equal(t, []string{"prod-web", "prod-infra", "dev-win", "unlabeled", "escaped"}, hostnames(&AgentQuery{}), "no filter")
+	assert.Equal(t, []string{"prod-web"}, hostnames(&AgentQuery{Labels: []LabelRequirement{
+		{Key: "env", Operator: LabelOperatorEquals, Value: "prod"},
+		{Key: "team", Operator: LabelOperatorNotEquals, Value: "infra"},
+		{Key: "canary", Operator: LabelOperatorExists},
+	}}), "label selector")
+	assert.Equal(t, []string{"prod-web"}, hostnames(&AgentQuery{Labels: []LabelRequirement{
+		{Key: "canary", Operator: LabelOperatorEquals, Value: "true"},
+	}}), "bare label matches true")
+	assert.Equal(t, []string{"prod-infra", "dev-win", "unlabeled", "escaped"}, hostnames(&AgentQuery{Labels: []LabelRequirement{
+		{Key: "canary", Operator: LabelOperatorDoesNotExist},
+	}}), "label does not exist")
+	assert.Equal(t, []string{"escaped"}, hostnames(&AgentQuery{Labels: []LabelRequirement{
+		{Key: "owner", Operator: LabelOperatorEquals, Value: "a&b"},
+		{Key: "path", Operator: LabelOperatorEquals, Value: "C:\\logs"},
+		{Key: "<tag>", Operator: LabelOperatorExists},
+	}}), "label with JSON escaped characters")
+	assert.Equal(t, []string{"prod-web", "prod-infra", "dev-win", "unlabeled"}, hostnames(&AgentQuery{Labels: []LabelRequirement{
+		{Key: "owner", Operator: LabelOperatorNotEquals, Value: "a&b"},
+	}}), "label with JSON escaped characters does not equal")
+	assert.Equal(t, []string{"dev-win"}, hostnames(&AgentQuery{Node: "windows"}), "node filter")
+
+	metrics := true
+	assert.Equal(t, []string{"prod-web"}, hostnames(&AgentQuery{Metrics: &metrics}), "metrics filter")
+	assert.Equal(t, []string{"prod-web", "unlabeled"}, hostnames(&AgentQuery{LogSourceScheme: "journal"}), "log source scheme filter")
+	assert.Equal(t, []string{"prod-infra", "dev-win"}, hostnames(&AgentQuery{AfterId: agentsData[0].ID, Limit: 2}), "pagination")
+}
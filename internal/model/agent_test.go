@@ -5,6 +5,7 @@ Licensed under the MIT License, see LICENSE file in the project root for details
 package model
 
 import (
+	"path/filepath"
 	"testing"
 	"time"
 
@@ -18,7 +19,7 @@ func newDatabase(t *testing.T) *gorm.DB {
 	if err != nil {
 		t.Fatal(err)
 	}
-	err = db.AutoMigrate(&Agent{})
+	err = db.AutoMigrate(&Agent{}, &AgentToken{}, &AuditEvent{}, &AgentConfigRevision{}, &Collector{}, &EnrollmentToken{}, &Tenant{}, &AgentUsage{}, &DashboardSession{})
 	if err != nil {
 		t.Fatal(err)
 	}
@@ -191,3 +192,268 @@ func Test_UpdateAgentModifiesAgent(t *testing.T) {
 	assert.Equal(t, createdAgent.ResourceId, updatedAgent.ResourceId, "agent resource ID")
 	assert.Equal(t, createdAgent.Labels, updatedAgent.Labels, "agent labels")
 }
+
+func Test_UpdateAgentsLastSeenSetsTimestamp(t *testing.T) {
+	db := newDatabase(t)
+	m := New(db)
+	assert.NotNil(t, m, "create model")
+
+	data := &Agent{
+		Hostname:   "test-agent",
+		ResourceId: "resource-123",
+	}
+	_, err := m.CreateAgent(data)
+	assert.NoError(t, err, "create agent")
+
+	seen := time.Now().Truncate(time.Second)
+	err = m.UpdateAgentsLastSeen(map[string]time.Time{
+		"resource-123": seen,
+		"resource-999": seen,
+	})
+	assert.NoError(t, err, "update agents last seen")
+
+	agent, err := m.GetAgent(&Agent{ResourceId: "resource-123"})
+	assert.NoError(t, err, "get agent")
+	assert.NotNil(t, agent.LastSeen, "agent last seen")
+	assert.True(t, seen.Equal(*agent.LastSeen), "agent last seen timestamp")
+}
+
+func Test_ListAgentTokensFiltersTokens(t *testing.T) {
+	db := newDatabase(t)
+	m := New(db)
+	assert.NotNil(t, m, "create model")
+
+	now := time.Now()
+	tokensData := []AgentToken{
+		{Jti: "jti-1", ResourceId: "resource-1", IssuedAt: now, ExpiresAt: now.Add(24 * time.Hour), Issuer: "test", Purpose: "config"},
+		{Jti: "jti-2", ResourceId: "resource-1", IssuedAt: now, ExpiresAt: now.Add(90 * 24 * time.Hour), Issuer: "test", Purpose: "config"},
+		{Jti: "jti-3", ResourceId: "resource-2", IssuedAt: now, ExpiresAt: now.Add(48 * time.Hour), Issuer: "test", Purpose: "dashboard"},
+	}
+	for i := range tokensData {
+		_, err := m.CreateAgentToken(&tokensData[i])
+		assert.NoError(t, err, "create agent token")
+	}
+
+	var tokens []AgentToken
+	_, err := m.ListAgentTokens(&tokens, "", nil, nil)
+	assert.NoError(t, err, "list all agent tokens")
+	assert.Len(t, tokens, 3, "number of listed tokens")
+
+	tokens = nil
+	_, err = m.ListAgentTokens(&tokens, "resource-1", nil, nil)
+	assert.NoError(t, err, "list agent tokens by resource ID")
+	assert.Len(t, tokens, 2, "number of listed tokens by resource ID")
+
+	tokens = nil
+	until := now.Add(7 * 24 * time.Hour)
+	_, err = m.ListAgentTokens(&tokens, "", &now, &until)
+	assert.NoError(t, err, "list expiring agent tokens")
+	assert.Len(t, tokens, 2, "number of expiring tokens")
+	assert.Equal(t, "jti-1", tokens[0].Jti, "tokens ordered by expiry")
+
+	err = m.MarkAgentTokensDeregistered("resource-1", now)
+	assert.NoError(t, err, "mark agent tokens deregistered")
+
+	tokens = nil
+	_, err = m.ListAgentTokens(&tokens, "resource-1", nil, nil)
+	assert.NoError(t, err, "list deregistered agent tokens")
+	assert.Len(t, tokens, 2, "deregistered tokens kept in ledger")
+	for _, token := range tokens {
+		assert.NotNil(t, token.DeregisteredAt, "token marked deregistered")
+	}
+}
+
+func Test_MarkAgentsStaleFlagsSilentAgents(t *testing.T) {
+	db := newDatabase(t)
+	m := New(db)
+	assert.NotNil(t, m, "create model")
+
+	now := time.Now()
+	old := now.Add(-48 * time.Hour)
+	agentsData := []Agent{
+		{Hostname: "silent-agent", ResourceId: "resource-1", RegisteredAt: old, LastSeen: &old},
+		{Hostname: "never-seen-agent", ResourceId: "resource-2", RegisteredAt: old},
+		{Hostname: "recent-agent", ResourceId: "resource-3", RegisteredAt: old, LastSeen: &now},
+	}
+	for i := range agentsData {
+		_, err := m.CreateAgent(&agentsData[i])
+		assert.NoError(t, err, "create agent")
+	}
+
+	stale, err := m.MarkAgentsStale(now.Add(-24 * time.Hour))
+	assert.NoError(t, err, "mark agents stale")
+	assert.Len(t, stale, 2, "number of stale agents")
+	for _, agent := range stale {
+		assert.True(t, agent.Stale, "returned agent marked stale")
+		assert.NotEqual(t, "recent-agent", agent.Hostname, "recent agent not marked stale")
+	}
+
+	stale, err = m.MarkAgentsStale(now.Add(-24 * time.Hour))
+	assert.NoError(t, err, "mark agents stale again")
+	assert.Len(t, stale, 0, "already stale agents are not marked again")
+
+	err = m.UpdateAgentsLastSeen(map[string]time.Time{"resource-1": now})
+	assert.NoError(t, err, "update agents last seen")
+
+	agent, err := m.GetAgent(&Agent{ResourceId: "resource-1"})
+	assert.NoError(t, err, "get agent")
+	assert.False(t, agent.Stale, "agent no longer stale after being seen")
+}
+
+func Test_MarkAgentsStaleRechecksLastSeenOnUpdate(t *testing.T) {
+	db, err := gorm.Open(sqlite.Open(filepath.Join(t.TempDir(), "finch.db")), &gorm.Config{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := db.AutoMigrate(&Agent{}); err != nil {
+		t.Fatal(err)
+	}
+	m := New(db)
+	assert.NotNil(t, m, "create model")
+
+	now := time.Now()
+	old := now.Add(-48 * time.Hour)
+	_, err = m.CreateAgent(&Agent{Hostname: "flushed-agent", ResourceId: "resource-1", RegisteredAt: old, LastSeen: &old})
+	assert.NoError(t, err, "create agent")
+
+	flushed := false
+	err = db.Callback().Update().Before("gorm:update").Register("test:flush_last_seen", func(tx *gorm.DB) {
+		if flushed {
+			return
+		}
+		flushed = true
+		assert.NoError(t, m.UpdateAgentsLastSeen(map[string]time.Time{"resource-1": now}), "flush last seen")
+	})
+	assert.NoError(t, err, "register flushing callback")
+
+	stale, err := m.MarkAgentsStale(now.Add(-24 * time.Hour))
+	assert.NoError(t, err, "mark agents stale")
+	assert.True(t, flushed, "last seen flushed during sweep")
+	assert.Len(t, stale, 0, "freshly seen agent not marked stale")
+
+	agent, err := m.GetAgent(&Agent{ResourceId: "resource-1"})
+	assert.NoError(t, err, "get agent")
+	assert.False(t, agent.Stale, "agent not stale")
+}
+
+func Test_CountAgentGroupsGroupsByStateAndNode(t *testing.T) {
+	db := newDatabase(t)
+	m := New(db)
+	assert.NotNil(t, m, "create model")
+
+	agentsData := []Agent{
+		{Hostname: "agent-1", ResourceId: "resource-1", Node: "unix", Active: true, Metrics: true},
+		{Hostname: "agent-2", ResourceId: "resource-2", Node: "unix", Active: true, Metrics: true},
+		{Hostname: "agent-3", ResourceId: "resource-3", Node: "windows", Active: true, Stale: true},
+	}
+	for i := range agentsData {
+		_, err := m.CreateAgent(&agentsData[i])
+		assert.NoError(t, err, "create agent")
+	}
+
+	counts, err := m.CountAgentGroups()
+	assert.NoError(t, err, "count agent groups")
+	assert.Len(t, counts, 2, "number of groups")
+
+	for _, count := range counts {
+		switch count.Node {
+		case "unix":
+			assert.Equal(t, int64(2), count.Count, "unix agents")
+			assert.True(t, count.Metrics, "unix agents collect metrics")
+		case "windows":
+			assert.Equal(t, int64(1), count.Count, "windows agents")
+			assert.True(t, count.Stale, "windows agent is stale")
+		default:
+			t.Errorf("unexpected node %q", count.Node)
+		}
+	}
+}
+
+func Test_AgentEventsSinceReturnsHistory(t *testing.T) {
+	db := newDatabase(t)
+	m := New(db)
+	assert.NotNil(t, m, "create model")
+
+	agent, err := m.CreateAgent(&Agent{Hostname: "test-agent", ResourceId: "resource-123"})
+	assert.NoError(t, err, "create agent")
+
+	revision := m.AgentRevision()
+
+	agent.Labels = []string{"env=prod"}
+	agent.Metrics = true
+	_, err = m.UpdateAgent(agent)
+	assert.NoError(t, err, "update agent")
+
+	events, ok := m.AgentEventsSince(revision)
+	assert.True(t, ok, "revision available")
+	assert.Len(t, events, 1, "number of events since revision")
+	assert.Equal(t, "update", events[0].Type, "event type")
+	assert.Equal(t, "resource-123", events[0].ResourceId, "event resource ID")
+	assert.Equal(t, "test-agent", events[0].Hostname, "event hostname")
+	assert.ElementsMatch(t, []string{"labels", "metrics"}, events[0].Fields, "event changed fields")
+
+	_, ok = m.AgentEventsSince(revision + 10)
+	assert.False(t, ok, "unknown revision")
+}
+
+func Test_UpdateAgentReturnsError_VersionConflict(t *testing.T) {
+	db := newDatabase(t)
+	m := New(db)
+	assert.NotNil(t, m, "create model")
+
+	agent, err := m.CreateAgent(&Agent{Hostname: "test-agent", ResourceId: "resource-123"})
+	assert.NoError(t, err, "create agent")
+	assert.Equal(t, uint64(1), agent.ResourceVersion, "initial resource version")
+
+	first, err := m.GetAgent(&Agent{ResourceId: "resource-123"})
+	assert.NoError(t, err, "get first copy")
+	second, err := m.GetAgent(&Agent{ResourceId: "resource-123"})
+	assert.NoError(t, err, "get second copy")
+
+	first.Metrics = true
+	updated, err := m.UpdateAgent(first)
+	assert.NoError(t, err, "update first copy")
+	assert.Equal(t, uint64(2), updated.ResourceVersion, "incremented resource version")
+
+	second.Profiles = true
+	_, err = m.UpdateAgent(second)
+	assert.ErrorIs(t, err, ErrAgentVersionConflict, "update stale copy")
+	assert.Equal(t, uint64(1), second.ResourceVersion, "stale copy keeps its version")
+
+	err = m.DeleteAgent(second)
+	assert.ErrorIs(t, err, ErrAgentVersionConflict, "delete stale copy")
+
+	current, err := m.GetAgent(&Agent{ResourceId: "resource-123"})
+	assert.NoError(t, err, "get current agent")
+	assert.True(t, current.Metrics, "first update persisted")
+	assert.False(t, current.Profiles, "stale update rejected")
+}
+
+func Test_UpdateAgentKeepsConcurrentLastSeen(t *testing.T) {
+	db := newDatabase(t)
+	m := New(db)
+	assert.NotNil(t, m, "create model")
+
+	old := time.Now().Add(-48 * time.Hour)
+	_, err := m.CreateAgent(&Agent{Hostname: "test-agent", ResourceId: "resource-123", RegisteredAt: old, LastSeen: &old})
+	assert.NoError(t, err, "create agent")
+	_, err = m.MarkAgentsStale(time.Now().Add(-24 * time.Hour))
+	assert.NoError(t, err, "mark agent stale")
+
+	agent, err := m.GetAgent(&Agent{ResourceId: "resource-123"})
+	assert.NoError(t, err, "get agent")
+
+	seen := time.Now().UTC()
+	err = m.UpdateAgentsLastSeen(map[string]time.Time{"resource-123": seen})
+	assert.NoError(t, err, "flush last seen")
+
+	agent.Metrics = true
+	_, err = m.UpdateAgent(agent)
+	assert.NoError(t, err, "update agent read before heartbeat")
+
+	current, err := m.GetAgent(&Agent{ResourceId: "resource-123"})
+	assert.NoError(t, err, "get current agent")
+	assert.True(t, current.Metrics, "update persisted")
+	assert.True(t, seen.Equal(*current.LastSeen), "heartbeat kept")
+	assert.False(t, current.Stale, "agent stays revived")
+}
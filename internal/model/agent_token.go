@@ -0,0 +1,76 @@
+/*
+Copyright (c) Tobias Schäfer. All rights reserved.
+Licensed under the MIT License, see LICENSE file in the project root for details.
+*/
+package model
+
+import (
+	"time"
+)
+
+type AgentToken struct {
+	ID         uint      `gorm:"primarykey" json:"-"`
+	CreatedAt  time.Time `json:"-"`
+	Jti        string    `gorm:"not null;uniqueIndex:uidx_agent_tokens_jti" json:"jti"`
+	ResourceId string    `gorm:"not null;index:idx_agent_tokens_resource_id" json:"resource_id"`
+	IssuedAt   time.Time `gorm:"not null" json:"issued_at"`
+	ExpiresAt  time.Time `gorm:"not null;index:idx_agent_tokens_expires_at" json:"expires_at"`
+	Issuer     string    `gorm:"not null" json:"issuer"`
+	Purpose    string    `gorm:"not null" json:"purpose"`
+	Kid        string    `gorm:"not null;default:'';index:idx_agent_tokens_kid" json:"kid"`
+
+	DeregisteredAt *time.Time `gorm:"default:NULL" json:"deregistered_at"`
+}
+
+func (m *Model) CreateAgentToken(token *AgentToken) (*AgentToken, error) {
+	if err := m.db.Create(token).Error; err != nil {
+		return nil, err
+	}
+
+	return token, nil
+}
+
+func (m *Model) ListAgentTokens(tokens *[]AgentToken, rid string, expiresAfter, expiresBefore *time.Time) (*[]AgentToken, error) {
+	query := m.db.Order("expires_at")
+	if rid != "" {
+		query = query.Where("resource_id = ?", rid)
+	}
+	if expiresAfter != nil {
+		query = query.Where("expires_at > ?", expiresAfter.UTC())
+	}
+	if expiresBefore != nil {
+		query = query.Where("expires_at <= ?", expiresBefore.UTC())
+	}
+
+	if err := query.Find(tokens).Error; err != nil {
+		return nil, err
+	}
+
+	return tokens, nil
+}
+
+func (m *Model) CountUnexpiredTokens(kids []string, now time.Time) (int64, error) {
+	var agentTokens, enrollmentTokens, dashboardSessions int64
+	err := m.db.Model(&AgentToken{}).Where("kid IN ? AND expires_at > ? AND deregistered_at IS NULL", kids, now.UTC()).Count(&agentTokens).Error
+	if err != nil {
+		return 0, err
+	}
+
+	err = m.db.Model(&EnrollmentToken{}).Where("kid IN ? AND expires_at > ? AND uses < usage_limit", kids, now.UTC()).Count(&enrollmentTokens).Error
+	if err != nil {
+		return 0, err
+	}
+
+	err = m.db.Model(&DashboardSession{}).Where("kid IN ? AND expires_at > ?", kids, now.UTC()).Count(&dashboardSessions).Error
+	if err != nil {
+		return 0, err
+	}
+
+	return agentTokens + enrollmentTokens + dashboardSessions, nil
+}
+
+func (m *Model) MarkAgentTokensDeregistered(rid string, at time.Time) error {
+	return m.db.Model(&AgentToken{}).
+		Where("resource_id = ? AND deregistered_at IS NULL", rid).
+		Update("deregistered_at", at.UTC()).Error
+}
@@ -0,0 +1,91 @@
+/*
+Copyright (c) Tobias Schäfer. All rights reserved.
+Licensed under the MIT License, see LICENSE file in the project root for details.
+*/
+package model
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type AgentUsage struct {
+	ID         uint      `gorm:"primarykey" json:"-"`
+	ResourceId string    `gorm:"not null;uniqueIndex:uidx_agent_usages_bucket,priority:1" json:"resource_id"`
+	Backend    string    `gorm:"not null;uniqueIndex:uidx_agent_usages_bucket,priority:2" json:"backend"`
+	Bucket     time.Time `gorm:"not null;uniqueIndex:uidx_agent_usages_bucket,priority:3;index:idx_agent_usages_bucket" json:"bucket"`
+	Requests   int64     `gorm:"not null;default:0" json:"requests"`
+	Bytes      int64     `gorm:"not null;default:0" json:"bytes"`
+}
+
+type AgentUsageQuery struct {
+	ResourceId string
+	Since      *time.Time
+	Until      *time.Time
+}
+
+func (m *Model) AddAgentUsage(usages []AgentUsage) error {
+	if len(usages) == 0 {
+		return nil
+	}
+
+	for i := range usages {
+		usages[i].Bucket = usages[i].Bucket.UTC()
+	}
+
+	return m.db.Clauses(clause.OnConflict{
+		Columns: []clause.Column{{Name: "resource_id"}, {Name: "backend"}, {Name: "bucket"}},
+		DoUpdates: clause.Assignments(map[string]any{
+			"requests": gorm.Expr("agent_usages.requests + excluded.requests"),
+			"bytes":    gorm.Expr("agent_usages.bytes + excluded.bytes"),
+		}),
+	}).Create(&usages).Error
+}
+
+func (m *Model) ListAgentUsage(usages *[]AgentUsage, query *AgentUsageQuery) (*[]AgentUsage, error) {
+	tx := m.agentUsageScope(query).Order("bucket, backend")
+
+	if err := tx.Find(usages).Error; err != nil {
+		return nil, err
+	}
+
+	return usages, nil
+}
+
+func (m *Model) SumAgentUsage(usages *[]AgentUsage, query *AgentUsageQuery) (*[]AgentUsage, error) {
+	tx := m.agentUsageScope(query).
+		Select("resource_id, backend, SUM(requests) AS requests, SUM(bytes) AS bytes").
+		Group("resource_id, backend")
+
+	if err := tx.Find(usages).Error; err != nil {
+		return nil, err
+	}
+
+	return usages, nil
+}
+
+func (m *Model) DeleteAgentUsageBefore(before time.Time) (int64, error) {
+	result := m.db.Where("bucket < ?", before.UTC()).Delete(&AgentUsage{})
+	if result.Error != nil {
+		return 0, result.Error
+	}
+
+	return result.RowsAffected, nil
+}
+
+func (m *Model) agentUsageScope(query *AgentUsageQuery) *gorm.DB {
+	tx := m.db.Model(&AgentUsage{})
+	if query.ResourceId != "" {
+		tx = tx.Where("resource_id = ?", query.ResourceId)
+	}
+	if query.Since != nil {
+		tx = tx.Where("bucket >= ?", query.Since.UTC())
+	}
+	if query.Until != nil {
+		tx = tx.Where("bucket < ?", query.Until.UTC())
+	}
+
+	return tx
+}
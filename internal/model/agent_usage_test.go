@@ -0,0 +1,49 @@
+/*
+Copyright (c) Tobias Schäfer. All rights reserved.
+Licensed under the MIT License, see LICENSE file in the project root for details.
+*/
+package model
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_AgentUsageAccumulatesPerBucket(t *testing.T) {
+	db := newDatabase(t)
+	m := New(db)
+
+	bucket := time.Now().Truncate(time.Hour)
+	previous := bucket.Add(-time.Hour)
+
+	err := m.AddAgentUsage([]AgentUsage{
+		{ResourceId: "rid-a", Backend: "loki", Bucket: bucket, Requests: 2, Bytes: 100},
+		{ResourceId: "rid-a", Backend: "mimir", Bucket: bucket, Requests: 1, Bytes: 50},
+		{ResourceId: "rid-b", Backend: "loki", Bucket: previous, Requests: 1, Bytes: 10},
+	})
+	assert.NoError(t, err, "add usage")
+
+	err = m.AddAgentUsage([]AgentUsage{
+		{ResourceId: "rid-a", Backend: "loki", Bucket: bucket, Requests: 3, Bytes: 200},
+	})
+	assert.NoError(t, err, "add usage to existing bucket")
+
+	var usages []AgentUsage
+	_, err = m.ListAgentUsage(&usages, &AgentUsageQuery{ResourceId: "rid-a"})
+	assert.NoError(t, err, "list usage")
+	assert.Len(t, usages, 2, "usage rows")
+	assert.Equal(t, "loki", usages[0].Backend, "usage backend")
+	assert.Equal(t, int64(5), usages[0].Requests, "accumulated requests")
+	assert.Equal(t, int64(300), usages[0].Bytes, "accumulated bytes")
+
+	var sums []AgentUsage
+	_, err = m.SumAgentUsage(&sums, &AgentUsageQuery{Since: &bucket})
+	assert.NoError(t, err, "sum usage")
+	assert.Len(t, sums, 2, "summed rows since bucket")
+
+	deleted, err := m.DeleteAgentUsageBefore(bucket)
+	assert.NoError(t, err, "delete old usage")
+	assert.Equal(t, int64(1), deleted, "deleted rows")
+}
@@ -0,0 +1,108 @@
+/*
+Copyright (c) Tobias Schäfer. All rights reserved.
+Licensed under the MIT License, see LICENSE file in the project root for details.
+*/
+package model
+
+import (
+	"reflect"
+	"strings"
+	"time"
+)
+
+type AuditChange struct {
+	Before any `json:"before"`
+	After  any `json:"after"`
+}
+
+type AuditEvent struct {
+	ID         uint                   `gorm:"primarykey" json:"id"`
+	CreatedAt  time.Time              `gorm:"index:idx_audit_events_created_at" json:"created_at"`
+	Actor      string                 `gorm:"not null;index:idx_audit_events_actor" json:"actor"`
+	Action     string                 `gorm:"not null;index:idx_audit_events_action" json:"action"`
+	ResourceId string                 `gorm:"index:idx_audit_events_resource_id" json:"resource_id"`
+	Diff       map[string]AuditChange `gorm:"serializer:json" json:"diff"`
+	SourceIp   string                 `json:"source_ip"`
+}
+
+type AuditQuery struct {
+	Since      *time.Time
+	Until      *time.Time
+	Actor      string
+	Action     string
+	ResourceId string
+	Limit      int
+}
+
+func (m *Model) CreateAuditEvent(event *AuditEvent) (*AuditEvent, error) {
+	if err := m.db.Create(event).Error; err != nil {
+		return nil, err
+	}
+
+	return event, nil
+}
+
+func (m *Model) ListAuditEvents(events *[]AuditEvent, query *AuditQuery) (*[]AuditEvent, error) {
+	tx := m.db.Order("created_at DESC, id DESC")
+	if query.Since != nil {
+		tx = tx.Where("created_at >= ?", query.Since.UTC())
+	}
+	if query.Until != nil {
+		tx = tx.Where("created_at < ?", query.Until.UTC())
+	}
+	if query.Actor != "" {
+		tx = tx.Where("actor = ?", query.Actor)
+	}
+	if query.Action != "" {
+		tx = tx.Where("action = ?", query.Action)
+	}
+	if query.ResourceId != "" {
+		tx = tx.Where("resource_id = ?", query.ResourceId)
+	}
+	if query.Limit > 0 {
+		tx = tx.Limit(query.Limit)
+	}
+
+	if err := tx.Find(events).Error; err != nil {
+		return nil, err
+	}
+
+	return events, nil
+}
+
+func AgentDiff(before, after *Agent) map[string]AuditChange {
+	diff := make(map[string]AuditChange)
+	if before == nil && after == nil {
+		return diff
+	}
+
+	var b, a reflect.Value
+	if before != nil {
+		b = reflect.ValueOf(before).Elem()
+	}
+	if after != nil {
+		a = reflect.ValueOf(after).Elem()
+	}
+
+	typ := reflect.TypeFor[Agent]()
+	for i := 0; i < typ.NumField(); i++ {
+		name, _, _ := strings.Cut(typ.Field(i).Tag.Get("json"), ",")
+		if name == "" || name == "-" || name == "resource_version" || name == "last_seen" {
+			continue
+		}
+
+		var change AuditChange
+		if b.IsValid() {
+			change.Before = b.Field(i).Interface()
+		}
+		if a.IsValid() {
+			change.After = a.Field(i).Interface()
+		}
+		if b.IsValid() && a.IsValid() && agentFieldEqual(change.Before, change.After) {
+			continue
+		}
+		diff[name] = change
+	}
+
+	return diff
+}
@@ -0,0 +1,84 @@
+/*
+Copyright (c) Tobias Schäfer. All rights reserved.
+Licensed under the MIT License, see LICENSE file in the project root for details.
+*/
+package model
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_CreateAuditEventPersistsDiff(t *testing.T) {
+	db := newDatabase(t)
+	m := New(db)
+
+	event, err := m.CreateAuditEvent(&AuditEvent{
+		Actor:      "cert:admin",
+		Action:     "agent.update",
+		ResourceId: "resource-123",
+		Diff:       map[string]AuditChange{"node": {Before: "unix", After: "windows"}},
+		SourceIp:   "10.0.0.1",
+	})
+	assert.NoError(t, err, "create audit event")
+	assert.NotZero(t, event.ID, "audit event id")
+
+	var events []AuditEvent
+	_, err = m.ListAuditEvents(&events, &AuditQuery{})
+	assert.NoError(t, err, "list audit events")
+	assert.Len(t, events, 1, "audit events count")
+	assert.Equal(t, "10.0.0.1", events[0].SourceIp, "audit event source ip")
+	assert.Equal(t, "unix", events[0].Diff["node"].Before, "audit event diff before")
+	assert.Equal(t, "windows", events[0].Diff["node"].After, "audit event diff after")
+}
+
+func Test_ListAuditEventsFiltersAndOrders(t *testing.T) {
+	db := newDatabase(t)
+	m := New(db)
+
+	for _, actor := range []string{"cert:alice", "cert:bob", "cert:alice"} {
+		_, err := m.CreateAuditEvent(&AuditEvent{Actor: actor, Action: "agent.register"})
+		assert.NoError(t, err, "create audit event")
+	}
+
+	var events []AuditEvent
+	_, err := m.ListAuditEvents(&events, &AuditQuery{Actor: "cert:alice"})
+	assert.NoError(t, err, "list audit events by actor")
+	assert.Len(t, events, 2, "audit events by actor count")
+	assert.Greater(t, events[0].ID, events[1].ID, "audit events newest first")
+
+	events = nil
+	_, err = m.ListAuditEvents(&events, &AuditQuery{Limit: 1})
+	assert.NoError(t, err, "list audit events with limit")
+	assert.Len(t, events, 1, "audit events limited count")
+
+	future := time.Now().Add(time.Hour)
+	events = nil
+	_, err = m.ListAuditEvents(&events, &AuditQuery{Since: &future})
+	assert.NoError(t, err, "list audit events since")
+	assert.Empty(t, events, "audit events since future")
+
+	events = nil
+	_, err = m.ListAuditEvents(&events, &AuditQuery{Until: &future})
+	assert.NoError(t, err, "list audit events until")
+	assert.Len(t, events, 3, "audit events until future")
+}
+
+func Test_AgentDiffReturnsChangedFields(t *testing.T) {
+	before := &Agent{Hostname: "test-agent", Node: "unix", Labels: []string{"env=dev"}, ResourceVersion: 1}
+	after := *before
+	after.Labels = []string{"env=prod"}
+	after.ResourceVersion = 2
+
+	diff := AgentDiff(before, &after)
+	assert.Len(t, diff, 1, "diff count")
+	assert.Equal(t, []string{"env=dev"}, diff["labels"].Before, "diff labels before")
+	assert.Equal(t, []string{"env=prod"}, diff["labels"].After, "diff labels after")
+
+	created := AgentDiff(nil, before)
+	assert.Equal(t, "test-agent", created["hostname"].After, "create diff hostname")
+	assert.Nil(t, created["hostname"].Before, "create diff before")
+	assert.NotContains(t, created, "resource_version", "create diff skips resource version")
+}
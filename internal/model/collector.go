@@ -0,0 +1,69 @@
+/*
+Copyright (c) Tobias Schäfer. All rights reserved.
+Licensed under the MIT License, see LICENSE file in the project root for details.
+*/
+package model
+
+import (
+	"errors"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type Collector struct {
+	ID          uint              `gorm:"primarykey" json:"-"`
+	CreatedAt   time.Time         `json:"-"`
+	UpdatedAt   time.Time         `json:"-"`
+	CollectorId string            `gorm:"not null;uniqueIndex:uidx_collectors_collector_id" json:"collector_id"`
+	ResourceId  string            `gorm:"not null;index:idx_collectors_resource_id" json:"resource_id"`
+	Name        string            `json:"name"`
+	Attributes  map[string]string `gorm:"serializer:json" json:"attributes"`
+	ConfigHash  string            `json:"config_hash"`
+	LastPoll    *time.Time        `gorm:"default:NULL" json:"last_poll"`
+}
+
+var (
+	ErrCollectorNotFound = errors.New("collector not found")
+)
+
+func (m *Model) SaveCollector(collector *Collector) (*Collector, error) {
+	err := m.db.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "collector_id"}},
+		DoUpdates: clause.AssignmentColumns([]string{"updated_at", "resource_id", "name", "attributes", "config_hash", "last_poll"}),
+	}).Create(collector).Error
+	if err != nil {
+		return nil, err
+	}
+
+	return collector, nil
+}
+
+func (m *Model) GetCollector(collectorId string) (*Collector, error) {
+	var collector Collector
+	if err := m.db.Where("collector_id = ?", collectorId).First(&collector).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrCollectorNotFound
+		}
+		return nil, err
+	}
+
+	return &collector, nil
+}
+
+func (m *Model) ListCollectors(collectors *[]Collector, rid string) (*[]Collector, error) {
+	if err := m.db.Where("resource_id = ?", rid).Order("collector_id").Find(collectors).Error; err != nil {
+		return nil, err
+	}
+
+	return collectors, nil
+}
+
+func (m *Model) DeleteCollector(collectorId string) error {
+	return m.db.Where("collector_id = ?", collectorId).Delete(&Collector{}).Error
+}
+
+func (m *Model) DeleteCollectors(rid string) error {
+	return m.db.Where("resource_id = ?", rid).Delete(&Collector{}).Error
+}
@@ -0,0 +1,45 @@
+/*
+Copyright (c) Tobias Schäfer. All rights reserved.
+Licensed under the MIT License, see LICENSE file in the project root for details.
+*/
+package model
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_SaveCollectorUpsertsByCollectorId(t *testing.T) {
+	db := newDatabase(t)
+	m := New(db)
+
+	_, err := m.SaveCollector(&Collector{CollectorId: "collector-1", ResourceId: "resource-123", Name: "alloy"})
+	assert.NoError(t, err, "create collector")
+
+	now := time.Now().UTC()
+	_, err = m.SaveCollector(&Collector{
+		CollectorId: "collector-1",
+		ResourceId:  "resource-123",
+		Name:        "alloy",
+		Attributes:  map[string]string{"os": "linux"},
+		ConfigHash:  "hash",
+		LastPoll:    &now,
+	})
+	assert.NoError(t, err, "update collector")
+
+	var collectors []Collector
+	_, err = m.ListCollectors(&collectors, "resource-123")
+	assert.NoError(t, err, "list collectors")
+	assert.Len(t, collectors, 1, "collectors count")
+	assert.Equal(t, "hash", collectors[0].ConfigHash, "collector config hash")
+	assert.Equal(t, "linux", collectors[0].Attributes["os"], "collector attributes")
+	assert.NotNil(t, collectors[0].LastPoll, "collector last poll")
+
+	err = m.DeleteCollectors("resource-123")
+	assert.NoError(t, err, "delete collectors")
+
+	_, err = m.GetCollector("collector-1")
+	assert.ErrorIs(t, err, ErrCollectorNotFound, "collector deleted")
+}
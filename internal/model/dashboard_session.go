@@ -0,0 +1,28 @@
+/*
+Copyright (c) Tobias Schäfer. All rights reserved.
+Licensed under the MIT License, see LICENSE file in the project root for details.
+*/
+package model
+
+import (
+	"time"
+)
+
+type DashboardSession struct {
+	ID        uint      `gorm:"primarykey" json:"-"`
+	CreatedAt time.Time `json:"-"`
+	Session   string    `gorm:"not null;uniqueIndex:uidx_dashboard_sessions_session" json:"session"`
+	Role      string    `gorm:"not null" json:"role"`
+	IssuedAt  time.Time `gorm:"not null" json:"issued_at"`
+	ExpiresAt time.Time `gorm:"not null;index:idx_dashboard_sessions_expires_at" json:"expires_at"`
+	Issuer    string    `gorm:"not null" json:"issuer"`
+	Kid       string    `gorm:"not null;default:'';index:idx_dashboard_sessions_kid" json:"kid"`
+}
+
+func (m *Model) CreateDashboardSession(session *DashboardSession) (*DashboardSession, error) {
+	if err := m.db.Create(session).Error; err != nil {
+		return nil, err
+	}
+
+	return session, nil
+}
@@ -0,0 +1,82 @@
+/*
+Copyright (c) Tobias Schäfer. All rights reserved.
+Licensed under the MIT License, see LICENSE file in the project root for details.
+*/
+package model
+
+import (
+	"errors"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+type EnrollmentToken struct {
+	ID              uint      `gorm:"primarykey" json:"-"`
+	CreatedAt       time.Time `json:"-"`
+	Jti             string    `gorm:"not null;uniqueIndex:uidx_enrollment_tokens_jti" json:"jti"`
+	IssuedAt        time.Time `gorm:"not null" json:"issued_at"`
+	ExpiresAt       time.Time `gorm:"not null" json:"expires_at"`
+	Issuer          string    `gorm:"not null" json:"issuer"`
+	UsageLimit      int       `gorm:"not null;default:1" json:"usage_limit"`
+	Uses            int       `gorm:"not null;default:0" json:"uses"`
+	HostnamePattern string    `gorm:"not null;default:''" json:"hostname_pattern"`
+	Labels          []string  `gorm:"serializer:json" json:"labels"`
+	LogSources      []string  `gorm:"serializer:json" json:"log_sources"`
+	Ephemeral       bool      `gorm:"not null;default:false" json:"ephemeral"`
+	Tenant          string    `gorm:"not null;default:'default'" json:"tenant"`
+	Kid             string    `gorm:"not null;default:''" json:"kid"`
+}
+
+var (
+	ErrEnrollmentTokenNotFound  = errors.New("enrollment token not found")
+	ErrEnrollmentTokenExhausted = errors.New("enrollment token exhausted")
+)
+
+func (m *Model) CreateEnrollmentToken(token *EnrollmentToken) (*EnrollmentToken, error) {
+	if err := m.db.Create(token).Error; err != nil {
+		return nil, err
+	}
+
+	return token, nil
+}
+
+func (m *Model) GetEnrollmentToken(jti string) (*EnrollmentToken, error) {
+	var token EnrollmentToken
+	if err := m.db.Where("jti = ?", jti).First(&token).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrEnrollmentTokenNotFound
+		}
+		return nil, err
+	}
+
+	return &token, nil
+}
+
+func (m *Model) ListEnrollmentTokens(tokens *[]EnrollmentToken) (*[]EnrollmentToken, error) {
+	if err := m.db.Order("expires_at").Find(tokens).Error; err != nil {
+		return nil, err
+	}
+
+	return tokens, nil
+}
+
+func (m *Model) ConsumeEnrollmentToken(jti string, now time.Time) error {
+	result := m.db.Model(&EnrollmentToken{}).
+		Where("jti = ? AND uses < usage_limit AND expires_at > ?", jti, now.UTC()).
+		UpdateColumn("uses", gorm.Expr("uses + 1"))
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return ErrEnrollmentTokenExhausted
+	}
+
+	return nil
+}
+
+func (m *Model) ReleaseEnrollmentToken(jti string) error {
+	return m.db.Model(&EnrollmentToken{}).
+		Where("jti = ? AND uses > 0", jti).
+		UpdateColumn("uses", gorm.Expr("uses - 1")).Error
+}
@@ -0,0 +1,42 @@
+/*
+Copyright (c) Tobias Schäfer. All rights reserved.
+Licensed under the MIT License, see LICENSE file in the project root for details.
+*/
+package model
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_ConsumeEnrollmentTokenEnforcesUsageLimitAndExpiry(t *testing.T) {
+	db := newDatabase(t)
+	m := New(db)
+
+	now := time.Now().UTC()
+	_, err := m.CreateEnrollmentToken(&EnrollmentToken{
+		Jti:        "jti-1",
+		IssuedAt:   now,
+		ExpiresAt:  now.Add(time.Hour),
+		UsageLimit: 2,
+	})
+	assert.NoError(t, err, "create enrollment token")
+
+	assert.NoError(t, m.ConsumeEnrollmentToken("jti-1", now), "first use")
+	assert.NoError(t, m.ConsumeEnrollmentToken("jti-1", now), "second use")
+	err = m.ConsumeEnrollmentToken("jti-1", now)
+	assert.ErrorIs(t, err, ErrEnrollmentTokenExhausted, "usage limit reached")
+
+	assert.NoError(t, m.ReleaseEnrollmentToken("jti-1"), "release use")
+	token, err := m.GetEnrollmentToken("jti-1")
+	assert.NoError(t, err, "get enrollment token")
+	assert.Equal(t, 1, token.Uses, "uses after release")
+
+	err = m.ConsumeEnrollmentToken("jti-1", now.Add(2*time.Hour))
+	assert.ErrorIs(t, err, ErrEnrollmentTokenExhausted, "token expired")
+
+	_, err = m.GetEnrollmentToken("unknown")
+	assert.ErrorIs(t, err, ErrEnrollmentTokenNotFound, "unknown token")
+}
@@ -4,43 +4,50 @@ Licensed under the MIT License, see LICENSE file in the project root for details
 */
 package model
 
-import "gorm.io/gorm"
+import (
+	"context"
 
-type AgentEvent struct {
-	Type string
-}
+	"gorm.io/gorm"
+)
 
 type Model struct {
-	db               *gorm.DB
-	agentEventChan   chan AgentEvent
-	agentSubscribers []chan AgentEvent
+	db          *gorm.DB
+	agentEvents *agentEventBus
+	pending     *[]func()
 }
 
 func New(db *gorm.DB) *Model {
 	return &Model{
-		db:               db,
-		agentEventChan:   make(chan AgentEvent, 100),
-		agentSubscribers: make([]chan AgentEvent, 0),
+		db:          db,
+		agentEvents: newAgentEventBus(),
 	}
 }
 
-func (m *Model) SubscribeAgentEvents() <-chan AgentEvent {
-	ch := make(chan AgentEvent, 10)
-	m.agentSubscribers = append(m.agentSubscribers, ch)
-	return ch
+func (m *Model) WithContext(ctx context.Context) *Model {
+	return &Model{
+		db:          m.db.WithContext(ctx),
+		agentEvents: m.agentEvents,
+		pending:     m.pending,
+	}
 }
 
-func (m *Model) notifyAgentEvent(eventType string) {
-	event := AgentEvent{Type: eventType}
-	select {
-	case m.agentEventChan <- event:
-	default:
+func (m *Model) Transaction(fn func(tx *Model) error) error {
+	if m.pending != nil {
+		return m.db.Transaction(func(db *gorm.DB) error {
+			return fn(&Model{db: db, agentEvents: m.agentEvents, pending: m.pending})
+		})
+	}
+
+	var pending []func()
+	err := m.db.Transaction(func(db *gorm.DB) error {
+		return fn(&Model{db: db, agentEvents: m.agentEvents, pending: &pending})
+	})
+	if err != nil {
+		return err
 	}
 
-	for _, sub := range m.agentSubscribers {
-		select {
-		case sub <- event:
-		default:
-		}
+	for _, publish := range pending {
+		publish()
 	}
+	return nil
 }
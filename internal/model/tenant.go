@@ -0,0 +1,64 @@
+/*
+Copyright (c) Tobias Schäfer. All rights reserved.
+Licensed under the MIT License, see LICENSE file in the project root for details.
+*/
+package model
+
+import (
+	"errors"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+type Tenant struct {
+	ID          uint      `gorm:"primarykey" json:"-"`
+	CreatedAt   time.Time `json:"created_at"`
+	Name        string    `gorm:"not null;uniqueIndex:uidx_tenants_name" json:"name"`
+	Description string    `gorm:"not null;default:''" json:"description"`
+}
+
+var (
+	ErrTenantNotFound = errors.New("tenant not found")
+)
+
+func (m *Model) CreateTenant(tenant *Tenant) (*Tenant, error) {
+	if err := m.db.Create(tenant).Error; err != nil {
+		return nil, err
+	}
+
+	return tenant, nil
+}
+
+func (m *Model) GetTenant(name string) (*Tenant, error) {
+	var tenant Tenant
+	if err := m.db.Where("name = ?", name).First(&tenant).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrTenantNotFound
+		}
+		return nil, err
+	}
+
+	return &tenant, nil
+}
+
+func (m *Model) ListTenants(tenants *[]Tenant) (*[]Tenant, error) {
+	if err := m.db.Order("name").Find(tenants).Error; err != nil {
+		return nil, err
+	}
+
+	return tenants, nil
+}
+
+func (m *Model) DeleteTenant(tenant *Tenant) error {
+	return m.db.Delete(tenant).Error
+}
+
+func (m *Model) CountTenantAgents(name string) (int64, error) {
+	var count int64
+	if err := m.db.Model(&Agent{}).Where("tenant = ?", name).Count(&count).Error; err != nil {
+		return 0, err
+	}
+
+	return count, nil
+}
@@ -0,0 +1,47 @@
+/*
+Copyright (c) Tobias Schäfer. All rights reserved.
+Licensed under the MIT License, see LICENSE file in the project root for details.
+*/
+package model
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_TenantLifecycle(t *testing.T) {
+	db := newDatabase(t)
+	m := New(db)
+
+	_, err := m.CreateTenant(&Tenant{Name: "team-a", Description: "Team A"})
+	assert.NoError(t, err, "create tenant")
+
+	_, err = m.CreateTenant(&Tenant{Name: "team-a"})
+	assert.Error(t, err, "create duplicate tenant")
+
+	tenant, err := m.GetTenant("team-a")
+	assert.NoError(t, err, "get tenant")
+	assert.Equal(t, "Team A", tenant.Description, "tenant description")
+
+	_, err = m.CreateAgent(&Agent{Hostname: "host-a", ResourceId: "rid-a", Tenant: "team-a"})
+	assert.NoError(t, err, "create agent in tenant")
+	_, err = m.CreateAgent(&Agent{Hostname: "host-b", ResourceId: "rid-b"})
+	assert.NoError(t, err, "create agent in default tenant")
+
+	count, err := m.CountTenantAgents("team-a")
+	assert.NoError(t, err, "count tenant agents")
+	assert.Equal(t, int64(1), count, "tenant agents")
+
+	var agents []Agent
+	_, err = m.QueryAgents(&agents, &AgentQuery{Tenant: "default"})
+	assert.NoError(t, err, "query agents by tenant")
+	assert.Len(t, agents, 1, "default tenant agents")
+	assert.Equal(t, "host-b", agents[0].Hostname, "default tenant agent")
+
+	err = m.DeleteTenant(tenant)
+	assert.NoError(t, err, "delete tenant")
+
+	_, err = m.GetTenant("team-a")
+	assert.ErrorIs(t, err, ErrTenantNotFound, "tenant deleted")
+}
@@ -0,0 +1,123 @@
+/*
+Copyright (c) Tobias Schäfer. All rights reserved.
+Licensed under the MIT License, see LICENSE file in the project root for details.
+*/
+package tracing
+
+import (
+	"context"
+	"log/slog"
+	"net/http"
+
+	"go.opentelemetry.io/contrib/instrumentation/net/http/otelhttp"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	semconv "go.opentelemetry.io/otel/semconv/v1.37.0"
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/tschaefer/finch/internal/config"
+	"github.com/tschaefer/finch/internal/version"
+)
+
+const (
+	instrumentationName = "github.com/tschaefer/finch"
+	serviceName         = "finch"
+	defaultSampleRatio  = 1.0
+)
+
+type Tracer struct {
+	endpoint    string
+	sampleRatio float64
+	provider    *sdktrace.TracerProvider
+}
+
+func New(cfg *config.Config) *Tracer {
+	tracing := cfg.Tracing()
+	slog.Debug("Initializing OpenTelemetry tracer", "endpoint", tracing.Endpoint, "sampleRatio", tracing.SampleRatio)
+
+	sampleRatio := tracing.SampleRatio
+	if sampleRatio == 0 {
+		sampleRatio = defaultSampleRatio
+	}
+
+	return &Tracer{
+		endpoint:    tracing.Endpoint,
+		sampleRatio: sampleRatio,
+	}
+}
+
+func (t *Tracer) Start(ctx context.Context) error {
+	slog.Debug("Starting OpenTelemetry tracer", "endpoint", t.endpoint)
+
+	otel.SetTextMapPropagator(propagation.NewCompositeTextMapPropagator(
+		propagation.TraceContext{},
+		propagation.Baggage{},
+	))
+
+	if t.endpoint == "" {
+		return nil
+	}
+
+	exporter, err := otlptracehttp.New(ctx, otlptracehttp.WithEndpointURL(t.endpoint))
+	if err != nil {
+		return err
+	}
+
+	res, err := resource.Merge(resource.Default(), resource.NewWithAttributes(
+		semconv.SchemaURL,
+		semconv.ServiceName(serviceName),
+		semconv.ServiceVersion(version.Release()),
+	))
+	if err != nil {
+		return err
+	}
+
+	t.provider = sdktrace.NewTracerProvider(
+		sdktrace.WithBatcher(exporter),
+		sdktrace.WithResource(res),
+		sdktrace.WithSampler(sdktrace.ParentBased(sdktrace.TraceIDRatioBased(t.sampleRatio))),
+	)
+	otel.SetTracerProvider(t.provider)
+
+	return nil
+}
+
+func (t *Tracer) Stop(ctx context.Context) error {
+	slog.Debug("Stopping OpenTelemetry tracer")
+
+	if t.provider == nil {
+		return nil
+	}
+
+	return t.provider.Shutdown(ctx)
+}
+
+func LogAttrs(ctx context.Context) []any {
+	spanContext := trace.SpanContextFromContext(ctx)
+	if !spanContext.IsValid() {
+		return nil
+	}
+
+	return []any{
+		slog.String("trace_id", spanContext.TraceID().String()),
+		slog.String("span_id", spanContext.SpanID().String()),
+	}
+}
+
+func StartSpan(ctx context.Context, name string, opts ...trace.SpanStartOption) (context.Context, trace.Span) {
+	return otel.Tracer(instrumentationName).Start(ctx, name, opts...)
+}
+
+func HTTPHandler(handler http.Handler, operation string) http.Handler {
+	return otelhttp.NewHandler(handler, operation, otelhttp.WithSpanNameFormatter(httpSpanName))
+}
+
+func httpSpanName(operation string, r *http.Request) string {
+	if r.Pattern == "" {
+		return operation
+	}
+	return r.Method + " " + r.Pattern
+}
@@ -0,0 +1,152 @@
+/*
+Copyright (c) Tobias Schäfer. All rights reserved.
+Licensed under the MIT License, see LICENSE file in the project root for details.
+*/
+package tracing
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/propagation"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
+	coltracepb "go.opentelemetry.io/proto/otlp/collector/trace/v1"
+	"google.golang.org/protobuf/proto"
+
+	"github.com/tschaefer/finch/internal/config"
+)
+
+type collector struct {
+	mu    sync.Mutex
+	spans map[string]string
+}
+
+func (c *collector) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.URL.Path != "/v1/traces" {
+		http.NotFound(w, r)
+		return
+	}
+
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	var req coltracepb.ExportTraceServiceRequest
+	if err := proto.Unmarshal(body, &req); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for _, resourceSpans := range req.ResourceSpans {
+		service := ""
+		for _, attr := range resourceSpans.Resource.GetAttributes() {
+			if attr.Key == "service.name" {
+				service = attr.Value.GetStringValue()
+			}
+		}
+		for _, scopeSpans := range resourceSpans.ScopeSpans {
+			for _, span := range scopeSpans.Spans {
+				c.spans[span.Name] = service
+			}
+		}
+	}
+
+	w.Header().Set("Content-Type", "application/x-protobuf")
+	w.WriteHeader(http.StatusOK)
+}
+
+func restoreGlobals(t *testing.T) {
+	previousProvider, previousPropagator := otel.GetTracerProvider(), otel.GetTextMapPropagator()
+	t.Cleanup(func() {
+		otel.SetTracerProvider(previousProvider)
+		otel.SetTextMapPropagator(previousPropagator)
+	})
+}
+
+func TestTracerExportsSpansToCollector(t *testing.T) {
+	restoreGlobals(t)
+
+	stub := &collector{spans: make(map[string]string)}
+	server := httptest.NewServer(stub)
+	defer server.Close()
+
+	cfg := config.NewFromData(&config.Data{
+		Tracing: &config.Tracing{Endpoint: server.URL},
+	}, "")
+
+	tracer := New(cfg)
+	assert.NoError(t, tracer.Start(context.Background()))
+
+	_, span := StartSpan(context.Background(), "test.span")
+	span.End()
+
+	assert.NoError(t, tracer.Stop(context.Background()))
+
+	stub.mu.Lock()
+	defer stub.mu.Unlock()
+	assert.Equal(t, map[string]string{"test.span": serviceName}, stub.spans)
+}
+
+func TestTracerWithoutEndpointOnlyPropagates(t *testing.T) {
+	restoreGlobals(t)
+
+	cfg := config.NewFromData(&config.Data{}, "")
+
+	tracer := New(cfg)
+	assert.NoError(t, tracer.Start(context.Background()))
+	assert.Nil(t, tracer.provider)
+
+	header := http.Header{}
+	header.Set("Traceparent", "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01")
+	ctx := otel.GetTextMapPropagator().Extract(context.Background(), propagation.HeaderCarrier(header))
+
+	args := LogAttrs(ctx)
+	assert.Len(t, args, 2)
+	assert.Equal(t, slog.String("trace_id", "4bf92f3577b34da6a3ce929d0e0e4736"), args[0])
+	assert.Equal(t, slog.String("span_id", "00f067aa0ba902b7"), args[1])
+
+	assert.NoError(t, tracer.Stop(context.Background()))
+}
+
+func TestLogAttrsWithoutSpan(t *testing.T) {
+	assert.Nil(t, LogAttrs(context.Background()))
+}
+
+func TestHTTPHandlerNamesSpansByPattern(t *testing.T) {
+	restoreGlobals(t)
+
+	exporter := tracetest.NewInMemoryExporter()
+	otel.SetTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSyncer(exporter)))
+
+	var inner trace.SpanContext
+	mux := http.NewServeMux()
+	mux.HandleFunc("/agent/config", func(w http.ResponseWriter, r *http.Request) {
+		inner = trace.SpanContextFromContext(r.Context())
+		w.WriteHeader(http.StatusNoContent)
+	})
+	handler := HTTPHandler(mux, "finch.http")
+
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/agent/config", nil))
+	rec = httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/unknown", nil))
+
+	spans := exporter.GetSpans()
+	assert.Len(t, spans, 2)
+	assert.Equal(t, "GET /agent/config", spans[0].Name)
+	assert.Equal(t, inner.SpanID(), spans[0].SpanContext.SpanID())
+	assert.Equal(t, "finch.http", spans[1].Name)
+}